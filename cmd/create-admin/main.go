@@ -0,0 +1,111 @@
+// Command create-admin creates the first admin account of a new
+// installation, or of a new organization. Later admins are invited through
+// the API.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/riparuk/meet-book-api/internal/config"
+	"github.com/riparuk/meet-book-api/internal/database"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"github.com/riparuk/meet-book-api/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+func main() {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	email := fs.String("email", "", "email of the admin (required)")
+	name := fs.String("name", "Administrator", "name of the admin")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin instead of generating one")
+	force := fs.Bool("force", false, "create the admin even if one already exists")
+	orgSlug := fs.String("org", model.DefaultOrganizationSlug, "slug of the organization the admin belongs to")
+	cfg, err := config.Load(fs, os.Args[1:])
+	if err != nil {
+		log.Fatalf("❌ Failed to load configuration: %v", err)
+	}
+	logger.Init(cfg.Log.Level, cfg.Log.Format)
+
+	if *email == "" {
+		log.Fatal("❌ -email is required")
+	}
+
+	database.Init(cfg.Database)
+	org, err := repository.NewOrganizationRepository(database.DB).WithContext(context.Background()).FindBySlug(*orgSlug)
+	if err != nil {
+		log.Fatalf("❌ Organization %s not found, create it with create-organization", *orgSlug)
+	}
+	// The admin shows up in the audit log as created by the system
+	users := repository.NewAuditedUserRepository(repository.NewUserRepository(database.DB), repository.NewAuditLogRepository(database.DB)).
+		WithContext(tenant.WithContext(context.Background(), org.ID))
+
+	if _, err := users.FindByEmail(*email); err == nil {
+		log.Fatalf("❌ A user with email %s already exists", *email)
+	}
+	if !*force {
+		all, err := users.FindAll()
+		if err != nil {
+			log.Fatalf("❌ Failed to list users: %v", err)
+		}
+		for _, u := range all {
+			if u.Role == model.RoleAdmin {
+				log.Fatalf("❌ An admin already exists (%s), invite further admins with POST /api/invitations or pass -force", u.Email)
+			}
+		}
+	}
+
+	password, generated, err := readPassword(*passwordStdin)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Fatalf("❌ Failed to hash password: %v", err)
+	}
+
+	admin := model.User{
+		Name:     *name,
+		Email:    *email,
+		Password: string(hashed),
+		Role:     model.RoleAdmin,
+		Status:   model.UserStatusActive,
+	}
+	if err := users.Create(&admin); err != nil {
+		log.Fatalf("❌ Failed to create admin: %v", err)
+	}
+
+	fmt.Printf("✅ Created admin %s (%s) in %s\n", admin.Email, admin.ID, org.Name)
+	if generated {
+		fmt.Printf("🔑 Password: %s\n", password)
+		fmt.Println("   It is shown only once, store it or change it after signing in.")
+	}
+}
+
+// readPassword reads the password from stdin, or generates one
+func readPassword(fromStdin bool) (password string, generated bool, err error) {
+	if !fromStdin {
+		token, _, err := utils.GenerateOpaqueToken()
+		return token, true, err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", false, fmt.Errorf("failed to read password from stdin: %w", err)
+	}
+	password = strings.TrimRight(line, "\r\n")
+	if len(password) < minPasswordLength {
+		return "", false, fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	return password, false, nil
+}
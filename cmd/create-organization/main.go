@@ -0,0 +1,56 @@
+// Command create-organization adds an organization to the installation.
+// Its first admin is created with create-admin -org.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/riparuk/meet-book-api/internal/config"
+	"github.com/riparuk/meet-book-api/internal/database"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+)
+
+func main() {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	name := fs.String("name", "", "name of the organization (required)")
+	slug := fs.String("slug", "", "short unique identifier of the organization (required)")
+	domain := fs.String("domain", "", "host requests for the organization arrive on, such as acme.meetbook.example")
+	cfg, err := config.Load(fs, os.Args[1:])
+	if err != nil {
+		log.Fatalf("❌ Failed to load configuration: %v", err)
+	}
+	logger.Init(cfg.Log.Level, cfg.Log.Format)
+
+	if *name == "" || *slug == "" {
+		log.Fatal("❌ -name and -slug are required")
+	}
+
+	database.Init(cfg.Database)
+	orgs := repository.NewOrganizationRepository(database.DB).WithContext(context.Background())
+
+	if _, err := orgs.FindBySlug(*slug); err == nil {
+		log.Fatalf("❌ An organization with slug %s already exists", *slug)
+	}
+
+	org := model.Organization{Name: *name, Slug: *slug}
+	if *domain != "" {
+		host := strings.ToLower(strings.TrimSpace(*domain))
+		if _, err := orgs.FindByDomain(host); err == nil {
+			log.Fatalf("❌ Domain %s is already used by another organization", host)
+		}
+		org.Domain = &host
+	}
+	if err := orgs.Create(&org); err != nil {
+		log.Fatalf("❌ Failed to create organization: %v", err)
+	}
+
+	fmt.Printf("✅ Created organization %s (%s)\n", org.Name, org.ID)
+	fmt.Printf("   Create its first admin with create-admin -org %s\n", org.Slug)
+}
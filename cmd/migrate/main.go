@@ -1,23 +1,26 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"strings"
 
+	"github.com/riparuk/meet-book-api/internal/config"
 	"github.com/riparuk/meet-book-api/internal/database"
-	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/logger"
 )
 
-var allModels = []interface{}{
-	&model.User{},
-	&model.Room{},
-	&model.Booking{},
-}
-
 func main() {
-	database.InitPostgres()
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	clean := fs.Bool("clean", false, "drop all tables before migrating")
+	cfg, err := config.Load(fs, os.Args[1:])
+	if err != nil {
+		log.Fatalf("❌ Failed to load configuration: %v", err)
+	}
+	logger.Init(cfg.Log.Level, cfg.Log.Format)
+
+	database.Init(cfg.Database)
 
 	// Test database connection
 	sqlDB, err := database.DB.DB()
@@ -32,40 +35,19 @@ func main() {
 		log.Fatalf("❌ Database ping failed: %v", err)
 	}
 
-	// Get database name for logging
-	var dbName string
-	err = database.DB.Raw("SELECT current_database()").Scan(&dbName).Error
-	if err != nil {
-		log.Fatalf("❌ Failed to get database name: %v", err)
-	}
-	fmt.Printf("🔍 Connected to database: %s\n", dbName)
-
-	// Check if extension exists
-	var extExists bool
-	err = database.DB.Raw("SELECT 1 FROM pg_extension WHERE extname = 'uuid-ossp'").Scan(&extExists).Error
-	if err != nil {
-		log.Fatalf("❌ Failed to check for uuid-ossp extension: %v", err)
-	}
-
-	if !extExists {
-		fmt.Println("🔧 Creating uuid-ossp extension...")
-		err = database.DB.Exec("CREATE EXTENSION \"uuid-ossp\"").Error
+	if database.IsPostgres(database.DB) {
+		// Get database name for logging
+		var dbName string
+		err = database.DB.Raw("SELECT current_database()").Scan(&dbName).Error
 		if err != nil {
-			log.Fatalf("❌ Failed to create uuid-ossp extension: %v", err)
+			log.Fatalf("❌ Failed to get database name: %v", err)
 		}
+		fmt.Printf("🔍 Connected to database: %s\n", dbName)
 	}
 
-	clean := false
-	for _, arg := range os.Args {
-		if strings.ToLower(arg) == "--clean" {
-			clean = true
-			break
-		}
-	}
-
-	if clean {
+	if *clean {
 		fmt.Println("⚠️  Dropping all tables...")
-		err = database.DB.Migrator().DropTable(allModels...)
+		err = database.DropAll(database.DB)
 		if err != nil {
 			log.Fatalf("❌ Failed to drop tables: %v", err)
 		}
@@ -74,17 +56,13 @@ func main() {
 
 	// Migrate schema
 	fmt.Println("🔄 Running database migrations...")
-	err = database.DB.AutoMigrate(allModels...)
+	err = database.Migrate(database.DB)
 	if err != nil {
 		log.Fatalf("❌ Migration failed: %v", err)
 	}
 
 	// Verify tables were created
-	var tables []string
-	err = database.DB.Raw(
-		`SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'`).
-		Pluck("table_name", &tables).Error
-
+	tables, err := database.DB.Migrator().GetTables()
 	if err != nil {
 		log.Fatalf("❌ Failed to list tables: %v", err)
 	}
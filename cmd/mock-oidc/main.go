@@ -0,0 +1,33 @@
+// Command mock-oidc runs a local OpenID Connect provider that signs every
+// login in as one configured user, for trying single sign-on without a
+// real identity provider.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/riparuk/meet-book-api/internal/oidc/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer URL, must match OIDC_ISSUER_URL")
+	subject := flag.String("sub", "mock-user", "subject of the signed in user")
+	email := flag.String("email", "sso.user@example.com", "email of the signed in user")
+	name := flag.String("name", "SSO User", "name of the signed in user")
+	groups := flag.String("groups", "", "comma separated groups, omitted from the ID token when empty")
+	flag.Parse()
+
+	user := oidctest.User{Subject: *subject, Email: *email, EmailVerified: true, Name: *name}
+	if *groups != "" {
+		user.Groups = strings.Split(*groups, ",")
+	}
+
+	log.Printf("🔑 Mock OIDC provider %s signing in %s", *issuer, *email)
+	if err := http.ListenAndServe(*addr, oidctest.New(*issuer, user)); err != nil {
+		log.Fatalf("❌ Mock OIDC provider stopped: %v", err)
+	}
+}
@@ -1,13 +1,24 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
 
+	"github.com/riparuk/meet-book-api/internal/config"
 	"github.com/riparuk/meet-book-api/internal/database"
+	"github.com/riparuk/meet-book-api/internal/logger"
 )
 
 func main() {
-	database.InitPostgres()
+	cfg, err := config.Load(flag.NewFlagSet(os.Args[0], flag.ExitOnError), os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	logger.Init(cfg.Log.Level, cfg.Log.Format)
+
+	database.Init(cfg.Database)
 	database.Seed()
 
 	fmt.Println("Seeding completed")
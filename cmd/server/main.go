@@ -1,18 +1,38 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
+	// Embedded zone data, so user timezones validate on hosts without it
+	_ "time/tzdata"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	"github.com/riparuk/meet-book-api/docs"
+	"github.com/riparuk/meet-book-api/internal/config"
 	"github.com/riparuk/meet-book-api/internal/database"
+	"github.com/riparuk/meet-book-api/internal/handler"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/metrics"
+	"github.com/riparuk/meet-book-api/internal/middleware"
+	"github.com/riparuk/meet-book-api/internal/ratelimit"
+	"github.com/riparuk/meet-book-api/internal/repository"
 	"github.com/riparuk/meet-book-api/internal/router"
+	"github.com/riparuk/meet-book-api/internal/tracing"
+	"github.com/riparuk/meet-book-api/internal/utils"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // @title Meet Book API
@@ -30,32 +50,228 @@ import (
 // @license.url http://www.apache.org/licenses/LICENSE-2.0.html
 
 func init() {
-	_ = godotenv.Load(".env") // Load file .env
-
-	docs.SwaggerInfo.Host = os.Getenv("SWAGGER_HOST")                // misalnya: "localhost:8080" atau "meet-book-api-api.a.run.app"
-	docs.SwaggerInfo.Schemes = []string{os.Getenv("SWAGGER_SCHEME")} // atau "http" untuk lokal
+	// Route gin's own debug output through slog
+	gin.DebugPrintRouteFunc = func(method, path, handler string, handlers int) {
+		slog.Debug("route registered", "method", method, "path", path, "handler", handler)
+	}
+	gin.DebugPrintFunc = func(format string, values ...interface{}) {
+		slog.Debug(strings.TrimSpace(fmt.Sprintf(format, values...)))
+	}
 }
 
-func CORSMiddleware() gin.HandlerFunc {
+func CORSMiddleware(cfg config.CORSConfig) gin.HandlerFunc {
 	return cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+		AllowOrigins:     cfg.AllowedOrigins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader},
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
 	})
 }
 
+// newLimiter builds the auth rate limiter on the configured store and
+// starts the worker that drops expired counters
+func newLimiter(ctx context.Context, workers *sync.WaitGroup, cfg config.RateLimitConfig) *ratelimit.Limiter {
+	var store interface {
+		ratelimit.Store
+		Cleanup(ctx context.Context, interval time.Duration)
+	}
+	if cfg.Store == "database" {
+		store = ratelimit.NewDatabaseStore(database.DB)
+	} else {
+		store = ratelimit.NewMemoryStore()
+	}
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		store.Cleanup(ctx, time.Minute)
+	}()
+
+	return ratelimit.New(store, ratelimit.Policy{
+		Window:      cfg.Window,
+		Threshold:   cfg.LockoutThreshold,
+		BaseLockout: cfg.LockoutBase,
+		MaxLockout:  cfg.LockoutMax,
+	})
+}
+
+// configureJWT signs tokens with the configured keys, falling back to the
+// shared secret when there are none
+func configureJWT(cfg config.AuthConfig) error {
+	opts := utils.JWTOptions{
+		Secret:   cfg.JWTSecret,
+		Issuer:   cfg.JWTIssuer,
+		Audience: cfg.JWTAudience,
+		TTL:      cfg.AccessTokenTTL,
+	}
+	if len(cfg.JWTSigningKeys) == 0 {
+		slog.Info("signing tokens with JWT_SECRET, set JWT_SIGNING_KEYS so other services can verify them")
+		utils.ConfigureJWT(opts)
+		return nil
+	}
+
+	keys, err := utils.LoadKeySet(cfg.JWTSigningKeys)
+	if err != nil {
+		return err
+	}
+	opts.Keys = keys
+	utils.ConfigureJWT(opts)
+	slog.Info("signing tokens with asymmetric keys", "kid", keys.SigningKeyID(), "keys", len(keys.JWKS().Keys))
+	return nil
+}
+
 func main() {
-	database.InitPostgres()
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	dumpConfig := fs.Bool("dump-config", false, "print the effective configuration with secrets redacted and exit")
+	cfg, err := config.Load(fs, os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *dumpConfig {
+		if err := cfg.Dump(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to dump configuration: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logger.Init(cfg.Log.Level, cfg.Log.Format)
+	if err := cfg.Validate(); err != nil {
+		slog.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
+	if os.Getenv("MASTER_PASSWORD") != "" {
+		slog.Warn("MASTER_PASSWORD is no longer supported and is ignored, invite admins with POST /api/invitations")
+	}
+
+	if err := configureJWT(cfg.Auth); err != nil {
+		slog.Error("failed to load JWT signing keys", "error", err)
+		os.Exit(1)
+	}
+	docs.SwaggerInfo.Host = cfg.Swagger.Host // misalnya: "localhost:8080" atau "meet-book-api-api.a.run.app"
+	if cfg.Swagger.Scheme != "" {
+		docs.SwaggerInfo.Schemes = []string{cfg.Swagger.Scheme} // atau "http" untuk lokal
+	}
+
+	// Cancelled on SIGINT or SIGTERM to start the graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	r := gin.Default()
+	shutdownTracing, err := tracing.Init(ctx)
+	if err != nil {
+		slog.Error("failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
 
-	r.Use(CORSMiddleware())
-	router.SetupRoutes(r)
+	database.Init(cfg.Database)
+	if err := metrics.InstrumentDB(database.DB); err != nil {
+		slog.Error("failed to instrument database", "error", err)
+		os.Exit(1)
+	}
+	if err := tracing.InstrumentDB(database.DB); err != nil {
+		slog.Error("failed to instrument database", "error", err)
+		os.Exit(1)
+	}
+
+	// Background workers stop when workerCtx is cancelled during shutdown
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		metrics.RefreshActiveBookings(workerCtx, repository.NewBookingRepository(database.DB), 30*time.Second)
+	}()
+	limiter := newLimiter(workerCtx, &workers, cfg.RateLimit)
+
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		slog.Error("invalid trusted proxies", "error", err)
+		os.Exit(1)
+	}
+
+	r.Use(otelgin.Middleware(tracing.ServiceName()))
+	r.Use(middleware.RequestID(), middleware.AccessLog(), middleware.Metrics(), middleware.Recovery())
+	r.Use(CORSMiddleware(cfg.CORS))
+	tracker := router.SetupRoutes(r, cfg, limiter)
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		// No-shows only become known once a booking has ended unchecked
+		tracker.Run(workerCtx, repository.NewTracedOrganizationRepository(repository.NewOrganizationRepository(database.DB)), time.Minute)
+	}()
+
+	healthHandler := handler.NewHealthHandler(database.DB)
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
+	r.GET("/.well-known/jwks.json", handler.JWKS)
+
+	if cfg.Metrics.Token != "" {
+		r.GET("/metrics", metrics.Handler(cfg.Metrics.Token))
+	} else {
+		slog.Info("METRICS_TOKEN not set, /metrics is disabled")
+	}
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	log.Println("🟢 Server running on port 8080, swagger on http://localhost:8080/swagger/index.html")
-	r.Run(":8080")
+
+	srv := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server starting", "addr", srv.Addr, "swagger", "/swagger/index.html")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		slog.Error("server stopped", "error", err)
+		exitCode = 1
+	case <-ctx.Done():
+		slog.Info("shutdown signal received, draining connections")
+	}
+	stop()
+
+	// Fail readiness first and keep serving while load balancers notice,
+	// then let in-flight requests finish within the shutdown timeout
+	healthHandler.SetDraining()
+	if exitCode == 0 && cfg.Server.DrainDelay > 0 {
+		slog.Info("readiness failing, waiting for load balancers", "delay", cfg.Server.DrainDelay)
+		time.Sleep(cfg.Server.DrainDelay)
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("graceful shutdown failed", "error", err)
+		exitCode = 1
+	}
+
+	stopWorkers()
+	workers.Wait()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Warn("failed to flush traces", "error", err)
+	}
+	if sqlDB, err := database.DB.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	slog.Info("server stopped")
+	os.Exit(exitCode)
 }
@@ -23,43 +23,89 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "Login",
-                "consumes": [
-                    "application/json"
+        "/audit-logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "List the recorded creates, updates, cancellations and deletions of users, rooms and bookings, newest first (audit.read). Each entry has the actor, the changed fields before and after, the client IP and the request ID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "audit"
                 ],
-                "summary": "Login",
+                "summary": "Query the audit log",
                 "parameters": [
                     {
-                        "description": "Email",
-                        "name": "email",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.LoginRequest"
-                        }
+                        "enum": [
+                            "user",
+                            "room",
+                            "booking"
+                        ],
+                        "type": "string",
+                        "description": "Kind of record",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the record",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user or service account that made the change",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Changes at or after this time, RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Changes before this time, RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries, 100 by default and at most 1000",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.AuditLog"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/auth/register": {
+        "/auth/forgot-password": {
             "post": {
-                "description": "Register",
+                "description": "Email a single-use password reset link. The response is the same whether or not the email is registered.",
                 "consumes": [
                     "application/json"
                 ],
@@ -69,36 +115,43 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Register",
+                "summary": "Request a password reset",
                 "parameters": [
                     {
-                        "description": "Email",
-                        "name": "email",
+                        "description": "Account email",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.RegisterRequest"
+                            "$ref": "#/definitions/model.ForgotPasswordRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too many attempts, see the Retry-After header",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/bookings": {
+        "/auth/login": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Create a new room booking",
+                "description": "Login. When two-factor authentication is enabled the response carries two_factor_required and a challenge_token to complete with /auth/login/2fa.",
                 "consumes": [
                     "application/json"
                 ],
@@ -106,52 +159,70 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "auth"
                 ],
-                "summary": "Create a new booking",
+                "summary": "Login",
                 "parameters": [
                     {
-                        "description": "Booking details",
-                        "name": "input",
+                        "description": "Email",
+                        "name": "email",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CreateBookingInput"
+                            "$ref": "#/definitions/model.LoginRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.BookingResponse"
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "403": {
+                        "description": "Account deactivated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too many attempts, see the Retry-After header",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/bookings/room/{room_id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/login/2fa": {
+            "post": {
+                "description": "Exchange the challenge token from /auth/login and an authenticator or recovery code for an access token",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Get a list of all bookings for a specific room",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "auth"
                 ],
-                "summary": "Get bookings for a specific room",
+                "summary": "Complete a two-factor login",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Room ID",
-                        "name": "room_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Challenge token and code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.LoginTwoFactorRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -159,201 +230,200 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "properties": {
-                                "data": {
-                                    "type": "array",
-                                    "items": {
-                                        "$ref": "#/definitions/model.BookingResponse"
-                                    }
-                                }
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Account deactivated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too many attempts, see the Retry-After header",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/bookings/room/{room_id}/{date}": {
+        "/auth/oidc/callback": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a list of all bookings for a specific room on a specific date with optional status filter",
+                "description": "Callback from the OpenID Connect provider. Links the account by email or creates it, then returns an access token, or redirects to the configured frontend page with the token in the URL fragment.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "auth"
                 ],
-                "summary": "Get bookings for a specific room on a specific date",
+                "summary": "Complete single sign-on",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Room ID",
-                        "name": "room_id",
-                        "in": "path",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Date (format: YYYY-MM-DD)",
-                        "name": "date",
-                        "in": "path",
+                        "description": "State",
+                        "name": "state",
+                        "in": "query",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by status (e.g., 'active', 'cancelled')",
-                        "name": "status",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of bookings",
+                        "description": "OK",
                         "schema": {
                             "type": "object",
-                            "properties": {
-                                "data": {
-                                    "type": "array",
-                                    "items": {
-                                        "$ref": "#/definitions/model.BookingResponse"
-                                    }
-                                }
-                            }
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Invalid room ID or date format",
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
-                            "properties": {
-                                "error": {
-                                    "type": "string"
-                                }
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Failed to fetch room bookings",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "properties": {
-                                "error": {
-                                    "type": "string"
-                                }
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/bookings/upcoming": {
+        "/auth/oidc/login": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get a list of all upcoming bookings",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Redirect to the OpenID Connect provider. Only available when OIDC is configured.",
                 "tags": [
-                    "bookings"
+                    "auth"
                 ],
-                "summary": "Get upcoming bookings",
+                "summary": "Start single sign-on",
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.BookingResponse"
-                            }
-                        }
+                    "302": {
+                        "description": "Found"
                     }
                 }
             }
         },
-        "/bookings/users/{user_id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/register": {
+            "post": {
+                "description": "Register an unverified account and email a verification link. Unverified users can sign in but not book rooms. With an invitation_token the account gets the invitation's role and needs no verification.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Get all bookings for a specific user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "auth"
                 ],
-                "summary": "Get all bookings for a user",
+                "summary": "Register",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Email",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RegisterRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.BookingResponse"
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many attempts, see the Retry-After header",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             }
         },
-        "/bookings/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/reset-password": {
+            "post": {
+                "description": "Set a new password with a reset token. All existing sessions of the user are revoked.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Get a booking by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "auth"
                 ],
-                "summary": "Get a booking by ID",
+                "summary": "Reset a password",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Booking ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Reset token and new password",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ResetPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.BookingResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update an existing booking",
+            }
+        },
+        "/auth/verify-email": {
+            "post": {
+                "description": "Confirm the email address of an account, or a new address set with PATCH /me, with the token from the verification email",
                 "consumes": [
                     "application/json"
                 ],
@@ -361,24 +431,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "bookings"
+                    "auth"
                 ],
-                "summary": "Update a booking",
+                "summary": "Verify an email address",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Booking ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Booking update details",
+                        "description": "Verification token",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.UpdateBookingInput"
+                            "$ref": "#/definitions/model.VerifyEmailRequest"
                         }
                     }
                 ],
@@ -386,136 +449,159 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.BookingResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "New email registered to another account meanwhile",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/bookings/{id}/cancel": {
+        "/bookings": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Cancel an existing booking",
+                "description": "Create a new room booking. Booking for another user needs a delegation from them or bookings.manage_any for the room. The organizer and the caller are emailed. Restricted rooms need both the caller and the booked user on the room's access list, unless the caller has rooms.access_all for it or is a service account.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "bookings"
                 ],
-                "summary": "Cancel a booking",
+                "summary": "Create a new booking",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Booking ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Booking details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateBookingInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/model.BookingResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/me": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get the authenticated user's profile",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "me"
-                ],
-                "summary": "Get current user profile",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "403": {
+                        "description": "Restricted room or another user's booking",
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/me/bookings": {
-            "get": {
+        "/bookings/batch": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a list of all bookings for the currently authenticated user",
+                "description": "Create bookings across rooms and times in a single transaction, for the authenticated user or for user_id. Booking for another user needs a delegation from them or bookings.manage_any for each room. Either all bookings are created or none are.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "me"
+                    "bookings"
                 ],
-                "summary": "Get current user's bookings",
+                "summary": "Create several bookings at once",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Filter by status (e.g., 'active', 'cancelled')",
-                        "name": "status",
-                        "in": "query"
+                        "description": "Batch booking details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateBatchBookingInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "List of user's bookings",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "type": "object",
                             "properties": {
                                 "data": {
-                                    "type": "array",
-                                    "items": {
-                                        "$ref": "#/definitions/model.BookingResponse"
-                                    }
+                                    "$ref": "#/definitions/model.BookingGroupResponse"
                                 }
                             }
                         }
                     },
                     "400": {
-                        "description": "Invalid user ID",
-                        "schema": {
-                            "type": "object",
-                            "properties": {
-                                "error": {
-                                    "type": "string"
-                                }
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                        "description": "Invalid items",
                         "schema": {
                             "type": "object",
                             "properties": {
+                                "conflicts": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.BatchBookingConflict"
+                                    }
+                                },
                                 "error": {
                                     "type": "string"
                                 }
                             }
                         }
                     },
-                    "500": {
-                        "description": "Failed to fetch bookings",
+                    "409": {
+                        "description": "Unavailable items",
                         "schema": {
                             "type": "object",
                             "properties": {
+                                "conflicts": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.BatchBookingConflict"
+                                    }
+                                },
                                 "error": {
                                     "type": "string"
                                 }
@@ -523,120 +609,124 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/bookings/groups/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new room booking for the currently authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a group of bookings created together in a batch, another user's group needs a delegation from them or bookings.manage_any for each room",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "me"
+                    "bookings"
                 ],
-                "summary": "Create a new booking for the authenticated user",
+                "summary": "Get a booking group",
                 "parameters": [
                     {
-                        "description": "Booking details",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CreateMyBookingInput"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/model.BookingResponse"
-                        }
+                        "type": "string",
+                        "description": "Booking group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
-                }
-            }
-        },
-        "/rooms": {
-            "get": {
-                "description": "Get a list of all meeting rooms",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "rooms"
                 ],
-                "summary": "Get all rooms",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Room"
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/model.BookingGroupResponse"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Missing booking group, or one the caller cannot see",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
-            },
+            }
+        },
+        "/bookings/groups/{id}/cancel": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new meeting room",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Cancel every active booking in a booking group, another user's group needs a delegation from them or bookings.manage_any for each room",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "bookings"
                 ],
-                "summary": "Create a new room",
+                "summary": "Cancel a booking group",
                 "parameters": [
                     {
-                        "description": "Room details",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CreateRoomInput"
-                        }
+                        "type": "string",
+                        "description": "Booking group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Room"
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/model.BookingGroupResponse"
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Another user's booking group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/rooms/{id}": {
+        "/bookings/room/{room_id}": {
             "get": {
-                "description": "Get a room by its ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of all bookings for a specific room",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "bookings"
                 ],
-                "summary": "Get a room by ID",
+                "summary": "Get bookings for a specific room",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Room ID",
-                        "name": "id",
+                        "name": "room_id",
                         "in": "path",
                         "required": true
                     }
@@ -645,122 +735,255 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Room"
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.BookingResponse"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Restricted room",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/bookings/room/{room_id}/{date}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update an existing room",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a list of all bookings for a specific room on a specific date with optional status filter",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "bookings"
                 ],
-                "summary": "Update a room",
+                "summary": "Get bookings for a specific room on a specific date",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Room ID",
-                        "name": "id",
+                        "name": "room_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Room details",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Date (format: YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status (e.g., 'active', 'cancelled')",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of bookings",
                         "schema": {
-                            "$ref": "#/definitions/model.UpdateRoomInput"
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.BookingResponse"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid room ID or date format",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Restricted room",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch room bookings",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/bookings/upcoming": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of all upcoming bookings, leaving out restricted rooms the caller is not on the access list of",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
                 ],
+                "summary": "Get upcoming bookings",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Room"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.BookingResponse"
+                            }
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/bookings/users/{user_id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a room by ID",
+                "description": "Get all bookings for a specific user, another user's bookings are listed only in rooms where the caller has a delegation from them or bookings.manage_any",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "bookings"
                 ],
-                "summary": "Delete a room",
+                "summary": "Get all bookings for a user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Room ID",
-                        "name": "id",
+                        "description": "User ID",
+                        "name": "user_id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.BookingResponse"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/users": {
+        "/bookings/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all users",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a booking by its ID, another user's booking needs a delegation from them or bookings.manage_any for the room",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "bookings"
+                ],
+                "summary": "Get a booking by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all users",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.User"
+                            "$ref": "#/definitions/model.BookingResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Missing booking, or one the caller cannot see",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new user",
+                "description": "Update an existing booking, fields left out keep their values. Other users' bookings need a delegation from them or bookings.manage_any for the room. A booking that stays or becomes active needs its slot free and the organizer's strikes to allow it, and in a restricted room the caller and the organizer on its access list.",
                 "consumes": [
                     "application/json"
                 ],
@@ -768,242 +991,4781 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "bookings"
                 ],
-                "summary": "Create a new user",
+                "summary": "Update a booking",
                 "parameters": [
                     {
-                        "description": "name",
-                        "name": "name",
+                        "type": "string",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Booking update details",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CreateUserInput"
+                            "$ref": "#/definitions/model.UpdateBookingInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "$ref": "#/definitions/model.BookingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid times or the room is not available",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Restricted room, another user's booking or restricted by strikes",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel an existing booking, other users' bookings need a delegation from them or bookings.manage_any for the room",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Cancel a booking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.BookingResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Another user's booking",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}/check-in": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record that a booking is in use, from 15 minutes before it starts until it ends, optionally with how many people came. Checking in again corrects the number. Bookings that end without a check-in count as no-shows. Other users' bookings need a delegation from them or bookings.manage_any for the room.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Check in to a booking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Attendance",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.CheckInInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/model.BookingResponse"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Cancelled booking or outside the check-in window",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Another user's booking",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List groups and teams (groups.read)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "List groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Group"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a group or team, kind defaults to group (groups.write)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Create a group",
+                "parameters": [
+                    {
+                        "description": "Group",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateGroupInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Group"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a group with its members (groups.read)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get a group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GroupResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a group, its members lose the room access it granted (groups.write)",
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Delete a group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change a group's name, kind or description, fields left out are kept (groups.write)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Update a group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateGroupInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Group"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/members/{user_id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Put a user in a group, adding a member again has no effect (groups.write)",
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Add a group member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Take a user out of a group (groups.write)",
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Remove a group member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List invitations, newest first (invitations.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitations"
+                ],
+                "summary": "List invitations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Invitation"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Email a single-use invitation to register with a role (invitations.write), only admins can invite admins. Earlier pending invitations for the same email are revoked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitations"
+                ],
+                "summary": "Invite a user",
+                "parameters": [
+                    {
+                        "description": "Invitation",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateInvitationInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Invitation"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin account",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Email already registered",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Withdraw a pending invitation (invitations.write)",
+                "tags": [
+                    "invitations"
+                ],
+                "summary": "Revoke an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invitation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the authenticated user's profile",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Get current user profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the authenticated user's name, timezone, locale or notification preferences, fields left out are kept. A new email is stored as pending_email and a confirmation link is sent to it, the email changes once the link is followed. Sending the current email withdraws a pending change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Update current user profile",
+                "parameters": [
+                    {
+                        "description": "Fields to change",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateProfileInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already registered",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Confirmation email could not be sent",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turn off two-factor authentication, requires the password and an authenticator or recovery code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Password and code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.DisableTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Required for admins",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/2fa/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Confirm enrollment with a code from the authenticator app. The response lists one-time recovery codes, they are shown only once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Enable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Authenticator code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/2fa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace all recovery codes, the previous ones stop working",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Regenerate recovery codes",
+                "parameters": [
+                    {
+                        "description": "Authenticator code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/2fa/setup": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generate a TOTP secret and the otpauth:// provisioning URI to show as a QR code. Two-factor authentication is enabled once a code is confirmed with /me/2fa/enable.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Start two-factor enrollment",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TwoFactorSetupResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already enabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/bookings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of all bookings for the currently authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Get current user's bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by status (e.g., 'active', 'cancelled')",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of user's bookings",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.BookingResponse"
+                                    }
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to fetch bookings",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "error": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new room booking for the currently authenticated user, who gets a receipt if their booking_receipts preference is on",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Create a new booking for the authenticated user",
+                "parameters": [
+                    {
+                        "description": "Booking details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateMyBookingInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.BookingResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Restricted room",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/delegates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the users who may book, change and cancel bookings for the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "List my delegates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.User"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/delegates/{user_id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Let another user book, change and cancel bookings for the authenticated user. Adding a delegate again is not an error.",
+                "tags": [
+                    "me"
+                ],
+                "summary": "Add a delegate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delegate user ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Delegating to yourself",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop another user from booking for the authenticated user, bookings they already made stay",
+                "tags": [
+                    "me"
+                ],
+                "summary": "Remove a delegate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delegate user ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the authenticated user's password. Every other session is signed out, the response carries a new token for this one. Wrong current passwords count towards the login lockout.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Current password is incorrect",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too many attempts, see the Retry-After header",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List what the authenticated user may do, grants from roles assigned for a site or building name it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Get my permissions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Grant"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/principals": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the users who made the authenticated user their delegate",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "List who I book for",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.User"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/strikes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the no-show and late cancellation strikes that count against the authenticated user, and until when their booking is restricted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Get my strikes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/model.StrikeStatus"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/verify-email/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a new verification link to the authenticated user, previous links stop working",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Resend the verification email",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Email already verified",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too many attempts, see the Retry-After header",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/organization": {
+            "get": {
+                "description": "Get the organization the request acts for, picked from the host it was sent to or, on other hosts, from the token. Clients use it to show which organization they are signed in to.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "Get the current organization",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Organization"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/utilization": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sum up the bookings starting between two days by room, floor, building, weekday or hour of day (reports.read). Each row has the booked hours, utilization against the opening hours, cancellation and no-show rates, average lead time and average attendance against capacity. Days, weekdays and hours are counted in the timezone of the opening hours.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Room utilization report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD, at most 366 days after from",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "room",
+                            "floor",
+                            "building",
+                            "weekday",
+                            "hour"
+                        ],
+                        "type": "string",
+                        "description": "Grouping, room by default",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Response format, json by default",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/model.UtilizationReport"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the roles defined by admins, the built-in admin and user roles are not included (roles.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List custom roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Role"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Define a role from a set of permissions (roles.write). Callers can only grant permissions they hold everywhere.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Create a custom role",
+                "parameters": [
+                    {
+                        "description": "Role",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateRoleInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown permission or built-in name",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Permission the caller does not hold",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List every permission a custom role can grant (roles.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List permissions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a role defined by admins (roles.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Get a custom role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Role"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a role, everyone it was assigned to loses its permissions (roles.write)",
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Delete a custom role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change a role's name, description or permissions, fields left out are kept (roles.write). Users with the role get the new permissions on their next request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Update a custom role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateRoleInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown permission or built-in name",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Permission the caller does not hold",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of the meeting rooms the caller may book. Restricted rooms are only listed for callers with rooms.access_all and members of the groups on their access list, so anonymous callers see the unrestricted rooms. API keys need the rooms:read scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Get all rooms",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Room"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new meeting room, needs rooms.write for its site and building",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Create a new room",
+                "parameters": [
+                    {
+                        "description": "Room details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateRoomInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Room"
+                        }
+                    },
+                    "403": {
+                        "description": "rooms.write not granted for the room",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a room by its ID, restricted rooms are not found for callers without access. API keys need the rooms:read scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Get a room by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Room"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update an existing room, needs rooms.write for both its current and its new site and building",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Update a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Room details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateRoomInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Room"
+                        }
+                    },
+                    "403": {
+                        "description": "rooms.write not granted for the room",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a room by ID, needs rooms.write for its site and building",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Delete a room",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "rooms.write not granted for the room",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/access": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the groups whose members may book a restricted room (rooms.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Get a room's access list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Group"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "rooms.write not granted for the room",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the groups whose members may book the room (rooms.write). The list only applies while the room is restricted, an empty list leaves a restricted room to callers with rooms.access_all.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Replace a room's access list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Groups",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SetRoomAccessInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Group"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "rooms.write not granted for the room",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List service accounts (service_accounts.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "List service accounts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ServiceAccount"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a service account for an integration, then issue it API keys (service_accounts.write)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "Create a service account",
+                "parameters": [
+                    {
+                        "description": "Service account",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateServiceAccountInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServiceAccount"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a service account with its API keys (service_accounts.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "Get a service account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServiceAccountResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a service account, its API keys stop working immediately (service_accounts.write)",
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "Delete a service account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts/{id}/keys": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issue a scoped API key for a service account (service_accounts.write). The key is only returned in this response, send it as \"Authorization: Bearer \u003ckey\u003e\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "Issue an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "API key",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateAPIKeyInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIKeyCreatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts/{id}/keys/{key_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke an API key, it stops working immediately (service_accounts.write)",
+                "tags": [
+                    "service-accounts"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "key_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/opening-hours": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get when rooms are open, utilization reports measure bookings against it (settings.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Get opening hours",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/model.OpeningHours"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set when rooms are open, on which weekdays (0 is Sunday) and in which timezone (settings.write)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Update opening hours",
+                "parameters": [
+                    {
+                        "description": "Opening hours",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.OpeningHours"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/model.OpeningHours"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/security": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the security settings (settings.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Get security settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SecuritySettings"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update the security settings (settings.write). When two-factor authentication is required for admins, admins and users with administrative permissions from custom roles are refused on admin routes until they enroll.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Update security settings",
+                "parameters": [
+                    {
+                        "description": "Security settings",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SecuritySettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SecuritySettings"
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/strikes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get what earns users strikes and how crossing the threshold restricts their booking (settings.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Get the strike policy",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/model.StrikePolicy"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set whether no-shows and late cancellations earn strikes, how many within how many days restrict booking, and to what (settings.write). Only bookings that end or are cancelled after strikes are enabled count.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Update the strike policy",
+                "parameters": [
+                    {
+                        "description": "Strike policy",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.StrikePolicy"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/model.StrikePolicy"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/strikes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List every user with strikes that count, those with the most first, and until when each is restricted (users.read)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List users with strikes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "type": "array",
+                                    "items": {
+                                        "$ref": "#/definitions/model.StrikeStatus"
+                                    }
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all users (users.read)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get all users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.User"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new user. Set verified to skip email verification, otherwise a verification link is sent.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "name",
+                        "name": "name",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateUserInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin account",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Email already registered",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a user by ID (users.read)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Soft-delete a user (users.write). Only admins can delete admins. Their bookings are kept, and their email is released so they can be invited again. The last active admin cannot be deleted.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Admin account",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Last active admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change a user's name, email or role, fields left out are kept (users.write). Only admins can change admin accounts or make users admins. The last active admin cannot be demoted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateUserInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin account",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Email already registered or last active admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/deactivate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Disable a user's account, they cannot sign in and their existing tokens stop working immediately (users.write). Only admins can deactivate admins. The last active admin cannot be deactivated.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Deactivate a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin account",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Last active admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/delegates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the users who may book for a user (users.read)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List a user's delegates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.User"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/delegates/{delegate_id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Let one user book, change and cancel bookings for another, such as an assistant for an executive (users.write)",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Add a delegate for a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delegate user ID",
+                        "name": "delegate_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Delegating to the same user",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop one user from booking for another (users.write)",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Remove a delegate of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delegate user ID",
+                        "name": "delegate_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/groups": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the groups and teams a user belongs to (users.read)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List a user's groups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Group"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/password-reset": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Email a password reset link to the user (users.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Send a password reset link to a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/reactivate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Let a deactivated user sign in again (users.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Reactivate a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin account",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the custom roles assigned to a user and where they apply (users.read)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List a user's custom roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.RoleAssignment"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Give a user a custom role, everywhere or only for the rooms at a site or building (roles.write). A role assigned for a site or building only grants its room permissions there. Callers can only assign permissions they hold for that site or building.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Assign a custom role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role and scope",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AssignRoleInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.RoleAssignment"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown role",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Permission the caller does not hold",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/roles/{assignment_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Take a role assignment away from a user (roles.write)",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Remove a custom role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Role assignment ID",
+                        "name": "assignment_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/strikes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the strikes that count against a user and until when their booking is restricted (users.read)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user's strikes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "data": {
+                                    "$ref": "#/definitions/model.StrikeStatus"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Clear every strike of a user, lifting any restriction; the bookings cannot earn another (users.write)",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Clear a user's strikes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/strikes/{strike_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Clear one of a user's strikes, such as a no-show for a meeting that did happen (users.write)",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Clear one strike",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Strike ID",
+                        "name": "strike_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lift a login lockout and clear the failed login count (users.write)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Unlock a user account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "model.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.APIScope"
+                    }
+                },
+                "service_account_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.APIKeyCreatedResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "description": "Key is the secret, it is only returned once",
+                    "type": "string",
+                    "example": "mbk_9Zt0k2J8S2yq0xjzv1P7yQ6l0p3bXfYwH5nA4cR2dE8"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.APIScope"
+                    }
+                },
+                "service_account_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.APIScope": {
+            "type": "string",
+            "enum": [
+                "rooms:read",
+                "rooms:write",
+                "bookings:read",
+                "bookings:write"
+            ],
+            "x-enum-varnames": [
+                "ScopeRoomsRead",
+                "ScopeRoomsWrite",
+                "ScopeBookingsRead",
+                "ScopeBookingsWrite"
+            ]
+        },
+        "model.AssignRoleInput": {
+            "type": "object",
+            "required": [
+                "role_id"
+            ],
+            "properties": {
+                "building": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Tower A"
+                },
+                "role_id": {
+                    "type": "string"
+                },
+                "site": {
+                    "description": "Site and Building limit the role to the rooms there, leave both out\nto grant it everywhere",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Jakarta"
+                }
+            }
+        },
+        "model.AuditAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "cancel",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "AuditActionCreate",
+                "AuditActionUpdate",
+                "AuditActionCancel",
+                "AuditActionDelete"
+            ]
+        },
+        "model.AuditActorType": {
+            "type": "string",
+            "enum": [
+                "user",
+                "service_account",
+                "system"
+            ],
+            "x-enum-varnames": [
+                "AuditActorUser",
+                "AuditActorServiceAccount",
+                "AuditActorSystem"
+            ]
+        },
+        "model.AuditChange": {
+            "type": "object",
+            "properties": {
+                "from": {},
+                "to": {}
+            }
+        },
+        "model.AuditChanges": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/model.AuditChange"
+            }
+        },
+        "model.AuditEntity": {
+            "type": "string",
+            "enum": [
+                "user",
+                "room",
+                "booking"
+            ],
+            "x-enum-varnames": [
+                "AuditEntityUser",
+                "AuditEntityRoom",
+                "AuditEntityBooking"
+            ]
+        },
+        "model.AuditLog": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/model.AuditAction"
+                },
+                "actor_id": {
+                    "description": "ActorID is the user or service account that made the change, empty\nfor the system",
+                    "type": "string"
+                },
+                "actor_type": {
+                    "$ref": "#/definitions/model.AuditActorType"
+                },
+                "changes": {
+                    "$ref": "#/definitions/model.AuditChanges"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "entity_type": {
+                    "$ref": "#/definitions/model.AuditEntity"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.BatchBookingConflict": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.BatchBookingItem": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "room_id",
+                "start_time"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.BookingGroupResponse": {
+            "type": "object",
+            "properties": {
+                "bookings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.BookingResponse"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.BookingResponse": {
+            "type": "object",
+            "properties": {
+                "attendees": {
+                    "type": "integer"
+                },
+                "checked_in_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_id": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "room": {
+                    "$ref": "#/definitions/model.Room"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.BookingStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.BookingStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "BookingStatusActive",
+                "BookingStatusCancelled"
+            ]
+        },
+        "model.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string",
+                    "example": "strongpassword"
+                },
+                "new_password": {
+                    "type": "string",
+                    "minLength": 8,
+                    "example": "newstrongpassword"
+                }
+            }
+        },
+        "model.CheckInInput": {
+            "type": "object",
+            "properties": {
+                "attendees": {
+                    "description": "Attendees is how many people came, checking in again corrects it",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 6
+                }
+            }
+        },
+        "model.CreateAPIKeyInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "description": "ExpiresAt is optional, keys without it work until revoked",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Room 101 tablet"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/model.APIScope"
+                    },
+                    "example": [
+                        "bookings:read",
+                        "bookings:write"
+                    ]
+                }
+            }
+        },
+        "model.CreateBatchBookingInput": {
+            "type": "object",
+            "required": [
+                "bookings"
+            ],
+            "properties": {
+                "bookings": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/model.BatchBookingItem"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Offsite 2025"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateBookingInput": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "room_id",
+                "start_time",
+                "user_id"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateGroupInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Building and workplace team"
+                },
+                "kind": {
+                    "enum": [
+                        "team",
+                        "group"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.GroupKind"
+                        }
+                    ],
+                    "example": "team"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Facilities"
+                }
+            }
+        },
+        "model.CreateInvitationInput": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "new.admin@example.com"
+                },
+                "role": {
+                    "enum": [
+                        "user",
+                        "admin"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.UserRole"
+                        }
+                    ],
+                    "example": "admin"
+                }
+            }
+        },
+        "model.CreateMyBookingInput": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "room_id",
+                "start_time"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateRoleInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "permissions"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Front desk staff"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "receptionist"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Permission"
+                    },
+                    "example": [
+                        "bookings.manage_any",
+                        "users.read"
+                    ]
+                }
+            }
+        },
+        "model.CreateRoomInput": {
+            "type": "object",
+            "required": [
+                "capacity",
+                "name"
+            ],
+            "properties": {
+                "building": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Tower A"
+                },
+                "capacity": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "floor": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "12"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Meeting Room 1"
+                },
+                "restricted": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "site": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Jakarta"
+                }
+            }
+        },
+        "model.CreateServiceAccountInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Tablets next to the meeting rooms"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Lobby displays"
+                }
+            }
+        },
+        "model.CreateUserInput": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "riparuk@gmail.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Rifa Faruqi"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "strongpassword"
+                },
+                "role": {
+                    "enum": [
+                        "user",
+                        "admin"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.UserRole"
+                        }
+                    ],
+                    "example": "user"
+                },
+                "verified": {
+                    "description": "Verified skips email verification, otherwise a verification link is sent",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "model.DisableTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "description": "Code is the current authenticator code or an unused recovery code",
+                    "type": "string",
+                    "example": "123456"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "strongpassword"
+                }
+            }
+        },
+        "model.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "riparuk@gmail.com"
+                }
+            }
+        },
+        "model.Grant": {
+            "type": "object",
+            "properties": {
+                "building": {
+                    "type": "string"
+                },
+                "permission": {
+                    "$ref": "#/definitions/model.Permission"
+                },
+                "site": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Group": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/model.GroupKind"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GroupKind": {
+            "type": "string",
+            "enum": [
+                "team",
+                "group"
+            ],
+            "x-enum-varnames": [
+                "GroupKindTeam",
+                "GroupKindGroup"
+            ]
+        },
+        "model.GroupResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/model.GroupKind"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.User"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Invitation": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "invited_by_id": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/model.UserRole"
+                }
+            }
+        },
+        "model.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "riparuk@gmail.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "strongpassword"
+                }
+            }
+        },
+        "model.LoginTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "description": "Code is the current authenticator code or an unused recovery code",
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "model.NotificationPreferences": {
+            "type": "object",
+            "properties": {
+                "booking_receipts": {
+                    "description": "BookingReceipts confirms bookings the user makes themselves",
+                    "type": "boolean"
+                },
+                "booking_updates": {
+                    "description": "BookingUpdates covers bookings someone else makes, changes or\ncancels for the user",
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.OpeningHours": {
+            "type": "object",
+            "required": [
+                "closes",
+                "opens",
+                "timezone"
+            ],
+            "properties": {
+                "closes": {
+                    "type": "string",
+                    "example": "18:00"
+                },
+                "days": {
+                    "description": "Days are the weekdays rooms are open, 0 is Sunday",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3,
+                        4,
+                        5
+                    ]
+                },
+                "opens": {
+                    "type": "string",
+                    "example": "08:00"
+                },
+                "timezone": {
+                    "description": "Timezone is the IANA zone of the opening hours, reports count days,\nweekdays and hours of day in it",
+                    "type": "string",
+                    "example": "Asia/Jakarta"
+                }
+            }
+        },
+        "model.Organization": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "description": "Domain is the host requests for the organization arrive on, such as\nacme.meetbook.example. Requests on any other host use the\norganization of their token, or the default one.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Permission": {
+            "type": "string",
+            "enum": [
+                "users.read",
+                "users.write",
+                "groups.read",
+                "groups.write",
+                "roles.write",
+                "invitations.write",
+                "service_accounts.write",
+                "settings.write",
+                "audit.read",
+                "reports.read",
+                "rooms.write",
+                "rooms.access_all",
+                "bookings.manage_any"
+            ],
+            "x-enum-varnames": [
+                "PermUsersRead",
+                "PermUsersWrite",
+                "PermGroupsRead",
+                "PermGroupsWrite",
+                "PermRolesWrite",
+                "PermInvitationsWrite",
+                "PermServiceAccountsWrite",
+                "PermSettingsWrite",
+                "PermAuditRead",
+                "PermReportsRead",
+                "PermRoomsWrite",
+                "PermRoomsAccessAll",
+                "PermBookingsManageAny"
+            ]
+        },
+        "model.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "riparuk@gmail.com"
+                },
+                "invitation_token": {
+                    "description": "InvitationToken registers with the invitation's role and skips email\nverification, the email must match the invitation",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Rifa Faruqi"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "strongpassword"
+                }
+            }
+        },
+        "model.ReportGrouping": {
+            "type": "string",
+            "enum": [
+                "room",
+                "floor",
+                "building",
+                "weekday",
+                "hour"
+            ],
+            "x-enum-varnames": [
+                "GroupByRoom",
+                "GroupByFloor",
+                "GroupByBuilding",
+                "GroupByWeekday",
+                "GroupByHour"
+            ]
+        },
+        "model.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "minLength": 8,
+                    "example": "newstrongpassword"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Role": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Permission"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RoleAssignment": {
+            "type": "object",
+            "properties": {
+                "building": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/model.Role"
+                },
+                "role_id": {
+                    "type": "string"
+                },
+                "site": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Room": {
+            "type": "object",
+            "properties": {
+                "building": {
+                    "type": "string"
+                },
+                "capacity": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "floor": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restricted": {
+                    "description": "Restricted rooms can only be seen and booked by admins and the\nmembers of the groups on their access list",
+                    "type": "boolean"
+                },
+                "site": {
+                    "description": "Site, Building and Floor locate the room, roles can be assigned for\na site or building",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SecuritySettings": {
+            "type": "object",
+            "properties": {
+                "require_admin_2fa": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "model.ServiceAccount": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ServiceAccountResponse": {
+            "type": "object",
+            "properties": {
+                "api_keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.APIKey"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SetRoomAccessInput": {
+            "type": "object",
+            "required": [
+                "group_ids"
+            ],
+            "properties": {
+                "group_ids": {
+                    "description": "GroupIDs replaces the groups whose members may book the room",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.BookingResponse": {
+        },
+        "model.Strike": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "booking_id": {
                     "type": "string"
                 },
-                "end_time": {
+                "cleared_at": {
                     "type": "string"
                 },
-                "id": {
+                "created_at": {
                     "type": "string"
                 },
-                "room": {
-                    "$ref": "#/definitions/model.Room"
-                },
-                "room_id": {
+                "id": {
                     "type": "string"
                 },
-                "start_time": {
+                "occurred_at": {
+                    "description": "OccurredAt is when the booking ended for no-shows and when it was\ncancelled for late cancellations",
                     "type": "string"
                 },
-                "status": {
-                    "$ref": "#/definitions/model.BookingStatus"
+                "reason": {
+                    "$ref": "#/definitions/model.StrikeReason"
                 },
-                "updated_at": {
+                "user_id": {
                     "type": "string"
+                }
+            }
+        },
+        "model.StrikePolicy": {
+            "type": "object",
+            "properties": {
+                "block_popular_rooms": {
+                    "description": "BlockPopularRooms keeps restricted users out of rooms used for at\nleast PopularUtilization percent of their opening hours over the last\nfour weeks",
+                    "type": "boolean",
+                    "example": true
                 },
-                "user": {
-                    "$ref": "#/definitions/model.User"
+                "enabled": {
+                    "type": "boolean"
                 },
-                "user_id": {
+                "late_cancellation_hours": {
+                    "description": "LateCancellationHours is how long before a booking starts cancelling\nit earns a strike, 0 leaves cancellations alone",
+                    "type": "integer",
+                    "maximum": 168,
+                    "minimum": 0,
+                    "example": 2
+                },
+                "max_active_bookings": {
+                    "description": "MaxActiveBookings limits restricted users to that many upcoming\nbookings, null leaves the number alone",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "popular_utilization": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 60
+                },
+                "since": {
+                    "description": "Since is when strikes were enabled, bookings that ended or were\ncancelled before never earn one. It is set by the server.",
                     "type": "string"
+                },
+                "threshold": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 3
+                },
+                "window_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1,
+                    "example": 30
                 }
             }
         },
-        "model.BookingStatus": {
+        "model.StrikeReason": {
             "type": "string",
             "enum": [
-                "active",
-                "cancelled"
+                "no_show",
+                "late_cancellation"
             ],
             "x-enum-varnames": [
-                "BookingStatusActive",
-                "BookingStatusCancelled"
+                "StrikeNoShow",
+                "StrikeLateCancellation"
             ]
         },
-        "model.CreateBookingInput": {
+        "model.StrikeStatus": {
             "type": "object",
-            "required": [
-                "end_time",
-                "room_id",
-                "start_time",
-                "user_id"
-            ],
             "properties": {
-                "end_time": {
+                "restricted_until": {
                     "type": "string"
                 },
-                "room_id": {
-                    "type": "string"
+                "strikes": {
+                    "description": "Strikes are the uncleared strikes that count, newest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Strike"
+                    }
                 },
-                "start_time": {
-                    "type": "string"
+                "threshold": {
+                    "type": "integer",
+                    "example": 3
                 },
                 "user_id": {
                     "type": "string"
                 }
             }
         },
-        "model.CreateMyBookingInput": {
+        "model.TwoFactorCodeRequest": {
             "type": "object",
             "required": [
-                "end_time",
-                "room_id",
-                "start_time"
+                "code"
             ],
             "properties": {
-                "end_time": {
-                    "type": "string"
-                },
-                "room_id": {
-                    "type": "string"
-                },
-                "start_time": {
-                    "type": "string"
+                "code": {
+                    "type": "string",
+                    "example": "123456"
                 }
             }
         },
-        "model.CreateRoomInput": {
+        "model.TwoFactorSetupResponse": {
             "type": "object",
-            "required": [
-                "capacity",
-                "name"
-            ],
             "properties": {
-                "capacity": {
-                    "type": "integer",
-                    "example": 10
+                "provisioning_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/Meet%20Book:riparuk@gmail.com?secret=JBSWY3DPEHPK3PXP\u0026issuer=Meet+Book"
                 },
-                "name": {
+                "secret": {
                     "type": "string",
-                    "example": "Meeting Room 1"
+                    "example": "JBSWY3DPEHPK3PXP"
                 }
             }
         },
-        "model.CreateUserInput": {
+        "model.UpdateBookingInput": {
             "type": "object",
-            "required": [
-                "email",
-                "name",
-                "password",
-                "role"
-            ],
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "riparuk@gmail.com"
+                "end_time": {
+                    "type": "string"
                 },
-                "name": {
-                    "type": "string",
-                    "example": "Rifa Faruqi"
+                "start_time": {
+                    "type": "string"
                 },
-                "password": {
+                "status": {
+                    "$ref": "#/definitions/model.BookingStatus"
+                }
+            }
+        },
+        "model.UpdateGroupInput": {
+            "type": "object",
+            "properties": {
+                "description": {
                     "type": "string",
-                    "example": "strongpassword"
+                    "example": "Building and workplace team"
                 },
-                "role": {
+                "kind": {
+                    "enum": [
+                        "team",
+                        "group"
+                    ],
                     "allOf": [
                         {
-                            "$ref": "#/definitions/model.UserRole"
+                            "$ref": "#/definitions/model.GroupKind"
                         }
                     ],
-                    "example": "user"
+                    "example": "team"
+                },
+                "name": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "Facilities"
                 }
             }
         },
-        "model.LoginRequest": {
+        "model.UpdateNotificationPreferences": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "riparuk@gmail.com"
+                "booking_receipts": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "password": {
-                    "type": "string",
-                    "example": "strongpassword"
+                "booking_updates": {
+                    "type": "boolean",
+                    "example": true
                 }
             }
         },
-        "model.RegisterRequest": {
+        "model.UpdateProfileInput": {
             "type": "object",
-            "required": [
-                "email",
-                "name",
-                "password"
-            ],
             "properties": {
                 "email": {
                     "type": "string",
                     "example": "riparuk@gmail.com"
                 },
-                "master_password": {
+                "locale": {
                     "type": "string",
-                    "example": "secret-master"
+                    "example": "id-ID"
                 },
                 "name": {
                     "type": "string",
+                    "minLength": 1,
                     "example": "Rifa Faruqi"
                 },
-                "password": {
+                "notifications": {
+                    "$ref": "#/definitions/model.UpdateNotificationPreferences"
+                },
+                "timezone": {
                     "type": "string",
-                    "example": "strongpassword"
+                    "example": "Asia/Jakarta"
                 }
             }
         },
-        "model.Room": {
+        "model.UpdateRoleInput": {
             "type": "object",
             "properties": {
-                "capacity": {
-                    "type": "integer"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
+                "description": {
+                    "type": "string",
+                    "example": "Front desk staff"
                 },
                 "name": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
-                }
-            }
-        },
-        "model.UpdateBookingInput": {
-            "type": "object",
-            "properties": {
-                "end_time": {
-                    "type": "string"
-                },
-                "start_time": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 1,
+                    "example": "receptionist"
                 },
-                "status": {
-                    "$ref": "#/definitions/model.BookingStatus"
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Permission"
+                    },
+                    "example": [
+                        "bookings.manage_any",
+                        "users.read"
+                    ]
                 }
             }
         },
@@ -1014,13 +5776,58 @@ const docTemplate = `{
                 "name"
             ],
             "properties": {
+                "building": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Tower A"
+                },
                 "capacity": {
                     "type": "integer",
                     "example": 10
                 },
+                "floor": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "12"
+                },
                 "name": {
                     "type": "string",
                     "example": "Meeting Room 1"
+                },
+                "restricted": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "site": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Jakarta"
+                }
+            }
+        },
+        "model.UpdateUserInput": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "riparuk@gmail.com"
+                },
+                "name": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "Rifa Faruqi"
+                },
+                "role": {
+                    "enum": [
+                        "user",
+                        "admin"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.UserRole"
+                        }
+                    ],
+                    "example": "user"
                 }
             }
         },
@@ -1030,18 +5837,42 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "deactivated_at": {
+                    "description": "DeactivatedAt is set while an admin has disabled the account, the\nuser cannot sign in and their existing tokens are rejected",
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
+                "locale": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
+                "notifications": {
+                    "$ref": "#/definitions/model.NotificationPreferences"
+                },
+                "pending_email": {
+                    "description": "PendingEmail is the address the user asked to change to, it replaces\nEmail once they follow the link sent to it",
+                    "type": "string"
+                },
                 "role": {
                     "$ref": "#/definitions/model.UserRole"
                 },
+                "status": {
+                    "$ref": "#/definitions/model.UserStatus"
+                },
+                "timezone": {
+                    "description": "Timezone is an IANA zone name and Locale a BCP 47 language tag, both\nused to present times and emails to the user",
+                    "type": "string"
+                },
+                "two_factor_enabled": {
+                    "type": "boolean"
+                },
                 "updated_at": {
                     "type": "string"
                 }
@@ -1057,6 +5888,112 @@ const docTemplate = `{
                 "RoleUser",
                 "RoleAdmin"
             ]
+        },
+        "model.UserStatus": {
+            "type": "string",
+            "enum": [
+                "unverified",
+                "active"
+            ],
+            "x-enum-varnames": [
+                "UserStatusUnverified",
+                "UserStatusActive"
+            ]
+        },
+        "model.UtilizationReport": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "group_by": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ReportGrouping"
+                        }
+                    ],
+                    "example": "floor"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.UtilizationRow"
+                    }
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Asia/Jakarta"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-03-31"
+                }
+            }
+        },
+        "model.UtilizationRow": {
+            "type": "object",
+            "properties": {
+                "attendance_rate": {
+                    "type": "number",
+                    "example": 61.7
+                },
+                "avg_attendance": {
+                    "type": "number",
+                    "example": 5.2
+                },
+                "avg_lead_time_hours": {
+                    "type": "number",
+                    "example": 26.4
+                },
+                "booked_hours": {
+                    "type": "number",
+                    "example": 310.5
+                },
+                "bookings": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "cancellation_rate": {
+                    "type": "number",
+                    "example": 8.33
+                },
+                "key": {
+                    "type": "string",
+                    "example": "Tower A/12"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "Tower A, floor 12"
+                },
+                "no_show_rate": {
+                    "type": "number",
+                    "example": 4.5
+                },
+                "open_hours": {
+                    "type": "number",
+                    "example": 880
+                },
+                "rooms": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "utilization": {
+                    "type": "number",
+                    "example": 35.28
+                }
+            }
+        },
+        "model.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
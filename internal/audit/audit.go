@@ -0,0 +1,51 @@
+// Package audit carries who makes a request, and from where, through its
+// context. The audited repositories record it with every change.
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+)
+
+// Actor is who makes the changes of a request
+type Actor struct {
+	Type model.AuditActorType
+	// ID is the user or service account, uuid.Nil for the system
+	ID uuid.UUID
+}
+
+// Source is where a request came from
+type Source struct {
+	IP        string
+	RequestID string
+}
+
+type actorKey struct{}
+type sourceKey struct{}
+
+// WithActor returns a copy of ctx acting as actor
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor of ctx, or the system when no user or
+// service account signed in
+func ActorFromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return Actor{Type: model.AuditActorSystem}
+}
+
+// WithSource returns a copy of ctx coming from source
+func WithSource(ctx context.Context, source Source) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// SourceFromContext returns where ctx came from, empty outside of requests
+func SourceFromContext(ctx context.Context) Source {
+	source, _ := ctx.Value(sourceKey{}).(Source)
+	return source
+}
@@ -0,0 +1,261 @@
+// Package config loads the application configuration from defaults, an
+// optional YAML file, environment variables and command line flags, in
+// increasing order of precedence.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Auth      AuthConfig      `yaml:"auth"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Log       LogConfig       `yaml:"log"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Swagger   SwaggerConfig   `yaml:"swagger"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Mail      MailConfig      `yaml:"mail"`
+	OIDC      OIDCConfig      `yaml:"oidc"`
+}
+
+type ServerConfig struct {
+	Addr              string        `yaml:"addr" env:"LISTEN_ADDR"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	// DrainDelay is how long readiness fails before the listener closes on
+	// shutdown, so load balancers stop routing here first
+	DrainDelay time.Duration `yaml:"drain_delay" env:"SERVER_DRAIN_DELAY"`
+	// TrustedProxies lists the proxy addresses or CIDRs allowed to set
+	// X-Forwarded-For, the client IP is the connection address when empty
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+}
+
+type DatabaseConfig struct {
+	Driver string `yaml:"driver" env:"DB_DRIVER"`
+	// URL is the Postgres connection string. DATABASE_DIRECT_URL takes
+	// precedence over DATABASE_URL when both are set.
+	URL             string        `yaml:"url" env:"DATABASE_URL,DATABASE_DIRECT_URL" secret:"true"`
+	SQLitePath      string        `yaml:"sqlite_path" env:"SQLITE_PATH"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+}
+
+type AuthConfig struct {
+	// JWTSecret signs tokens with HS256 when no signing keys are set
+	JWTSecret string `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+	// JWTSigningKeys are PEM files with RSA or Ed25519 private keys. The
+	// first signs new tokens, all of them verify and are published at
+	// /.well-known/jwks.json.
+	JWTSigningKeys []string      `yaml:"jwt_signing_keys" env:"JWT_SIGNING_KEYS"`
+	JWTIssuer      string        `yaml:"jwt_issuer" env:"JWT_ISSUER"`
+	JWTAudience    string        `yaml:"jwt_audience" env:"JWT_AUDIENCE"`
+	AccessTokenTTL time.Duration `yaml:"access_token_ttl" env:"ACCESS_TOKEN_TTL"`
+	// InvitationTTL is how long an invitation to register stays valid
+	InvitationTTL time.Duration `yaml:"invitation_ttl" env:"INVITATION_TTL"`
+	// PasswordResetTTL is how long a password reset link stays valid
+	PasswordResetTTL time.Duration `yaml:"password_reset_ttl" env:"PASSWORD_RESET_TTL"`
+	// EmailVerificationTTL is how long an email verification link stays valid
+	EmailVerificationTTL time.Duration `yaml:"email_verification_ttl" env:"EMAIL_VERIFICATION_TTL"`
+	// TOTPIssuer is the account issuer shown in authenticator apps
+	TOTPIssuer string `yaml:"totp_issuer" env:"TOTP_ISSUER"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           time.Duration `yaml:"max_age" env:"CORS_MAX_AGE"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+type MetricsConfig struct {
+	// Token protects /metrics, the endpoint is disabled when empty
+	Token string `yaml:"token" env:"METRICS_TOKEN" secret:"true"`
+}
+
+// SwaggerConfig overrides the host and scheme shown in the API docs, the
+// docs use the serving host when empty
+type SwaggerConfig struct {
+	Host   string `yaml:"host" env:"SWAGGER_HOST"`
+	Scheme string `yaml:"scheme" env:"SWAGGER_SCHEME"`
+}
+
+// RateLimitConfig throttles the auth endpoints per client IP and per
+// account, limits are counted over Window
+type RateLimitConfig struct {
+	// Store is memory for a single instance or database to share limits
+	// between replicas
+	Store                   string        `yaml:"store" env:"RATE_LIMIT_STORE"`
+	Window                  time.Duration `yaml:"window" env:"RATE_LIMIT_WINDOW"`
+	LoginPerIP              int           `yaml:"login_per_ip" env:"RATE_LIMIT_LOGIN_PER_IP"`
+	LoginPerAccount         int           `yaml:"login_per_account" env:"RATE_LIMIT_LOGIN_PER_ACCOUNT"`
+	RegisterPerIP           int           `yaml:"register_per_ip" env:"RATE_LIMIT_REGISTER_PER_IP"`
+	RegisterPerAccount      int           `yaml:"register_per_account" env:"RATE_LIMIT_REGISTER_PER_ACCOUNT"`
+	PasswordResetPerIP      int           `yaml:"password_reset_per_ip" env:"RATE_LIMIT_PASSWORD_RESET_PER_IP"`
+	PasswordResetPerAccount int           `yaml:"password_reset_per_account" env:"RATE_LIMIT_PASSWORD_RESET_PER_ACCOUNT"`
+	VerificationPerAccount  int           `yaml:"verification_per_account" env:"RATE_LIMIT_VERIFICATION_PER_ACCOUNT"`
+	LockoutThreshold        int           `yaml:"lockout_threshold" env:"LOCKOUT_THRESHOLD"`
+	LockoutBase             time.Duration `yaml:"lockout_base" env:"LOCKOUT_BASE"`
+	LockoutMax              time.Duration `yaml:"lockout_max" env:"LOCKOUT_MAX"`
+}
+
+// MailConfig selects how transactional email is delivered
+type MailConfig struct {
+	// Driver is log to write messages to the log, or smtp
+	Driver       string `yaml:"driver" env:"MAIL_DRIVER"`
+	From         string `yaml:"from" env:"MAIL_FROM"`
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     int    `yaml:"smtp_port" env:"SMTP_PORT"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"SMTP_PASSWORD" secret:"true"`
+	// LinkBaseURL is the frontend URL that links in emails point to
+	LinkBaseURL string `yaml:"link_base_url" env:"MAIL_LINK_BASE_URL"`
+}
+
+// OIDCConfig enables single sign-on with an OpenID Connect provider, it is
+// disabled when IssuerURL is empty
+type OIDCConfig struct {
+	IssuerURL    string `yaml:"issuer_url" env:"OIDC_ISSUER_URL"`
+	ClientID     string `yaml:"client_id" env:"OIDC_CLIENT_ID"`
+	ClientSecret string `yaml:"client_secret" env:"OIDC_CLIENT_SECRET" secret:"true"`
+	// RedirectURL is this API's callback, ending in /api/auth/oidc/callback
+	RedirectURL string   `yaml:"redirect_url" env:"OIDC_REDIRECT_URL"`
+	Scopes      []string `yaml:"scopes" env:"OIDC_SCOPES"`
+	// GroupsClaim is the ID token claim listing the user's groups, roles
+	// are synced from it on every login when it is present
+	GroupsClaim string   `yaml:"groups_claim" env:"OIDC_GROUPS_CLAIM"`
+	AdminGroups []string `yaml:"admin_groups" env:"OIDC_ADMIN_GROUPS"`
+	// PostLoginRedirectURL is the frontend page that receives the access
+	// token in the URL fragment, the callback answers with JSON when empty
+	PostLoginRedirectURL string `yaml:"post_login_redirect_url" env:"OIDC_POST_LOGIN_REDIRECT_URL"`
+}
+
+// Enabled reports whether single sign-on is configured
+func (o OIDCConfig) Enabled() bool {
+	return o.IssuerURL != ""
+}
+
+// Default returns the configuration used when nothing overrides it
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+			DrainDelay:        5 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:          "postgres",
+			SQLitePath:      "meetbook.db",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		Auth: AuthConfig{
+			JWTIssuer:            "meet-book-api",
+			JWTAudience:          "meet-book-api",
+			AccessTokenTTL:       24 * time.Hour,
+			InvitationTTL:        7 * 24 * time.Hour,
+			PasswordResetTTL:     time.Hour,
+			EmailVerificationTTL: 48 * time.Hour,
+			TOTPIssuer:           "Meet Book",
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"*"},
+			MaxAge:         12 * time.Hour,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		RateLimit: RateLimitConfig{
+			Store:                   "memory",
+			Window:                  15 * time.Minute,
+			LoginPerIP:              50,
+			LoginPerAccount:         10,
+			RegisterPerIP:           10,
+			RegisterPerAccount:      3,
+			PasswordResetPerIP:      10,
+			PasswordResetPerAccount: 3,
+			VerificationPerAccount:  3,
+			LockoutThreshold:        5,
+			LockoutBase:             time.Minute,
+			LockoutMax:              time.Hour,
+		},
+		OIDC: OIDCConfig{
+			Scopes:      []string{"openid", "email", "profile"},
+			GroupsClaim: "groups",
+		},
+		Mail: MailConfig{
+			Driver:      "log",
+			From:        "Meet Book <no-reply@localhost>",
+			SMTPPort:    587,
+			LinkBaseURL: "http://localhost:3000",
+		},
+	}
+}
+
+// Load registers the common flags on fs, parses args and builds the
+// configuration. The .env file is loaded into the environment first when
+// present. Load does not validate, callers validate the sections they use.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	_ = godotenv.Load()
+
+	cfg := Default()
+
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML configuration file")
+	overrides := registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(cfg, *configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	overrides.apply(fs, cfg)
+	return cfg, nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
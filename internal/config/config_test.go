@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const strongSecret = "k8Qw3nV0pZ7rT2yB5mX9cL1fH4jD6sGa"
+
+func load(t *testing.T, args ...string) *Config {
+	t.Helper()
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := `
+server:
+  addr: ":9000"
+  read_timeout: 3s
+database:
+  driver: sqlite
+log:
+  level: warn
+`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("LOG_LEVEL", "error")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example, https://b.example")
+
+	cfg := load(t, "-config", path, "-addr", ":9100")
+
+	if cfg.Server.Addr != ":9100" {
+		t.Errorf("addr = %q, flag should win", cfg.Server.Addr)
+	}
+	if cfg.Server.ReadTimeout != 3*time.Second {
+		t.Errorf("read timeout = %v, want value from file", cfg.Server.ReadTimeout)
+	}
+	if cfg.Database.Driver != "sqlite" {
+		t.Errorf("driver = %q, want value from file", cfg.Database.Driver)
+	}
+	if cfg.Log.Level != "error" {
+		t.Errorf("log level = %q, env should win over file", cfg.Log.Level)
+	}
+	if got := strings.Join(cfg.CORS.AllowedOrigins, "|"); got != "https://a.example|https://b.example" {
+		t.Errorf("origins = %q", got)
+	}
+	if cfg.Server.WriteTimeout != 30*time.Second {
+		t.Errorf("write timeout = %v, want default", cfg.Server.WriteTimeout)
+	}
+}
+
+func TestDirectURLWinsOverDatabaseURL(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://remote")
+	t.Setenv("DATABASE_DIRECT_URL", "postgres://direct")
+
+	if cfg := load(t); cfg.Database.URL != "postgres://direct" {
+		t.Errorf("url = %q, want direct url", cfg.Database.URL)
+	}
+}
+
+func TestValidateJWTSecret(t *testing.T) {
+	cases := map[string]bool{
+		"":                       false,
+		"secret":                 false,
+		"short-but-random-9f8e":  false,
+		strings.Repeat("ab", 20): false,
+		strongSecret:             true,
+	}
+	for secret, ok := range cases {
+		auth := Default().Auth
+		auth.JWTSecret = secret
+		if err := auth.Validate(); (err == nil) != ok {
+			t.Errorf("secret %q: err = %v, want ok = %v", secret, err, ok)
+		}
+	}
+}
+
+func TestValidateSigningKeysReplaceSecret(t *testing.T) {
+	auth := Default().Auth
+	auth.JWTSigningKeys = []string{"/run/secrets/jwt.pem"}
+	if err := auth.Validate(); err != nil {
+		t.Fatalf("signing keys without secret: %v", err)
+	}
+
+	auth.JWTAudience = ""
+	if err := auth.Validate(); err == nil {
+		t.Fatal("expected an empty audience to be rejected")
+	}
+}
+
+func TestValidateRejectsCredentialedWildcardCORS(t *testing.T) {
+	cors := CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}
+	if err := cors.Validate(); err == nil {
+		t.Fatal("expected wildcard origin with credentials to be rejected")
+	}
+}
+
+func TestValidateOIDC(t *testing.T) {
+	oidc := Default().OIDC
+	if err := oidc.Validate(); err != nil {
+		t.Fatalf("disabled OIDC: %v", err)
+	}
+
+	oidc.IssuerURL = "https://idp.example.com"
+	oidc.ClientID = "meet-book"
+	oidc.RedirectURL = "https://api.example.com/api/auth/oidc/callback"
+	if err := oidc.Validate(); err != nil {
+		t.Fatalf("valid OIDC: %v", err)
+	}
+
+	oidc.RedirectURL = "/api/auth/oidc/callback"
+	oidc.Scopes = []string{"email"}
+	if err := oidc.Validate(); err == nil {
+		t.Fatal("expected a relative redirect URL and missing openid scope to be rejected")
+	}
+}
+
+func TestValidateDrainDelay(t *testing.T) {
+	t.Setenv("SERVER_DRAIN_DELAY", "0s")
+	if cfg := load(t); cfg.Server.DrainDelay != 0 || cfg.Server.Validate() != nil {
+		t.Fatalf("drain delay = %v, want 0 to be accepted", cfg.Server.DrainDelay)
+	}
+
+	server := Default().Server
+	server.DrainDelay = -time.Second
+	if err := server.Validate(); err == nil {
+		t.Fatal("expected a negative drain delay to be rejected")
+	}
+}
+
+func TestDumpRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Auth.JWTSecret = strongSecret
+	cfg.Database.URL = "postgres://user:pass@db/meetbook"
+
+	var buf bytes.Buffer
+	if err := cfg.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, leaked := range []string{strongSecret, "user:pass"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("dump leaked %q:\n%s", leaked, out)
+		}
+	}
+	if !strings.Contains(out, "read_timeout: 15s") {
+		t.Errorf("expected durations as strings:\n%s", out)
+	}
+}
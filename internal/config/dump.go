@@ -0,0 +1,51 @@
+package config
+
+import (
+	"io"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[REDACTED]"
+
+// Dump writes the configuration as YAML with secrets redacted
+func (c *Config) Dump(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	defer encoder.Close()
+	return encoder.Encode(dumpValue(reflect.ValueOf(*c)))
+}
+
+// dumpValue converts the config into ordered YAML nodes, printing durations
+// as strings and hiding fields tagged secret
+func dumpValue(v reflect.Value) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: field.Tag.Get("yaml")}
+		var val *yaml.Node
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			val = dumpValue(value)
+		case field.Tag.Get("secret") == "true":
+			val = &yaml.Node{Kind: yaml.ScalarNode, Value: "", Style: yaml.DoubleQuotedStyle}
+			if value.String() != "" {
+				val.Value = redacted
+			}
+		case field.Type == durationType:
+			val = &yaml.Node{Kind: yaml.ScalarNode, Value: time.Duration(value.Int()).String()}
+		default:
+			val = &yaml.Node{}
+			if err := val.Encode(value.Interface()); err != nil {
+				val = &yaml.Node{Kind: yaml.ScalarNode, Value: err.Error()}
+			}
+		}
+		node.Content = append(node.Content, key, val)
+	}
+	return node
+}
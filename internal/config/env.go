@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// loadEnv overrides fields with the environment variables named in their
+// env tag. When a tag lists several variables the last one set wins.
+func loadEnv(cfg *Config) error {
+	// PORT is honoured for platforms that inject it, LISTEN_ADDR wins
+	if port := os.Getenv("PORT"); port != "" && os.Getenv("LISTEN_ADDR") == "" {
+		cfg.Server.Addr = ":" + port
+	}
+
+	var errs []error
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, value reflect.Value) {
+		for _, name := range strings.Split(field.Tag.Get("env"), ",") {
+			raw, ok := os.LookupEnv(name)
+			if name == "" || !ok {
+				continue
+			}
+			if err := setValue(value, raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// walk calls fn for every leaf field of the nested config structs
+func walk(v reflect.Value, fn func(reflect.StructField, reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			walk(value, fn)
+			continue
+		}
+		fn(field, value)
+	}
+}
+
+// setValue parses raw into value according to its type
+func setValue(value reflect.Value, raw string) error {
+	if value.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config type %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"flag"
+	"strings"
+)
+
+// flagOverrides holds the flags that can override the configuration
+type flagOverrides struct {
+	addr        *string
+	dbDriver    *string
+	logLevel    *string
+	corsOrigins *string
+}
+
+func registerFlags(fs *flag.FlagSet) *flagOverrides {
+	return &flagOverrides{
+		addr:        fs.String("addr", "", "listen address, e.g. :8080"),
+		dbDriver:    fs.String("db-driver", "", "database driver: postgres or sqlite"),
+		logLevel:    fs.String("log-level", "", "log level: debug, info, warn or error"),
+		corsOrigins: fs.String("cors-origins", "", "comma separated list of allowed CORS origins"),
+	}
+}
+
+// apply copies the flags that were set explicitly on the command line
+func (o *flagOverrides) apply(fs *flag.FlagSet, cfg *Config) {
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			cfg.Server.Addr = *o.addr
+		case "db-driver":
+			cfg.Database.Driver = *o.dbDriver
+		case "log-level":
+			cfg.Log.Level = *o.logLevel
+		case "cors-origins":
+			cfg.CORS.AllowedOrigins = nil
+			for _, origin := range strings.Split(*o.corsOrigins, ",") {
+				if origin = strings.TrimSpace(origin); origin != "" {
+					cfg.CORS.AllowedOrigins = append(cfg.CORS.AllowedOrigins, origin)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const minJWTSecretLength = 32
+
+// weakSecrets are placeholder values that must never be used in production
+var weakSecrets = []string{"secret", "changeme", "change-me", "password", "jwt_secret", "your-secret-key"}
+
+// Validate checks every section and reports all problems at once
+func (c *Config) Validate() error {
+	return errors.Join(
+		c.Server.Validate(),
+		c.Database.Validate(),
+		c.Auth.Validate(),
+		c.CORS.Validate(),
+		c.Log.Validate(),
+		c.RateLimit.Validate(),
+		c.Mail.Validate(),
+		c.OIDC.Validate(),
+	)
+}
+
+func (s ServerConfig) Validate() error {
+	var errs []error
+	if s.Addr == "" {
+		errs = append(errs, errors.New("server.addr is required"))
+	}
+	for name, d := range map[string]time.Duration{
+		"server.read_header_timeout": s.ReadHeaderTimeout,
+		"server.read_timeout":        s.ReadTimeout,
+		"server.write_timeout":       s.WriteTimeout,
+		"server.idle_timeout":        s.IdleTimeout,
+		"server.shutdown_timeout":    s.ShutdownTimeout,
+	} {
+		if d <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", name))
+		}
+	}
+	if s.DrainDelay < 0 {
+		errs = append(errs, errors.New("server.drain_delay must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+func (d DatabaseConfig) Validate() error {
+	var errs []error
+	switch d.Driver {
+	case "postgres":
+		if d.URL == "" {
+			errs = append(errs, errors.New("database.url (DATABASE_URL or DATABASE_DIRECT_URL) is required for postgres"))
+		}
+	case "sqlite":
+		if d.SQLitePath == "" {
+			errs = append(errs, errors.New("database.sqlite_path is required for sqlite"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("database.driver %q is not supported, expected postgres or sqlite", d.Driver))
+	}
+	if d.MaxOpenConns < 0 || d.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database pool sizes must not be negative"))
+	}
+	if d.MaxOpenConns > 0 && d.MaxIdleConns > d.MaxOpenConns {
+		errs = append(errs, errors.New("database.max_idle_conns must not exceed database.max_open_conns"))
+	}
+	return errors.Join(errs...)
+}
+
+func (a AuthConfig) Validate() error {
+	var errs []error
+	if len(a.JWTSigningKeys) == 0 {
+		if err := validateSecret(a.JWTSecret); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if a.JWTIssuer == "" || a.JWTAudience == "" {
+		errs = append(errs, errors.New("auth.jwt_issuer and auth.jwt_audience are required"))
+	}
+	if a.AccessTokenTTL <= 0 {
+		errs = append(errs, errors.New("auth.access_token_ttl must be positive"))
+	}
+	if a.PasswordResetTTL <= 0 || a.EmailVerificationTTL <= 0 || a.InvitationTTL <= 0 {
+		errs = append(errs, errors.New("auth.password_reset_ttl, auth.email_verification_ttl and auth.invitation_ttl must be positive"))
+	}
+	if a.TOTPIssuer == "" {
+		errs = append(errs, errors.New("auth.totp_issuer is required"))
+	}
+	return errors.Join(errs...)
+}
+
+func validateSecret(secret string) error {
+	switch {
+	case secret == "":
+		return errors.New("auth.jwt_secret (JWT_SECRET) is required unless auth.jwt_signing_keys is set")
+	case slices.Contains(weakSecrets, strings.ToLower(secret)):
+		return errors.New("auth.jwt_secret is a known placeholder value, generate one with: openssl rand -base64 48")
+	case len(secret) < minJWTSecretLength:
+		return fmt.Errorf("auth.jwt_secret must be at least %d characters, generate one with: openssl rand -base64 48", minJWTSecretLength)
+	case distinctBytes(secret) < 8:
+		return errors.New("auth.jwt_secret is too repetitive, generate one with: openssl rand -base64 48")
+	}
+	return nil
+}
+
+func distinctBytes(s string) int {
+	seen := make(map[byte]struct{})
+	for i := 0; i < len(s); i++ {
+		seen[s[i]] = struct{}{}
+	}
+	return len(seen)
+}
+
+func (c CORSConfig) Validate() error {
+	if len(c.AllowedOrigins) == 0 {
+		return errors.New("cors.allowed_origins must list at least one origin")
+	}
+	if c.AllowCredentials && slices.Contains(c.AllowedOrigins, "*") {
+		return errors.New("cors.allow_credentials cannot be used with the wildcard origin \"*\"")
+	}
+	return nil
+}
+
+func (l LogConfig) Validate() error {
+	var errs []error
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, strings.ToLower(l.Level)) {
+		errs = append(errs, fmt.Errorf("log.level %q is not supported", l.Level))
+	}
+	if !slices.Contains([]string{"json", "text"}, strings.ToLower(l.Format)) {
+		errs = append(errs, fmt.Errorf("log.format %q is not supported", l.Format))
+	}
+	return errors.Join(errs...)
+}
+
+func (r RateLimitConfig) Validate() error {
+	var errs []error
+	if r.Store != "memory" && r.Store != "database" {
+		errs = append(errs, fmt.Errorf("rate_limit.store %q is not supported, expected memory or database", r.Store))
+	}
+	for name, n := range map[string]int{
+		"rate_limit.login_per_ip":               r.LoginPerIP,
+		"rate_limit.login_per_account":          r.LoginPerAccount,
+		"rate_limit.register_per_ip":            r.RegisterPerIP,
+		"rate_limit.register_per_account":       r.RegisterPerAccount,
+		"rate_limit.password_reset_per_ip":      r.PasswordResetPerIP,
+		"rate_limit.password_reset_per_account": r.PasswordResetPerAccount,
+		"rate_limit.verification_per_account":   r.VerificationPerAccount,
+		"rate_limit.lockout_threshold":          r.LockoutThreshold,
+	} {
+		if n <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", name))
+		}
+	}
+	if r.Window <= 0 || r.LockoutBase <= 0 {
+		errs = append(errs, errors.New("rate_limit.window and rate_limit.lockout_base must be positive"))
+	}
+	if r.LockoutMax < r.LockoutBase {
+		errs = append(errs, errors.New("rate_limit.lockout_max must not be shorter than rate_limit.lockout_base"))
+	}
+	return errors.Join(errs...)
+}
+
+func (m MailConfig) Validate() error {
+	var errs []error
+	switch m.Driver {
+	case "log":
+	case "smtp":
+		if m.SMTPHost == "" {
+			errs = append(errs, errors.New("mail.smtp_host (SMTP_HOST) is required for smtp"))
+		}
+		if m.SMTPPort <= 0 {
+			errs = append(errs, errors.New("mail.smtp_port must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("mail.driver %q is not supported, expected log or smtp", m.Driver))
+	}
+	if m.From == "" {
+		errs = append(errs, errors.New("mail.from is required"))
+	}
+	if u, err := url.Parse(m.LinkBaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("mail.link_base_url %q must be an absolute URL", m.LinkBaseURL))
+	}
+	return errors.Join(errs...)
+}
+
+func (o OIDCConfig) Validate() error {
+	if !o.Enabled() {
+		return nil
+	}
+	var errs []error
+	if o.ClientID == "" {
+		errs = append(errs, errors.New("oidc.client_id (OIDC_CLIENT_ID) is required when oidc.issuer_url is set"))
+	}
+	urls := []struct{ name, raw string }{
+		{"oidc.issuer_url", o.IssuerURL},
+		{"oidc.redirect_url", o.RedirectURL},
+		{"oidc.post_login_redirect_url", o.PostLoginRedirectURL},
+	}
+	for _, f := range urls {
+		if f.raw == "" && f.name == "oidc.post_login_redirect_url" {
+			continue
+		}
+		if u, err := url.Parse(f.raw); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s %q must be an absolute URL", f.name, f.raw))
+		}
+	}
+	if !slices.Contains(o.Scopes, "openid") {
+		errs = append(errs, errors.New("oidc.scopes must include openid"))
+	}
+	return errors.Join(errs...)
+}
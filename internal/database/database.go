@@ -0,0 +1,51 @@
+package database
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/riparuk/meet-book-api/internal/config"
+	"gorm.io/gorm"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+var DB *gorm.DB
+
+// Init connects to the database selected by cfg.Driver and applies the
+// connection pool settings
+func Init(cfg config.DatabaseConfig) {
+	if err := cfg.Validate(); err != nil {
+		slog.Error("invalid database configuration", "error", err)
+		os.Exit(1)
+	}
+
+	switch cfg.Driver {
+	case DriverPostgres:
+		InitPostgres(cfg)
+	case DriverSQLite:
+		InitSQLite(cfg)
+	}
+}
+
+// Open opens a database connection for the given driver and DSN without
+// touching the global DB
+func Open(driver, dsn string) (*gorm.DB, error) {
+	switch driver {
+	case DriverPostgres:
+		return openPostgres(dsn)
+	case DriverSQLite:
+		return openSQLite(dsn)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+}
+
+// IsPostgres reports whether db is a Postgres connection
+func IsPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == DriverPostgres
+}
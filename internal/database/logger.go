@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const slowQueryThreshold = 200 * time.Millisecond
+
+// slogLogger sends GORM logs to the request's slog logger. Queries are
+// logged at debug level, slow queries as warnings and failures as errors.
+type slogLogger struct {
+	level gormlogger.LogLevel
+}
+
+func newLogger() gormlogger.Interface {
+	return &slogLogger{level: gormlogger.Warn}
+}
+
+func (l *slogLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	return &slogLogger{level: level}
+}
+
+func (l *slogLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		logger.FromContext(ctx).InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *slogLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		logger.FromContext(ctx).WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *slogLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		logger.FromContext(ctx).ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	log := logger.FromContext(ctx)
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		log.ErrorContext(ctx, "query failed", "error", err, "sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds())
+	case elapsed > slowQueryThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		log.WarnContext(ctx, "slow query", "sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds())
+	case log.Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		log.DebugContext(ctx, "query", "sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds())
+	}
+}
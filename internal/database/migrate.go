@@ -0,0 +1,225 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/riparuk/meet-book-api/internal/model"
+	"gorm.io/gorm"
+)
+
+// SchemaVersion is the schema version this build expects. Bump it whenever
+// Models or their fields change so readiness checks catch pending migrations.
+const SchemaVersion = 17
+
+// SchemaMigration records each schema version applied by Migrate
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// Models lists every table managed by the migrations
+var Models = []interface{}{
+	&model.Organization{},
+	&model.User{},
+	&model.Room{},
+	&model.BookingGroup{},
+	&model.Booking{},
+	&model.RateLimitCounter{},
+	&model.LoginLockout{},
+	&model.UserToken{},
+	&model.Setting{},
+	&model.ServiceAccount{},
+	&model.APIKey{},
+	&model.Invitation{},
+	&model.Group{},
+	&model.GroupMember{},
+	&model.RoomAccess{},
+	&model.Role{},
+	&model.RoleAssignment{},
+	&model.AuditLog{},
+	&model.Delegation{},
+	&model.Strike{},
+}
+
+// Prepare installs the engine specific prerequisites of the schema
+func Prepare(db *gorm.DB) error {
+	if db.Dialector.Name() != DriverPostgres {
+		return nil
+	}
+
+	var extExists bool
+	err := db.Raw("SELECT 1 FROM pg_extension WHERE extname = 'uuid-ossp'").Scan(&extExists).Error
+	if err != nil {
+		return fmt.Errorf("failed to check for uuid-ossp extension: %w", err)
+	}
+
+	if !extExists {
+		if err := db.Exec("CREATE EXTENSION \"uuid-ossp\"").Error; err != nil {
+			return fmt.Errorf("failed to create uuid-ossp extension: %w", err)
+		}
+	}
+	return nil
+}
+
+// Migrate creates or updates every table in Models and records SchemaVersion
+func Migrate(db *gorm.DB) error {
+	if err := Prepare(db); err != nil {
+		return err
+	}
+	if err := prepareOrganizations(db); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(append(Models, &SchemaMigration{})...); err != nil {
+		return err
+	}
+	if err := seedOrganizations(db); err != nil {
+		return err
+	}
+	if err := protectAuditLogs(db); err != nil {
+		return err
+	}
+
+	return db.Where(SchemaMigration{Version: SchemaVersion}).
+		Attrs(SchemaMigration{AppliedAt: time.Now().UTC()}).
+		FirstOrCreate(&SchemaMigration{}).Error
+}
+
+// legacySettings holds the settings of a schema from before organizations
+// while the table is rebuilt with the organization in its primary key
+const legacySettings = "legacy_settings"
+
+// legacyIndexes were unique across the whole database before
+// organizations, names and emails are now unique within an organization
+var legacyIndexes = []struct {
+	model any
+	name  string
+}{
+	{&model.User{}, "idx_users_oidc"},
+	{&model.Group{}, "idx_groups_name"},
+	{&model.Role{}, "idx_roles_name"},
+	{&model.ServiceAccount{}, "idx_service_accounts_name"},
+}
+
+// prepareOrganizations moves a schema from before organizations out of
+// AutoMigrate's way. Existing rows end up in the default organization
+// through the organization_id column default.
+func prepareOrganizations(db *gorm.DB) error {
+	m := db.Migrator()
+	if m.HasTable(&model.Setting{}) && !m.HasColumn(&model.Setting{}, "organization_id") {
+		if err := m.RenameTable(&model.Setting{}, legacySettings); err != nil {
+			return fmt.Errorf("failed to move legacy settings: %w", err)
+		}
+	}
+
+	for _, idx := range legacyIndexes {
+		if m.HasTable(idx.model) && m.HasIndex(idx.model, idx.name) {
+			if err := m.DropIndex(idx.model, idx.name); err != nil {
+				return fmt.Errorf("failed to drop index %s: %w", idx.name, err)
+			}
+		}
+	}
+
+	if m.HasTable(&model.User{}) && m.HasConstraint(&model.User{}, "uni_users_email") {
+		err := withoutForeignKeys(db, func() error {
+			return m.DropConstraint(&model.User{}, "uni_users_email")
+		})
+		if err != nil {
+			return fmt.Errorf("failed to drop unique email constraint: %w", err)
+		}
+	}
+	return nil
+}
+
+// withoutForeignKeys runs fn with SQLite's foreign keys off, SQLite drops
+// a constraint by copying the table and dropping the original would
+// otherwise cascade to every row referencing it. It relies on SQLite
+// using a single connection.
+func withoutForeignKeys(db *gorm.DB, fn func() error) error {
+	if IsPostgres(db) {
+		return fn()
+	}
+	if err := db.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+		return err
+	}
+	err := fn()
+	if restore := db.Exec("PRAGMA foreign_keys = ON").Error; err == nil {
+		err = restore
+	}
+	return err
+}
+
+// seedOrganizations creates the default organization and gives it the
+// settings saved before organizations existed
+func seedOrganizations(db *gorm.DB) error {
+	err := db.Where(model.Organization{ID: model.DefaultOrganizationID}).
+		Attrs(model.Organization{Name: model.DefaultOrganizationName, Slug: model.DefaultOrganizationSlug}).
+		FirstOrCreate(&model.Organization{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to create the default organization: %w", err)
+	}
+
+	if !db.Migrator().HasTable(legacySettings) {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("INSERT INTO settings (organization_id, key, value, updated_at) SELECT ?, key, value, updated_at FROM "+legacySettings, model.DefaultOrganizationID).Error
+		if err != nil {
+			return fmt.Errorf("failed to copy legacy settings: %w", err)
+		}
+		return tx.Migrator().DropTable(legacySettings)
+	})
+}
+
+// protectAuditLogs installs triggers that refuse to update or delete audit
+// log entries, so not even a bug or a stray query can rewrite history.
+// Dropping the table, as migrate -clean does, still works.
+func protectAuditLogs(db *gorm.DB) error {
+	var statements []string
+	if IsPostgres(db) {
+		statements = []string{
+			`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql`,
+			"DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs",
+			"CREATE TRIGGER audit_logs_append_only BEFORE UPDATE OR DELETE ON audit_logs FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()",
+			"DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs",
+			"CREATE TRIGGER audit_logs_no_truncate BEFORE TRUNCATE ON audit_logs FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only()",
+		}
+	} else {
+		statements = []string{
+			"CREATE TRIGGER IF NOT EXISTS audit_logs_no_update BEFORE UPDATE ON audit_logs BEGIN SELECT RAISE(ABORT, 'audit_logs is append-only'); END",
+			"CREATE TRIGGER IF NOT EXISTS audit_logs_no_delete BEFORE DELETE ON audit_logs BEGIN SELECT RAISE(ABORT, 'audit_logs is append-only'); END",
+		}
+	}
+
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("failed to protect the audit log: %w", err)
+		}
+	}
+	return nil
+}
+
+// CurrentSchemaVersion returns the latest schema version applied to db,
+// or 0 when migrations never ran
+func CurrentSchemaVersion(db *gorm.DB) (int, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return 0, nil
+	}
+
+	var latest SchemaMigration
+	err := db.Order("version DESC").First(&latest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return latest.Version, err
+}
+
+// DropAll drops every table in Models
+func DropAll(db *gorm.DB) error {
+	return db.Migrator().DropTable(append(Models, &SchemaMigration{})...)
+}
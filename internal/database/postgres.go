@@ -1,48 +1,38 @@
 package database
 
 import (
-	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
-	"github.com/joho/godotenv"
+	"github.com/riparuk/meet-book-api/internal/config"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
+func openPostgres(dsn string) (*gorm.DB, error) {
+	return gorm.Open(postgres.Open(dsn), &gorm.Config{
+		PrepareStmt: false,
+		Logger:      newLogger(),
+	})
+}
 
-func InitPostgres() {
-	err := godotenv.Load()
+func InitPostgres(cfg config.DatabaseConfig) {
+	var err error
+	DB, err = openPostgres(cfg.URL)
 	if err != nil {
-		log.Println("⚠️  Warning: No .env file found, using environment variables.")
-	}
-
-	// Prioritize direct connection if available
-	dsn := os.Getenv("DATABASE_DIRECT_URL")
-	if dsn != "" {
-		DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-			PrepareStmt: false,
-		})
-		if err != nil {
-			log.Fatalf("failed to connect to Direct Postgres: %v", err)
-		}
-		fmt.Println("🟢 Connected to Direct Postgres")
-		return
+		slog.Error("failed to connect to Postgres", "error", err)
+		os.Exit(1)
 	}
 
-	// Remote Postgres
-	dsn = os.Getenv("DATABASE_URL")
-	if dsn != "" {
-		DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-			PrepareStmt: false,
-		})
-		if err != nil {
-			log.Fatalf("failed to connect to Remote Postgres: %v", err)
-		}
-		fmt.Println("🟢 Connected to Remote Postgres")
-		return
+	sqlDB, err := DB.DB()
+	if err != nil {
+		slog.Error("failed to get database instance", "error", err)
+		os.Exit(1)
 	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-	fmt.Println("🟢 Connected to Postgres")
+	slog.Info("connected to database", "driver", DriverPostgres)
 }
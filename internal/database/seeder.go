@@ -1,7 +1,7 @@
 package database
 
 import (
-	"log"
+	"log/slog"
 
 	"golang.org/x/crypto/bcrypt"
 
@@ -11,7 +11,7 @@ import (
 func Seed() {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
 	if err != nil {
-		log.Printf("seeding error: %v", err)
+		slog.Error("seeding failed", "error", err)
 		return
 	}
 	users := []model.User{
@@ -23,7 +23,7 @@ func Seed() {
 	for _, user := range users {
 		err := DB.Create(&user).Error
 		if err != nil {
-			log.Printf("seeding error: %v", err)
+			slog.Error("seeding failed", "error", err)
 		}
 	}
 }
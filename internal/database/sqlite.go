@@ -0,0 +1,54 @@
+package database
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"github.com/riparuk/meet-book-api/internal/config"
+	"gorm.io/gorm"
+)
+
+func openSQLite(dsn string) (*gorm.DB, error) {
+	// Foreign keys are off by default in SQLite
+	if !strings.Contains(dsn, "foreign_keys") {
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		dsn += sep + "_pragma=foreign_keys(1)"
+	}
+
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: newLogger(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer, serialize access through one connection
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
+// InitSQLite opens the file database at cfg.SQLitePath, suitable for local
+// development and single binary deployments. Pool settings don't apply,
+// SQLite always uses a single connection.
+func InitSQLite(cfg config.DatabaseConfig) {
+	path := cfg.SQLitePath
+
+	var err error
+	DB, err = openSQLite(path)
+	if err != nil {
+		slog.Error("failed to open SQLite database", "path", path, "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("connected to database", "driver", DriverSQLite, "path", path)
+}
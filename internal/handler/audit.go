@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+)
+
+// defaultAuditLimit is the number of entries returned without a limit
+const defaultAuditLimit = 100
+
+type AuditHandler struct {
+	logs repository.AuditLogRepository
+}
+
+func NewAuditHandler(logs repository.AuditLogRepository) *AuditHandler {
+	return &AuditHandler{logs: logs}
+}
+
+// GetAuditLogs godoc
+// @Summary Query the audit log
+// @Description List the recorded creates, updates, cancellations and deletions of users, rooms and bookings, newest first (audit.read). Each entry has the actor, the changed fields before and after, the client IP and the request ID.
+// @Tags audit
+// @Produce json
+// @Security BearerAuth
+// @Param entity_type query string false "Kind of record" Enums(user, room, booking)
+// @Param entity_id query string false "ID of the record"
+// @Param actor_id query string false "ID of the user or service account that made the change"
+// @Param from query string false "Changes at or after this time, RFC 3339"
+// @Param to query string false "Changes before this time, RFC 3339"
+// @Param limit query int false "Maximum number of entries, 100 by default and at most 1000"
+// @Success 200 {array} model.AuditLog
+// @Failure 400 {object} map[string]string
+// @Router /audit-logs [get]
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query model.AuditLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := model.AuditLogFilter{EntityType: query.EntityType, Limit: query.Limit}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if query.EntityID != "" {
+		id := uuid.MustParse(query.EntityID)
+		filter.EntityID = &id
+	}
+	if query.ActorID != "" {
+		id := uuid.MustParse(query.ActorID)
+		filter.ActorID = &id
+	}
+	if !query.From.IsZero() {
+		filter.From = &query.From
+	}
+	if !query.To.IsZero() {
+		filter.To = &query.To
+	}
+
+	entries, err := h.logs.WithContext(ctx).Find(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
@@ -2,52 +2,162 @@ package handler
 
 import (
 	"net/http"
-	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/metrics"
 	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/ratelimit"
 	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
 	"github.com/riparuk/meet-book-api/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// AuthLimits are the per account request limits, per IP limits are applied
+// by middleware.RateLimit
+type AuthLimits struct {
+	LoginPerAccount    int
+	RegisterPerAccount int
+}
+
 type AuthHandler struct {
-	repo repository.UserRepository
+	repo         repository.UserRepository
+	invites      repository.InvitationRepository
+	limiter      *ratelimit.Limiter
+	limits       AuthLimits
+	verification *VerificationHandler
 }
 
-func NewAuthHandler(repo repository.UserRepository) *AuthHandler {
-	return &AuthHandler{repo}
+func NewAuthHandler(repo repository.UserRepository, invites repository.InvitationRepository, limiter *ratelimit.Limiter, limits AuthLimits, verification *VerificationHandler) *AuthHandler {
+	return &AuthHandler{repo: repo, invites: invites, limiter: limiter, limits: limits, verification: verification}
+}
+
+// accountKey normalizes an email so case variants share one lockout
+func accountKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// limiterKey is the rate limit and lockout key of email in organization
+// org, so the same address in two organizations is limited apart
+func limiterKey(org uuid.UUID, email string) string {
+	return org.String() + ":" + accountKey(email)
+}
+
+// unknownAccountHash is compared against when a login names no account, so
+// unknown emails take as long to refuse as wrong passwords
+var unknownAccountHash = []byte("$2a$10$aeflQEY333r9NEFMSViJzO3g0WZaYviPQ/mXr0sMcEXTvjZvtzyaS")
+
+func tooManyRequests(c *gin.Context, retryAfter time.Duration, message string) {
+	c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": message})
 }
 
 // Login godoc
 // @Summary Login
-// @Description Login
+// @Description Login. When two-factor authentication is enabled the response carries two_factor_required and a challenge_token to complete with /auth/login/2fa.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param email body model.LoginRequest true "Email"
 // @Success 200 {object} model.User
+// @Failure 403 {object} map[string]string "Account deactivated"
+// @Failure 429 {object} map[string]string "Too many attempts, see the Retry-After header"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
 	var req model.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
 		return
 	}
 
-	user, err := h.repo.FindByEmail(req.Email)
+	// One form of the address for the lockout and the lookup alike
+	email := accountKey(req.Email)
+	account := limiterKey(tenant.FromContext(ctx), email)
+	allowed, retryAfter, err := h.limiter.Allow(ctx, "login:account:"+account, h.limits.LoginPerAccount)
+	if err != nil {
+		log.Error("rate limiter unavailable", "error", err)
+	} else if !allowed {
+		metrics.LoginFailures.WithLabelValues("rate_limited").Inc()
+		tooManyRequests(c, retryAfter, "Too many login attempts, try again later")
+		return
+	}
+
+	locked, retryAfter, err := h.limiter.Locked(ctx, account)
+	if err != nil {
+		log.Error("rate limiter unavailable", "error", err)
+	} else if locked {
+		metrics.LoginFailures.WithLabelValues("locked").Inc()
+		tooManyRequests(c, retryAfter, "Account temporarily locked after repeated failed logins, try again later")
+		return
+	}
+
+	// Unknown emails count as failures too and still run bcrypt, so neither
+	// responses nor their timing reveal which accounts exist
+	user, err := h.repo.WithContext(ctx).FindByEmail(email)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		bcrypt.CompareHashAndPassword(unknownAccountHash, []byte(req.Password))
+		metrics.LoginFailures.WithLabelValues("unknown_user").Inc()
+		h.recordFailure(c, account)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		metrics.LoginFailures.WithLabelValues("invalid_password").Inc()
+		h.recordFailure(c, account)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
+	if rejectDeactivated(c, user) {
+		return
+	}
+
+	// Failures keep counting until the second factor is verified too
+	if user.TOTPEnabled {
+		challenge, err := utils.GenerateChallengeJWT(user.ID.String(), user.OrganizationID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": gin.H{
+				"two_factor_required": true,
+				"challenge_token":     challenge,
+			},
+		})
+		return
+	}
 
-	token, err := utils.GenerateJWT(user.ID.String(), user.Role)
+	if err := h.limiter.RecordSuccess(ctx, account); err != nil {
+		log.Error("failed to clear login failures", "error", err)
+	}
+
+	respondWithToken(c, user)
+}
+
+// rejectDeactivated answers 403 when an admin has deactivated the account.
+// Call it only after the credentials were verified so it reveals nothing
+// about the account to someone who does not own it.
+func rejectDeactivated(c *gin.Context, user *model.User) bool {
+	if !user.Deactivated() {
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Account has been deactivated"})
+	return true
+}
+
+// respondWithToken completes a login by issuing an access token
+func respondWithToken(c *gin.Context, user *model.User) {
+	token, err := utils.GenerateJWT(user.ID.String(), user.Role, user.OrganizationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
@@ -57,10 +167,11 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		"data": gin.H{
 			"token": token,
 			"user": gin.H{
-				"id":    user.ID,
-				"email": user.Email,
-				"name":  user.Name,
-				"role":  user.Role,
+				"id":     user.ID,
+				"email":  user.Email,
+				"name":   user.Name,
+				"role":   user.Role,
+				"status": user.Status,
 			},
 		},
 	})
@@ -68,38 +179,33 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 // Register godoc
 // @Summary Register
-// @Description Register
+// @Description Register an unverified account and email a verification link. Unverified users can sign in but not book rooms. With an invitation_token the account gets the invitation's role and needs no verification.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param email body model.RegisterRequest true "Email"
 // @Success 200 {object} model.User
+// @Failure 429 {object} map[string]string "Too many attempts, see the Retry-After header"
 // @Router /auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	var req model.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
 		return
 	}
 
-	role := model.RoleUser
-	if req.MasterPassword != "" {
-		masterPassword := os.Getenv("MASTER_PASSWORD")
-		if masterPassword == "" {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Master password not configured"})
-			return
-		}
-
-		if req.MasterPassword != masterPassword {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid master password"})
-			return
-		}
-
-		role = model.RoleAdmin
+	allowed, retryAfter, err := h.limiter.Allow(ctx, "register:account:"+limiterKey(tenant.FromContext(ctx), req.Email), h.limits.RegisterPerAccount)
+	if err != nil {
+		logger.FromContext(ctx).Error("rate limiter unavailable", "error", err)
+	} else if !allowed {
+		tooManyRequests(c, retryAfter, "Too many registration attempts, try again later")
+		return
 	}
 
 	// Check if email already exists
-	_, err := h.repo.FindByEmail(req.Email)
+	_, err = h.repo.WithContext(ctx).FindByEmail(req.Email)
 	if err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
 		return
@@ -111,26 +217,100 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	role, status := model.RoleUser, model.UserStatusUnverified
+	if req.InvitationToken != "" {
+		invitation, err := h.invites.WithContext(ctx).FindPending(utils.HashToken(req.InvitationToken), time.Now())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invitation is invalid, expired or already used"})
+			return
+		}
+		if invitation.Email != accountKey(req.Email) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invitation was sent to a different email address"})
+			return
+		}
+		// Accept is guarded, only one of two racing registrations wins
+		if err := h.invites.WithContext(ctx).Accept(invitation.ID, time.Now()); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invitation is invalid, expired or already used"})
+			return
+		}
+		// The invitation link proves the user owns the email
+		role, status = invitation.Role, model.UserStatusActive
+		logger.FromContext(ctx).Info("invitation accepted", "invitation", invitation.ID, "role", role)
+	}
+
 	user := model.User{
 		Email:    req.Email,
 		Name:     req.Name,
 		Password: string(hashedPassword),
 		Role:     role,
+		Status:   status,
 	}
 
-	if err := h.repo.Create(&user); err != nil {
+	if err := h.repo.WithContext(ctx).Create(&user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
+	message := "User registered"
+	if user.Status == model.UserStatusUnverified {
+		message = "User registered, check your email to verify your account"
+		// The account exists either way, the user can ask for a new link
+		if err := h.verification.SendVerification(ctx, &user); err != nil {
+			logger.FromContext(ctx).Error("failed to send verification email", "error", err)
+		}
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"data": gin.H{
-			"message": "User registered successfully",
+			"message": message,
 			"user": gin.H{
-				"id":    user.ID,
-				"email": user.Email,
-				"name":  user.Name,
+				"id":     user.ID,
+				"email":  user.Email,
+				"name":   user.Name,
+				"role":   user.Role,
+				"status": user.Status,
 			},
 		},
 	})
 }
+
+// recordFailure counts a failed login towards the account lockout
+func (h *AuthHandler) recordFailure(c *gin.Context, account string) {
+	ctx := c.Request.Context()
+	lockout, err := h.limiter.RecordFailure(ctx, account)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to record login failure", "error", err)
+		return
+	}
+	if lockout > 0 {
+		logger.FromContext(ctx).Warn("account locked after repeated failed logins", "account", account, "lockout", lockout.String())
+	}
+}
+
+// UnlockUser godoc
+// @Summary Unlock a user account
+// @Description Lift a login lockout and clear the failed login count (users.write)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/unlock [post]
+func (h *AuthHandler) UnlockUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, err := h.repo.WithContext(ctx).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := h.limiter.Unlock(ctx, limiterKey(user.OrganizationID, user.Email)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+
+	logger.FromContext(ctx).Info("account unlocked", "user", user.ID)
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "User unlocked"}})
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/riparuk/meet-book-api/internal/model"
+)
+
+func TestLoginIgnoresEmailCase(t *testing.T) {
+	s := newTestServer(t)
+	user := s.user(t, "alice@example.com", model.RoleUser)
+
+	code, resp := s.do(t, http.MethodPost, "/api/auth/login", "", map[string]any{"email": "Alice@Example.com", "password": "password1"})
+	if data, _ := resp["data"].(map[string]any); code != http.StatusOK || data["token"] == nil {
+		t.Fatalf("login with another case = %d %v, want a token", code, resp)
+	}
+
+	// failures under any case lock the one account
+	for i := 0; i < 5; i++ {
+		email := user.Email
+		if i%2 == 0 {
+			email = strings.ToUpper(email)
+		}
+		if code, resp := s.do(t, http.MethodPost, "/api/auth/login", "", map[string]any{"email": email, "password": "wrong"}); code != http.StatusUnauthorized {
+			t.Fatalf("wrong password = %d %v, want 401", code, resp)
+		}
+	}
+	if code, resp := s.do(t, http.MethodPost, "/api/auth/login", "", map[string]any{"email": user.Email, "password": "password1"}); code != http.StatusTooManyRequests {
+		t.Fatalf("login after failures = %d %v, want 429", code, resp)
+	}
+}
@@ -1,34 +1,52 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/mailer"
+	"github.com/riparuk/meet-book-api/internal/metrics"
 	"github.com/riparuk/meet-book-api/internal/model"
 	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/strike"
 )
 
 type BookingHandler struct {
-	repo repository.BookingRepository
+	repo        repository.BookingRepository
+	access      roomAccess
+	delegations repository.DelegationRepository
+	notifier    bookingNotifier
+	strikes     *strike.Tracker
 }
 
-func NewBookingHandler(repo repository.BookingRepository) *BookingHandler {
-	return &BookingHandler{repo}
+func NewBookingHandler(repo repository.BookingRepository, rooms repository.RoomRepository, delegations repository.DelegationRepository, users repository.UserRepository, m mailer.Mailer, strikes *strike.Tracker) *BookingHandler {
+	return &BookingHandler{
+		repo:        repo,
+		access:      roomAccess{rooms: rooms},
+		delegations: delegations,
+		notifier:    bookingNotifier{users: users, mailer: m},
+		strikes:     strikes,
+	}
 }
 
 // CreateBooking godoc
 // @Summary Create a new booking
-// @Description Create a new room booking
+// @Description Create a new room booking. Booking for another user needs a delegation from them or bookings.manage_any for the room. The organizer and the caller are emailed. Restricted rooms need both the caller and the booked user on the room's access list, unless the caller has rooms.access_all for it or is a service account.
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param input body model.CreateBookingInput true "Booking details"
 // @Success 201 {object} model.BookingResponse
+// @Failure 403 {object} map[string]string "Restricted room or another user's booking"
+// @Failure 404 {object} map[string]string "Room not found"
 // @Router /bookings [post]
 func (h *BookingHandler) CreateBooking(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	var input model.CreateBookingInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -43,6 +61,9 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 		EndTime:   input.EndTime,
 		Status:    model.BookingStatusActive,
 	}
+	if creator := callerID(c); creator != uuid.Nil {
+		booking.CreatedByID = &creator
+	}
 
 	// Validasi booking
 	if err := booking.Validate(); err != nil {
@@ -50,50 +71,64 @@ func (h *BookingHandler) CreateBooking(c *gin.Context) {
 		return
 	}
 
+	room, ok := h.access.allow(c, input.RoomID, input.UserID)
+	if !ok || !h.managesBooking(c, room, input.UserID) {
+		return
+	}
+	if !strikesAllow(c, h.strikes, input.UserID, []*model.Room{room}, 1) {
+		return
+	}
+
 	// Check if room is available
-	available, err := h.repo.IsRoomAvailable(input.RoomID, input.StartTime, input.EndTime, nil)
+	available, err := h.repo.WithContext(ctx).IsRoomAvailable(input.RoomID, input.StartTime, input.EndTime, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check room availability"})
 		return
 	}
 	if !available {
+		metrics.AvailabilityConflicts.Inc()
 		c.JSON(http.StatusBadRequest, gin.H{"error": "room is not available for the selected time slot"})
 		return
 	}
 
 	// Create the booking
-	if err := h.repo.Create(&booking); err != nil {
+	if err := h.repo.WithContext(ctx).Create(&booking); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create booking"})
 		return
 	}
+	metrics.BookingsCreated.WithLabelValues(booking.RoomID.String()).Inc()
 
 	// Ambil data booking yang baru dibuat untuk mendapatkan data lengkap
-	createdBooking, err := h.repo.FindByID(booking.ID)
+	createdBooking, err := h.repo.WithContext(ctx).FindByID(booking.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch created booking"})
 		return
 	}
+	h.notifier.notify(c, bookingCreated, *createdBooking)
 
 	c.JSON(http.StatusCreated, createdBooking.ToResponse())
 }
 
 // GetBooking godoc
 // @Summary Get a booking by ID
-// @Description Get a booking by its ID
+// @Description Get a booking by its ID, another user's booking needs a delegation from them or bookings.manage_any for the room
 // @Tags bookings
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Booking ID"
 // @Success 200 {object} model.BookingResponse
+// @Failure 404 {object} map[string]string "Missing booking, or one the caller cannot see"
 // @Router /bookings/{id} [get]
 func (h *BookingHandler) GetBooking(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
 		return
 	}
 
-	booking, err := h.repo.FindByID(id)
+	booking, err := h.repo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch booking"})
 		return
@@ -103,13 +138,22 @@ func (h *BookingHandler) GetBooking(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
 		return
 	}
+	ok, err := h.mayView(c, booking)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check booking access"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"data": booking.ToResponse()})
 }
 
 // GetUserBookings godoc
 // @Summary Get all bookings for a user
-// @Description Get all bookings for a specific user
+// @Description Get all bookings for a specific user, another user's bookings are listed only in rooms where the caller has a delegation from them or bookings.manage_any
 // @Tags bookings
 // @Produce json
 // @Security BearerAuth
@@ -117,21 +161,30 @@ func (h *BookingHandler) GetBooking(c *gin.Context) {
 // @Success 200 {array} model.BookingResponse
 // @Router /bookings/users/{user_id} [get]
 func (h *BookingHandler) GetUserBookings(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	userID, err := uuid.Parse(c.Param("user_id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
 		return
 	}
 
-	bookings, err := h.repo.FindByUserID(userID)
+	bookings, err := h.repo.WithContext(ctx).FindByUserID(userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user bookings"})
 		return
 	}
 
-	responses := make([]model.BookingResponse, len(bookings))
-	for i, b := range bookings {
-		responses[i] = b.ToResponse()
+	responses := make([]model.BookingResponse, 0, len(bookings))
+	for i := range bookings {
+		ok, err := h.mayView(c, &bookings[i])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check booking access"})
+			return
+		}
+		if ok {
+			responses = append(responses, bookings[i].ToResponse())
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": responses})
@@ -139,7 +192,7 @@ func (h *BookingHandler) GetUserBookings(c *gin.Context) {
 
 // UpdateBooking godoc
 // @Summary Update a booking
-// @Description Update an existing booking
+// @Description Update an existing booking, fields left out keep their values. Other users' bookings need a delegation from them or bookings.manage_any for the room. A booking that stays or becomes active needs its slot free and the organizer's strikes to allow it, and in a restricted room the caller and the organizer on its access list.
 // @Tags bookings
 // @Accept json
 // @Produce json
@@ -147,8 +200,12 @@ func (h *BookingHandler) GetUserBookings(c *gin.Context) {
 // @Param id path string true "Booking ID"
 // @Param input body model.UpdateBookingInput true "Booking update details"
 // @Success 200 {object} model.BookingResponse
+// @Failure 400 {object} map[string]string "Invalid times or the room is not available"
+// @Failure 403 {object} map[string]string "Restricted room, another user's booking or restricted by strikes"
 // @Router /bookings/{id} [put]
 func (h *BookingHandler) UpdateBooking(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
@@ -156,7 +213,7 @@ func (h *BookingHandler) UpdateBooking(c *gin.Context) {
 	}
 
 	// Get existing booking
-	existing, err := h.repo.FindByID(id)
+	existing, err := h.repo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch booking"})
 		return
@@ -165,6 +222,9 @@ func (h *BookingHandler) UpdateBooking(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
 		return
 	}
+	if !h.managesBooking(c, &existing.Room, existing.UserID) {
+		return
+	}
 
 	var input model.UpdateBookingInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -172,18 +232,7 @@ func (h *BookingHandler) UpdateBooking(c *gin.Context) {
 		return
 	}
 
-	booking := model.Booking{
-		RoomID:    existing.RoomID,
-		UserID:    existing.UserID,
-		StartTime: *input.StartTime,
-		EndTime:   *input.EndTime,
-		Status:    existing.Status,
-	}
-
-	if err := booking.Validate(); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+	wasActive := existing.Status == model.BookingStatusActive
 
 	// Update fields if provided
 	if input.Status != nil {
@@ -196,37 +245,75 @@ func (h *BookingHandler) UpdateBooking(c *gin.Context) {
 		existing.EndTime = *input.EndTime
 	}
 
-	// If time is being updated, check room availability
-	if input.StartTime != nil || input.EndTime != nil {
-		available, err := h.repo.IsRoomAvailable(existing.RoomID, existing.StartTime, existing.EndTime, &existing.ID)
+	if err := existing.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// A booking that stays or becomes active holds its slot, so it must be
+	// free and the organizer's strikes must allow it. Bringing back a
+	// cancelled booking adds one like creating it would. It needs the room
+	// like booking it does, so users taken off a restricted room's access
+	// list cannot move or bring back their bookings there.
+	if existing.Status == model.BookingStatusActive {
+		ok, err := h.access.permits(c, &existing.Room, existing.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check room access"})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": roomAccessDenied})
+			return
+		}
+
+		adding := 0
+		if !wasActive {
+			adding = 1
+		}
+		if !strikesAllow(c, h.strikes, existing.UserID, []*model.Room{&existing.Room}, adding) {
+			return
+		}
+
+		available, err := h.repo.WithContext(ctx).IsRoomAvailable(existing.RoomID, existing.StartTime, existing.EndTime, &existing.ID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check room availability"})
 			return
 		}
 		if !available {
+			metrics.AvailabilityConflicts.Inc()
 			c.JSON(http.StatusBadRequest, gin.H{"error": "room is not available for the selected time slot"})
 			return
 		}
 	}
 
-	if err := h.repo.Update(existing); err != nil {
+	if err := h.repo.WithContext(ctx).Update(existing); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update booking"})
 		return
 	}
+	event := bookingUpdated
+	if wasActive && existing.Status == model.BookingStatusCancelled {
+		metrics.BookingsCancelled.WithLabelValues(existing.RoomID.String()).Inc()
+		event = bookingCancelled
+		h.countCancellations(c, *existing)
+	}
+	h.notifier.notify(c, event, *existing)
 
 	c.JSON(http.StatusOK, gin.H{"data": existing.ToResponse()})
 }
 
 // CancelBooking godoc
 // @Summary Cancel a booking
-// @Description Cancel an existing booking
+// @Description Cancel an existing booking, other users' bookings need a delegation from them or bookings.manage_any for the room
 // @Tags bookings
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Booking ID"
 // @Success 200 {object} model.BookingResponse
+// @Failure 403 {object} map[string]string "Another user's booking"
 // @Router /bookings/{id}/cancel [post]
 func (h *BookingHandler) CancelBooking(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
@@ -234,7 +321,7 @@ func (h *BookingHandler) CancelBooking(c *gin.Context) {
 	}
 
 	// Get existing booking
-	existing, err := h.repo.FindByID(id)
+	existing, err := h.repo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch booking"})
 		return
@@ -244,38 +331,121 @@ func (h *BookingHandler) CancelBooking(c *gin.Context) {
 		return
 	}
 
+	if !h.managesBooking(c, &existing.Room, existing.UserID) {
+		return
+	}
+
 	if existing.Status == model.BookingStatusCancelled {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "booking is already cancelled"})
 		return
 	}
 
-	if err := h.repo.Cancel(id); err != nil {
+	if err := h.repo.WithContext(ctx).Cancel(id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel booking"})
 		return
 	}
+	metrics.BookingsCancelled.WithLabelValues(existing.RoomID.String()).Inc()
 
 	existing.Status = model.BookingStatusCancelled
+	h.notifier.notify(c, bookingCancelled, *existing)
+	h.countCancellations(c, *existing)
+	c.JSON(http.StatusOK, gin.H{"data": existing.ToResponse()})
+}
+
+// CheckInBooking godoc
+// @Summary Check in to a booking
+// @Description Record that a booking is in use, from 15 minutes before it starts until it ends, optionally with how many people came. Checking in again corrects the number. Bookings that end without a check-in count as no-shows. Other users' bookings need a delegation from them or bookings.manage_any for the room.
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Booking ID"
+// @Param input body model.CheckInInput false "Attendance"
+// @Success 200 {object} object{data=model.BookingResponse}
+// @Failure 400 {object} map[string]string "Cancelled booking or outside the check-in window"
+// @Failure 403 {object} map[string]string "Another user's booking"
+// @Router /bookings/{id}/check-in [post]
+func (h *BookingHandler) CheckInBooking(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	var input model.CheckInInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	existing, err := h.repo.WithContext(ctx).FindByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch booking"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		return
+	}
+	if !h.managesBooking(c, &existing.Room, existing.UserID) {
+		return
+	}
+
+	if existing.Status != model.BookingStatusActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cancelled bookings cannot be checked in to"})
+		return
+	}
+	now := time.Now()
+	if now.Before(existing.StartTime.Add(-model.CheckInOpensBefore)) || !now.Before(existing.EndTime) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "check-in opens 15 minutes before the booking starts and closes when it ends"})
+		return
+	}
+
+	if existing.CheckedInAt == nil {
+		existing.CheckedInAt = &now
+	}
+	if input.Attendees != nil {
+		existing.Attendees = input.Attendees
+	}
+	if err := h.repo.WithContext(ctx).Update(existing); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check in"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"data": existing.ToResponse()})
 }
 
 // GetUpcomingBookings godoc
 // @Summary Get upcoming bookings
-// @Description Get a list of all upcoming bookings
+// @Description Get a list of all upcoming bookings, leaving out restricted rooms the caller is not on the access list of
 // @Tags bookings
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {array} model.BookingResponse
 // @Router /bookings/upcoming [get]
 func (h *BookingHandler) GetUpcomingBookings(c *gin.Context) {
-	bookings, err := h.repo.GetUpcomingBookings()
+	ctx := c.Request.Context()
+
+	bookings, err := h.repo.WithContext(ctx).GetUpcomingBookings()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch upcoming bookings"})
 		return
 	}
 
-	responses := make([]model.BookingResponse, len(bookings))
-	for i, b := range bookings {
-		responses[i] = b.ToResponse()
+	responses := make([]model.BookingResponse, 0, len(bookings))
+	for i := range bookings {
+		ok, err := h.access.permits(c, &bookings[i].Room)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check room access"})
+			return
+		}
+		if ok {
+			responses = append(responses, bookings[i].ToResponse())
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": responses})
@@ -289,15 +459,22 @@ func (h *BookingHandler) GetUpcomingBookings(c *gin.Context) {
 // @Security BearerAuth
 // @Param room_id path string true "Room ID"
 // @Success 200 {object} object{data=[]model.BookingResponse}
+// @Failure 403 {object} object{error=string} "Restricted room"
+// @Failure 404 {object} object{error=string} "Room not found"
 // @Router /bookings/room/{room_id} [get]
 func (h *BookingHandler) GetRoomBookings(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	roomID, err := uuid.Parse(c.Param("room_id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid room id"})
 		return
 	}
+	if _, ok := h.access.allow(c, roomID); !ok {
+		return
+	}
 
-	bookings, err := h.repo.FindByRoomID(roomID)
+	bookings, err := h.repo.WithContext(ctx).FindByRoomID(roomID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch room bookings"})
 		return
@@ -322,14 +499,21 @@ func (h *BookingHandler) GetRoomBookings(c *gin.Context) {
 // @Param status query string false "Filter by status (e.g., 'active', 'cancelled')"
 // @Success 200 {object} object{data=[]model.BookingResponse} "List of bookings"
 // @Failure 400 {object} object{error=string} "Invalid room ID or date format"
+// @Failure 403 {object} object{error=string} "Restricted room"
+// @Failure 404 {object} object{error=string} "Room not found"
 // @Failure 500 {object} object{error=string} "Failed to fetch room bookings"
 // @Router /bookings/room/{room_id}/{date} [get]
 func (h *BookingHandler) GetRoomBookingsByDate(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	roomID, err := uuid.Parse(c.Param("room_id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid room id"})
 		return
 	}
+	if _, ok := h.access.allow(c, roomID); !ok {
+		return
+	}
 
 	date, err := time.Parse("2006-01-02", c.Param("date"))
 	if err != nil {
@@ -344,7 +528,7 @@ func (h *BookingHandler) GetRoomBookingsByDate(c *gin.Context) {
 		statusPtr = &status
 	}
 
-	bookings, err := h.repo.FindByRoomIDAndDate(roomID, date, statusPtr)
+	bookings, err := h.repo.WithContext(ctx).FindByRoomIDAndDate(roomID, date, statusPtr)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch room bookings"})
 		return
@@ -357,3 +541,326 @@ func (h *BookingHandler) GetRoomBookingsByDate(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": responses})
 }
+
+// CreateBatchBooking godoc
+// @Summary Create several bookings at once
+// @Description Create bookings across rooms and times in a single transaction, for the authenticated user or for user_id. Booking for another user needs a delegation from them or bookings.manage_any for each room. Either all bookings are created or none are.
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.CreateBatchBookingInput true "Batch booking details"
+// @Success 201 {object} object{data=model.BookingGroupResponse}
+// @Failure 400 {object} object{error=string,conflicts=[]model.BatchBookingConflict} "Invalid items"
+// @Failure 409 {object} object{error=string,conflicts=[]model.BatchBookingConflict} "Unavailable items"
+// @Router /bookings/batch [post]
+func (h *BookingHandler) CreateBatchBooking(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userUUID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var input model.CreateBatchBookingInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organizer := userUUID
+	if input.UserID != nil {
+		organizer = *input.UserID
+	}
+	delegated, err := h.actsFor(c, organizer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check delegation"})
+		return
+	}
+
+	bookings := make([]model.Booking, len(input.Bookings))
+	var conflicts []model.BatchBookingConflict
+	rooms := make(map[uuid.UUID]string)
+	var booked []*model.Room
+	for i, item := range input.Bookings {
+		bookings[i] = model.Booking{
+			RoomID:      item.RoomID,
+			UserID:      organizer,
+			CreatedByID: &userUUID,
+			StartTime:   item.StartTime,
+			EndTime:     item.EndTime,
+			Status:      model.BookingStatusActive,
+		}
+
+		if err := bookings[i].Validate(); err != nil {
+			conflicts = append(conflicts, batchConflict(i, bookings[i], err.Error()))
+			continue
+		}
+
+		reason, checked := rooms[item.RoomID]
+		if !checked {
+			var room *model.Room
+			room, reason, err = h.roomProblem(c, item.RoomID, organizer, delegated)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check room access"})
+				return
+			}
+			rooms[item.RoomID] = reason
+			if room != nil {
+				booked = append(booked, room)
+			}
+		}
+		if reason != "" {
+			conflicts = append(conflicts, batchConflict(i, bookings[i], reason))
+			continue
+		}
+
+		// Items of the same batch must not overlap each other
+		for j := 0; j < i; j++ {
+			if bookings[j].RoomID == item.RoomID && bookings[j].Overlaps(item.StartTime, item.EndTime) {
+				conflicts = append(conflicts, batchConflict(i, bookings[i], fmt.Sprintf("overlaps booking at index %d", j)))
+				break
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bookings in batch", "conflicts": conflicts})
+		return
+	}
+	if !strikesAllow(c, h.strikes, organizer, booked, len(bookings)) {
+		return
+	}
+
+	group := model.BookingGroup{
+		UserID: organizer,
+		Title:  input.Title,
+	}
+
+	unavailable, err := h.repo.WithContext(ctx).CreateGroup(&group, bookings)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create bookings"})
+		return
+	}
+	if len(unavailable) > 0 {
+		metrics.AvailabilityConflicts.Add(float64(len(unavailable)))
+		for _, i := range unavailable {
+			conflicts = append(conflicts, batchConflict(i, bookings[i], "room is not available for the selected time slot"))
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "some rooms are not available, no bookings were created", "conflicts": conflicts})
+		return
+	}
+
+	for _, b := range bookings {
+		metrics.BookingsCreated.WithLabelValues(b.RoomID.String()).Inc()
+	}
+
+	createdGroup, err := h.repo.WithContext(ctx).FindGroupByID(group.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch created bookings"})
+		return
+	}
+	h.notifier.notify(c, bookingCreated, createdGroup.Bookings...)
+
+	c.JSON(http.StatusCreated, gin.H{"data": createdGroup.ToResponse()})
+}
+
+// GetBookingGroup godoc
+// @Summary Get a booking group
+// @Description Get a group of bookings created together in a batch, another user's group needs a delegation from them or bookings.manage_any for each room
+// @Tags bookings
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Booking group ID"
+// @Success 200 {object} object{data=model.BookingGroupResponse}
+// @Failure 404 {object} map[string]string "Missing booking group, or one the caller cannot see"
+// @Router /bookings/groups/{id} [get]
+func (h *BookingHandler) GetBookingGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking group id"})
+		return
+	}
+
+	group, err := h.repo.WithContext(ctx).FindGroupByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch booking group"})
+		return
+	}
+	if group == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "booking group not found"})
+		return
+	}
+	for i := range group.Bookings {
+		ok, err := h.mayView(c, &group.Bookings[i])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check booking group access"})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking group not found"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": group.ToResponse()})
+}
+
+// CancelBookingGroup godoc
+// @Summary Cancel a booking group
+// @Description Cancel every active booking in a booking group, another user's group needs a delegation from them or bookings.manage_any for each room
+// @Tags bookings
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Booking group ID"
+// @Success 200 {object} object{data=model.BookingGroupResponse}
+// @Failure 403 {object} map[string]string "Another user's booking group"
+// @Router /bookings/groups/{id}/cancel [post]
+func (h *BookingHandler) CancelBookingGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking group id"})
+		return
+	}
+
+	group, err := h.repo.WithContext(ctx).FindGroupByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch booking group"})
+		return
+	}
+	if group == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "booking group not found"})
+		return
+	}
+	for i := range group.Bookings {
+		if !h.managesBooking(c, &group.Bookings[i].Room, group.UserID) {
+			return
+		}
+	}
+
+	if err := h.repo.WithContext(ctx).CancelGroup(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel booking group"})
+		return
+	}
+
+	var cancelled []model.Booking
+	for i := range group.Bookings {
+		if group.Bookings[i].Status == model.BookingStatusActive {
+			metrics.BookingsCancelled.WithLabelValues(group.Bookings[i].RoomID.String()).Inc()
+			cancelled = append(cancelled, group.Bookings[i])
+			cancelled[len(cancelled)-1].Status = model.BookingStatusCancelled
+		}
+		group.Bookings[i].Status = model.BookingStatusCancelled
+	}
+	if len(cancelled) > 0 {
+		h.notifier.notify(c, bookingCancelled, cancelled...)
+		h.countCancellations(c, cancelled...)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": group.ToResponse()})
+}
+
+// roomProblem returns the room and why the caller cannot book it for
+// organizer, or "" when they can. delegated tells whether they act for the
+// organizer.
+func (h *BookingHandler) roomProblem(c *gin.Context, roomID, organizer uuid.UUID, delegated bool) (*model.Room, string, error) {
+	room, err := h.access.rooms.WithContext(c.Request.Context()).FindByID(roomID)
+	if err != nil {
+		return nil, "", err
+	}
+	if room == nil {
+		return nil, "room not found", nil
+	}
+	if !delegated && !can(c, model.PermBookingsManageAny, room) {
+		return room, manageBookingsDenied, nil
+	}
+	ok, err := h.access.permits(c, room, organizer)
+	if err != nil || ok {
+		return room, "", err
+	}
+	return room, roomAccessDenied, nil
+}
+
+// countCancellations hands the cancelled bookings the caller organizes, or
+// acts for the organizer of, to the strike tracker. Cancellations by admins
+// and integrations never earn strikes.
+func (h *BookingHandler) countCancellations(c *gin.Context, cancelled ...model.Booking) {
+	var own []model.Booking
+	for _, b := range cancelled {
+		if ok, err := h.actsFor(c, b.UserID); err == nil && ok {
+			own = append(own, b)
+		}
+	}
+	h.strikes.Cancelled(c.Request.Context(), own...)
+}
+
+// actsFor reports whether the caller is userID or one of their delegates
+func (h *BookingHandler) actsFor(c *gin.Context, userID uuid.UUID) (bool, error) {
+	caller := callerID(c)
+	if caller == uuid.Nil {
+		return false, nil
+	}
+	if userID == caller {
+		return true, nil
+	}
+	return h.delegations.WithContext(c.Request.Context()).IsDelegate(userID, caller)
+}
+
+// managesBooking reports whether the caller may create, change or cancel a
+// booking of userID in room, which takes a delegation from userID or
+// bookings.manage_any for the room unless it is their own, and responds
+// with 403 when they may not
+func (h *BookingHandler) managesBooking(c *gin.Context, room *model.Room, userID uuid.UUID) bool {
+	ok, err := h.mayManage(c, room, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check delegation"})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": manageBookingsDenied})
+	}
+	return ok
+}
+
+// mayManage reports whether the caller may manage a booking of userID in
+// room, without responding
+func (h *BookingHandler) mayManage(c *gin.Context, room *model.Room, userID uuid.UUID) (bool, error) {
+	if can(c, model.PermBookingsManageAny, room) {
+		return true, nil
+	}
+	return h.actsFor(c, userID)
+}
+
+// mayView reports whether the caller may see b, which takes what managing
+// it takes and access to its room. Bookings they may not see are answered
+// as missing rather than refused, so their IDs cannot be probed.
+func (h *BookingHandler) mayView(c *gin.Context, b *model.Booking) (bool, error) {
+	ok, err := h.mayManage(c, &b.Room, b.UserID)
+	if err != nil || !ok {
+		return false, err
+	}
+	return h.access.permits(c, &b.Room)
+}
+
+// manageBookingsDenied explains why another user's booking cannot be made
+// or changed
+const manageBookingsDenied = "managing other users' bookings in this room needs a delegation from them or bookings.manage_any"
+
+func batchConflict(index int, b model.Booking, reason string) model.BatchBookingConflict {
+	return model.BatchBookingConflict{
+		Index:     index,
+		RoomID:    b.RoomID,
+		StartTime: b.StartTime,
+		EndTime:   b.EndTime,
+		Error:     reason,
+	}
+}
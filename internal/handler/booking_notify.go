@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/mailer"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+)
+
+type bookingEvent string
+
+const (
+	bookingCreated   bookingEvent = "created"
+	bookingUpdated   bookingEvent = "changed"
+	bookingCancelled bookingEvent = "cancelled"
+)
+
+// bookingNotifier emails the organizer of a booking, and whoever made it
+// for them, when it is created, changed or cancelled. Changes the recipient
+// made to their own bookings follow their booking_receipts preference,
+// changes made by anyone else their booking_updates preference. Delegates
+// always get a copy of what they did for someone else.
+type bookingNotifier struct {
+	users  repository.UserRepository
+	mailer mailer.Mailer
+}
+
+// notify sends each recipient one message listing the bookings that
+// concern them. Failures are logged, the bookings have already changed.
+func (n bookingNotifier) notify(c *gin.Context, event bookingEvent, bookings ...model.Booking) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+	users := n.users.WithContext(ctx)
+
+	var recipients []uuid.UUID
+	concerns := make(map[uuid.UUID][]model.Booking)
+	for _, b := range bookings {
+		ids := []uuid.UUID{b.UserID}
+		if b.CreatedByID != nil && *b.CreatedByID != b.UserID {
+			ids = append(ids, *b.CreatedByID)
+		}
+		for _, id := range ids {
+			if _, seen := concerns[id]; !seen {
+				recipients = append(recipients, id)
+			}
+			concerns[id] = append(concerns[id], b)
+		}
+	}
+
+	caller := callerID(c)
+	actor := "An integration"
+	if caller != uuid.Nil {
+		if u, err := users.FindByID(caller.String()); err == nil {
+			actor = u.Name
+		}
+	}
+
+	for _, id := range recipients {
+		user, err := users.FindByID(id.String())
+		if err != nil || user.Deactivated() {
+			continue
+		}
+		wanted := user.Notifications.BookingUpdates
+		who := actor
+		if id == caller {
+			wanted = user.Notifications.BookingReceipts || forOthers(concerns[id], id)
+			who = "You"
+		}
+		if !wanted {
+			continue
+		}
+
+		msg := bookingMessage(user, who, event, concerns[id])
+		if err := n.mailer.Send(ctx, msg); err != nil {
+			log.Error("failed to send booking notification", "error", err, "user", user.ID, "event", event)
+		}
+	}
+}
+
+// forOthers reports whether any of bookings is organized by someone other
+// than userID
+func forOthers(bookings []model.Booking, userID uuid.UUID) bool {
+	for _, b := range bookings {
+		if b.UserID != userID {
+			return true
+		}
+	}
+	return false
+}
+
+// bookingMessage describes the bookings to user, with times in their
+// timezone
+func bookingMessage(user *model.User, who string, event bookingEvent, bookings []model.Booking) mailer.Message {
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	noun := "booking"
+	if len(bookings) > 1 {
+		noun = "bookings"
+	}
+
+	var lines strings.Builder
+	for _, b := range bookings {
+		start, end := b.StartTime.In(loc), b.EndTime.In(loc)
+		endFormat := "15:04 MST"
+		if start.YearDay() != end.YearDay() || start.Year() != end.Year() {
+			endFormat = "Mon 2 Jan 2006 15:04 MST"
+		}
+		fmt.Fprintf(&lines, "- %s, %s to %s", b.Room.Name, start.Format("Mon 2 Jan 2006 15:04"), end.Format(endFormat))
+		if b.UserID != user.ID && b.User.Name != "" {
+			fmt.Fprintf(&lines, ", for %s", b.User.Name)
+		}
+		lines.WriteString("\n")
+	}
+
+	return mailer.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("Meet Book %s %s", noun, event),
+		Body: fmt.Sprintf("Hi %s,\n\n%s %s the following %s:\n\n%s\nYou can change which booking emails you receive in your profile.\n",
+			user.Name, who, event, noun, lines.String()),
+	}
+}
@@ -0,0 +1,362 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+)
+
+func bookingBody(room *model.Room, organizer *model.User, hours int) map[string]any {
+	start, end := slot(hours)
+	return map[string]any{"room_id": room.ID, "user_id": organizer.ID, "start_time": start, "end_time": end}
+}
+
+func TestDelegateActsForPrincipal(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.user(t, "alice@example.com", model.RoleUser)
+	dave := s.user(t, "dave@example.com", model.RoleUser)
+	room := s.room(t, "Hall")
+	if err := s.delegations.Grant(alice.ID, dave.ID); err != nil {
+		t.Fatal(err)
+	}
+	daveToken := token(t, dave)
+
+	code, resp := s.do(t, http.MethodPost, "/api/bookings", daveToken, bookingBody(room, alice, 24))
+	if code != http.StatusCreated {
+		t.Fatalf("create for principal = %d %v, want 201", code, resp)
+	}
+	if resp["user_id"] != alice.ID.String() {
+		t.Fatalf("organizer = %v, want alice", resp["user_id"])
+	}
+	id := resp["id"].(string)
+	booking, err := s.bookings.FindByID(uuid.MustParse(id))
+	if err != nil || booking.CreatedByID == nil || *booking.CreatedByID != dave.ID {
+		t.Fatalf("created by = %+v, %v, want dave", booking, err)
+	}
+
+	start, end := slot(26)
+	if code, resp := s.do(t, http.MethodPut, "/api/bookings/"+id, daveToken, map[string]any{"start_time": start, "end_time": end}); code != http.StatusOK {
+		t.Fatalf("update for principal = %d %v, want 200", code, resp)
+	}
+	if code, resp := s.do(t, http.MethodPost, "/api/bookings/"+id+"/cancel", daveToken, nil); code != http.StatusOK {
+		t.Fatalf("cancel for principal = %d %v, want 200", code, resp)
+	}
+
+	// alice hears about each change dave made, and dave gets a copy even
+	// with booking receipts off
+	sent := s.mail.to(alice.Email)
+	if len(sent) != 3 {
+		t.Fatalf("alice got %d emails, want 3", len(sent))
+	}
+	for _, msg := range sent {
+		if !strings.Contains(msg.Body, dave.Name) {
+			t.Errorf("email %q does not name dave:\n%s", msg.Subject, msg.Body)
+		}
+	}
+	sent = s.mail.to(dave.Email)
+	if len(sent) != 3 {
+		t.Fatalf("dave got %d emails, want 3", len(sent))
+	}
+	for _, msg := range sent {
+		if !strings.Contains(msg.Body, "for "+alice.Name) {
+			t.Errorf("email %q does not name alice:\n%s", msg.Subject, msg.Body)
+		}
+	}
+
+	// their own bookings still follow the receipts preference
+	if code, resp := s.do(t, http.MethodPost, "/api/bookings", daveToken, bookingBody(room, dave, 30)); code != http.StatusCreated {
+		t.Fatalf("create own booking = %d %v, want 201", code, resp)
+	}
+	if sent := s.mail.to(dave.Email); len(sent) != 3 {
+		t.Fatalf("dave got %d emails after booking for themselves, want 3", len(sent))
+	}
+}
+
+func TestNonDelegateRefused(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.user(t, "alice@example.com", model.RoleUser)
+	eve := s.user(t, "eve@example.com", model.RoleUser)
+	room := s.room(t, "Hall")
+	eveToken := token(t, eve)
+
+	if code, resp := s.do(t, http.MethodPost, "/api/bookings", eveToken, bookingBody(room, alice, 24)); code != http.StatusForbidden {
+		t.Fatalf("create for another user = %d %v, want 403", code, resp)
+	}
+	start, end := slot(26)
+	batch := map[string]any{"user_id": alice.ID, "bookings": []map[string]any{{"room_id": room.ID, "start_time": start, "end_time": end}}}
+	if code, resp := s.do(t, http.MethodPost, "/api/bookings/batch", eveToken, batch); code == http.StatusCreated {
+		t.Fatalf("batch for another user = %d %v, want it refused", code, resp)
+	}
+
+	code, resp := s.do(t, http.MethodPost, "/api/bookings", token(t, alice), bookingBody(room, alice, 24))
+	if code != http.StatusCreated {
+		t.Fatalf("create own booking = %d %v, want 201", code, resp)
+	}
+	id := resp["id"].(string)
+
+	start, end = slot(28)
+	if code, resp := s.do(t, http.MethodPut, "/api/bookings/"+id, eveToken, map[string]any{"start_time": start, "end_time": end}); code != http.StatusForbidden {
+		t.Fatalf("update another user's booking = %d %v, want 403", code, resp)
+	}
+	if code, resp := s.do(t, http.MethodPost, "/api/bookings/"+id+"/cancel", eveToken, nil); code != http.StatusForbidden {
+		t.Fatalf("cancel another user's booking = %d %v, want 403", code, resp)
+	}
+
+	// a revoked delegation no longer lets eve in
+	if err := s.delegations.Grant(alice.ID, eve.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.delegations.Revoke(alice.ID, eve.ID); err != nil {
+		t.Fatal(err)
+	}
+	if code, resp := s.do(t, http.MethodPost, "/api/bookings/"+id+"/cancel", eveToken, nil); code != http.StatusForbidden {
+		t.Fatalf("cancel after revoking = %d %v, want 403", code, resp)
+	}
+
+	booking, err := s.bookings.FindByID(uuid.MustParse(id))
+	if start, _ := slot(24); err != nil || booking.Status != model.BookingStatusActive || !booking.StartTime.Equal(start) {
+		t.Fatalf("booking = %+v, %v, want it untouched", booking, err)
+	}
+	if sent := s.mail.to(alice.Email); len(sent) != 0 {
+		t.Fatalf("alice got %d emails, want none", len(sent))
+	}
+}
+
+func TestRestrictedRoomNeedsAccess(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.user(t, "alice@example.com", model.RoleUser)
+	room := s.room(t, "Boardroom")
+	room.Restricted = true
+	if err := s.rooms.Update(room); err != nil {
+		t.Fatal(err)
+	}
+	start, end := slot(24)
+	body := map[string]any{"room_id": room.ID, "start_time": start, "end_time": end}
+
+	if code, resp := s.do(t, http.MethodPost, "/api/me/bookings", token(t, alice), body); code != http.StatusForbidden {
+		t.Fatalf("book restricted room = %d %v, want 403", code, resp)
+	}
+
+	board := model.Group{Name: "Board"}
+	if err := s.groups.Create(&board); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.groups.AddMember(board.ID, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.rooms.SetAccess(room.ID, []uuid.UUID{board.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if code, resp := s.do(t, http.MethodPost, "/api/me/bookings", token(t, alice), body); code != http.StatusCreated {
+		t.Fatalf("book restricted room from its group = %d %v, want 201", code, resp)
+	}
+}
+
+func TestRoomAccessRemoved(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.user(t, "alice@example.com", model.RoleUser)
+	room := s.room(t, "Boardroom")
+	room.Restricted = true
+	if err := s.rooms.Update(room); err != nil {
+		t.Fatal(err)
+	}
+	board := model.Group{Name: "Board"}
+	if err := s.groups.Create(&board); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.groups.AddMember(board.ID, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.rooms.SetAccess(room.ID, []uuid.UUID{board.ID}); err != nil {
+		t.Fatal(err)
+	}
+	aliceToken := token(t, alice)
+
+	code, resp := s.do(t, http.MethodPost, "/api/bookings", aliceToken, bookingBody(room, alice, 24))
+	if code != http.StatusCreated {
+		t.Fatalf("book from the room's group = %d %v, want 201", code, resp)
+	}
+	path := "/api/bookings/" + resp["id"].(string)
+	if err := s.groups.RemoveMember(board.ID, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, resp := s.do(t, http.MethodGet, path, aliceToken, nil); code != http.StatusNotFound {
+		t.Fatalf("read after leaving the group = %d %v, want 404", code, resp)
+	}
+	for _, list := range []string{"/api/bookings/upcoming", "/api/bookings/users/" + alice.ID.String()} {
+		code, resp := s.do(t, http.MethodGet, list, aliceToken, nil)
+		if listed, _ := resp["data"].([]any); code != http.StatusOK || len(listed) != 0 {
+			t.Errorf("%s after leaving the group = %d %v, want no bookings", list, code, resp)
+		}
+	}
+
+	start, end := slot(26)
+	if code, resp := s.do(t, http.MethodPut, path, aliceToken, map[string]any{"start_time": start, "end_time": end}); code != http.StatusForbidden {
+		t.Fatalf("move after leaving the group = %d %v, want 403", code, resp)
+	}
+	// letting go of the booking is still allowed
+	if code, resp := s.do(t, http.MethodPut, path, aliceToken, map[string]any{"status": model.BookingStatusCancelled}); code != http.StatusOK {
+		t.Fatalf("cancel after leaving the group = %d %v, want 200", code, resp)
+	}
+	if code, resp := s.do(t, http.MethodPut, path, aliceToken, map[string]any{"status": model.BookingStatusActive}); code != http.StatusForbidden {
+		t.Fatalf("reactivate after leaving the group = %d %v, want 403", code, resp)
+	}
+}
+
+func TestUpdateBookingPartially(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.user(t, "alice@example.com", model.RoleUser)
+	bob := s.user(t, "bob@example.com", model.RoleUser)
+	room := s.room(t, "Hall")
+	aliceToken := token(t, alice)
+
+	code, resp := s.do(t, http.MethodPost, "/api/bookings", aliceToken, bookingBody(room, alice, 24))
+	if code != http.StatusCreated {
+		t.Fatalf("create = %d %v, want 201", code, resp)
+	}
+	path := "/api/bookings/" + resp["id"].(string)
+
+	_, end := slot(25)
+	if code, resp := s.do(t, http.MethodPut, path, aliceToken, map[string]any{"end_time": end}); code != http.StatusOK {
+		t.Fatalf("extend = %d %v, want 200", code, resp)
+	}
+	if code, resp := s.do(t, http.MethodPut, path, aliceToken, map[string]any{"status": model.BookingStatusCancelled}); code != http.StatusOK {
+		t.Fatalf("cancel = %d %v, want 200", code, resp)
+	}
+
+	// bob takes the slot, so bringing alice's booking back would double book it
+	if code, resp := s.do(t, http.MethodPost, "/api/bookings", token(t, bob), bookingBody(room, bob, 25)); code != http.StatusCreated {
+		t.Fatalf("book the freed slot = %d %v, want 201", code, resp)
+	}
+	if code, resp := s.do(t, http.MethodPut, path, aliceToken, map[string]any{"status": model.BookingStatusActive}); code != http.StatusBadRequest {
+		t.Fatalf("reactivate into a taken slot = %d %v, want 400", code, resp)
+	}
+	start, _ := slot(24)
+	if code, resp := s.do(t, http.MethodPut, path, aliceToken, map[string]any{"status": model.BookingStatusActive, "end_time": start.Add(time.Hour)}); code != http.StatusOK {
+		t.Fatalf("reactivate the free hour = %d %v, want 200", code, resp)
+	}
+}
+
+func TestStrikesRestrictBooking(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.user(t, "alice@example.com", model.RoleUser)
+	admin := s.user(t, "admin@example.com", model.RoleAdmin)
+	room := s.room(t, "Hall")
+
+	policy := model.DefaultStrikePolicy
+	policy.Enabled, policy.Threshold, policy.BlockPopularRooms = true, 1, false
+	since := time.Now().Add(-24 * time.Hour)
+	policy.Since = &since
+	value, err := json.Marshal(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.settings.Set(model.SettingStrikePolicy, string(value)); err != nil {
+		t.Fatal(err)
+	}
+
+	aliceToken := token(t, alice)
+	if code, resp := s.do(t, http.MethodPost, "/api/bookings", aliceToken, bookingBody(room, alice, 24)); code != http.StatusCreated {
+		t.Fatalf("first booking = %d %v, want 201", code, resp)
+	}
+
+	start, end := slot(-3)
+	missed := model.Booking{RoomID: room.ID, UserID: alice.ID, StartTime: start, EndTime: end, Status: model.BookingStatusActive}
+	if err := s.bookings.Create(&missed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.strikes.Create(&model.Strike{UserID: alice.ID, BookingID: missed.ID, Reason: model.StrikeNoShow, OccurredAt: end}); err != nil {
+		t.Fatal(err)
+	}
+
+	code, resp := s.do(t, http.MethodPost, "/api/bookings", aliceToken, bookingBody(room, alice, 26))
+	if code != http.StatusForbidden || !strings.Contains(resp["error"].(string), "at most 1 active bookings") {
+		t.Fatalf("booking past the limit = %d %v, want 403", code, resp)
+	}
+	// admins are not held back by the organizer's strikes
+	if code, resp := s.do(t, http.MethodPost, "/api/bookings", token(t, admin), bookingBody(room, alice, 26)); code != http.StatusCreated {
+		t.Fatalf("admin booking for restricted user = %d %v, want 201", code, resp)
+	}
+}
+
+func TestBookingGroupHiddenFromOthers(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.user(t, "alice@example.com", model.RoleUser)
+	dave := s.user(t, "dave@example.com", model.RoleUser)
+	eve := s.user(t, "eve@example.com", model.RoleUser)
+	admin := s.user(t, "admin@example.com", model.RoleAdmin)
+	room := s.room(t, "Hall")
+	if err := s.delegations.Grant(alice.ID, dave.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	start, end := slot(24)
+	batch := map[string]any{"bookings": []map[string]any{{"room_id": room.ID, "start_time": start, "end_time": end}}}
+	code, resp := s.do(t, http.MethodPost, "/api/bookings/batch", token(t, alice), batch)
+	if code != http.StatusCreated {
+		t.Fatalf("batch = %d %v, want 201", code, resp)
+	}
+	path := "/api/bookings/groups/" + resp["data"].(map[string]any)["id"].(string)
+
+	cases := []struct {
+		name   string
+		caller *model.User
+		want   int
+	}{
+		{"organizer", alice, http.StatusOK},
+		{"delegate", dave, http.StatusOK},
+		{"admin", admin, http.StatusOK},
+		{"stranger", eve, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		if code, resp := s.do(t, http.MethodGet, path, token(t, tc.caller), nil); code != tc.want {
+			t.Errorf("%s reading the group = %d %v, want %d", tc.name, code, resp, tc.want)
+		}
+	}
+}
+
+func TestBookingHiddenFromOthers(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.user(t, "alice@example.com", model.RoleUser)
+	dave := s.user(t, "dave@example.com", model.RoleUser)
+	eve := s.user(t, "eve@example.com", model.RoleUser)
+	admin := s.user(t, "admin@example.com", model.RoleAdmin)
+	room := s.room(t, "Hall")
+	if err := s.delegations.Grant(alice.ID, dave.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	code, resp := s.do(t, http.MethodPost, "/api/bookings", token(t, alice), bookingBody(room, alice, 24))
+	if code != http.StatusCreated {
+		t.Fatalf("create = %d %v, want 201", code, resp)
+	}
+	path := "/api/bookings/" + resp["id"].(string)
+
+	cases := []struct {
+		name   string
+		caller *model.User
+		want   int
+		listed int
+	}{
+		{"organizer", alice, http.StatusOK, 1},
+		{"delegate", dave, http.StatusOK, 1},
+		{"admin", admin, http.StatusOK, 1},
+		{"stranger", eve, http.StatusNotFound, 0},
+	}
+	for _, tc := range cases {
+		callerToken := token(t, tc.caller)
+		if code, resp := s.do(t, http.MethodGet, path, callerToken, nil); code != tc.want {
+			t.Errorf("%s reading the booking = %d %v, want %d", tc.name, code, resp, tc.want)
+		}
+		code, resp := s.do(t, http.MethodGet, "/api/bookings/users/"+alice.ID.String(), callerToken, nil)
+		if listed, _ := resp["data"].([]any); code != http.StatusOK || len(listed) != tc.listed {
+			t.Errorf("%s listing alice's bookings = %d %v, want %d", tc.name, code, resp, tc.listed)
+		}
+	}
+}
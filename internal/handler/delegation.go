@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DelegationHandler manages who may book, change and cancel bookings on
+// someone else's behalf. Users choose their own delegates, admins with
+// users.write can set them up for anyone.
+type DelegationHandler struct {
+	delegations repository.DelegationRepository
+	users       repository.UserRepository
+}
+
+func NewDelegationHandler(delegations repository.DelegationRepository, users repository.UserRepository) *DelegationHandler {
+	return &DelegationHandler{delegations: delegations, users: users}
+}
+
+// GetMyDelegates godoc
+// @Summary List my delegates
+// @Description List the users who may book, change and cancel bookings for the authenticated user
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.User
+// @Router /me/delegates [get]
+func (h *DelegationHandler) GetMyDelegates(c *gin.Context) {
+	h.listDelegates(c, callerID(c))
+}
+
+// AddMyDelegate godoc
+// @Summary Add a delegate
+// @Description Let another user book, change and cancel bookings for the authenticated user. Adding a delegate again is not an error.
+// @Tags me
+// @Security BearerAuth
+// @Param user_id path string true "Delegate user ID"
+// @Success 204
+// @Failure 400 {object} map[string]string "Delegating to yourself"
+// @Failure 404 {object} map[string]string "User not found"
+// @Router /me/delegates/{user_id} [put]
+func (h *DelegationHandler) AddMyDelegate(c *gin.Context) {
+	h.grant(c, callerID(c), c.Param("user_id"))
+}
+
+// RemoveMyDelegate godoc
+// @Summary Remove a delegate
+// @Description Stop another user from booking for the authenticated user, bookings they already made stay
+// @Tags me
+// @Security BearerAuth
+// @Param user_id path string true "Delegate user ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /me/delegates/{user_id} [delete]
+func (h *DelegationHandler) RemoveMyDelegate(c *gin.Context) {
+	h.revoke(c, callerID(c), c.Param("user_id"))
+}
+
+// GetMyPrincipals godoc
+// @Summary List who I book for
+// @Description List the users who made the authenticated user their delegate
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.User
+// @Router /me/principals [get]
+func (h *DelegationHandler) GetMyPrincipals(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	users, err := h.delegations.WithContext(ctx).FindPrincipals(callerID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch principals"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": users})
+}
+
+// GetUserDelegates godoc
+// @Summary List a user's delegates
+// @Description List the users who may book for a user (users.read)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {array} model.User
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/delegates [get]
+func (h *DelegationHandler) GetUserDelegates(c *gin.Context) {
+	user, err := h.users.WithContext(c.Request.Context()).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	h.listDelegates(c, user.ID)
+}
+
+// AddUserDelegate godoc
+// @Summary Add a delegate for a user
+// @Description Let one user book, change and cancel bookings for another, such as an assistant for an executive (users.write)
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param delegate_id path string true "Delegate user ID"
+// @Success 204
+// @Failure 400 {object} map[string]string "Delegating to the same user"
+// @Failure 404 {object} map[string]string "User not found"
+// @Router /users/{id}/delegates/{delegate_id} [put]
+func (h *DelegationHandler) AddUserDelegate(c *gin.Context) {
+	principalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	h.grant(c, principalID, c.Param("delegate_id"))
+}
+
+// RemoveUserDelegate godoc
+// @Summary Remove a delegate of a user
+// @Description Stop one user from booking for another (users.write)
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param delegate_id path string true "Delegate user ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/delegates/{delegate_id} [delete]
+func (h *DelegationHandler) RemoveUserDelegate(c *gin.Context) {
+	principalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	h.revoke(c, principalID, c.Param("delegate_id"))
+}
+
+func (h *DelegationHandler) listDelegates(c *gin.Context, principalID uuid.UUID) {
+	users, err := h.delegations.WithContext(c.Request.Context()).FindDelegates(principalID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch delegates"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": users})
+}
+
+func (h *DelegationHandler) grant(c *gin.Context, principalID uuid.UUID, delegate string) {
+	ctx := c.Request.Context()
+
+	delegateID, err := uuid.Parse(delegate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delegate id"})
+		return
+	}
+	if delegateID == principalID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a user cannot be their own delegate"})
+		return
+	}
+
+	if err := h.delegations.WithContext(ctx).Grant(principalID, delegateID); err != nil {
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add delegate"})
+		return
+	}
+	logger.FromContext(ctx).Info("delegate added", "principal", principalID, "delegate", delegateID)
+	c.Status(http.StatusNoContent)
+}
+
+func (h *DelegationHandler) revoke(c *gin.Context, principalID uuid.UUID, delegate string) {
+	ctx := c.Request.Context()
+
+	delegateID, err := uuid.Parse(delegate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delegate id"})
+		return
+	}
+
+	if err := h.delegations.WithContext(ctx).Revoke(principalID, delegateID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user is not a delegate"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove delegate"})
+		return
+	}
+	logger.FromContext(ctx).Info("delegate removed", "principal", principalID, "delegate", delegateID)
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"gorm.io/gorm"
+)
+
+type GroupHandler struct {
+	groups repository.GroupRepository
+	users  repository.UserRepository
+}
+
+func NewGroupHandler(groups repository.GroupRepository, users repository.UserRepository) *GroupHandler {
+	return &GroupHandler{groups: groups, users: users}
+}
+
+// GetGroups godoc
+// @Summary List groups
+// @Description List groups and teams (groups.read)
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Group
+// @Router /groups [get]
+func (h *GroupHandler) GetGroups(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	groups, err := h.groups.WithContext(ctx).FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch groups"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": groups})
+}
+
+// CreateGroup godoc
+// @Summary Create a group
+// @Description Create a group or team, kind defaults to group (groups.write)
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.CreateGroupInput true "Group"
+// @Success 201 {object} model.Group
+// @Failure 409 {object} map[string]string
+// @Router /groups [post]
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input model.CreateGroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.nameAvailable(c, input.Name, uuid.Nil) {
+		return
+	}
+
+	group := model.Group{Name: input.Name, Kind: input.Kind, Description: input.Description}
+	if err := h.groups.WithContext(ctx).Create(&group); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create group"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": group})
+}
+
+// GetGroup godoc
+// @Summary Get a group
+// @Description Get a group with its members (groups.read)
+// @Tags groups
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Group ID"
+// @Success 200 {object} model.GroupResponse
+// @Failure 404 {object} map[string]string
+// @Router /groups/{id} [get]
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	group, ok := h.findGroup(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.groups.WithContext(ctx).FindMembers(group.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch members"})
+		return
+	}
+	if members == nil {
+		members = []model.User{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": model.GroupResponse{Group: *group, Members: members}})
+}
+
+// UpdateGroup godoc
+// @Summary Update a group
+// @Description Change a group's name, kind or description, fields left out are kept (groups.write)
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Group ID"
+// @Param input body model.UpdateGroupInput true "Fields to change"
+// @Success 200 {object} model.Group
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /groups/{id} [patch]
+func (h *GroupHandler) UpdateGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input model.UpdateGroupInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group, ok := h.findGroup(c)
+	if !ok {
+		return
+	}
+
+	if input.Name != nil && *input.Name != group.Name {
+		if !h.nameAvailable(c, *input.Name, group.ID) {
+			return
+		}
+		group.Name = *input.Name
+	}
+	if input.Kind != nil {
+		group.Kind = *input.Kind
+	}
+	if input.Description != nil {
+		group.Description = *input.Description
+	}
+
+	if err := h.groups.WithContext(ctx).Update(group); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update group"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": group})
+}
+
+// DeleteGroup godoc
+// @Summary Delete a group
+// @Description Delete a group, its members lose the room access it granted (groups.write)
+// @Tags groups
+// @Security BearerAuth
+// @Param id path string true "Group ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /groups/{id} [delete]
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return
+	}
+
+	if err := h.groups.WithContext(ctx).Delete(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete group"})
+		return
+	}
+	logger.FromContext(ctx).Info("group deleted", "group", id)
+	c.Status(http.StatusNoContent)
+}
+
+// AddGroupMember godoc
+// @Summary Add a group member
+// @Description Put a user in a group, adding a member again has no effect (groups.write)
+// @Tags groups
+// @Security BearerAuth
+// @Param id path string true "Group ID"
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /groups/{id}/members/{user_id} [put]
+func (h *GroupHandler) AddGroupMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	group, ok := h.findGroup(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.users.WithContext(ctx).FindByID(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	if err := h.groups.WithContext(ctx).AddMember(group.ID, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add member"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveGroupMember godoc
+// @Summary Remove a group member
+// @Description Take a user out of a group (groups.write)
+// @Tags groups
+// @Security BearerAuth
+// @Param id path string true "Group ID"
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /groups/{id}/members/{user_id} [delete]
+func (h *GroupHandler) RemoveGroupMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	if err := h.groups.WithContext(ctx).RemoveMember(groupID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user is not a member of the group"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove member"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetUserGroups godoc
+// @Summary List a user's groups
+// @Description List the groups and teams a user belongs to (users.read)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {array} model.Group
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/groups [get]
+func (h *GroupHandler) GetUserGroups(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, err := h.users.WithContext(ctx).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	groups, err := h.groups.WithContext(ctx).FindByUserID(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch groups"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": groups})
+}
+
+func (h *GroupHandler) findGroup(c *gin.Context) (*model.Group, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group id"})
+		return nil, false
+	}
+
+	group, err := h.groups.WithContext(c.Request.Context()).FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch group"})
+		return nil, false
+	}
+	return group, true
+}
+
+// nameAvailable reports whether no group other than except has name, and
+// responds with 409 when one does
+func (h *GroupHandler) nameAvailable(c *gin.Context, name string, except uuid.UUID) bool {
+	groups, err := h.groups.WithContext(c.Request.Context()).FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch groups"})
+		return false
+	}
+	for _, g := range groups {
+		if g.Name == name && g.ID != except {
+			c.JSON(http.StatusConflict, gin.H{"error": "a group with this name already exists"})
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/mailer"
+	"github.com/riparuk/meet-book-api/internal/middleware"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/ratelimit"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/repository/memory"
+	"github.com/riparuk/meet-book-api/internal/strike"
+	"github.com/riparuk/meet-book-api/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// recordingMailer keeps the messages sent through it
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// to returns the messages sent to email
+func (m *recordingMailer) to(email string) []mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var msgs []mailer.Message
+	for _, msg := range m.sent {
+		if msg.To == email {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+// testServer serves the routes under test from memory repositories, with
+// the same middleware the router puts in front of them
+type testServer struct {
+	engine      *gin.Engine
+	users       repository.UserRepository
+	rooms       repository.RoomRepository
+	bookings    repository.BookingRepository
+	groups      repository.GroupRepository
+	roles       repository.RoleRepository
+	delegations repository.DelegationRepository
+	settings    repository.SettingRepository
+	strikes     repository.StrikeRepository
+	accounts    repository.ServiceAccountRepository
+	mail        *recordingMailer
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	utils.ConfigureJWT(utils.JWTOptions{Secret: "handler-test-secret", Issuer: "meet-book-api", Audience: "meet-book-api"})
+
+	store := memory.NewStore()
+	s := &testServer{
+		engine:      gin.New(),
+		users:       memory.NewUserRepository(store),
+		rooms:       memory.NewRoomRepository(store),
+		bookings:    memory.NewBookingRepository(store),
+		groups:      memory.NewGroupRepository(store),
+		roles:       memory.NewRoleRepository(store),
+		delegations: memory.NewDelegationRepository(store),
+		settings:    memory.NewSettingRepository(store),
+		strikes:     memory.NewStrikeRepository(store),
+		accounts:    memory.NewServiceAccountRepository(store),
+		mail:        &recordingMailer{},
+	}
+	tokens := memory.NewUserTokenRepository(store)
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Policy{Window: time.Minute, Threshold: 5, BaseLockout: time.Minute, MaxLockout: time.Hour})
+
+	tracker := strike.NewTracker(s.strikes, s.bookings, s.rooms, memory.NewReportRepository(store), s.settings, s.users, s.mail)
+	verification := NewVerificationHandler(s.users, tokens, s.mail, limiter, VerificationOptions{TTL: time.Hour})
+	authHandler := NewAuthHandler(s.users, memory.NewInvitationRepository(store), limiter, AuthLimits{LoginPerAccount: 10}, verification)
+	twoFactorHandler := NewTwoFactorHandler(s.users, tokens, s.settings, limiter, "Meet Book")
+	userHandler := NewUserHandler(s.users, s.bookings, s.rooms, verification, s.mail, tracker)
+	bookingHandler := NewBookingHandler(s.bookings, s.rooms, s.delegations, s.users, s.mail, tracker)
+	roomHandler := NewRoomHandler(s.rooms, s.groups)
+
+	auth := middleware.JWTAuthMiddleware(s.users)
+	perms := middleware.LoadPermissions(s.roles)
+	can := func(permission model.Permission) gin.HandlerFunc {
+		return middleware.RequirePermission(s.roles, permission)
+	}
+
+	api := s.engine.Group("/api")
+	api.POST("/auth/login", authHandler.Login)
+	api.POST("/auth/login/2fa", twoFactorHandler.CompleteLogin)
+
+	users := api.Group("/users", auth, middleware.RequireAdminTwoFactor(s.settings, s.roles))
+	users.PATCH("/:id", can(model.PermUsersWrite), userHandler.UpdateUser)
+	users.POST("/:id/deactivate", can(model.PermUsersWrite), userHandler.DeactivateUser)
+
+	api.POST("/me/bookings", auth, perms, middleware.RequireVerified(), userHandler.CreateMyBooking)
+
+	readRooms := middleware.OptionalAPIKeyAuthMiddleware(s.users, s.accounts, model.ScopeRoomsRead)
+	api.GET("/rooms", readRooms, perms, roomHandler.GetRooms)
+	api.GET("/rooms/:id", readRooms, perms, roomHandler.GetRoom)
+
+	bookings := api.Group("/bookings", auth, perms)
+	bookings.POST("", middleware.RequireVerified(), bookingHandler.CreateBooking)
+	bookings.POST("/batch", middleware.RequireVerified(), bookingHandler.CreateBatchBooking)
+	bookings.GET("/upcoming", bookingHandler.GetUpcomingBookings)
+	bookings.GET("/groups/:id", bookingHandler.GetBookingGroup)
+	bookings.GET("/:id", bookingHandler.GetBooking)
+	bookings.GET("/users/:user_id", bookingHandler.GetUserBookings)
+	bookings.POST("/groups/:id/cancel", bookingHandler.CancelBookingGroup)
+	bookings.PUT("/:id", middleware.RequireVerified(), bookingHandler.UpdateBooking)
+	bookings.POST("/:id/cancel", bookingHandler.CancelBooking)
+	return s
+}
+
+// user creates a user with password "password1"
+func (s *testServer) user(t *testing.T, email string, role model.UserRole) *model.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("password1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{
+		Name:          email,
+		Email:         email,
+		Password:      string(hash),
+		Role:          role,
+		Notifications: model.NotificationPreferences{BookingUpdates: true},
+	}
+	if err := s.users.Create(user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// grant assigns user a custom role holding permissions
+func (s *testServer) grant(t *testing.T, user *model.User, permissions ...model.Permission) {
+	t.Helper()
+	role := model.Role{Name: "role " + uuid.NewString(), Permissions: permissions}
+	if err := s.roles.Create(&role); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	if err := s.roles.Assign(&model.RoleAssignment{UserID: user.ID, RoleID: role.ID}); err != nil {
+		t.Fatalf("assign role: %v", err)
+	}
+}
+
+func (s *testServer) room(t *testing.T, name string) *model.Room {
+	t.Helper()
+	room := &model.Room{Name: name, Capacity: 8}
+	if err := s.rooms.Create(room); err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	return room
+}
+
+// apiKey issues a key holding scopes to a new service account and returns
+// its secret
+func (s *testServer) apiKey(t *testing.T, scopes ...model.APIScope) string {
+	t.Helper()
+	account := &model.ServiceAccount{Name: "account " + uuid.NewString()}
+	if err := s.accounts.Create(account); err != nil {
+		t.Fatalf("create service account: %v", err)
+	}
+	secret, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &model.APIKey{ServiceAccountID: account.ID, Name: "key", Prefix: secret[:len(utils.APIKeyPrefix)+8], KeyHash: hash, Scopes: scopes}
+	if err := s.accounts.CreateKey(key); err != nil {
+		t.Fatalf("create api key: %v", err)
+	}
+	return secret
+}
+
+// token signs an access token for user
+func token(t *testing.T, user *model.User) string {
+	t.Helper()
+	tok, err := utils.GenerateJWT(user.ID.String(), user.Role, user.OrganizationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tok
+}
+
+// do sends body as JSON with token, when set, and decodes the response
+func (s *testServer) do(t *testing.T, method, path, token string, body any) (int, map[string]any) {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.engine.ServeHTTP(rec, req)
+
+	var resp map[string]any
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s %s: decode %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code, resp
+}
+
+// slot returns the start and end of an hour long slot hours from now
+func slot(hours int) (time.Time, time.Time) {
+	start := time.Now().UTC().Truncate(time.Hour).Add(time.Duration(hours) * time.Hour)
+	return start, start.Add(time.Hour)
+}
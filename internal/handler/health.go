@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/database"
+	"gorm.io/gorm"
+)
+
+const readinessTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	db       *gorm.DB
+	draining atomic.Bool
+}
+
+func NewHealthHandler(db *gorm.DB) *HealthHandler {
+	return &HealthHandler{db: db}
+}
+
+// SetDraining makes readiness fail so load balancers stop sending traffic
+// while the server shuts down
+func (h *HealthHandler) SetDraining() {
+	h.draining.Store(true)
+}
+
+// Liveness reports that the process is running, served on /healthz
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness reports whether the database is reachable and migrated to the
+// expected schema version, served on /readyz
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	checks := gin.H{"database": "ok", "migrations": "ok"}
+	ready := true
+
+	sqlDB, err := h.db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		checks["database"] = "unreachable"
+		checks["migrations"] = "unknown"
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+
+	version, err := database.CurrentSchemaVersion(h.db.WithContext(ctx))
+	switch {
+	case err != nil:
+		checks["migrations"] = "unknown"
+		ready = false
+	case version != database.SchemaVersion:
+		checks["migrations"] = "schema version mismatch"
+		checks["schema_version"] = version
+		checks["expected_schema_version"] = database.SchemaVersion
+		ready = false
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": checks})
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/mailer"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/utils"
+	"gorm.io/gorm"
+)
+
+// InvitationOptions configures invitation links
+type InvitationOptions struct {
+	// TTL is how long an invitation stays valid
+	TTL time.Duration
+	// LinkBaseURL is the frontend URL, links point to LinkBaseURL/accept-invitation
+	LinkBaseURL string
+}
+
+type InvitationHandler struct {
+	invites repository.InvitationRepository
+	users   repository.UserRepository
+	mailer  mailer.Mailer
+	opts    InvitationOptions
+}
+
+func NewInvitationHandler(invites repository.InvitationRepository, users repository.UserRepository, m mailer.Mailer, opts InvitationOptions) *InvitationHandler {
+	return &InvitationHandler{invites: invites, users: users, mailer: m, opts: opts}
+}
+
+// GetInvitations godoc
+// @Summary List invitations
+// @Description List invitations, newest first (invitations.write)
+// @Tags invitations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Invitation
+// @Router /invitations [get]
+func (h *InvitationHandler) GetInvitations(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	invitations, err := h.invites.WithContext(ctx).FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch invitations"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+// CreateInvitation godoc
+// @Summary Invite a user
+// @Description Email a single-use invitation to register with a role (invitations.write), only admins can invite admins. Earlier pending invitations for the same email are revoked.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.CreateInvitationInput true "Invitation"
+// @Success 201 {object} model.Invitation
+// @Failure 403 {object} map[string]string "Admin account"
+// @Failure 409 {object} map[string]string "Email already registered"
+// @Router /invitations [post]
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var input model.CreateInvitationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !managesAdmins(c, input.Role) {
+		return
+	}
+	email := accountKey(input.Email)
+
+	if _, err := h.users.WithContext(ctx).FindByEmail(email); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check email"})
+		return
+	}
+
+	now := time.Now()
+	if err := h.invites.WithContext(ctx).RevokePending(email, now); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invitation"})
+		return
+	}
+
+	plain, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invitation"})
+		return
+	}
+
+	invitation := model.Invitation{
+		Email:     email,
+		Role:      input.Role,
+		TokenHash: hash,
+		ExpiresAt: now.Add(h.opts.TTL),
+	}
+	if inviterID, err := uuid.Parse(c.GetString("user_id")); err == nil {
+		invitation.InvitedByID = &inviterID
+	}
+	if err := h.invites.WithContext(ctx).Create(&invitation); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create invitation"})
+		return
+	}
+
+	link := fmt.Sprintf("%s/accept-invitation?token=%s", strings.TrimRight(h.opts.LinkBaseURL, "/"), url.QueryEscape(plain))
+	msg := mailer.Message{
+		To:      email,
+		Subject: "You're invited to Meet Book",
+		Body: fmt.Sprintf("You have been invited to Meet Book as %s. Create your account here:\n\n%s\n\nThe invitation expires in %s.",
+			invitation.Role, link, h.opts.TTL),
+	}
+	if err := h.mailer.Send(ctx, msg); err != nil {
+		log.Error("failed to send invitation email", "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "invitation created but the email could not be sent, try again"})
+		return
+	}
+
+	log.Info("invitation sent", "invitation", invitation.ID, "role", invitation.Role)
+	c.JSON(http.StatusCreated, gin.H{"data": invitation})
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke an invitation
+// @Description Withdraw a pending invitation (invitations.write)
+// @Tags invitations
+// @Security BearerAuth
+// @Param id path string true "Invitation ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /invitations/{id} [delete]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation id"})
+		return
+	}
+
+	if err := h.invites.WithContext(ctx).Revoke(id, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no pending invitation with this id"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke invitation"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/utils"
+)
+
+// JWKS serves the public keys access tokens are signed with on
+// /.well-known/jwks.json, in the standard format rather than wrapped in
+// data so JWT libraries can consume it directly
+func JWKS(c *gin.Context) {
+	// Short enough that verifiers pick up a new key well before it signs
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.PublicKeys())
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/oidc"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/utils"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+// oidcFlowCookie carries the state, nonce and PKCE verifier from the login
+// redirect to the callback
+const oidcFlowCookie = "oidc_flow"
+
+var errOIDCEmailUnverified = errors.New("the identity provider has not verified this email address")
+var errOIDCAccountLinked = errors.New("this email is already linked to another identity provider account")
+
+type OIDCHandler struct {
+	users                repository.UserRepository
+	provider             *oidc.Provider
+	postLoginRedirectURL string
+	secureCookie         bool
+}
+
+func NewOIDCHandler(users repository.UserRepository, provider *oidc.Provider, postLoginRedirectURL string, secureCookie bool) *OIDCHandler {
+	return &OIDCHandler{users: users, provider: provider, postLoginRedirectURL: postLoginRedirectURL, secureCookie: secureCookie}
+}
+
+// Login godoc
+// @Summary Start single sign-on
+// @Description Redirect to the OpenID Connect provider. Only available when OIDC is configured.
+// @Tags auth
+// @Success 302
+// @Router /auth/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	state, nonce := randomString(), randomString()
+	verifier := oauth2.GenerateVerifier()
+
+	authURL, err := h.provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		logger.FromContext(ctx).Error("oidc provider unavailable", "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "identity provider unavailable"})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, strings.Join([]string{state, nonce, verifier}, "."), 600, "/api/auth/oidc", "", h.secureCookie, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback godoc
+// @Summary Complete single sign-on
+// @Description Callback from the OpenID Connect provider. Links the account by email or creates it, then returns an access token, or redirects to the configured frontend page with the token in the URL fragment.
+// @Tags auth
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	if errCode := c.Query("error"); errCode != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sign-in failed at the identity provider: " + errCode})
+		return
+	}
+
+	flow, err := c.Cookie(oidcFlowCookie)
+	parts := strings.Split(flow, ".")
+	if err != nil || len(parts) != 3 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sign-in session expired, start again"})
+		return
+	}
+	state, nonce, verifier := parts[0], parts[1], parts[2]
+	c.SetCookie(oidcFlowCookie, "", -1, "/api/auth/oidc", "", h.secureCookie, true)
+
+	if subtle.ConstantTimeCompare([]byte(state), []byte(c.Query("state"))) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sign-in state, start again"})
+		return
+	}
+
+	identity, err := h.provider.Exchange(ctx, c.Query("code"), verifier, nonce)
+	if err != nil {
+		log.Warn("oidc sign-in failed", "error", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "sign-in could not be verified"})
+		return
+	}
+
+	user, err := h.linkUser(ctx, identity)
+	if err != nil {
+		if errors.Is(err, errOIDCEmailUnverified) || errors.Is(err, errOIDCAccountLinked) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		log.Error("failed to link oidc user", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to sign in"})
+		return
+	}
+	if rejectDeactivated(c, user) {
+		return
+	}
+
+	if h.postLoginRedirectURL == "" {
+		respondWithToken(c, user)
+		return
+	}
+
+	token, err := utils.GenerateJWT(user.ID.String(), user.Role, user.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	// The fragment never reaches servers or logs
+	c.Redirect(http.StatusFound, h.postLoginRedirectURL+"#token="+url.QueryEscape(token))
+}
+
+// linkUser finds the user linked to identity, links an existing account
+// with the same verified email, or provisions a new one
+func (h *OIDCHandler) linkUser(ctx context.Context, identity *oidc.Identity) (*model.User, error) {
+	users := h.users.WithContext(ctx)
+
+	user, err := users.FindByOIDCSubject(identity.Issuer, identity.Subject)
+	if err == nil {
+		if identity.Role != "" && user.Role != identity.Role {
+			user.Role = identity.Role
+			return user, users.Update(user)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// Linking by email is only safe when the provider vouches for it
+	if !identity.EmailVerified {
+		return nil, errOIDCEmailUnverified
+	}
+
+	user, err = users.FindByEmail(identity.Email)
+	switch {
+	case err == nil:
+		if user.OIDCSubject != nil {
+			return nil, errOIDCAccountLinked
+		}
+		user.OIDCIssuer, user.OIDCSubject = &identity.Issuer, &identity.Subject
+		user.Status = model.UserStatusActive
+		if identity.Role != "" {
+			user.Role = identity.Role
+		}
+		if err := users.Update(user); err != nil {
+			return nil, err
+		}
+		logger.FromContext(ctx).Info("linked user to identity provider", "user", user.ID)
+		return user, nil
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	// Provisioned users have no password, they can set one with a reset
+	name := identity.Name
+	if name == "" {
+		name = identity.Email
+	}
+	role := identity.Role
+	if role == "" {
+		role = model.RoleUser
+	}
+	user = &model.User{
+		Name:        name,
+		Email:       identity.Email,
+		Role:        role,
+		Status:      model.UserStatusActive,
+		OIDCIssuer:  &identity.Issuer,
+		OIDCSubject: &identity.Subject,
+	}
+	if err := users.Create(user); err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("provisioned user from identity provider", "user", user.ID)
+	return user, nil
+}
+
+// randomString returns 128 random bits, URL safe
+func randomString() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
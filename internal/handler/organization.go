@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+)
+
+type OrganizationHandler struct {
+	orgs repository.OrganizationRepository
+}
+
+func NewOrganizationHandler(orgs repository.OrganizationRepository) *OrganizationHandler {
+	return &OrganizationHandler{orgs: orgs}
+}
+
+// GetOrganization godoc
+// @Summary Get the current organization
+// @Description Get the organization the request acts for, picked from the host it was sent to or, on other hosts, from the token. Clients use it to show which organization they are signed in to.
+// @Tags organization
+// @Produce json
+// @Success 200 {object} model.Organization
+// @Router /organization [get]
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	org, err := h.orgs.WithContext(ctx).FindByID(tenant.FromContext(ctx))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": org})
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/mailer"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/ratelimit"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"github.com/riparuk/meet-book-api/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// PasswordResetOptions configures reset links
+type PasswordResetOptions struct {
+	// TTL is how long a reset link stays valid
+	TTL time.Duration
+	// LinkBaseURL is the frontend URL, links point to LinkBaseURL/reset-password
+	LinkBaseURL string
+	// PerAccount limits reset requests per account in the limiter window
+	PerAccount int
+}
+
+type PasswordHandler struct {
+	users   repository.UserRepository
+	tokens  repository.UserTokenRepository
+	links   tokenLinks
+	limiter *ratelimit.Limiter
+	opts    PasswordResetOptions
+}
+
+func NewPasswordHandler(users repository.UserRepository, tokens repository.UserTokenRepository, m mailer.Mailer, limiter *ratelimit.Limiter, opts PasswordResetOptions) *PasswordHandler {
+	return &PasswordHandler{
+		users:   users,
+		tokens:  tokens,
+		links:   tokenLinks{tokens: tokens, mailer: m, linkBaseURL: opts.LinkBaseURL},
+		limiter: limiter,
+		opts:    opts,
+	}
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body model.ForgotPasswordRequest true "Account email"
+// @Success 202 {object} map[string]string
+// @Failure 429 {object} map[string]string "Too many attempts, see the Retry-After header"
+// @Router /auth/forgot-password [post]
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var req model.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	allowed, retryAfter, err := h.limiter.Allow(ctx, "password-reset:account:"+limiterKey(tenant.FromContext(ctx), req.Email), h.opts.PerAccount)
+	if err != nil {
+		log.Error("rate limiter unavailable", "error", err)
+	} else if !allowed {
+		tooManyRequests(c, retryAfter, "Too many password reset requests, try again later")
+		return
+	}
+
+	// The lookup and email happen after responding so the response time
+	// doesn't reveal whether the account exists
+	go func(ctx context.Context) {
+		user, err := h.users.WithContext(ctx).FindByEmail(req.Email)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Error("failed to look up user for password reset", "error", err)
+			}
+			return
+		}
+		if err := h.sendResetLink(ctx, user); err != nil {
+			log.Error("failed to send password reset link", "error", err)
+		}
+	}(context.WithoutCancel(ctx))
+
+	c.JSON(http.StatusAccepted, gin.H{"data": gin.H{"message": "If the email is registered, a password reset link has been sent"}})
+}
+
+// ResetPassword godoc
+// @Summary Reset a password
+// @Description Set a new password with a reset token. All existing sessions of the user are revoked.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body model.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /auth/reset-password [post]
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var req model.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.tokens.WithContext(ctx).Consume(model.TokenPurposePasswordReset, utils.HashToken(req.Token), time.Now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	user, err := h.users.WithContext(ctx).FindByID(token.UserID.String())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	now := time.Now()
+	user.Password = string(hashedPassword)
+	user.TokensValidAfter = &now
+	if err := h.users.WithContext(ctx).Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	// Other outstanding links are useless now, and the lockout no longer
+	// protects anything
+	if err := h.tokens.WithContext(ctx).RevokeAll(user.ID, model.TokenPurposePasswordReset); err != nil {
+		log.Error("failed to revoke password reset tokens", "error", err)
+	}
+	if err := h.limiter.Unlock(ctx, limiterKey(user.OrganizationID, user.Email)); err != nil {
+		log.Error("failed to clear login failures", "error", err)
+	}
+
+	log.Info("password reset", "user", user.ID)
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "Password has been reset, please log in again"}})
+}
+
+// SendPasswordReset godoc
+// @Summary Send a password reset link to a user
+// @Description Email a password reset link to the user (users.write)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 202 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/password-reset [post]
+func (h *PasswordHandler) SendPasswordReset(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, err := h.users.WithContext(ctx).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := h.sendResetLink(ctx, user); err != nil {
+		logger.FromContext(ctx).Error("failed to send password reset link", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send password reset link"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": gin.H{"message": "Password reset link sent"}})
+}
+
+// sendResetLink replaces any outstanding reset links of user with a new one
+func (h *PasswordHandler) sendResetLink(ctx context.Context, user *model.User) error {
+	return h.links.send(ctx, user, model.TokenPurposePasswordReset, h.opts.TTL, "reset-password",
+		"Reset your Meet Book password",
+		func(link string) string {
+			return fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s and can be used once.\n\n%s\n\nIf you didn't ask for a password reset you can ignore this email.\n",
+				user.Name, h.opts.TTL, link)
+		})
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change the authenticated user's password. Every other session is signed out, the response carries a new token for this one. Wrong current passwords count towards the login lockout.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string "Current password is incorrect"
+// @Failure 429 {object} map[string]string "Too many attempts, see the Retry-After header"
+// @Router /me/password [post]
+func (h *PasswordHandler) ChangePassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.users.WithContext(ctx).FindByID(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// A stolen token must not become a way around the login lockout
+	account := limiterKey(user.OrganizationID, user.Email)
+	locked, retryAfter, err := h.limiter.Locked(ctx, account)
+	if err != nil {
+		log.Error("rate limiter unavailable", "error", err)
+	} else if locked {
+		tooManyRequests(c, retryAfter, "Account temporarily locked after repeated failed logins, try again later")
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		if _, err := h.limiter.RecordFailure(ctx, account); err != nil {
+			log.Error("failed to record login failure", "error", err)
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	now := time.Now()
+	user.Password = string(hashedPassword)
+	user.TokensValidAfter = &now
+	if err := h.users.WithContext(ctx).Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	if err := h.tokens.WithContext(ctx).RevokeAll(user.ID, model.TokenPurposePasswordReset); err != nil {
+		log.Error("failed to revoke password reset tokens", "error", err)
+	}
+
+	log.Info("password changed", "user", user.ID)
+	respondWithToken(c, user)
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/model"
+)
+
+// callerGrants returns the permissions middleware.LoadPermissions or
+// middleware.RequirePermission loaded for the caller. Without them only
+// admins, whose grants follow from their role, hold any.
+func callerGrants(c *gin.Context) model.Grants {
+	if grants, ok := c.Get("permissions"); ok {
+		return grants.(model.Grants)
+	}
+	if role, _ := c.Get("user_role"); role == model.RoleAdmin {
+		return model.GrantsFor(model.RoleAdmin, nil)
+	}
+	return nil
+}
+
+// can reports whether the caller holds permission for room, or everywhere
+// when room is nil. Service accounts are authorized by their API key scope
+// and may do whatever their route allows.
+func can(c *gin.Context, permission model.Permission, room *model.Room) bool {
+	if _, ok := c.Get("service_account_id"); ok {
+		return true
+	}
+	if room == nil {
+		return callerGrants(c).Covers(permission, "", "")
+	}
+	return callerGrants(c).Allows(permission, room)
+}
+
+// requireFor reports whether the caller holds permission for room, and
+// responds with 403 when they do not
+func requireFor(c *gin.Context, permission model.Permission, room *model.Room) bool {
+	if can(c, permission, room) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": string(permission) + " is not granted for this room's site or building"})
+	return false
+}
+
+// managesAdmins reports whether the caller may act on an account with role
+// or hand out role. Only admins manage admin accounts, so users.write cannot
+// be turned into full access, and it responds with 403 otherwise.
+func managesAdmins(c *gin.Context, role model.UserRole) bool {
+	if role != model.RoleAdmin {
+		return true
+	}
+	if callerRole, _ := c.Get("user_role"); callerRole == model.RoleAdmin {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "only admins can manage admin accounts"})
+	return false
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+)
+
+// maxReportDays is the longest range a report may cover
+const maxReportDays = 366
+
+type ReportHandler struct {
+	reports  repository.ReportRepository
+	rooms    repository.RoomRepository
+	settings repository.SettingRepository
+}
+
+func NewReportHandler(reports repository.ReportRepository, rooms repository.RoomRepository, settings repository.SettingRepository) *ReportHandler {
+	return &ReportHandler{reports: reports, rooms: rooms, settings: settings}
+}
+
+// GetUtilization godoc
+// @Summary Room utilization report
+// @Description Sum up the bookings starting between two days by room, floor, building, weekday or hour of day (reports.read). Each row has the booked hours, utilization against the opening hours, cancellation and no-show rates, average lead time and average attendance against capacity. Days, weekdays and hours are counted in the timezone of the opening hours.
+// @Tags reports
+// @Produce json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param from query string true "First day, YYYY-MM-DD"
+// @Param to query string true "Last day, YYYY-MM-DD, at most 366 days after from"
+// @Param group_by query string false "Grouping, room by default" Enums(room, floor, building, weekday, hour)
+// @Param format query string false "Response format, json by default" Enums(json, csv)
+// @Success 200 {object} object{data=model.UtilizationReport}
+// @Failure 400 {object} map[string]string
+// @Router /reports/utilization [get]
+func (h *ReportHandler) GetUtilization(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var query model.UtilizationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if query.GroupBy == "" {
+		query.GroupBy = model.GroupByRoom
+	}
+
+	hours, err := repository.LoadOpeningHours(h.settings.WithContext(ctx))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load opening hours"})
+		return
+	}
+	loc := hours.Location()
+
+	from, err := time.ParseInLocation(time.DateOnly, query.From, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date"})
+		return
+	}
+	to, err := time.ParseInLocation(time.DateOnly, query.To, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date"})
+		return
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+	if to.After(from.AddDate(0, 0, maxReportDays)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("reports cover at most %d days", maxReportDays)})
+		return
+	}
+
+	// to is the last day of the report, bookings starting on it count
+	filter := model.UtilizationFilter{From: from, To: to.AddDate(0, 0, 1), GroupBy: query.GroupBy, Location: loc}
+	aggregates, err := h.reports.WithContext(ctx).Utilization(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute report"})
+		return
+	}
+	rooms, err := h.rooms.WithContext(ctx).FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch rooms"})
+		return
+	}
+
+	report := model.UtilizationReport{
+		From:     query.From,
+		To:       query.To,
+		GroupBy:  query.GroupBy,
+		Timezone: loc.String(),
+		Rows:     model.BuildUtilizationRows(filter, hours, rooms, aggregates),
+	}
+	if query.Format == "csv" {
+		writeUtilizationCSV(c, report)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// writeUtilizationCSV writes the report as a CSV download, averages
+// without bookings to average are left empty
+func writeUtilizationCSV(c *gin.Context, report model.UtilizationReport) {
+	number := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	optional := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return number(*v)
+	}
+
+	filename := fmt.Sprintf("utilization-%s-%s-by-%s.csv", report.From, report.To, report.GroupBy)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{
+		string(report.GroupBy), "label", "rooms", "bookings", "booked_hours", "open_hours", "utilization",
+		"cancellation_rate", "no_show_rate", "avg_lead_time_hours", "avg_attendance", "attendance_rate",
+	})
+	for _, row := range report.Rows {
+		_ = w.Write([]string{
+			row.Key, row.Label, strconv.Itoa(row.Rooms), strconv.FormatInt(row.Bookings, 10),
+			number(row.BookedHours), number(row.OpenHours), number(row.Utilization),
+			number(row.CancellationRate), number(row.NoShowRate),
+			optional(row.AvgLeadTimeHours), optional(row.AvgAttendance), optional(row.AttendanceRate),
+		})
+	}
+	w.Flush()
+}
@@ -0,0 +1,397 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"gorm.io/gorm"
+)
+
+type RoleHandler struct {
+	roles repository.RoleRepository
+	users repository.UserRepository
+}
+
+func NewRoleHandler(roles repository.RoleRepository, users repository.UserRepository) *RoleHandler {
+	return &RoleHandler{roles: roles, users: users}
+}
+
+// GetPermissions godoc
+// @Summary List permissions
+// @Description List every permission a custom role can grant (roles.write)
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} string
+// @Router /roles/permissions [get]
+func (h *RoleHandler) GetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": model.Permissions})
+}
+
+// GetRoles godoc
+// @Summary List custom roles
+// @Description List the roles defined by admins, the built-in admin and user roles are not included (roles.write)
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Role
+// @Router /roles [get]
+func (h *RoleHandler) GetRoles(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	roles, err := h.roles.WithContext(ctx).FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch roles"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": roles})
+}
+
+// CreateRole godoc
+// @Summary Create a custom role
+// @Description Define a role from a set of permissions (roles.write). Callers can only grant permissions they hold everywhere.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.CreateRoleInput true "Role"
+// @Success 201 {object} model.Role
+// @Failure 400 {object} map[string]string "Unknown permission or built-in name"
+// @Failure 403 {object} map[string]string "Permission the caller does not hold"
+// @Failure 409 {object} map[string]string
+// @Router /roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input model.CreateRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	permissions, ok := grantablePermissions(c, input.Permissions)
+	if !ok || !h.nameAvailable(c, input.Name, uuid.Nil) {
+		return
+	}
+
+	role := model.Role{Name: input.Name, Description: input.Description, Permissions: permissions}
+	if err := h.roles.WithContext(ctx).Create(&role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create role"})
+		return
+	}
+
+	logger.FromContext(ctx).Info("role created", "role", role.ID, "permissions", role.Permissions)
+	c.JSON(http.StatusCreated, gin.H{"data": role})
+}
+
+// GetRole godoc
+// @Summary Get a custom role
+// @Description Get a role defined by admins (roles.write)
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Success 200 {object} model.Role
+// @Failure 404 {object} map[string]string
+// @Router /roles/{id} [get]
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, ok := h.findRole(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": role})
+}
+
+// UpdateRole godoc
+// @Summary Update a custom role
+// @Description Change a role's name, description or permissions, fields left out are kept (roles.write). Users with the role get the new permissions on their next request.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param input body model.UpdateRoleInput true "Fields to change"
+// @Success 200 {object} model.Role
+// @Failure 400 {object} map[string]string "Unknown permission or built-in name"
+// @Failure 403 {object} map[string]string "Permission the caller does not hold"
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /roles/{id} [patch]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input model.UpdateRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, ok := h.findRole(c)
+	if !ok {
+		return
+	}
+
+	if input.Name != nil && *input.Name != role.Name {
+		if !h.nameAvailable(c, *input.Name, role.ID) {
+			return
+		}
+		role.Name = *input.Name
+	}
+	if input.Description != nil {
+		role.Description = *input.Description
+	}
+	if input.Permissions != nil {
+		permissions, ok := grantablePermissions(c, *input.Permissions)
+		if !ok {
+			return
+		}
+		role.Permissions = permissions
+	}
+
+	if err := h.roles.WithContext(ctx).Update(role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update role"})
+		return
+	}
+
+	logger.FromContext(ctx).Info("role updated", "role", role.ID, "permissions", role.Permissions)
+	c.JSON(http.StatusOK, gin.H{"data": role})
+}
+
+// DeleteRole godoc
+// @Summary Delete a custom role
+// @Description Delete a role, everyone it was assigned to loses its permissions (roles.write)
+// @Tags roles
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /roles/{id} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role id"})
+		return
+	}
+
+	if err := h.roles.WithContext(ctx).Delete(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete role"})
+		return
+	}
+	logger.FromContext(ctx).Info("role deleted", "role", id)
+	c.Status(http.StatusNoContent)
+}
+
+// GetUserRoles godoc
+// @Summary List a user's custom roles
+// @Description List the custom roles assigned to a user and where they apply (users.read)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {array} model.RoleAssignment
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/roles [get]
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, err := h.users.WithContext(ctx).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	assignments, err := h.roles.WithContext(ctx).FindAssignments(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch roles"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": assignments})
+}
+
+// AssignRole godoc
+// @Summary Assign a custom role
+// @Description Give a user a custom role, everywhere or only for the rooms at a site or building (roles.write). A role assigned for a site or building only grants its room permissions there. Callers can only assign permissions they hold for that site or building.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param input body model.AssignRoleInput true "Role and scope"
+// @Success 201 {object} model.RoleAssignment
+// @Failure 400 {object} map[string]string "Unknown role"
+// @Failure 403 {object} map[string]string "Permission the caller does not hold"
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/roles [post]
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input model.AssignRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.users.WithContext(ctx).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	role, err := h.roles.WithContext(ctx).FindByID(input.RoleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "role not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch role"})
+		return
+	}
+
+	assignment := model.RoleAssignment{UserID: user.ID, RoleID: role.ID, Site: input.Site, Building: input.Building}
+	grants := callerGrants(c)
+	for _, p := range role.Permissions {
+		if assignment.Scoped() && !p.RoomScoped() {
+			continue
+		}
+		if !grants.Covers(p, assignment.Site, assignment.Building) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("cannot assign %s, which you do not hold there", p)})
+			return
+		}
+	}
+
+	if err := h.roles.WithContext(ctx).Assign(&assignment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to assign role"})
+		return
+	}
+	assignment.Role = *role
+
+	logger.FromContext(ctx).Info("role assigned", "user", user.ID, "role", role.ID,
+		"site", assignment.Site, "building", assignment.Building)
+	c.JSON(http.StatusCreated, gin.H{"data": assignment})
+}
+
+// UnassignRole godoc
+// @Summary Remove a custom role
+// @Description Take a role assignment away from a user (roles.write)
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param assignment_id path string true "Role assignment ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/roles/{assignment_id} [delete]
+func (h *RoleHandler) UnassignRole(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	assignmentID, err := uuid.Parse(c.Param("assignment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role assignment id"})
+		return
+	}
+
+	if err := h.roles.WithContext(ctx).Unassign(userID, assignmentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "role assignment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove role"})
+		return
+	}
+	logger.FromContext(ctx).Info("role unassigned", "user", userID, "assignment", assignmentID)
+	c.Status(http.StatusNoContent)
+}
+
+// MyPermissions godoc
+// @Summary Get my permissions
+// @Description List what the authenticated user may do, grants from roles assigned for a site or building name it
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Grant
+// @Router /me/permissions [get]
+func (h *RoleHandler) MyPermissions(c *gin.Context) {
+	grants := callerGrants(c)
+	if grants == nil {
+		grants = model.Grants{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": grants})
+}
+
+func (h *RoleHandler) findRole(c *gin.Context) (*model.Role, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role id"})
+		return nil, false
+	}
+
+	role, err := h.roles.WithContext(c.Request.Context()).FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch role"})
+		return nil, false
+	}
+	return role, true
+}
+
+// nameAvailable reports whether name is neither built in nor used by a
+// role other than except, and responds with 400 or 409 when it is
+func (h *RoleHandler) nameAvailable(c *gin.Context, name string, except uuid.UUID) bool {
+	if model.BuiltInRole(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%q is a built-in role", name)})
+		return false
+	}
+
+	roles, err := h.roles.WithContext(c.Request.Context()).FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch roles"})
+		return false
+	}
+	for _, r := range roles {
+		if r.Name == name && r.ID != except {
+			c.JSON(http.StatusConflict, gin.H{"error": "a role with this name already exists"})
+			return false
+		}
+	}
+	return true
+}
+
+// grantablePermissions validates and deduplicates permissions. Callers can
+// only put permissions they hold everywhere in a role, so roles.write
+// cannot be used to gain more. It responds with 400 or 403 when ok is false.
+func grantablePermissions(c *gin.Context, permissions []model.Permission) (list model.PermissionList, ok bool) {
+	grants := callerGrants(c)
+	for _, p := range permissions {
+		if !p.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown permission %q, valid permissions are %v", p, model.Permissions)})
+			return nil, false
+		}
+		if !grants.Covers(p, "", "") {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("cannot grant %s, which you do not hold everywhere", p)})
+			return nil, false
+		}
+		if !slices.Contains(list, p) {
+			list = append(list, p)
+		}
+	}
+	return list, true
+}
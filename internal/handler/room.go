@@ -10,24 +10,29 @@ import (
 )
 
 type RoomHandler struct {
-	repo repository.RoomRepository
+	repo   repository.RoomRepository
+	groups repository.GroupRepository
+	access roomAccess
 }
 
-func NewRoomHandler(repo repository.RoomRepository) *RoomHandler {
-	return &RoomHandler{repo}
+func NewRoomHandler(repo repository.RoomRepository, groups repository.GroupRepository) *RoomHandler {
+	return &RoomHandler{repo: repo, groups: groups, access: roomAccess{rooms: repo}}
 }
 
 // CreateRoom godoc
 // @Summary Create a new room
-// @Description Create a new meeting room
+// @Description Create a new meeting room, needs rooms.write for its site and building
 // @Tags rooms
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param input body model.CreateRoomInput true "Room details"
 // @Success 201 {object} model.Room
+// @Failure 403 {object} map[string]string "rooms.write not granted for the room"
 // @Router /rooms [post]
 func (h *RoomHandler) CreateRoom(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	var input model.CreateRoomInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -35,11 +40,18 @@ func (h *RoomHandler) CreateRoom(c *gin.Context) {
 	}
 
 	room := model.Room{
-		Name:     input.Name,
-		Capacity: input.Capacity,
+		Name:       input.Name,
+		Capacity:   input.Capacity,
+		Site:       input.Site,
+		Building:   input.Building,
+		Floor:      input.Floor,
+		Restricted: input.Restricted,
+	}
+	if !requireFor(c, model.PermRoomsWrite, &room) {
+		return
 	}
 
-	if err := h.repo.Create(&room); err != nil {
+	if err := h.repo.WithContext(ctx).Create(&room); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -49,13 +61,14 @@ func (h *RoomHandler) CreateRoom(c *gin.Context) {
 
 // GetRooms godoc
 // @Summary Get all rooms
-// @Description Get a list of all meeting rooms
+// @Description Get a list of the meeting rooms the caller may book. Restricted rooms are only listed for callers with rooms.access_all and members of the groups on their access list, so anonymous callers see the unrestricted rooms. API keys need the rooms:read scope.
 // @Tags rooms
 // @Produce json
+// @Security BearerAuth
 // @Success 200 {array} model.Room
 // @Router /rooms [get]
 func (h *RoomHandler) GetRooms(c *gin.Context) {
-	rooms, err := h.repo.FindAll()
+	rooms, err := h.access.visibleRooms(c)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -65,20 +78,23 @@ func (h *RoomHandler) GetRooms(c *gin.Context) {
 
 // GetRoom godoc
 // @Summary Get a room by ID
-// @Description Get a room by its ID
+// @Description Get a room by its ID, restricted rooms are not found for callers without access. API keys need the rooms:read scope.
 // @Tags rooms
 // @Produce json
+// @Security BearerAuth
 // @Param id path string true "Room ID"
 // @Success 200 {object} model.Room
 // @Router /rooms/{id} [get]
 func (h *RoomHandler) GetRoom(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid room id"})
 		return
 	}
 
-	room, err := h.repo.FindByID(id)
+	room, err := h.repo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -89,12 +105,22 @@ func (h *RoomHandler) GetRoom(c *gin.Context) {
 		return
 	}
 
+	ok, err := h.access.permits(c, room)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"data": room})
 }
 
 // UpdateRoom godoc
 // @Summary Update a room
-// @Description Update an existing room
+// @Description Update an existing room, needs rooms.write for both its current and its new site and building
 // @Tags rooms
 // @Accept json
 // @Produce json
@@ -102,8 +128,11 @@ func (h *RoomHandler) GetRoom(c *gin.Context) {
 // @Param id path string true "Room ID"
 // @Param input body model.UpdateRoomInput true "Room details"
 // @Success 200 {object} model.Room
+// @Failure 403 {object} map[string]string "rooms.write not granted for the room"
 // @Router /rooms/{id} [put]
 func (h *RoomHandler) UpdateRoom(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid room id"})
@@ -116,7 +145,7 @@ func (h *RoomHandler) UpdateRoom(c *gin.Context) {
 		return
 	}
 
-	room, err := h.repo.FindByID(id)
+	room, err := h.repo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -126,10 +155,23 @@ func (h *RoomHandler) UpdateRoom(c *gin.Context) {
 		return
 	}
 
+	// a role for one building can neither edit rooms elsewhere nor move
+	// rooms out of its reach
+	if !requireFor(c, model.PermRoomsWrite, room) {
+		return
+	}
+
 	room.Name = input.Name
 	room.Capacity = input.Capacity
+	room.Site = input.Site
+	room.Building = input.Building
+	room.Floor = input.Floor
+	room.Restricted = input.Restricted
+	if !requireFor(c, model.PermRoomsWrite, room) {
+		return
+	}
 
-	if err := h.repo.Update(room); err != nil {
+	if err := h.repo.WithContext(ctx).Update(room); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -139,21 +181,24 @@ func (h *RoomHandler) UpdateRoom(c *gin.Context) {
 
 // DeleteRoom godoc
 // @Summary Delete a room
-// @Description Delete a room by ID
+// @Description Delete a room by ID, needs rooms.write for its site and building
 // @Tags rooms
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Room ID"
 // @Success 204 "No Content"
+// @Failure 403 {object} map[string]string "rooms.write not granted for the room"
 // @Router /rooms/{id} [delete]
 func (h *RoomHandler) DeleteRoom(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid room id"})
 		return
 	}
 
-	room, err := h.repo.FindByID(id)
+	room, err := h.repo.WithContext(ctx).FindByID(id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -162,11 +207,119 @@ func (h *RoomHandler) DeleteRoom(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
 		return
 	}
+	if !requireFor(c, model.PermRoomsWrite, room) {
+		return
+	}
 
-	if err := h.repo.Delete(id); err != nil {
+	if err := h.repo.WithContext(ctx).Delete(id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+// GetRoomAccess godoc
+// @Summary Get a room's access list
+// @Description List the groups whose members may book a restricted room (rooms.write)
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Room ID"
+// @Success 200 {array} model.Group
+// @Failure 403 {object} map[string]string "rooms.write not granted for the room"
+// @Failure 404 {object} map[string]string
+// @Router /rooms/{id}/access [get]
+func (h *RoomHandler) GetRoomAccess(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	room, ok := h.findRoom(c)
+	if !ok {
+		return
+	}
+
+	groups, err := h.repo.WithContext(ctx).FindAccess(room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": groups})
+}
+
+// SetRoomAccess godoc
+// @Summary Replace a room's access list
+// @Description Replace the groups whose members may book the room (rooms.write). The list only applies while the room is restricted, an empty list leaves a restricted room to callers with rooms.access_all.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Room ID"
+// @Param input body model.SetRoomAccessInput true "Groups"
+// @Success 200 {array} model.Group
+// @Failure 403 {object} map[string]string "rooms.write not granted for the room"
+// @Failure 400 {object} map[string]string "Unknown group"
+// @Failure 404 {object} map[string]string
+// @Router /rooms/{id}/access [put]
+func (h *RoomHandler) SetRoomAccess(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input model.SetRoomAccessInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	room, ok := h.findRoom(c)
+	if !ok {
+		return
+	}
+
+	groupIDs := make([]uuid.UUID, 0, len(input.GroupIDs))
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range input.GroupIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := h.groups.WithContext(ctx).FindByID(id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "group not found: " + id.String()})
+			return
+		}
+		groupIDs = append(groupIDs, id)
+	}
+
+	if err := h.repo.WithContext(ctx).SetAccess(room.ID, groupIDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	groups, err := h.repo.WithContext(ctx).FindAccess(room.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": groups})
+}
+
+// findRoom returns the room in the path if the caller may edit it
+func (h *RoomHandler) findRoom(c *gin.Context) (*model.Room, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid room id"})
+		return nil, false
+	}
+
+	room, err := h.repo.WithContext(c.Request.Context()).FindByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if room == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+		return nil, false
+	}
+	if !requireFor(c, model.PermRoomsWrite, room) {
+		return nil, false
+	}
+	return room, true
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+)
+
+// roomAccess enforces the access lists of restricted rooms. Service
+// accounts and callers with rooms.access_all for the room are not bound by
+// them, everyone else needs one of the room's groups, and so does every
+// user they book for.
+type roomAccess struct {
+	rooms repository.RoomRepository
+}
+
+// callerID returns the signed in user, or uuid.Nil for anonymous callers
+func callerID(c *gin.Context) uuid.UUID {
+	userID, _ := c.Get("user_id")
+	id, _ := userID.(string)
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil
+	}
+	return parsed
+}
+
+// visibleRooms returns the rooms the caller may see and book
+func (a roomAccess) visibleRooms(c *gin.Context) ([]model.Room, error) {
+	rooms := a.rooms.WithContext(c.Request.Context())
+	if can(c, model.PermRoomsAccessAll, nil) {
+		return rooms.FindAll()
+	}
+	visible, err := rooms.FindAllForUser(callerID(c))
+	if err != nil || !callerGrants(c).Has(model.PermRoomsAccessAll) {
+		return visible, err
+	}
+
+	// rooms.access_all for some sites or buildings adds their restricted
+	// rooms, kept in the order FindAll returns them
+	listed := make(map[uuid.UUID]bool, len(visible))
+	for _, room := range visible {
+		listed[room.ID] = true
+	}
+	all, err := rooms.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	visible = visible[:0]
+	for _, room := range all {
+		if listed[room.ID] || can(c, model.PermRoomsAccessAll, &room) {
+			visible = append(visible, room)
+		}
+	}
+	return visible, nil
+}
+
+// permits reports whether the caller and bookers may use room
+func (a roomAccess) permits(c *gin.Context, room *model.Room, bookers ...uuid.UUID) (bool, error) {
+	if !room.Restricted || can(c, model.PermRoomsAccessAll, room) {
+		return true, nil
+	}
+
+	checked := make(map[uuid.UUID]bool)
+	for _, userID := range append([]uuid.UUID{callerID(c)}, bookers...) {
+		if checked[userID] {
+			continue
+		}
+		checked[userID] = true
+
+		ok, err := a.rooms.WithContext(c.Request.Context()).HasAccess(room.ID, userID)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// allow returns the room if the caller and bookers may use it, and
+// responds with 404 or 403 when they may not
+func (a roomAccess) allow(c *gin.Context, roomID uuid.UUID, bookers ...uuid.UUID) (*model.Room, bool) {
+	room, err := a.rooms.WithContext(c.Request.Context()).FindByID(roomID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check room access"})
+		return nil, false
+	}
+	if room == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+		return nil, false
+	}
+
+	ok, err := a.permits(c, room, bookers...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check room access"})
+		return nil, false
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": roomAccessDenied})
+		return nil, false
+	}
+	return room, true
+}
+
+// roomAccessDenied explains why a restricted room cannot be used
+const roomAccessDenied = "room is restricted to the groups on its access list"
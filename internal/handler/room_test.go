@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/riparuk/meet-book-api/internal/model"
+)
+
+func TestRoomReadsNeedRoomsReadScope(t *testing.T) {
+	s := newTestServer(t)
+	room := s.room(t, "Orion")
+	reader := s.apiKey(t, model.ScopeRoomsRead)
+	writer := s.apiKey(t, model.ScopeRoomsWrite, model.ScopeBookingsRead)
+
+	for _, path := range []string{"/api/rooms", "/api/rooms/" + room.ID.String()} {
+		if code, resp := s.do(t, http.MethodGet, path, reader, nil); code != http.StatusOK {
+			t.Fatalf("GET %s with rooms:read = %d %v, want 200", path, code, resp)
+		}
+		// a key without the scope is refused rather than served anonymously
+		if code, resp := s.do(t, http.MethodGet, path, writer, nil); code != http.StatusForbidden {
+			t.Fatalf("GET %s without rooms:read = %d %v, want 403", path, code, resp)
+		}
+		if code, resp := s.do(t, http.MethodGet, path, "mbk_unknown", nil); code != http.StatusUnauthorized {
+			t.Fatalf("GET %s with an unknown key = %d %v, want 401", path, code, resp)
+		}
+		if code, resp := s.do(t, http.MethodGet, path, "", nil); code != http.StatusOK {
+			t.Fatalf("GET %s anonymously = %d %v, want 200", path, code, resp)
+		}
+	}
+}
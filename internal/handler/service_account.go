@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/utils"
+	"gorm.io/gorm"
+)
+
+type ServiceAccountHandler struct {
+	repo repository.ServiceAccountRepository
+}
+
+func NewServiceAccountHandler(repo repository.ServiceAccountRepository) *ServiceAccountHandler {
+	return &ServiceAccountHandler{repo: repo}
+}
+
+// GetServiceAccounts godoc
+// @Summary List service accounts
+// @Description List service accounts (service_accounts.write)
+// @Tags service-accounts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.ServiceAccount
+// @Router /service-accounts [get]
+func (h *ServiceAccountHandler) GetServiceAccounts(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	accounts, err := h.repo.WithContext(ctx).FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch service accounts"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": accounts})
+}
+
+// CreateServiceAccount godoc
+// @Summary Create a service account
+// @Description Create a service account for an integration, then issue it API keys (service_accounts.write)
+// @Tags service-accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.CreateServiceAccountInput true "Service account"
+// @Success 201 {object} model.ServiceAccount
+// @Failure 409 {object} map[string]string
+// @Router /service-accounts [post]
+func (h *ServiceAccountHandler) CreateServiceAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input model.CreateServiceAccountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := h.repo.WithContext(ctx).FindAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch service accounts"})
+		return
+	}
+	for _, a := range existing {
+		if a.Name == input.Name {
+			c.JSON(http.StatusConflict, gin.H{"error": "a service account with this name already exists"})
+			return
+		}
+	}
+
+	account := model.ServiceAccount{Name: input.Name, Description: input.Description}
+	if err := h.repo.WithContext(ctx).Create(&account); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create service account"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": account})
+}
+
+// GetServiceAccount godoc
+// @Summary Get a service account
+// @Description Get a service account with its API keys (service_accounts.write)
+// @Tags service-accounts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Service account ID"
+// @Success 200 {object} model.ServiceAccountResponse
+// @Failure 404 {object} map[string]string
+// @Router /service-accounts/{id} [get]
+func (h *ServiceAccountHandler) GetServiceAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	account, ok := h.findAccount(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.repo.WithContext(ctx).FindKeys(account.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch api keys"})
+		return
+	}
+	if keys == nil {
+		keys = []model.APIKey{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": model.ServiceAccountResponse{ServiceAccount: *account, APIKeys: keys}})
+}
+
+// DeleteServiceAccount godoc
+// @Summary Delete a service account
+// @Description Delete a service account, its API keys stop working immediately (service_accounts.write)
+// @Tags service-accounts
+// @Security BearerAuth
+// @Param id path string true "Service account ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /service-accounts/{id} [delete]
+func (h *ServiceAccountHandler) DeleteServiceAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid service account id"})
+		return
+	}
+
+	if err := h.repo.WithContext(ctx).Delete(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "service account not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete service account"})
+		return
+	}
+	logger.FromContext(ctx).Info("service account deleted", "service_account", id)
+	c.Status(http.StatusNoContent)
+}
+
+// CreateAPIKey godoc
+// @Summary Issue an API key
+// @Description Issue a scoped API key for a service account (service_accounts.write). The key is only returned in this response, send it as "Authorization: Bearer <key>".
+// @Tags service-accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Service account ID"
+// @Param input body model.CreateAPIKeyInput true "API key"
+// @Success 201 {object} model.APIKeyCreatedResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /service-accounts/{id}/keys [post]
+func (h *ServiceAccountHandler) CreateAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	account, ok := h.findAccount(c)
+	if !ok {
+		return
+	}
+
+	var input model.CreateAPIKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var scopes model.ScopeList
+	for _, scope := range input.Scopes {
+		if !scope.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown scope %q, valid scopes are %v", scope, model.APIScopes)})
+			return
+		}
+		if !scopes.Has(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	secret, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate api key"})
+		return
+	}
+
+	key := model.APIKey{
+		ServiceAccountID: account.ID,
+		Name:             input.Name,
+		Prefix:           secret[:len(utils.APIKeyPrefix)+8],
+		KeyHash:          hash,
+		Scopes:           scopes,
+		ExpiresAt:        input.ExpiresAt,
+	}
+	if err := h.repo.WithContext(ctx).CreateKey(&key); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create api key"})
+		return
+	}
+
+	logger.FromContext(ctx).Info("api key issued", "service_account", account.ID, "api_key", key.ID, "scopes", scopes)
+	c.JSON(http.StatusCreated, gin.H{"data": model.APIKeyCreatedResponse{APIKey: key, Key: secret}})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke an API key, it stops working immediately (service_accounts.write)
+// @Tags service-accounts
+// @Security BearerAuth
+// @Param id path string true "Service account ID"
+// @Param key_id path string true "API key ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /service-accounts/{id}/keys/{key_id} [delete]
+func (h *ServiceAccountHandler) RevokeAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	accountID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid service account id"})
+		return
+	}
+	keyID, err := uuid.Parse(c.Param("key_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid api key id"})
+		return
+	}
+
+	if err := h.repo.WithContext(ctx).RevokeKey(accountID, keyID, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "api key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke api key"})
+		return
+	}
+	logger.FromContext(ctx).Info("api key revoked", "service_account", accountID, "api_key", keyID)
+	c.Status(http.StatusNoContent)
+}
+
+// findAccount loads the service account in the id path parameter, writing
+// the error response when it can't
+func (h *ServiceAccountHandler) findAccount(c *gin.Context) (*model.ServiceAccount, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid service account id"})
+		return nil, false
+	}
+
+	account, err := h.repo.WithContext(c.Request.Context()).FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "service account not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch service account"})
+		return nil, false
+	}
+	return account, true
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+)
+
+type SettingsHandler struct {
+	settings repository.SettingRepository
+}
+
+func NewSettingsHandler(settings repository.SettingRepository) *SettingsHandler {
+	return &SettingsHandler{settings: settings}
+}
+
+// GetSecuritySettings godoc
+// @Summary Get security settings
+// @Description Get the security settings (settings.write)
+// @Tags settings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.SecuritySettings
+// @Router /settings/security [get]
+func (h *SettingsHandler) GetSecuritySettings(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	requireAdmin2FA, err := h.settings.WithContext(ctx).Get(model.SettingRequireAdmin2FA, "false")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": model.SecuritySettings{RequireAdmin2FA: requireAdmin2FA == "true"}})
+}
+
+// UpdateSecuritySettings godoc
+// @Summary Update security settings
+// @Description Update the security settings (settings.write). When two-factor authentication is required for admins, admins and users with administrative permissions from custom roles are refused on admin routes until they enroll.
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.SecuritySettings true "Security settings"
+// @Success 200 {object} model.SecuritySettings
+// @Router /settings/security [put]
+func (h *SettingsHandler) UpdateSecuritySettings(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input model.SecuritySettings
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.settings.WithContext(ctx).Set(model.SettingRequireAdmin2FA, strconv.FormatBool(input.RequireAdmin2FA)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save settings"})
+		return
+	}
+
+	logger.FromContext(ctx).Info("security settings updated", "require_admin_2fa", input.RequireAdmin2FA)
+	c.JSON(http.StatusOK, gin.H{"data": input})
+}
+
+// GetOpeningHours godoc
+// @Summary Get opening hours
+// @Description Get when rooms are open, utilization reports measure bookings against it (settings.write)
+// @Tags settings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{data=model.OpeningHours}
+// @Router /settings/opening-hours [get]
+func (h *SettingsHandler) GetOpeningHours(c *gin.Context) {
+	hours, err := repository.LoadOpeningHours(h.settings.WithContext(c.Request.Context()))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": hours})
+}
+
+// UpdateOpeningHours godoc
+// @Summary Update opening hours
+// @Description Set when rooms are open, on which weekdays (0 is Sunday) and in which timezone (settings.write)
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.OpeningHours true "Opening hours"
+// @Success 200 {object} object{data=model.OpeningHours}
+// @Router /settings/opening-hours [put]
+func (h *SettingsHandler) UpdateOpeningHours(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input model.OpeningHours
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := input.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Days == nil {
+		input.Days = []time.Weekday{}
+	}
+
+	value, err := json.Marshal(input)
+	if err == nil {
+		err = h.settings.WithContext(ctx).Set(model.SettingOpeningHours, string(value))
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save settings"})
+		return
+	}
+
+	logger.FromContext(ctx).Info("opening hours updated", "opens", input.Opens, "closes", input.Closes, "timezone", input.Timezone)
+	c.JSON(http.StatusOK, gin.H{"data": input})
+}
+
+// GetStrikePolicy godoc
+// @Summary Get the strike policy
+// @Description Get what earns users strikes and how crossing the threshold restricts their booking (settings.write)
+// @Tags settings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{data=model.StrikePolicy}
+// @Router /settings/strikes [get]
+func (h *SettingsHandler) GetStrikePolicy(c *gin.Context) {
+	policy, err := repository.LoadStrikePolicy(h.settings.WithContext(c.Request.Context()))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": policy})
+}
+
+// UpdateStrikePolicy godoc
+// @Summary Update the strike policy
+// @Description Set whether no-shows and late cancellations earn strikes, how many within how many days restrict booking, and to what (settings.write). Only bookings that end or are cancelled after strikes are enabled count.
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.StrikePolicy true "Strike policy"
+// @Success 200 {object} object{data=model.StrikePolicy}
+// @Router /settings/strikes [put]
+func (h *SettingsHandler) UpdateStrikePolicy(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input model.StrikePolicy
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := input.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings := h.settings.WithContext(ctx)
+	current, err := repository.LoadStrikePolicy(settings)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load settings"})
+		return
+	}
+	// Strikes count from when they were enabled, so turning them on does
+	// not punish bookings from before
+	input.Since = nil
+	if input.Enabled {
+		input.Since = current.Since
+		if !current.Enabled || input.Since == nil {
+			now := time.Now().UTC()
+			input.Since = &now
+		}
+	}
+
+	value, err := json.Marshal(input)
+	if err == nil {
+		err = settings.Set(model.SettingStrikePolicy, string(value))
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save settings"})
+		return
+	}
+
+	logger.FromContext(ctx).Info("strike policy updated", "enabled", input.Enabled, "threshold", input.Threshold, "window_days", input.WindowDays)
+	c.JSON(http.StatusOK, gin.H{"data": input})
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/strike"
+	"gorm.io/gorm"
+)
+
+// StrikeHandler shows users their no-show and late cancellation strikes,
+// and lets admins review and clear them
+type StrikeHandler struct {
+	tracker *strike.Tracker
+	strikes repository.StrikeRepository
+	users   repository.UserRepository
+}
+
+func NewStrikeHandler(tracker *strike.Tracker, strikes repository.StrikeRepository, users repository.UserRepository) *StrikeHandler {
+	return &StrikeHandler{tracker: tracker, strikes: strikes, users: users}
+}
+
+// GetMyStrikes godoc
+// @Summary Get my strikes
+// @Description Get the no-show and late cancellation strikes that count against the authenticated user, and until when their booking is restricted
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{data=model.StrikeStatus}
+// @Router /me/strikes [get]
+func (h *StrikeHandler) GetMyStrikes(c *gin.Context) {
+	h.status(c, callerID(c))
+}
+
+// GetStrikes godoc
+// @Summary List users with strikes
+// @Description List every user with strikes that count, those with the most first, and until when each is restricted (users.read)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object{data=[]model.StrikeStatus}
+// @Router /strikes [get]
+func (h *StrikeHandler) GetStrikes(c *gin.Context) {
+	statuses, err := h.tracker.Statuses(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch strikes"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": statuses})
+}
+
+// GetUserStrikes godoc
+// @Summary Get a user's strikes
+// @Description Get the strikes that count against a user and until when their booking is restricted (users.read)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} object{data=model.StrikeStatus}
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/strikes [get]
+func (h *StrikeHandler) GetUserStrikes(c *gin.Context) {
+	user, err := h.users.WithContext(c.Request.Context()).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	h.status(c, user.ID)
+}
+
+// ClearUserStrikes godoc
+// @Summary Clear a user's strikes
+// @Description Clear every strike of a user, lifting any restriction; the bookings cannot earn another (users.write)
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/strikes [delete]
+func (h *StrikeHandler) ClearUserStrikes(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, err := h.users.WithContext(ctx).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	cleared, err := h.strikes.WithContext(ctx).ClearAll(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to clear strikes"})
+		return
+	}
+	logger.FromContext(ctx).Info("strikes cleared", "user", user.ID, "count", cleared)
+	c.Status(http.StatusNoContent)
+}
+
+// ClearUserStrike godoc
+// @Summary Clear one strike
+// @Description Clear one of a user's strikes, such as a no-show for a meeting that did happen (users.write)
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param strike_id path string true "Strike ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/strikes/{strike_id} [delete]
+func (h *StrikeHandler) ClearUserStrike(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	strikeID, err := uuid.Parse(c.Param("strike_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid strike id"})
+		return
+	}
+
+	if err := h.strikes.WithContext(ctx).Clear(userID, strikeID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "strike not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to clear strike"})
+		return
+	}
+	logger.FromContext(ctx).Info("strike cleared", "user", userID, "strike", strikeID)
+	c.Status(http.StatusNoContent)
+}
+
+func (h *StrikeHandler) status(c *gin.Context, userID uuid.UUID) {
+	status, err := h.tracker.Status(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch strikes"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": status})
+}
+
+// strikesAllow reports whether organizer's strikes let them add bookings
+// in rooms, adding is how many active bookings they would gain, and
+// responds with 403 when they do not. Callers with bookings.manage_any for
+// every room, such as receptionists, are not held back.
+func strikesAllow(c *gin.Context, tracker *strike.Tracker, organizer uuid.UUID, rooms []*model.Room, adding int) bool {
+	exempt := len(rooms) > 0
+	ids := make([]uuid.UUID, len(rooms))
+	for i, room := range rooms {
+		ids[i] = room.ID
+		exempt = exempt && can(c, model.PermBookingsManageAny, room)
+	}
+	if exempt {
+		return true
+	}
+
+	err := tracker.Check(c.Request.Context(), organizer, ids, adding)
+	var restricted *strike.RestrictedError
+	if errors.As(err, &restricted) {
+		c.JSON(http.StatusForbidden, gin.H{"error": restricted.Error()})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check strikes"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/riparuk/meet-book-api/internal/mailer"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/utils"
+)
+
+// tokenLinks issues single-use tokens and emails them to users as links
+// to the frontend
+type tokenLinks struct {
+	tokens      repository.UserTokenRepository
+	mailer      mailer.Mailer
+	linkBaseURL string
+}
+
+// send replaces any outstanding tokens of user for purpose with a new one
+// and emails body(link), where link is linkBaseURL/path?token=...
+func (l tokenLinks) send(ctx context.Context, user *model.User, purpose model.TokenPurpose, ttl time.Duration, path, subject string, body func(link string) string) error {
+	return l.sendTo(ctx, user.Email, user, purpose, ttl, path, subject, body)
+}
+
+// sendTo is send with the email going to the address to instead of the
+// user's current one
+func (l tokenLinks) sendTo(ctx context.Context, to string, user *model.User, purpose model.TokenPurpose, ttl time.Duration, path, subject string, body func(link string) string) error {
+	if err := l.tokens.WithContext(ctx).RevokeAll(user.ID, purpose); err != nil {
+		return err
+	}
+
+	plain, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	token := model.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := l.tokens.WithContext(ctx).Create(&token); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/%s?token=%s", strings.TrimRight(l.linkBaseURL, "/"), path, url.QueryEscape(plain))
+	return l.mailer.Send(ctx, mailer.Message{To: to, Subject: subject, Body: body(link)})
+}
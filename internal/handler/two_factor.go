@@ -0,0 +1,417 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/metrics"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/ratelimit"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"github.com/riparuk/meet-book-api/internal/totp"
+	"github.com/riparuk/meet-book-api/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+// recoveryCodesExpire is far enough away that recovery codes never expire
+var recoveryCodesExpire = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+type TwoFactorHandler struct {
+	users    repository.UserRepository
+	tokens   repository.UserTokenRepository
+	settings repository.SettingRepository
+	limiter  *ratelimit.Limiter
+	issuer   string
+}
+
+func NewTwoFactorHandler(users repository.UserRepository, tokens repository.UserTokenRepository, settings repository.SettingRepository, limiter *ratelimit.Limiter, issuer string) *TwoFactorHandler {
+	return &TwoFactorHandler{users: users, tokens: tokens, settings: settings, limiter: limiter, issuer: issuer}
+}
+
+// currentUser loads the authenticated user, writing the error response
+// when it fails
+func (h *TwoFactorHandler) currentUser(c *gin.Context) (*model.User, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return nil, false
+	}
+
+	user, err := h.users.WithContext(c.Request.Context()).FindByID(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return nil, false
+	}
+	return user, true
+}
+
+// Setup godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and the otpauth:// provisioning URI to show as a QR code. Two-factor authentication is enabled once a code is confirmed with /me/2fa/enable.
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.TwoFactorSetupResponse
+// @Failure 409 {object} map[string]string "Already enabled"
+// @Router /me/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate secret"})
+		return
+	}
+
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	if err := h.users.WithContext(ctx).Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start enrollment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": model.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(h.issuer, user.Email, secret),
+	}})
+}
+
+// Enable godoc
+// @Summary Enable two-factor authentication
+// @Description Confirm enrollment with a code from the authenticator app. The response lists one-time recovery codes, they are shown only once.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.TwoFactorCodeRequest true "Authenticator code"
+// @Success 200 {object} model.RecoveryCodesResponse
+// @Failure 400 {object} map[string]string
+// @Router /me/2fa/enable [post]
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication is already enabled"})
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start enrollment with /me/2fa/setup first"})
+		return
+	}
+
+	step, valid := totp.Validate(user.TOTPSecret, req.Code, time.Now(), user.TOTPLastStep)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+		return
+	}
+
+	user.TOTPEnabled = true
+	user.TOTPLastStep = step
+	if err := h.users.WithContext(ctx).Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enable two-factor authentication"})
+		return
+	}
+
+	codes, err := h.issueRecoveryCodes(ctx, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate recovery codes"})
+		return
+	}
+
+	logger.FromContext(ctx).Info("two-factor authentication enabled", "user", user.ID)
+	c.JSON(http.StatusOK, gin.H{"data": model.RecoveryCodesResponse{RecoveryCodes: codes}})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes, the previous ones stop working
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.TwoFactorCodeRequest true "Authenticator code"
+// @Success 200 {object} model.RecoveryCodesResponse
+// @Failure 400 {object} map[string]string
+// @Router /me/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "two-factor authentication is not enabled"})
+		return
+	}
+
+	// Only an authenticator code is accepted, not a recovery code
+	step, valid := totp.Validate(user.TOTPSecret, req.Code, time.Now(), user.TOTPLastStep)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+		return
+	}
+	user.TOTPLastStep = step
+	if err := h.users.WithContext(ctx).Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate recovery codes"})
+		return
+	}
+
+	codes, err := h.issueRecoveryCodes(ctx, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": model.RecoveryCodesResponse{RecoveryCodes: codes}})
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Turn off two-factor authentication, requires the password and an authenticator or recovery code
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.DisableTwoFactorRequest true "Password and code"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Required for admins"
+// @Router /me/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req model.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "two-factor authentication is not enabled"})
+		return
+	}
+
+	if callerGrants(c).Administrative() {
+		required, err := h.adminTwoFactorRequired(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load settings"})
+			return
+		}
+		if required {
+			c.JSON(http.StatusForbidden, gin.H{"error": "two-factor authentication is required for administrative permissions"})
+			return
+		}
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid password or code"})
+		return
+	}
+	valid, err := h.verifySecondFactor(ctx, user, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify code"})
+		return
+	}
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid password or code"})
+		return
+	}
+
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	if err := h.users.WithContext(ctx).Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to disable two-factor authentication"})
+		return
+	}
+	if err := h.tokens.WithContext(ctx).RevokeAll(user.ID, model.TokenPurposeRecoveryCode); err != nil {
+		logger.FromContext(ctx).Error("failed to revoke recovery codes", "error", err)
+	}
+
+	logger.FromContext(ctx).Info("two-factor authentication disabled", "user", user.ID)
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "two-factor authentication disabled"}})
+}
+
+// CompleteLogin godoc
+// @Summary Complete a two-factor login
+// @Description Exchange the challenge token from /auth/login and an authenticator or recovery code for an access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body model.LoginTwoFactorRequest true "Challenge token and code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string "Account deactivated"
+// @Failure 429 {object} map[string]string "Too many attempts, see the Retry-After header"
+// @Router /auth/login/2fa [post]
+func (h *TwoFactorHandler) CompleteLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	var req model.LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	// The challenge is only valid in the organization it was issued in
+	userID, orgID, err := utils.ParseChallengeJWT(req.ChallengeToken)
+	if err != nil || orgID != tenant.FromContext(ctx) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge, log in again"})
+		return
+	}
+
+	user, err := h.users.WithContext(ctx).FindByID(userID)
+	if err != nil || !user.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge, log in again"})
+		return
+	}
+
+	account := limiterKey(user.OrganizationID, user.Email)
+	locked, retryAfter, err := h.limiter.Locked(ctx, account)
+	if err != nil {
+		log.Error("rate limiter unavailable", "error", err)
+	} else if locked {
+		metrics.LoginFailures.WithLabelValues("locked").Inc()
+		tooManyRequests(c, retryAfter, "Account temporarily locked after repeated failed logins, try again later")
+		return
+	}
+
+	valid, err := h.verifySecondFactor(ctx, user, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+	if !valid {
+		metrics.LoginFailures.WithLabelValues("invalid_2fa").Inc()
+		if _, err := h.limiter.RecordFailure(ctx, account); err != nil {
+			log.Error("failed to record login failure", "error", err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	if err := h.limiter.RecordSuccess(ctx, account); err != nil {
+		log.Error("failed to clear login failures", "error", err)
+	}
+	if rejectDeactivated(c, user) {
+		return
+	}
+
+	respondWithToken(c, user)
+}
+
+// verifySecondFactor accepts a current authenticator code or an unused
+// recovery code, consuming either
+func (h *TwoFactorHandler) verifySecondFactor(ctx context.Context, user *model.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) == totp.Digits {
+		step, valid := totp.Validate(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+		if !valid {
+			return false, nil
+		}
+		user.TOTPLastStep = step
+		return true, h.users.WithContext(ctx).Update(user)
+	}
+
+	_, err := h.tokens.WithContext(ctx).ConsumeFor(user.ID, model.TokenPurposeRecoveryCode, utils.HashToken(normalizeRecoveryCode(code)), time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	logger.FromContext(ctx).Info("recovery code used", "user", user.ID)
+	return true, nil
+}
+
+// issueRecoveryCodes replaces the recovery codes of user and returns the
+// new ones in plain text
+func (h *TwoFactorHandler) issueRecoveryCodes(ctx context.Context, user *model.User) ([]string, error) {
+	if err := h.tokens.WithContext(ctx).RevokeAll(user.ID, model.TokenPurposeRecoveryCode); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		token := model.UserToken{
+			UserID:    user.ID,
+			Purpose:   model.TokenPurposeRecoveryCode,
+			TokenHash: utils.HashToken(normalizeRecoveryCode(code)),
+			ExpiresAt: recoveryCodesExpire,
+		}
+		if err := h.tokens.WithContext(ctx).Create(&token); err != nil {
+			return nil, err
+		}
+		codes[i] = code
+	}
+	return codes, nil
+}
+
+func (h *TwoFactorHandler) adminTwoFactorRequired(ctx context.Context) (bool, error) {
+	value, err := h.settings.WithContext(ctx).Get(model.SettingRequireAdmin2FA, "false")
+	return value == "true", err
+}
+
+// generateRecoveryCode returns 80 random bits formatted as xxxx-xxxx-xxxx-xxxx
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	s := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return s[0:4] + "-" + s[4:8] + "-" + s[8:12] + "-" + s[12:16], nil
+}
+
+// normalizeRecoveryCode makes codes match regardless of case and dashes
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
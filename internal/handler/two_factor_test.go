@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/totp"
+)
+
+func TestLoginTwoFactorChallenge(t *testing.T) {
+	s := newTestServer(t)
+	user := s.user(t, "alice@example.com", model.RoleUser)
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.TOTPSecret, user.TOTPEnabled = secret, true
+	if err := s.users.Update(user); err != nil {
+		t.Fatal(err)
+	}
+
+	code, resp := s.do(t, http.MethodPost, "/api/auth/login", "", map[string]any{"email": user.Email, "password": "password1"})
+	data, _ := resp["data"].(map[string]any)
+	if code != http.StatusOK || data["two_factor_required"] != true || data["token"] != nil {
+		t.Fatalf("login = %d %v, want a challenge and no token", code, resp)
+	}
+	challenge := data["challenge_token"].(string)
+
+	// the challenge is not an access token
+	if code, resp := s.do(t, http.MethodPost, "/api/me/bookings", challenge, map[string]any{}); code != http.StatusUnauthorized {
+		t.Fatalf("challenge as access token = %d %v, want 401", code, resp)
+	}
+	if code, resp := s.do(t, http.MethodPost, "/api/auth/login/2fa", "", map[string]any{"challenge_token": challenge, "code": "000000x"}); code != http.StatusUnauthorized {
+		t.Fatalf("wrong code = %d %v, want 401", code, resp)
+	}
+
+	current, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, resp = s.do(t, http.MethodPost, "/api/auth/login/2fa", "", map[string]any{"challenge_token": challenge, "code": current})
+	data, _ = resp["data"].(map[string]any)
+	if code != http.StatusOK || data["token"] == nil {
+		t.Fatalf("complete login = %d %v, want a token", code, resp)
+	}
+	// a code is accepted once
+	if code, resp := s.do(t, http.MethodPost, "/api/auth/login/2fa", "", map[string]any{"challenge_token": challenge, "code": current}); code != http.StatusUnauthorized {
+		t.Fatalf("replayed code = %d %v, want 401", code, resp)
+	}
+}
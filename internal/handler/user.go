@@ -2,37 +2,54 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/mailer"
+	"github.com/riparuk/meet-book-api/internal/metrics"
 	"github.com/riparuk/meet-book-api/internal/model"
 	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/strike"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserHandler struct {
-	userRepo    repository.UserRepository
-	bookingRepo repository.BookingRepository
+	userRepo     repository.UserRepository
+	bookingRepo  repository.BookingRepository
+	access       roomAccess
+	verification *VerificationHandler
+	notifier     bookingNotifier
+	strikes      *strike.Tracker
 }
 
-func NewUserHandler(userRepo repository.UserRepository, bookingRepo repository.BookingRepository) *UserHandler {
+func NewUserHandler(userRepo repository.UserRepository, bookingRepo repository.BookingRepository, roomRepo repository.RoomRepository, verification *VerificationHandler, m mailer.Mailer, strikes *strike.Tracker) *UserHandler {
 	return &UserHandler{
-		userRepo:    userRepo,
-		bookingRepo: bookingRepo,
+		userRepo:     userRepo,
+		bookingRepo:  bookingRepo,
+		access:       roomAccess{rooms: roomRepo},
+		verification: verification,
+		notifier:     bookingNotifier{users: userRepo, mailer: m},
+		strikes:      strikes,
 	}
 }
 
 // CreateMyBooking godoc
 // @Summary Create a new booking for the authenticated user
-// @Description Create a new room booking for the currently authenticated user
+// @Description Create a new room booking for the currently authenticated user, who gets a receipt if their booking_receipts preference is on
 // @Tags me
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param input body model.CreateMyBookingInput true "Booking details"
 // @Success 201 {object} model.BookingResponse
+// @Failure 403 {object} map[string]string "Restricted room"
+// @Failure 404 {object} map[string]string "Room not found"
 // @Router /me/bookings [post]
 func (h *UserHandler) CreateMyBooking(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -54,11 +71,12 @@ func (h *UserHandler) CreateMyBooking(c *gin.Context) {
 
 	// Create booking object
 	booking := model.Booking{
-		RoomID:    input.RoomID,
-		UserID:    userUUID,
-		StartTime: input.StartTime,
-		EndTime:   input.EndTime,
-		Status:    model.BookingStatusActive,
+		RoomID:      input.RoomID,
+		UserID:      userUUID,
+		CreatedByID: &userUUID,
+		StartTime:   input.StartTime,
+		EndTime:     input.EndTime,
+		Status:      model.BookingStatusActive,
 	}
 
 	// Validate booking
@@ -67,36 +85,44 @@ func (h *UserHandler) CreateMyBooking(c *gin.Context) {
 		return
 	}
 
+	room, ok := h.access.allow(c, input.RoomID)
+	if !ok || !strikesAllow(c, h.strikes, userUUID, []*model.Room{room}, 1) {
+		return
+	}
+
 	// Check if room is available
-	available, err := h.bookingRepo.IsRoomAvailable(input.RoomID, input.StartTime, input.EndTime, nil)
+	available, err := h.bookingRepo.WithContext(ctx).IsRoomAvailable(input.RoomID, input.StartTime, input.EndTime, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check room availability"})
 		return
 	}
 	if !available {
+		metrics.AvailabilityConflicts.Inc()
 		c.JSON(http.StatusBadRequest, gin.H{"error": "room is not available for the selected time slot"})
 		return
 	}
 
 	// Create the booking
-	if err := h.bookingRepo.Create(&booking); err != nil {
+	if err := h.bookingRepo.WithContext(ctx).Create(&booking); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create booking"})
 		return
 	}
+	metrics.BookingsCreated.WithLabelValues(booking.RoomID.String()).Inc()
 
 	// Get the created booking with related data
-	createdBooking, err := h.bookingRepo.FindByID(booking.ID)
+	createdBooking, err := h.bookingRepo.WithContext(ctx).FindByID(booking.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch created booking"})
 		return
 	}
+	h.notifier.notify(c, bookingCreated, *createdBooking)
 
 	c.JSON(http.StatusCreated, gin.H{"data": createdBooking.ToResponse()})
 }
 
 // GetUsers godoc
 // @Summary Get all users
-// @Description Get all users
+// @Description Get all users (users.read)
 // @Tags users
 // @Accept json
 // @Produce json
@@ -104,7 +130,9 @@ func (h *UserHandler) CreateMyBooking(c *gin.Context) {
 // @Success 200 {array} model.User
 // @Router /users [get]
 func (h *UserHandler) GetUsers(c *gin.Context) {
-	users, err := h.userRepo.FindAll()
+	ctx := c.Request.Context()
+
+	users, err := h.userRepo.WithContext(ctx).FindAll()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -114,21 +142,33 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 
 // CreateUser godoc
 // @Summary Create a new user
-// @Description Create a new user
+// @Description Create a new user. Set verified to skip email verification, otherwise a verification link is sent.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param name body model.CreateUserInput true "name"
 // @Success 201 {object} model.User
+// @Failure 403 {object} map[string]string "Admin account"
+// @Failure 409 {object} map[string]string "Email already registered"
 // @Router /users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	var input model.CreateUserInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	if !managesAdmins(c, input.Role) {
+		return
+	}
+	if _, err := h.userRepo.WithContext(ctx).FindByEmail(input.Email); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
+		return
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to hash password"})
@@ -139,16 +179,240 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		Name:     input.Name,
 		Email:    input.Email,
 		Password: string(hashedPassword),
+		Role:     input.Role,
+		Status:   model.UserStatusUnverified,
+	}
+	if input.Verified {
+		user.Status = model.UserStatusActive
 	}
 
-	if err := h.userRepo.Create(&user); err != nil {
+	if err := h.userRepo.WithContext(ctx).Create(&user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
 		return
 	}
 
+	if !input.Verified {
+		if err := h.verification.SendVerification(ctx, &user); err != nil {
+			logger.FromContext(ctx).Error("failed to send verification email", "error", err)
+		}
+	}
+
 	c.JSON(http.StatusCreated, gin.H{"data": user})
 }
 
+// GetUser godoc
+// @Summary Get a user
+// @Description Get a user by ID (users.read)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} model.User
+// @Failure 404 {object} map[string]string
+// @Router /users/{id} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, err := h.userRepo.WithContext(ctx).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// UpdateUser godoc
+// @Summary Update a user
+// @Description Change a user's name, email or role, fields left out are kept (users.write). Only admins can change admin accounts or make users admins. The last active admin cannot be demoted.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param input body model.UpdateUserInput true "Fields to change"
+// @Success 200 {object} model.User
+// @Failure 403 {object} map[string]string "Admin account"
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Email already registered or last active admin"
+// @Router /users/{id} [patch]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var input model.UpdateUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userRepo.WithContext(ctx).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if !managesAdmins(c, user.Role) {
+		return
+	}
+
+	if input.Email != nil && *input.Email != user.Email {
+		if _, err := h.userRepo.WithContext(ctx).FindByEmail(*input.Email); err == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
+			return
+		}
+		user.Email = *input.Email
+	}
+	if input.Role != nil && *input.Role != user.Role {
+		if !managesAdmins(c, *input.Role) || !h.keepsAnAdmin(c, user) {
+			return
+		}
+		user.Role = *input.Role
+	}
+	if input.Name != nil {
+		user.Name = *input.Name
+	}
+
+	if err := h.userRepo.WithContext(ctx).Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
+		return
+	}
+
+	logger.FromContext(ctx).Info("user updated", "user", user.ID, "role", user.Role)
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// DeactivateUser godoc
+// @Summary Deactivate a user
+// @Description Disable a user's account, they cannot sign in and their existing tokens stop working immediately (users.write). Only admins can deactivate admins. The last active admin cannot be deactivated.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} model.User
+// @Failure 403 {object} map[string]string "Admin account"
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Last active admin"
+// @Router /users/{id}/deactivate [post]
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, err := h.userRepo.WithContext(ctx).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if !managesAdmins(c, user.Role) {
+		return
+	}
+	if user.Deactivated() {
+		c.JSON(http.StatusOK, gin.H{"data": user})
+		return
+	}
+	if !h.keepsAnAdmin(c, user) {
+		return
+	}
+
+	// Revoke the sessions too, so reactivating the account does not bring
+	// back tokens issued before it was deactivated
+	now := time.Now()
+	user.DeactivatedAt = &now
+	user.TokensValidAfter = &now
+	if err := h.userRepo.WithContext(ctx).Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to deactivate user"})
+		return
+	}
+
+	logger.FromContext(ctx).Info("user deactivated", "user", user.ID)
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// ReactivateUser godoc
+// @Summary Reactivate a user
+// @Description Let a deactivated user sign in again (users.write)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} model.User
+// @Failure 403 {object} map[string]string "Admin account"
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/reactivate [post]
+func (h *UserHandler) ReactivateUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, err := h.userRepo.WithContext(ctx).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if !managesAdmins(c, user.Role) {
+		return
+	}
+	if !user.Deactivated() {
+		c.JSON(http.StatusOK, gin.H{"data": user})
+		return
+	}
+
+	user.DeactivatedAt = nil
+	if err := h.userRepo.WithContext(ctx).Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reactivate user"})
+		return
+	}
+
+	logger.FromContext(ctx).Info("user reactivated", "user", user.ID)
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// DeleteUser godoc
+// @Summary Delete a user
+// @Description Soft-delete a user (users.write). Only admins can delete admins. Their bookings are kept, and their email is released so they can be invited again. The last active admin cannot be deleted.
+// @Tags users
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 403 {object} map[string]string "Admin account"
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Last active admin"
+// @Router /users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, err := h.userRepo.WithContext(ctx).FindByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if !managesAdmins(c, user.Role) || !h.keepsAnAdmin(c, user) {
+		return
+	}
+
+	if err := h.userRepo.WithContext(ctx).Delete(user.ID.String()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete user"})
+		return
+	}
+
+	logger.FromContext(ctx).Info("user deleted", "user", user.ID)
+	c.Status(http.StatusNoContent)
+}
+
+// keepsAnAdmin reports whether demoting, deactivating or deleting user
+// leaves at least one active admin, and responds with 409 when it does not
+func (h *UserHandler) keepsAnAdmin(c *gin.Context, user *model.User) bool {
+	if user.Role != model.RoleAdmin || user.Deactivated() {
+		return true
+	}
+
+	count, err := h.userRepo.WithContext(c.Request.Context()).CountActiveAdmins()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count admins"})
+		return false
+	}
+	if count <= 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "cannot remove the last active admin"})
+		return false
+	}
+	return true
+}
+
 // Profile godoc
 // @Summary Get current user profile
 // @Description Get the authenticated user's profile
@@ -158,6 +422,8 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 // @Success 200 {object} model.User
 // @Router /me [get]
 func (h *UserHandler) Profile(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -171,12 +437,100 @@ func (h *UserHandler) Profile(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userRepo.FindByID(userIDStr)
+	user, err := h.userRepo.WithContext(ctx).FindByID(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// UpdateProfile godoc
+// @Summary Update current user profile
+// @Description Change the authenticated user's name, timezone, locale or notification preferences, fields left out are kept. A new email is stored as pending_email and a confirmation link is sent to it, the email changes once the link is followed. Sending the current email withdraws a pending change.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body model.UpdateProfileInput true "Fields to change"
+// @Success 200 {object} model.User
+// @Failure 409 {object} map[string]string "Email already registered"
+// @Failure 502 {object} map[string]string "Confirmation email could not be sent"
+// @Router /me [patch]
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	ctx := c.Request.Context()
+	log := logger.FromContext(ctx)
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input model.UpdateProfileInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userRepo.WithContext(ctx).FindByID(userID.(string))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
 	}
 
+	if input.Name != nil {
+		user.Name = *input.Name
+	}
+	if input.Timezone != nil {
+		user.Timezone = *input.Timezone
+	}
+	if input.Locale != nil {
+		user.Locale = *input.Locale
+	}
+	if prefs := input.Notifications; prefs != nil {
+		if prefs.BookingUpdates != nil {
+			user.Notifications.BookingUpdates = *prefs.BookingUpdates
+		}
+		if prefs.BookingReceipts != nil {
+			user.Notifications.BookingReceipts = *prefs.BookingReceipts
+		}
+	}
+
+	var sendChange, cancelChange bool
+	if input.Email != nil {
+		if *input.Email == user.Email {
+			cancelChange = user.PendingEmail != nil
+			user.PendingEmail = nil
+		} else {
+			if _, err := h.userRepo.WithContext(ctx).FindByEmail(*input.Email); err == nil {
+				c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
+				return
+			}
+			user.PendingEmail = input.Email
+			sendChange = true
+		}
+	}
+
+	if err := h.userRepo.WithContext(ctx).Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update profile"})
+		return
+	}
+
+	if cancelChange {
+		if err := h.verification.CancelEmailChange(ctx, user); err != nil {
+			log.Error("failed to revoke email change tokens", "error", err)
+		}
+	}
+	if sendChange {
+		if err := h.verification.SendEmailChange(ctx, user); err != nil {
+			log.Error("failed to send email change confirmation", "error", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to send the confirmation email, try again"})
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"data": user})
 }
 
@@ -193,6 +547,8 @@ func (h *UserHandler) Profile(c *gin.Context) {
 // @Failure 500 {object} object{error=string} "Failed to fetch bookings"
 // @Router /me/bookings [get]
 func (h *UserHandler) GetMyBookings(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -214,7 +570,7 @@ func (h *UserHandler) GetMyBookings(c *gin.Context) {
 	}
 
 	// Get user's bookings
-	bookings, err := h.bookingRepo.FindByUserID(userUUID)
+	bookings, err := h.bookingRepo.WithContext(ctx).FindByUserID(userUUID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch bookings"})
 		return
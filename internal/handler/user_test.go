@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/riparuk/meet-book-api/internal/model"
+)
+
+func TestLastAdminIsKept(t *testing.T) {
+	s := newTestServer(t)
+	admin := s.user(t, "admin@example.com", model.RoleAdmin)
+	adminToken := token(t, admin)
+
+	if code, resp := s.do(t, http.MethodPatch, "/api/users/"+admin.ID.String(), adminToken, map[string]any{"role": "user"}); code != http.StatusConflict {
+		t.Fatalf("demote last admin = %d %v, want 409", code, resp)
+	}
+	if code, resp := s.do(t, http.MethodPost, "/api/users/"+admin.ID.String()+"/deactivate", adminToken, nil); code != http.StatusConflict {
+		t.Fatalf("deactivate last admin = %d %v, want 409", code, resp)
+	}
+
+	other := s.user(t, "other@example.com", model.RoleAdmin)
+	if code, resp := s.do(t, http.MethodPatch, "/api/users/"+other.ID.String(), adminToken, map[string]any{"role": "user"}); code != http.StatusOK {
+		t.Fatalf("demote an admin with another left = %d %v, want 200", code, resp)
+	}
+	if code, resp := s.do(t, http.MethodPost, "/api/users/"+admin.ID.String()+"/deactivate", adminToken, nil); code != http.StatusConflict {
+		t.Fatalf("deactivate the admin left = %d %v, want 409", code, resp)
+	}
+}
+
+func TestAdminTwoFactorFollowsPermissions(t *testing.T) {
+	s := newTestServer(t)
+	if err := s.settings.Set(model.SettingRequireAdmin2FA, "true"); err != nil {
+		t.Fatal(err)
+	}
+	admin := s.user(t, "admin@example.com", model.RoleAdmin)
+	manager := s.user(t, "manager@example.com", model.RoleUser)
+	s.grant(t, manager, model.PermUsersWrite)
+	booker := s.user(t, "booker@example.com", model.RoleUser)
+	s.grant(t, booker, model.PermRoomsAccessAll)
+	target := s.user(t, "target@example.com", model.RoleUser)
+	path := "/api/users/" + target.ID.String()
+
+	cases := []struct {
+		name   string
+		caller *model.User
+		want   int
+	}{
+		{"admin without 2fa", admin, http.StatusForbidden},
+		{"custom role without 2fa", manager, http.StatusForbidden},
+		// refused for lacking users.write, not for lacking 2fa
+		{"non-administrative role", booker, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		code, resp := s.do(t, http.MethodPatch, path, token(t, tc.caller), map[string]any{"name": "Target"})
+		if code != tc.want {
+			t.Errorf("%s = %d %v, want %d", tc.name, code, resp, tc.want)
+		}
+		twoFactor := strings.Contains(fmt.Sprint(resp["error"]), "two-factor")
+		if twoFactor != (tc.caller != booker) {
+			t.Errorf("%s refused with %q", tc.name, resp["error"])
+		}
+	}
+
+	manager.TOTPEnabled = true
+	if err := s.users.Update(manager); err != nil {
+		t.Fatal(err)
+	}
+	if code, resp := s.do(t, http.MethodPatch, path, token(t, manager), map[string]any{"name": "Target"}); code != http.StatusOK {
+		t.Fatalf("custom role with 2fa = %d %v, want 200", code, resp)
+	}
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/mailer"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/ratelimit"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/utils"
+	"gorm.io/gorm"
+)
+
+// VerificationOptions configures email verification links
+type VerificationOptions struct {
+	// TTL is how long a verification link stays valid
+	TTL time.Duration
+	// LinkBaseURL is the frontend URL, links point to LinkBaseURL/verify-email
+	LinkBaseURL string
+	// ResendPerAccount limits resend requests per account in the limiter window
+	ResendPerAccount int
+}
+
+type VerificationHandler struct {
+	users   repository.UserRepository
+	tokens  repository.UserTokenRepository
+	links   tokenLinks
+	limiter *ratelimit.Limiter
+	opts    VerificationOptions
+}
+
+func NewVerificationHandler(users repository.UserRepository, tokens repository.UserTokenRepository, m mailer.Mailer, limiter *ratelimit.Limiter, opts VerificationOptions) *VerificationHandler {
+	return &VerificationHandler{
+		users:   users,
+		tokens:  tokens,
+		links:   tokenLinks{tokens: tokens, mailer: m, linkBaseURL: opts.LinkBaseURL},
+		limiter: limiter,
+		opts:    opts,
+	}
+}
+
+// SendVerification emails user a new verification link, replacing any
+// outstanding one
+func (h *VerificationHandler) SendVerification(ctx context.Context, user *model.User) error {
+	return h.links.send(ctx, user, model.TokenPurposeEmailVerification, h.opts.TTL, "verify-email",
+		"Confirm your Meet Book email address",
+		func(link string) string {
+			return fmt.Sprintf("Hi %s,\n\nConfirm your email address to start booking rooms. The link expires in %s.\n\n%s\n\nIf you didn't create an account you can ignore this email.\n",
+				user.Name, h.opts.TTL, link)
+		})
+}
+
+// SendEmailChange emails a confirmation link to the user's pending email,
+// replacing any outstanding one
+func (h *VerificationHandler) SendEmailChange(ctx context.Context, user *model.User) error {
+	return h.links.sendTo(ctx, *user.PendingEmail, user, model.TokenPurposeEmailChange, h.opts.TTL, "verify-email",
+		"Confirm your new Meet Book email address",
+		func(link string) string {
+			return fmt.Sprintf("Hi %s,\n\nConfirm this address to use it for your Meet Book account. The link expires in %s.\n\n%s\n\nIf you didn't ask for this change you can ignore this email.\n",
+				user.Name, h.opts.TTL, link)
+		})
+}
+
+// CancelEmailChange invalidates outstanding links to a pending email that
+// the user withdrew
+func (h *VerificationHandler) CancelEmailChange(ctx context.Context, user *model.User) error {
+	return h.tokens.WithContext(ctx).RevokeAll(user.ID, model.TokenPurposeEmailChange)
+}
+
+// VerifyEmail godoc
+// @Summary Verify an email address
+// @Description Confirm the email address of an account, or a new address set with PATCH /me, with the token from the verification email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body model.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string "New email registered to another account meanwhile"
+// @Router /auth/verify-email [post]
+func (h *VerificationHandler) VerifyEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req model.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	hash, now := utils.HashToken(req.Token), time.Now()
+	token, err := h.tokens.WithContext(ctx).Consume(model.TokenPurposeEmailVerification, hash, now)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		token, err = h.tokens.WithContext(ctx).Consume(model.TokenPurposeEmailChange, hash, now)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token, request a new one"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	user, err := h.users.WithContext(ctx).FindByID(token.UserID.String())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token, request a new one"})
+		return
+	}
+
+	if token.Purpose == model.TokenPurposeEmailChange {
+		h.confirmEmailChange(c, user)
+		return
+	}
+
+	if user.Status == model.UserStatusUnverified {
+		user.Status = model.UserStatusActive
+		if err := h.users.WithContext(ctx).Update(user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+			return
+		}
+		logger.FromContext(ctx).Info("email verified", "user", user.ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "Email verified"}})
+}
+
+// confirmEmailChange replaces the user's email with the pending one the
+// link was sent to, which also verifies the account
+func (h *VerificationHandler) confirmEmailChange(c *gin.Context, user *model.User) {
+	ctx := c.Request.Context()
+
+	if user.PendingEmail == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token, request a new one"})
+		return
+	}
+	if _, err := h.users.WithContext(ctx).FindByEmail(*user.PendingEmail); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+		return
+	}
+
+	user.Email = *user.PendingEmail
+	user.PendingEmail = nil
+	user.Status = model.UserStatusActive
+	if err := h.users.WithContext(ctx).Update(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	// Links sent to the old address must not verify the new one
+	if err := h.tokens.WithContext(ctx).RevokeAll(user.ID, model.TokenPurposeEmailVerification); err != nil {
+		logger.FromContext(ctx).Error("failed to revoke verification tokens", "error", err)
+	}
+	logger.FromContext(ctx).Info("email changed", "user", user.ID)
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"message": "Email changed"}})
+}
+
+// ResendVerification godoc
+// @Summary Resend the verification email
+// @Description Send a new verification link to the authenticated user, previous links stop working
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} map[string]string
+// @Failure 409 {object} map[string]string "Email already verified"
+// @Failure 429 {object} map[string]string "Too many attempts, see the Retry-After header"
+// @Router /me/verify-email/resend [post]
+func (h *VerificationHandler) ResendVerification(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	user, err := h.users.WithContext(ctx).FindByID(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	if user.Status != model.UserStatusUnverified {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already verified"})
+		return
+	}
+
+	allowed, retryAfter, err := h.limiter.Allow(ctx, "verification:account:"+limiterKey(user.OrganizationID, user.Email), h.opts.ResendPerAccount)
+	if err != nil {
+		logger.FromContext(ctx).Error("rate limiter unavailable", "error", err)
+	} else if !allowed {
+		tooManyRequests(c, retryAfter, "Too many verification emails requested, try again later")
+		return
+	}
+
+	if err := h.SendVerification(ctx, user); err != nil {
+		logger.FromContext(ctx).Error("failed to send verification email", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": gin.H{"message": "Verification email sent"}})
+}
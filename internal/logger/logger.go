@@ -0,0 +1,62 @@
+// Package logger configures structured logging with log/slog and carries
+// per-request loggers through context.Context.
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type ctxKey struct{}
+
+// Init installs the default logger writing to stdout, level is debug, info,
+// warn or error and format is json or text
+func Init(level, format string) *slog.Logger {
+	l := New(os.Stdout, level, format)
+	slog.SetDefault(l)
+	return l
+}
+
+// New creates a logger writing to w. Sensitive attributes and values are
+// redacted before they are written.
+func New(w io.Writer, level, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{
+		Level:       ParseLevel(level),
+		ReplaceAttr: Redact,
+	}
+
+	if strings.ToLower(format) == "text" {
+		return slog.New(slog.NewTextHandler(w, opts))
+	}
+	return slog.New(slog.NewJSONHandler(w, opts))
+}
+
+// ParseLevel converts a level name to a slog.Level, defaulting to info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// WithContext returns a copy of ctx carrying l
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	emailPattern = regexp.MustCompile(`([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*@([A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+	jwtPattern   = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	bearerPrefix = regexp.MustCompile(`(?i)bearer\s+\S+`)
+)
+
+// sensitiveKeys are attribute names whose values are never logged
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "cookie"}
+
+// Redact is a slog ReplaceAttr function that hides credentials and masks
+// email addresses, keeping the first character and the domain
+func Redact(groups []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return slog.String(a.Key, redacted)
+		}
+	}
+
+	if a.Value.Kind() == slog.KindString {
+		return slog.String(a.Key, RedactString(a.Value.String()))
+	}
+	return a
+}
+
+// RedactString masks emails and tokens found in free text
+func RedactString(s string) string {
+	if s == "" {
+		return s
+	}
+	s = bearerPrefix.ReplaceAllString(s, "Bearer "+redacted)
+	s = jwtPattern.ReplaceAllString(s, redacted)
+	return emailPattern.ReplaceAllString(s, "$1***@$2")
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRedactString(t *testing.T) {
+	cases := map[string]string{
+		"login failed for riparuk@gmail.com":   "login failed for r***@gmail.com",
+		"Authorization: Bearer abc.def.ghi":    "Authorization: Bearer [REDACTED]",
+		"token eyJhbGciOi.eyJ1c2VyX2lk.sig-_1": "token [REDACTED]",
+		"room 42 booked":                       "room 42 booked",
+	}
+	for in, want := range cases {
+		if got := RedactString(in); got != want {
+			t.Errorf("RedactString(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLoggerRedactsSensitiveAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, "info", "json")
+
+	l.Info("user registered", "email", "alice@example.com", "password", "hunter2", "refresh_token", "abc")
+
+	out := buf.String()
+	for _, leaked := range []string{"alice@example.com", "hunter2", `"abc"`} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("log output leaked %q: %s", leaked, out)
+		}
+	}
+	if !strings.Contains(out, "a***@example.com") {
+		t.Errorf("expected masked email in %s", out)
+	}
+}
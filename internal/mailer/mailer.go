@@ -0,0 +1,84 @@
+// Package mailer sends transactional email such as password reset links.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/riparuk/meet-book-api/internal/config"
+	"github.com/riparuk/meet-book-api/internal/logger"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer selected by cfg.Driver
+func New(cfg config.MailConfig) Mailer {
+	if cfg.Driver == "smtp" {
+		return NewSMTPMailer(cfg)
+	}
+	return NewLogMailer()
+}
+
+// LogMailer writes messages to the log instead of sending them, for
+// development. Links in the body are logged in full.
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	logger.FromContext(ctx).Info("email not sent, MAIL_DRIVER is log",
+		slog.String("to", msg.To),
+		slog.String("subject", msg.Subject),
+		slog.String("body", msg.Body),
+	)
+	return nil
+}
+
+// SMTPMailer sends plain text messages through an SMTP server, using
+// STARTTLS when the server offers it
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(cfg config.MailConfig) *SMTPMailer {
+	m := &SMTPMailer{
+		addr: net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		from: cfg.From,
+	}
+	if cfg.SMTPUsername != "" {
+		m.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return m
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, []byte(b.String())); err != nil {
+		return fmt.Errorf("send mail: %w", err)
+	}
+	return nil
+}
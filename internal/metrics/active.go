@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ActiveBookingsCounter counts active bookings in progress at a given time
+type ActiveBookingsCounter interface {
+	CountActiveAt(at time.Time) (int64, error)
+}
+
+// RefreshActiveBookings updates the ActiveBookings gauge every interval
+// until ctx is cancelled
+func RefreshActiveBookings(ctx context.Context, counter ActiveBookingsCounter, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := counter.CountActiveAt(time.Now())
+		if err != nil {
+			slog.WarnContext(ctx, "failed to count active bookings", "error", err)
+		} else {
+			ActiveBookings.Set(float64(count))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
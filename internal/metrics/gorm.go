@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const startTimeKey = "metrics:start_time"
+
+// GormPlugin records the duration of every GORM operation in DBQueryDuration
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, h := range hooks {
+		if err := h.before("metrics:before_"+h.operation, startTimer); err != nil {
+			return err
+		}
+		if err := h.after("metrics:after_"+h.operation, observe(h.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(startTimeKey, time.Now())
+}
+
+func observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(startTimeKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		DBQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+	}
+}
+
+// InstrumentDB registers the query timing plugin and exports the
+// connection pool statistics of db
+func InstrumentDB(db *gorm.DB) error {
+	if err := db.Use(GormPlugin{}); err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return Registry.Register(collectors.NewDBStatsCollector(sqlDB, "meetbook"))
+}
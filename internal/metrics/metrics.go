@@ -0,0 +1,100 @@
+// Package metrics exposes Prometheus metrics for HTTP traffic, database
+// queries and booking business KPIs.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "meetbook"
+
+// Registry holds every metric exposed on /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "GORM query latency by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"operation", "table"})
+
+	BookingsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bookings_created_total",
+		Help:      "Bookings created by room.",
+	}, []string{"room_id"})
+
+	BookingsCancelled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bookings_cancelled_total",
+		Help:      "Bookings cancelled by room.",
+	}, []string{"room_id"})
+
+	AvailabilityConflicts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "booking_availability_conflicts_total",
+		Help:      "Booking attempts rejected because the room was already taken.",
+	})
+
+	LoginFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_login_failures_total",
+		Help:      "Failed login attempts by reason.",
+	}, []string{"reason"})
+
+	ActiveBookings = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "bookings_active",
+		Help:      "Active bookings in progress right now.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		DBQueryDuration,
+		BookingsCreated,
+		BookingsCancelled,
+		AvailabilityConflicts,
+		LoginFailures,
+		ActiveBookings,
+	)
+}
+
+// Handler serves the registry, callers must present token as a Bearer token
+func Handler(token string) gin.HandlerFunc {
+	h := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+
+	return func(c *gin.Context) {
+		presented := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid metrics token"})
+			return
+		}
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
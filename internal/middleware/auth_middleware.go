@@ -4,12 +4,23 @@ import (
 	"net/http"
 	"strings"
 
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/audit"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
 	"github.com/riparuk/meet-book-api/internal/utils"
 )
 
-// JWTAuthMiddleware verifies JWT token and injects userID into context
-func JWTAuthMiddleware() gin.HandlerFunc {
+// JWTAuthMiddleware verifies JWT token and injects userID into context.
+// The request acts for the organization the token was issued in. Tokens
+// of deleted or deactivated users and tokens issued before the
+// user's sessions were revoked, for example by a password reset, are
+// rejected. The role is read from the user rather than the token, so role
+// changes apply to existing sessions immediately.
+func JWTAuthMiddleware(users repository.UserRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -27,16 +38,140 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenString := parts[1]
-		userID, userRole, err := utils.ValidateJWT(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		if strings.HasPrefix(tokenString, utils.APIKeyPrefix) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot be used on this route"})
 			c.Abort()
 			return
 		}
 
-		// Set userID and role to context
-		c.Set("user_id", userID)
-		c.Set("user_role", userRole)
+		user, reason := userFromToken(c, users, tokenString)
+		if user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": reason})
+			c.Abort()
+			return
+		}
+
+		setUser(c, user)
 		c.Next()
 	}
 }
+
+// OptionalAuthMiddleware identifies the user on public routes. Requests
+// without a valid user token, API keys included, continue anonymously.
+func OptionalAuthMiddleware(users repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if ok && !strings.HasPrefix(tokenString, utils.APIKeyPrefix) {
+			if user, _ := userFromToken(c, users, tokenString); user != nil {
+				setUser(c, user)
+			}
+		}
+		c.Next()
+	}
+}
+
+// OptionalAPIKeyAuthMiddleware is OptionalAuthMiddleware for public routes
+// that API keys may read too. A request made with a key is authenticated
+// like APIKeyAuthMiddleware does and needs scope, so a key is never
+// downgraded to an anonymous caller.
+func OptionalAPIKeyAuthMiddleware(users repository.UserRepository, keys repository.ServiceAccountRepository, scope model.APIScope) gin.HandlerFunc {
+	keyAuth := APIKeyAuthMiddleware(users, keys, scope)
+	optional := OptionalAuthMiddleware(users)
+	return func(c *gin.Context) {
+		if secret, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && strings.HasPrefix(secret, utils.APIKeyPrefix) {
+			keyAuth(c)
+			return
+		}
+		optional(c)
+	}
+}
+
+// userFromToken returns the user a token was issued to, or the reason to
+// reject it with
+func userFromToken(c *gin.Context, users repository.UserRepository, tokenString string) (*model.User, string) {
+	claims, err := utils.ParseJWT(tokenString)
+	if err != nil {
+		return nil, "Invalid or expired token"
+	}
+	if !switchTenant(c, claims.OrganizationID) {
+		return nil, "Token was issued for another organization"
+	}
+
+	user, err := users.WithContext(c.Request.Context()).FindByID(claims.UserID)
+	if err != nil {
+		return nil, "Invalid or expired token"
+	}
+
+	if user.Deactivated() {
+		return nil, "Account has been deactivated"
+	}
+
+	// iat has second precision, compare at the same precision
+	if user.TokensValidAfter != nil && claims.IssuedAt.Before(user.TokensValidAfter.Truncate(time.Second)) {
+		return nil, "Session has been revoked, please log in again"
+	}
+	return user, ""
+}
+
+// setUser injects the user ID, role and status into context
+func setUser(c *gin.Context, user *model.User) {
+	c.Set("user_id", user.ID.String())
+	c.Set("user_role", user.Role)
+	c.Set("user_status", user.Status)
+	c.Set("user_2fa", user.TOTPEnabled)
+	c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), audit.Actor{Type: model.AuditActorUser, ID: user.ID}))
+}
+
+// keyTouchInterval limits last-used writes to one per key per interval
+const keyTouchInterval = time.Minute
+
+// APIKeyAuthMiddleware accepts service account API keys that hold scope,
+// and user tokens like JWTAuthMiddleware. Requests made with a key act for
+// the organization of its service account and carry service_account_id
+// instead of user_id, and the role checks after it let them through, so
+// scope is the only thing a key is authorized by.
+func APIKeyAuthMiddleware(users repository.UserRepository, keys repository.ServiceAccountRepository, scope model.APIScope) gin.HandlerFunc {
+	jwtAuth := JWTAuthMiddleware(users)
+	return func(c *gin.Context) {
+		secret, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || !strings.HasPrefix(secret, utils.APIKeyPrefix) {
+			jwtAuth(c)
+			return
+		}
+
+		ctx := c.Request.Context()
+		now := time.Now()
+		key, err := keys.WithContext(ctx).FindKeyByHash(utils.HashToken(secret))
+		if err != nil || !key.Active(now) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid, expired or revoked API key"})
+			return
+		}
+		if !switchTenant(c, key.ServiceAccount.OrganizationID) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key belongs to another organization"})
+			return
+		}
+		ctx = c.Request.Context()
+		if !key.Scopes.Has(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + string(scope) + " scope"})
+			return
+		}
+
+		if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= keyTouchInterval {
+			if err := keys.WithContext(ctx).TouchKey(key.ID, now); err != nil {
+				logger.FromContext(ctx).Warn("failed to record api key use", "api_key", key.ID, "error", err)
+			}
+		}
+
+		c.Set("service_account_id", key.ServiceAccountID.String())
+		c.Set("api_key_id", key.ID.String())
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), audit.Actor{Type: model.AuditActorServiceAccount, ID: key.ServiceAccountID}))
+		c.Next()
+	}
+}
+
+// isServiceAccount reports whether the request was authenticated with an
+// API key that APIKeyAuthMiddleware already checked the scope of
+func isServiceAccount(c *gin.Context) bool {
+	_, ok := c.Get("service_account_id")
+	return ok
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/logger"
+)
+
+// AccessLog writes one structured log line per request with the route
+// template, status, latency and authenticated user
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if userID, ok := c.Get("user_id"); ok {
+			attrs = append(attrs, slog.Any("user_id", userID))
+		}
+		if keyID, ok := c.Get("api_key_id"); ok {
+			attrs = append(attrs, slog.String("service_account_id", c.GetString("service_account_id")), slog.Any("api_key_id", keyID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		logger.FromContext(c.Request.Context()).LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery turns panics into a 500 response and logs them with the stack
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				logger.FromContext(c.Request.Context()).Error("panic recovered",
+					"error", fmt.Sprint(err),
+					"stack", string(debug.Stack()),
+				)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/ratelimit"
+)
+
+// RateLimit allows limit requests per client IP in the limiter window,
+// name separates the counters of different routes. Requests are let
+// through when the store fails so an outage doesn't lock everyone out.
+func RateLimit(limiter *ratelimit.Limiter, name string, limit int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		allowed, retryAfter, err := limiter.Allow(ctx, name+":ip:"+c.ClientIP(), limit)
+		if err != nil {
+			logger.FromContext(ctx).Error("rate limiter unavailable", "error", err)
+			c.Next()
+			return
+		}
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests, try again later"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/audit"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limits incoming IDs to a safe charset and length so they
+// can't be used to inject content into logs
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID accepts the caller's X-Request-ID or generates one, echoes it in
+// the response and attaches a logger carrying it, and the audit source, to
+// the request context
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := c.Request.Context()
+		l := logger.FromContext(ctx).With("request_id", requestID)
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			l = l.With("trace_id", sc.TraceID().String())
+		}
+		ctx = audit.WithSource(ctx, audit.Source{IP: c.ClientIP(), RequestID: requestID})
+		c.Request = c.Request.WithContext(logger.WithContext(ctx, l))
+
+		c.Next()
+	}
+}
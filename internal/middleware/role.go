@@ -3,21 +3,34 @@ package middleware
 import (
 	"net/http"
 
+	"github.com/google/uuid"
 	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RequireRole checks if the user has the required role
-func RequireRole(requiredRole model.UserRole) gin.HandlerFunc {
+// RequirePermission lets users through who hold permission, room scoped
+// permissions held for only some sites or buildings included, the handler
+// checks the room itself. Service accounts are authorized by their API key
+// scope instead. It must run after JWTAuthMiddleware or
+// APIKeyAuthMiddleware.
+func RequirePermission(roles repository.RoleRepository, permission model.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userRole, exists := c.Get("user_role")
-		if !exists {
+		if isServiceAccount(c) {
+			c.Next()
+			return
+		}
+		if _, exists := c.Get("user_id"); !exists {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "access denied"})
 			return
 		}
 
-		if userRole.(model.UserRole) != requiredRole {
+		grants, ok := loadGrants(c, roles)
+		if !ok {
+			return
+		}
+		if !grants.Has(permission) {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
 			return
 		}
@@ -25,3 +38,94 @@ func RequireRole(requiredRole model.UserRole) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// LoadPermissions puts the signed in user's grants in the context under
+// "permissions" for handlers that check them per room. Anonymous callers
+// and service accounts are passed through without any.
+func LoadPermissions(roles repository.RoleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("user_id"); exists && !isServiceAccount(c) {
+			if _, ok := loadGrants(c, roles); !ok {
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// loadGrants returns the grants of the signed in user, loading them once
+// per request. ok is false when the request was aborted.
+func loadGrants(c *gin.Context, roles repository.RoleRepository) (grants model.Grants, ok bool) {
+	if cached, exists := c.Get("permissions"); exists {
+		return cached.(model.Grants), true
+	}
+
+	role, _ := c.Get("user_role")
+	userID, _ := c.Get("user_id")
+	id, _ := uuid.Parse(userID.(string))
+
+	var assignments []model.RoleAssignment
+	if role != model.RoleAdmin {
+		var err error
+		assignments, err = roles.WithContext(c.Request.Context()).FindAssignments(id)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to load permissions"})
+			return nil, false
+		}
+	}
+
+	userRole, _ := role.(model.UserRole)
+	grants = model.GrantsFor(userRole, assignments)
+	c.Set("permissions", grants)
+	return grants, true
+}
+
+// RequireVerified rejects users who have not confirmed their email yet, it
+// must run after JWTAuthMiddleware
+func RequireVerified() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isServiceAccount(c) {
+			c.Next()
+			return
+		}
+		if status, _ := c.Get("user_status"); status != model.UserStatusActive {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "verify your email address before booking rooms"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAdminTwoFactor refuses users holding an administrative
+// permission, through the admin role or a custom one, without two-factor
+// authentication while the security.require_admin_2fa setting is on. It
+// must run after JWTAuthMiddleware.
+func RequireAdminTwoFactor(settings repository.SettingRepository, roles repository.RoleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if enabled, _ := c.Get("user_2fa"); enabled == true || isServiceAccount(c) {
+			c.Next()
+			return
+		}
+		grants, ok := loadGrants(c, roles)
+		if !ok {
+			return
+		}
+		if !grants.Administrative() {
+			c.Next()
+			return
+		}
+
+		required, err := settings.WithContext(c.Request.Context()).Get(model.SettingRequireAdmin2FA, "false")
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to load settings"})
+			return
+		}
+		if required == "true" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "two-factor authentication is required for administrative permissions, enroll at /api/me/2fa/setup"})
+			return
+		}
+
+		c.Next()
+	}
+}
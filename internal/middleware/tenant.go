@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+// ResolveTenant picks the organization a request acts for from its Host
+// header. Requests on a host no organization claims act for the default
+// organization until their token says otherwise, see switchTenant.
+func ResolveTenant(orgs repository.OrganizationRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		org, err := orgs.WithContext(ctx).FindByDomain(requestHost(c.Request))
+		switch {
+		case err == nil:
+			c.Set("organization_from_host", true)
+			setTenant(c, org.ID)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			setTenant(c, model.DefaultOrganizationID)
+		default:
+			logger.FromContext(ctx).Error("failed to resolve organization", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve organization"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestHost returns the host the request was sent to, lower case and
+// without the port
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// setTenant makes the request act for organization id
+func setTenant(c *gin.Context, id uuid.UUID) {
+	c.Set("organization_id", id)
+	c.Request = c.Request.WithContext(tenant.WithContext(c.Request.Context(), id))
+}
+
+// switchTenant moves the request to the organization its credentials
+// belong to. It refuses when the host already named another organization,
+// so a token of one organization is never accepted on another's domain.
+func switchTenant(c *gin.Context, id uuid.UUID) bool {
+	if c.GetBool("organization_from_host") && tenant.FromContext(c.Request.Context()) != id {
+		return false
+	}
+	setTenant(c, id)
+	return true
+}
@@ -0,0 +1,238 @@
+package model
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditAction is the kind of change an audit log entry records
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionCancel AuditAction = "cancel"
+	AuditActionDelete AuditAction = "delete"
+)
+
+// AuditEntity is the kind of record an audit log entry is about
+type AuditEntity string
+
+const (
+	AuditEntityUser    AuditEntity = "user"
+	AuditEntityRoom    AuditEntity = "room"
+	AuditEntityBooking AuditEntity = "booking"
+)
+
+// AuditActorType tells who made a change
+type AuditActorType string
+
+const (
+	AuditActorUser           AuditActorType = "user"
+	AuditActorServiceAccount AuditActorType = "service_account"
+	// AuditActorSystem made changes outside of a signed in request, such
+	// as sign ups, password resets and the command line tools
+	AuditActorSystem AuditActorType = "system"
+)
+
+// AuditChange is the value of one field before and after a change, From
+// is empty for created records and To for deleted ones
+type AuditChange struct {
+	From any `json:"from,omitempty"`
+	To   any `json:"to,omitempty"`
+}
+
+// AuditChanges maps the JSON name of each changed field to its change. It
+// is stored as a JSON document.
+type AuditChanges map[string]AuditChange
+
+// Value implements driver.Valuer
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		c = AuditChanges{}
+	}
+	b, err := json.Marshal(c)
+	return string(b), err
+}
+
+// Scan implements sql.Scanner
+func (c *AuditChanges) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case nil:
+		*c = nil
+		return nil
+	default:
+		return fmt.Errorf("scan audit changes from %T", src)
+	}
+	return json.Unmarshal(raw, c)
+}
+
+// auditIgnored fields change with every write and say nothing about it
+var auditIgnored = map[string]bool{"updated_at": true}
+
+// AuditRedacted stands in for the values of secret fields that changed
+const AuditRedacted = "changed"
+
+// auditSecrets is implemented by records with fields hidden from JSON,
+// such as password hashes, whose changes still belong in the audit log
+type auditSecrets interface {
+	// AuditSecrets maps a name for each hidden field to its value
+	AuditSecrets() map[string]any
+}
+
+// DiffAudit compares the JSON form of before and after, either may be nil
+// for created and deleted records. Fields hidden from JSON, such as
+// password hashes, never show their values; when both are given and one
+// of their AuditSecrets changed it shows up with AuditRedacted as its new
+// value. The fields in ignore never show up.
+func DiffAudit(before, after any, ignore ...string) (AuditChanges, error) {
+	from, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := func(field string) bool {
+		return auditIgnored[field] || slices.Contains(ignore, field)
+	}
+
+	changes := AuditChanges{}
+	for field, value := range from {
+		if !skip(field) && !reflect.DeepEqual(value, to[field]) {
+			changes[field] = AuditChange{From: value, To: to[field]}
+		}
+	}
+	for field, value := range to {
+		if _, seen := from[field]; !seen && !skip(field) {
+			changes[field] = AuditChange{To: value}
+		}
+	}
+
+	secretsFrom, secretsTo := auditSecretsOf(before), auditSecretsOf(after)
+	if secretsFrom == nil || secretsTo == nil {
+		return changes, nil
+	}
+	for field, value := range secretsTo {
+		if skip(field) {
+			continue
+		}
+		same, err := sameJSON(secretsFrom[field], value)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			changes[field] = AuditChange{To: AuditRedacted}
+		}
+	}
+	return changes, nil
+}
+
+// auditSecretsOf returns the secret fields of v, or nil when it has none
+func auditSecretsOf(v any) map[string]any {
+	if v == nil {
+		return nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil
+	}
+	if s, ok := v.(auditSecrets); ok {
+		return s.AuditSecrets()
+	}
+	return nil
+}
+
+// sameJSON reports whether a and b have the same JSON form, so times
+// compare by instant and pointers by what they point to
+func sameJSON(a, b any) (bool, error) {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	y, err := json.Marshal(b)
+	return bytes.Equal(x, y), err
+}
+
+// auditFields decodes the JSON form of v into its fields
+func auditFields(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	err = json.Unmarshal(b, &fields)
+	return fields, err
+}
+
+// AuditLog records one change to a user, room or booking. Entries are
+// only ever added, the database refuses to update or delete them.
+type AuditLog struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID      `json:"-" gorm:"type:uuid;not null;default:'00000000-0000-0000-0000-000000000001';index"`
+	ActorType      AuditActorType `json:"actor_type" gorm:"type:varchar(20);not null"`
+	// ActorID is the user or service account that made the change, empty
+	// for the system
+	ActorID    *uuid.UUID   `json:"actor_id,omitempty" gorm:"type:uuid;index"`
+	Action     AuditAction  `json:"action" gorm:"type:varchar(20);not null"`
+	EntityType AuditEntity  `json:"entity_type" gorm:"type:varchar(20);not null;index:idx_audit_logs_entity"`
+	EntityID   uuid.UUID    `json:"entity_id" gorm:"type:uuid;not null;index:idx_audit_logs_entity"`
+	Changes    AuditChanges `json:"changes" gorm:"type:text;not null"`
+	IP         string       `json:"ip,omitempty" gorm:"size:45;not null;default:''"`
+	RequestID  string       `json:"request_id,omitempty" gorm:"size:128;not null;default:''"`
+	CreatedAt  time.Time    `json:"created_at" gorm:"not null;index"`
+}
+
+// BeforeCreate is a hook that runs before creating an audit log entry. The
+// time is stored in UTC so ranges compare correctly on SQLite.
+func (l *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&l.ID)
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = time.Now()
+	}
+	l.CreatedAt = l.CreatedAt.UTC()
+	return nil
+}
+
+// AuditLogFilter narrows an audit log query, zero fields match everything
+type AuditLogFilter struct {
+	EntityType AuditEntity
+	EntityID   *uuid.UUID
+	ActorID    *uuid.UUID
+	// From and To bound the time of the change, From inclusive and To
+	// exclusive
+	From *time.Time
+	To   *time.Time
+	// Limit caps the number of entries, newest first
+	Limit int
+}
+
+// AuditLogQuery are the query parameters of the audit log API
+type AuditLogQuery struct {
+	EntityType AuditEntity `form:"entity_type" binding:"omitempty,oneof=user room booking"`
+	EntityID   string      `form:"entity_id" binding:"omitempty,uuid"`
+	ActorID    string      `form:"actor_id" binding:"omitempty,uuid"`
+	From       time.Time   `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time   `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit      int         `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
@@ -15,16 +15,27 @@ const (
 	BookingStatusCancelled BookingStatus = "cancelled"
 )
 
+// Booking reserves a room for its organizer, UserID. CreatedByID is the
+// user who made it, someone other than the organizer when a delegate or an
+// admin booked for them. It is empty for bookings made with an API key and
+// those made before it was recorded. CheckedInAt is set when someone
+// checks in, with the number of Attendees if they gave it; bookings that
+// end without a check-in are no-shows.
 type Booking struct {
-	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	RoomID    uuid.UUID      `json:"room_id" gorm:"type:uuid;not null"`
-	UserID    uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
-	StartTime time.Time      `json:"start_time" gorm:"not null"`
-	EndTime   time.Time      `json:"end_time" gorm:"not null"`
-	Status    BookingStatus  `json:"status" gorm:"type:varchar(20);not null;default:'active'"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID      `json:"-" gorm:"type:uuid;not null;default:'00000000-0000-0000-0000-000000000001';index"`
+	RoomID         uuid.UUID      `json:"room_id" gorm:"type:uuid;not null"`
+	UserID         uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
+	CreatedByID    *uuid.UUID     `json:"created_by_id,omitempty" gorm:"type:uuid;index"`
+	GroupID        *uuid.UUID     `json:"group_id,omitempty" gorm:"type:uuid;index"`
+	StartTime      time.Time      `json:"start_time" gorm:"not null"`
+	EndTime        time.Time      `json:"end_time" gorm:"not null"`
+	Status         BookingStatus  `json:"status" gorm:"type:varchar(20);not null;default:'active'"`
+	CheckedInAt    *time.Time     `json:"checked_in_at,omitempty"`
+	Attendees      *int           `json:"attendees,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
 	Room Room `json:"room" gorm:"foreignKey:RoomID"`
@@ -50,13 +61,26 @@ type UpdateBookingInput struct {
 	EndTime   *time.Time     `json:"end_time,omitempty"`
 }
 
+// CheckInOpensBefore is how long before its start a booking can be
+// checked in to
+const CheckInOpensBefore = 15 * time.Minute
+
+type CheckInInput struct {
+	// Attendees is how many people came, checking in again corrects it
+	Attendees *int `json:"attendees,omitempty" binding:"omitempty,min=0" example:"6"`
+}
+
 type BookingResponse struct {
 	ID        uuid.UUID     `json:"id"`
 	RoomID    uuid.UUID     `json:"room_id"`
 	UserID    uuid.UUID     `json:"user_id"`
+	CreatedBy *uuid.UUID    `json:"created_by_id,omitempty"`
+	GroupID   *uuid.UUID    `json:"group_id,omitempty"`
 	StartTime time.Time     `json:"start_time"`
 	EndTime   time.Time     `json:"end_time"`
 	Status    BookingStatus `json:"status"`
+	CheckedIn *time.Time    `json:"checked_in_at,omitempty"`
+	Attendees *int          `json:"attendees,omitempty"`
 	CreatedAt time.Time     `json:"created_at"`
 	UpdatedAt time.Time     `json:"updated_at"`
 
@@ -70,9 +94,13 @@ func (b *Booking) ToResponse() BookingResponse {
 		ID:        b.ID,
 		RoomID:    b.RoomID,
 		UserID:    b.UserID,
+		CreatedBy: b.CreatedByID,
+		GroupID:   b.GroupID,
 		StartTime: b.StartTime,
 		EndTime:   b.EndTime,
 		Status:    b.Status,
+		CheckedIn: b.CheckedInAt,
+		Attendees: b.Attendees,
 		CreatedAt: b.CreatedAt,
 		UpdatedAt: b.UpdatedAt,
 		Room:      b.Room,
@@ -82,10 +110,24 @@ func (b *Booking) ToResponse() BookingResponse {
 
 // BeforeCreate is a hook that runs before creating a booking
 func (b *Booking) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&b.ID)
 	b.Status = BookingStatusActive
 	return nil
 }
 
+// BeforeSave stores booking times in UTC so they compare correctly on
+// databases that keep timestamps as text, like SQLite
+func (b *Booking) BeforeSave(tx *gorm.DB) error {
+	b.StartTime = b.StartTime.UTC()
+	b.EndTime = b.EndTime.UTC()
+	return nil
+}
+
+// Overlaps reports whether the booking overlaps the given time range
+func (b *Booking) Overlaps(startTime, endTime time.Time) bool {
+	return b.StartTime.Before(endTime) && startTime.Before(b.EndTime)
+}
+
 // Validate checks if the booking time is valid
 func (b *Booking) Validate() error {
 	if b.StartTime.IsZero() || b.EndTime.IsZero() {
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BookingGroup links bookings that were created together in a single batch
+// so they can be looked up and cancelled as one unit.
+type BookingGroup struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID      `json:"-" gorm:"type:uuid;not null;default:'00000000-0000-0000-0000-000000000001';index"`
+	UserID         uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
+	Title          string         `json:"title"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Relationships
+	User     User      `json:"user" gorm:"foreignKey:UserID"`
+	Bookings []Booking `json:"bookings" gorm:"foreignKey:GroupID"`
+}
+
+// BeforeCreate is a hook that runs before creating a booking group
+func (g *BookingGroup) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&g.ID)
+	return nil
+}
+
+type BatchBookingItem struct {
+	RoomID    uuid.UUID `json:"room_id" binding:"required"`
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required"`
+}
+
+// CreateBatchBookingInput books for UserID when it is set, which takes a
+// delegation from them or bookings.manage_any for the rooms. The caller is
+// the organizer otherwise.
+type CreateBatchBookingInput struct {
+	Title    string             `json:"title" example:"Offsite 2025"`
+	UserID   *uuid.UUID         `json:"user_id,omitempty"`
+	Bookings []BatchBookingItem `json:"bookings" binding:"required,min=1,dive"`
+}
+
+// BatchBookingConflict reports why a single item of a batch could not be booked.
+// Index refers to the position of the item in the request.
+type BatchBookingConflict struct {
+	Index     int       `json:"index"`
+	RoomID    uuid.UUID `json:"room_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Error     string    `json:"error"`
+}
+
+type BookingGroupResponse struct {
+	ID        uuid.UUID         `json:"id"`
+	UserID    uuid.UUID         `json:"user_id"`
+	Title     string            `json:"title"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Bookings  []BookingResponse `json:"bookings"`
+}
+
+// ToResponse converts a BookingGroup to a BookingGroupResponse
+func (g *BookingGroup) ToResponse() BookingGroupResponse {
+	bookings := make([]BookingResponse, len(g.Bookings))
+	for i, b := range g.Bookings {
+		bookings[i] = b.ToResponse()
+	}
+
+	return BookingGroupResponse{
+		ID:        g.ID,
+		UserID:    g.UserID,
+		Title:     g.Title,
+		CreatedAt: g.CreatedAt,
+		UpdatedAt: g.UpdatedAt,
+		Bookings:  bookings,
+	}
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Delegation lets the delegate, such as an assistant, create, change and
+// cancel bookings on behalf of the principal
+type Delegation struct {
+	PrincipalID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	DelegateID  uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	CreatedAt   time.Time
+
+	Principal User `gorm:"constraint:OnDelete:CASCADE"`
+	Delegate  User `gorm:"constraint:OnDelete:CASCADE"`
+}
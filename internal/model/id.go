@@ -0,0 +1,11 @@
+package model
+
+import "github.com/google/uuid"
+
+// ensureID assigns a random UUID when none was set, so primary keys don't
+// depend on database specific defaults like uuid_generate_v4()
+func ensureID(id *uuid.UUID) {
+	if *id == uuid.Nil {
+		*id = uuid.New()
+	}
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Invitation lets the owner of Email register with Role. It expires and
+// works once, only the SHA-256 hash of its token is stored.
+type Invitation struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID  `json:"-" gorm:"type:uuid;not null;default:'00000000-0000-0000-0000-000000000001';index"`
+	Email          string     `json:"email" gorm:"not null;index"`
+	Role           UserRole   `json:"role" gorm:"type:varchar(20);not null"`
+	TokenHash      string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	InvitedByID    *uuid.UUID `json:"invited_by_id,omitempty" gorm:"type:uuid"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"not null"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	InvitedBy *User `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+}
+
+// BeforeCreate is a hook that runs before creating an invitation
+func (i *Invitation) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&i.ID)
+	return nil
+}
+
+// BeforeSave stores the expiry in UTC so it compares correctly on SQLite
+func (i *Invitation) BeforeSave(tx *gorm.DB) error {
+	i.ExpiresAt = i.ExpiresAt.UTC()
+	return nil
+}
+
+// Pending reports whether the invitation can still be accepted at now
+func (i *Invitation) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
+
+type CreateInvitationInput struct {
+	Email string   `json:"email" binding:"required,email" example:"new.admin@example.com"`
+	Role  UserRole `json:"role" binding:"required,oneof=user admin" example:"admin"`
+}
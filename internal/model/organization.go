@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultOrganizationID is the organization requests belong to when no
+// other one is resolved, and the one rows created before organizations
+// existed were moved to. The organization_id column defaults use the same
+// value.
+var DefaultOrganizationID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// Name and slug the default organization is created with
+const (
+	DefaultOrganizationName = "Default"
+	DefaultOrganizationSlug = "default"
+)
+
+// Organization is a tenant. Users, rooms, bookings and everything admins
+// manage belong to exactly one organization and are never visible from
+// another.
+type Organization struct {
+	ID   uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	Name string    `json:"name" gorm:"size:100;not null"`
+	Slug string    `json:"slug" gorm:"size:50;not null;uniqueIndex"`
+	// Domain is the host requests for the organization arrive on, such as
+	// acme.meetbook.example. Requests on any other host use the
+	// organization of their token, or the default one.
+	Domain    *string   `json:"domain,omitempty" gorm:"size:255;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate is a hook that runs before creating an organization
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&o.ID)
+	return nil
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// RateLimitCounter is a fixed window request counter used by the database
+// backed rate limiter store, shared by every replica
+type RateLimitCounter struct {
+	Bucket       string    `gorm:"primaryKey;size:255"`
+	Count        int       `gorm:"not null"`
+	WindowEndsAt time.Time `gorm:"not null;index"`
+}
+
+// LoginLockout tracks consecutive failed logins for an account and how
+// long it is locked out
+type LoginLockout struct {
+	Account     string    `gorm:"primaryKey;size:255"`
+	Failures    int       `gorm:"not null"`
+	LockedUntil time.Time `gorm:"index"`
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,327 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SettingOpeningHours holds the organization's OpeningHours as JSON
+const SettingOpeningHours = "rooms.opening_hours"
+
+// OpeningHours are when rooms are meant to be in use, utilization is the
+// booked share of them. They apply to every room of the organization.
+type OpeningHours struct {
+	Opens  string `json:"opens" binding:"required,datetime=15:04" example:"08:00"`
+	Closes string `json:"closes" binding:"required,datetime=15:04" example:"18:00"`
+	// Days are the weekdays rooms are open, 0 is Sunday
+	Days []time.Weekday `json:"days" binding:"dive,min=0,max=6" swaggertype:"array,integer" example:"1,2,3,4,5"`
+	// Timezone is the IANA zone of the opening hours, reports count days,
+	// weekdays and hours of day in it
+	Timezone string `json:"timezone" binding:"required,timezone" example:"Asia/Jakarta"`
+}
+
+// DefaultOpeningHours apply until an organization sets its own
+var DefaultOpeningHours = OpeningHours{
+	Opens:    "08:00",
+	Closes:   "18:00",
+	Days:     []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	Timezone: "UTC",
+}
+
+// Validate checks that rooms close after they open
+func (h OpeningHours) Validate() error {
+	opens, err := time.Parse("15:04", h.Opens)
+	if err != nil {
+		return fmt.Errorf("invalid opening time %q", h.Opens)
+	}
+	closes, err := time.Parse("15:04", h.Closes)
+	if err != nil {
+		return fmt.Errorf("invalid closing time %q", h.Closes)
+	}
+	if !closes.After(opens) {
+		return errors.New("closing time must be after opening time")
+	}
+	return nil
+}
+
+// Location returns the timezone of the opening hours, UTC when it is unknown
+func (h OpeningHours) Location() *time.Location {
+	loc, err := time.LoadLocation(h.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// on returns when rooms open and close on the day starting at midnight,
+// ok is false on days they stay closed
+func (h OpeningHours) on(midnight time.Time) (opens, closes time.Time, ok bool) {
+	open := false
+	for _, d := range h.Days {
+		open = open || d == midnight.Weekday()
+	}
+	o, err1 := time.Parse("15:04", h.Opens)
+	c, err2 := time.Parse("15:04", h.Closes)
+	if !open || err1 != nil || err2 != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	y, m, d := midnight.Date()
+	opens = time.Date(y, m, d, o.Hour(), o.Minute(), 0, 0, midnight.Location())
+	closes = time.Date(y, m, d, c.Hour(), c.Minute(), 0, 0, midnight.Location())
+	return opens, closes, true
+}
+
+// ReportGrouping is what a utilization report sums bookings by
+type ReportGrouping string
+
+const (
+	GroupByRoom     ReportGrouping = "room"
+	GroupByFloor    ReportGrouping = "floor"
+	GroupByBuilding ReportGrouping = "building"
+	GroupByWeekday  ReportGrouping = "weekday"
+	GroupByHour     ReportGrouping = "hour"
+)
+
+// UtilizationQuery are the query parameters of the utilization report
+type UtilizationQuery struct {
+	// From and To are the first and last day of the report, in the
+	// timezone of the opening hours
+	From    string         `form:"from" binding:"required,datetime=2006-01-02"`
+	To      string         `form:"to" binding:"required,datetime=2006-01-02"`
+	GroupBy ReportGrouping `form:"group_by" binding:"omitempty,oneof=room floor building weekday hour"`
+	Format  string         `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+// UtilizationFilter selects the bookings a utilization report covers, those
+// starting from From until before To, and how to group them
+type UtilizationFilter struct {
+	From    time.Time
+	To      time.Time
+	GroupBy ReportGrouping
+	// Location is where weekdays and hours of day are counted
+	Location *time.Location
+}
+
+// UtilizationAggregate sums up the bookings of one group. Only the fields
+// of the grouping are set: RoomID, Building and Floor, Building, Weekday or
+// Hour. Grouped by hour, a booking counts in every hour of day it overlaps
+// and only its time within that hour is booked.
+type UtilizationAggregate struct {
+	RoomID   uuid.UUID
+	Building string
+	Floor    string
+	Weekday  int
+	Hour     int
+
+	Bookings  int64
+	Cancelled int64
+	// Held bookings have ended without being cancelled, NoShows are those
+	// of them nobody checked in to
+	Held    int64
+	NoShows int64
+	// BookedSeconds is the time held or upcoming bookings take up
+	BookedSeconds float64
+	// LeadSeconds is the total time between making and starting bookings
+	LeadSeconds float64
+	// Attended bookings were checked in to with a number of Attendees,
+	// CapacityShare sums each one's attendees over its room's capacity
+	Attended      int64
+	Attendees     int64
+	CapacityShare float64
+}
+
+// UtilizationRow is one group of a utilization report. Rates and
+// utilization are percentages, averages are nil without bookings to
+// average.
+type UtilizationRow struct {
+	Key              string   `json:"key" example:"Tower A/12"`
+	Label            string   `json:"label" example:"Tower A, floor 12"`
+	Rooms            int      `json:"rooms" example:"4"`
+	Bookings         int64    `json:"bookings" example:"120"`
+	BookedHours      float64  `json:"booked_hours" example:"310.5"`
+	OpenHours        float64  `json:"open_hours" example:"880"`
+	Utilization      float64  `json:"utilization" example:"35.28"`
+	CancellationRate float64  `json:"cancellation_rate" example:"8.33"`
+	NoShowRate       float64  `json:"no_show_rate" example:"4.5"`
+	AvgLeadTimeHours *float64 `json:"avg_lead_time_hours" example:"26.4"`
+	AvgAttendance    *float64 `json:"avg_attendance" example:"5.2"`
+	AttendanceRate   *float64 `json:"attendance_rate" example:"61.7"`
+}
+
+type UtilizationReport struct {
+	From     string           `json:"from" example:"2025-01-01"`
+	To       string           `json:"to" example:"2025-03-31"`
+	GroupBy  ReportGrouping   `json:"group_by" example:"floor"`
+	Timezone string           `json:"timezone" example:"Asia/Jakarta"`
+	Rows     []UtilizationRow `json:"rows"`
+}
+
+// BuildUtilizationRows turns aggregates into report rows. Every group of
+// rooms, weekday or hour of day gets a row, with or without bookings, so
+// unused rooms show up. Open hours are those of rooms in the group, for
+// weekdays and hours of day those of every room.
+func BuildUtilizationRows(filter UtilizationFilter, hours OpeningHours, rooms []Room, aggregates []UtilizationAggregate) []UtilizationRow {
+	loc := filter.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	// open is the opening time in the report's range, in total and per
+	// weekday and hour of day, for a single room
+	var open time.Duration
+	var openByWeekday [7]time.Duration
+	var openByHour [24]time.Duration
+	for day := filter.From.In(loc); day.Before(filter.To); day = day.AddDate(0, 0, 1) {
+		opens, closes, ok := hours.on(day)
+		if !ok {
+			continue
+		}
+		open += closes.Sub(opens)
+		openByWeekday[day.Weekday()] += closes.Sub(opens)
+		for h := 0; h < 24; h++ {
+			start := time.Date(day.Year(), day.Month(), day.Day(), h, 0, 0, 0, loc)
+			openByHour[h] += overlap(start, start.Add(time.Hour), opens, closes)
+		}
+	}
+
+	type group struct {
+		row UtilizationRow
+		agg UtilizationAggregate
+	}
+	var groups []*group
+	byKey := make(map[string]*group)
+	add := func(key, label string) *group {
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{row: UtilizationRow{Key: key, Label: label}}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		return g
+	}
+
+	switch filter.GroupBy {
+	case GroupByWeekday:
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			g := add(strconv.Itoa(int(d)), d.String())
+			g.row.Rooms = len(rooms)
+			g.row.OpenHours = openByWeekday[d].Hours() * float64(len(rooms))
+		}
+	case GroupByHour:
+		for h := 0; h < 24; h++ {
+			g := add(strconv.Itoa(h), fmt.Sprintf("%02d:00", h))
+			g.row.Rooms = len(rooms)
+			g.row.OpenHours = openByHour[h].Hours() * float64(len(rooms))
+		}
+	default:
+		for _, room := range rooms {
+			key, label := roomGroup(filter.GroupBy, room.ID, room.Name, room.Building, room.Floor)
+			g := add(key, label)
+			g.row.Rooms++
+			g.row.OpenHours += open.Hours()
+		}
+	}
+
+	for _, a := range aggregates {
+		var key string
+		switch filter.GroupBy {
+		case GroupByWeekday:
+			key = strconv.Itoa(a.Weekday)
+		case GroupByHour:
+			key = strconv.Itoa(a.Hour)
+		default:
+			key, _ = roomGroup(filter.GroupBy, a.RoomID, "", a.Building, a.Floor)
+		}
+		g, ok := byKey[key]
+		if !ok {
+			continue
+		}
+		g.agg.Bookings += a.Bookings
+		g.agg.Cancelled += a.Cancelled
+		g.agg.Held += a.Held
+		g.agg.NoShows += a.NoShows
+		g.agg.BookedSeconds += a.BookedSeconds
+		g.agg.LeadSeconds += a.LeadSeconds
+		g.agg.Attended += a.Attended
+		g.agg.Attendees += a.Attendees
+		g.agg.CapacityShare += a.CapacityShare
+	}
+
+	rows := make([]UtilizationRow, len(groups))
+	for i, g := range groups {
+		row, a := g.row, g.agg
+		row.Bookings = a.Bookings
+		row.Utilization = percent(a.BookedSeconds/3600, row.OpenHours)
+		row.BookedHours = round2(a.BookedSeconds / 3600)
+		row.OpenHours = round2(row.OpenHours)
+		row.CancellationRate = percent(float64(a.Cancelled), float64(a.Bookings))
+		row.NoShowRate = percent(float64(a.NoShows), float64(a.Held))
+		if a.Bookings > 0 {
+			row.AvgLeadTimeHours = ptr(round2(a.LeadSeconds / 3600 / float64(a.Bookings)))
+		}
+		if a.Attended > 0 {
+			row.AvgAttendance = ptr(round2(float64(a.Attendees) / float64(a.Attended)))
+			row.AttendanceRate = ptr(percent(a.CapacityShare, float64(a.Attended)))
+		}
+		rows[i] = row
+	}
+	if filter.GroupBy != GroupByWeekday && filter.GroupBy != GroupByHour {
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].Label < rows[j].Label })
+	}
+	return rows
+}
+
+// roomGroup returns the key and label of the group a room falls in
+func roomGroup(by ReportGrouping, id uuid.UUID, name, building, floor string) (key, label string) {
+	if building == "" {
+		building = "No building"
+	}
+	switch by {
+	case GroupByBuilding:
+		return building, building
+	case GroupByFloor:
+		if floor == "" {
+			return building + "/", building + ", no floor"
+		}
+		return building + "/" + floor, building + ", floor " + floor
+	default:
+		return id.String(), name
+	}
+}
+
+// overlap returns how long [aStart, aEnd) and [bStart, bEnd) overlap
+func overlap(aStart, aEnd, bStart, bEnd time.Time) time.Duration {
+	start, end := aStart, aEnd
+	if bStart.After(start) {
+		start = bStart
+	}
+	if bEnd.Before(end) {
+		end = bEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// percent returns part of whole as a percentage, 0 when whole is
+func percent(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return round2(part / whole * 100)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,203 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBuildUtilizationRows(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Monday 5 January 2026 to Sunday 11 January, five working days
+	week := UtilizationFilter{
+		From:     time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC),
+		Location: time.UTC,
+	}
+	// Monday 5 January in Jakarta, which is still Sunday in UTC
+	jakartaMonday := UtilizationFilter{
+		From:     time.Date(2026, 1, 5, 0, 0, 0, 0, jakarta),
+		To:       time.Date(2026, 1, 6, 0, 0, 0, 0, jakarta),
+		Location: jakarta,
+	}
+	jakartaHours := DefaultOpeningHours
+	jakartaHours.Timezone = "Asia/Jakarta"
+	partialHours := DefaultOpeningHours
+	partialHours.Opens, partialHours.Closes = "08:30", "17:15"
+
+	orchid := Room{ID: uuid.MustParse("00000000-0000-0000-0000-00000000000a"), Name: "Orchid", Building: "Tower A", Floor: "12"}
+	lotus := Room{ID: uuid.MustParse("00000000-0000-0000-0000-00000000000b"), Name: "Lotus", Building: "Tower A", Floor: "12"}
+	annex := Room{ID: uuid.MustParse("00000000-0000-0000-0000-00000000000c"), Name: "Annex"}
+	rooms := []Room{orchid, lotus, annex}
+
+	byKey := func(rows []UtilizationRow) map[string]UtilizationRow {
+		m := make(map[string]UtilizationRow, len(rows))
+		for _, r := range rows {
+			m[r.Key] = r
+		}
+		return m
+	}
+
+	cases := []struct {
+		name       string
+		filter     UtilizationFilter
+		groupBy    ReportGrouping
+		hours      OpeningHours
+		aggregates []UtilizationAggregate
+		// labels is the order of the rows, when set
+		labels []string
+		want   map[string]UtilizationRow
+	}{
+		{
+			name:    "RoomRates",
+			filter:  week,
+			groupBy: GroupByRoom,
+			hours:   DefaultOpeningHours,
+			aggregates: []UtilizationAggregate{{
+				RoomID: orchid.ID, Bookings: 4, Cancelled: 1, Held: 2, NoShows: 1,
+				BookedSeconds: 10 * 3600, LeadSeconds: 4 * 24 * 3600,
+				Attended: 1, Attendees: 6, CapacityShare: 0.75,
+			}},
+			labels: []string{"Annex", "Lotus", "Orchid"},
+			want: map[string]UtilizationRow{
+				orchid.ID.String(): {
+					Key: orchid.ID.String(), Label: "Orchid", Rooms: 1, Bookings: 4,
+					BookedHours: 10, OpenHours: 50, Utilization: 20, CancellationRate: 25, NoShowRate: 50,
+					AvgLeadTimeHours: ptr(24.0), AvgAttendance: ptr(6.0), AttendanceRate: ptr(75.0),
+				},
+				// unused rooms show up at 0% without averages
+				lotus.ID.String(): {Key: lotus.ID.String(), Label: "Lotus", Rooms: 1, OpenHours: 50},
+			},
+		},
+		{
+			name:    "Floors",
+			filter:  week,
+			groupBy: GroupByFloor,
+			hours:   DefaultOpeningHours,
+			aggregates: []UtilizationAggregate{
+				{Building: "Tower A", Floor: "12", Bookings: 2, Held: 2, BookedSeconds: 25 * 3600},
+				// rooms deleted since, or on floors no room is on any more
+				{Building: "Tower B", Floor: "1", Bookings: 1, BookedSeconds: 3600},
+			},
+			labels: []string{"No building, no floor", "Tower A, floor 12"},
+			want: map[string]UtilizationRow{
+				"Tower A/12":   {Key: "Tower A/12", Label: "Tower A, floor 12", Rooms: 2, Bookings: 2, BookedHours: 25, OpenHours: 100, Utilization: 25, AvgLeadTimeHours: ptr(0.0)},
+				"No building/": {Key: "No building/", Label: "No building, no floor", Rooms: 1, OpenHours: 50},
+			},
+		},
+		{
+			name:    "WeekdaysInTimezone",
+			filter:  jakartaMonday,
+			groupBy: GroupByWeekday,
+			hours:   jakartaHours,
+			aggregates: []UtilizationAggregate{
+				{Weekday: 1, Bookings: 3, Held: 3, NoShows: 1, BookedSeconds: 6 * 3600},
+			},
+			want: map[string]UtilizationRow{
+				"0": {Key: "0", Label: "Sunday", Rooms: 3},
+				"1": {Key: "1", Label: "Monday", Rooms: 3, Bookings: 3, BookedHours: 6, OpenHours: 30, Utilization: 20, NoShowRate: 33.33, AvgLeadTimeHours: ptr(0.0)},
+				"2": {Key: "2", Label: "Tuesday", Rooms: 3},
+			},
+		},
+		{
+			name:    "HoursInTimezone",
+			filter:  jakartaMonday,
+			groupBy: GroupByHour,
+			hours:   jakartaHours,
+			aggregates: []UtilizationAggregate{
+				{Hour: 9, Bookings: 1, BookedSeconds: 1.5 * 3600},
+			},
+			want: map[string]UtilizationRow{
+				"7":  {Key: "7", Label: "07:00", Rooms: 3},
+				"8":  {Key: "8", Label: "08:00", Rooms: 3, OpenHours: 3},
+				"9":  {Key: "9", Label: "09:00", Rooms: 3, Bookings: 1, BookedHours: 1.5, OpenHours: 3, Utilization: 50, AvgLeadTimeHours: ptr(0.0)},
+				"17": {Key: "17", Label: "17:00", Rooms: 3, OpenHours: 3},
+				"18": {Key: "18", Label: "18:00", Rooms: 3},
+			},
+		},
+		{
+			name:    "PartialHours",
+			filter:  week,
+			groupBy: GroupByHour,
+			hours:   partialHours,
+			want: map[string]UtilizationRow{
+				"8":  {Key: "8", Label: "08:00", Rooms: 3, OpenHours: 7.5},
+				"12": {Key: "12", Label: "12:00", Rooms: 3, OpenHours: 15},
+				"17": {Key: "17", Label: "17:00", Rooms: 3, OpenHours: 3.75},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := tc.filter
+			filter.GroupBy = tc.groupBy
+			rows := BuildUtilizationRows(filter, tc.hours, rooms, tc.aggregates)
+
+			switch tc.groupBy {
+			case GroupByWeekday:
+				if len(rows) != 7 {
+					t.Fatalf("got %d rows, want one per weekday", len(rows))
+				}
+			case GroupByHour:
+				if len(rows) != 24 {
+					t.Fatalf("got %d rows, want one per hour", len(rows))
+				}
+			}
+			if tc.labels != nil {
+				labels := make([]string, len(rows))
+				for i, r := range rows {
+					labels[i] = r.Label
+				}
+				if !reflect.DeepEqual(labels, tc.labels) {
+					t.Fatalf("labels = %v, want %v", labels, tc.labels)
+				}
+			}
+
+			got := byKey(rows)
+			for key, want := range tc.want {
+				if row, ok := got[key]; !ok {
+					t.Errorf("no row %q", key)
+				} else if !reflect.DeepEqual(row, want) {
+					t.Errorf("row %q = %+v, want %+v", key, describe(row), describe(want))
+				}
+			}
+		})
+	}
+}
+
+// describe shows the averages of a row rather than their addresses
+func describe(r UtilizationRow) map[string]any {
+	deref := func(v *float64) any {
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	return map[string]any{
+		"row": r, "avg_lead_time_hours": deref(r.AvgLeadTimeHours),
+		"avg_attendance": deref(r.AvgAttendance), "attendance_rate": deref(r.AttendanceRate),
+	}
+}
+
+func TestOpeningHoursOn(t *testing.T) {
+	hours := OpeningHours{Opens: "07:30", Closes: "19:00", Days: []time.Weekday{time.Saturday}, Timezone: "Asia/Jakarta"}
+	loc := hours.Location()
+
+	saturday := time.Date(2026, 1, 10, 0, 0, 0, 0, loc)
+	opens, closes, ok := hours.on(saturday)
+	if !ok || !opens.Equal(time.Date(2026, 1, 10, 7, 30, 0, 0, loc)) || !closes.Equal(time.Date(2026, 1, 10, 19, 0, 0, 0, loc)) {
+		t.Fatalf("on(Saturday) = %v, %v, %v", opens, closes, ok)
+	}
+	if opens.UTC().Hour() != 0 || opens.UTC().Minute() != 30 {
+		t.Fatalf("opens at %v UTC, want 00:30", opens.UTC())
+	}
+	if _, _, ok := hours.on(saturday.AddDate(0, 0, 1)); ok {
+		t.Fatal("open on Sunday")
+	}
+}
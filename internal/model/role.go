@@ -0,0 +1,222 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Permission allows an action on a kind of resource
+type Permission string
+
+const (
+	PermUsersRead            Permission = "users.read"
+	PermUsersWrite           Permission = "users.write"
+	PermGroupsRead           Permission = "groups.read"
+	PermGroupsWrite          Permission = "groups.write"
+	PermRolesWrite           Permission = "roles.write"
+	PermInvitationsWrite     Permission = "invitations.write"
+	PermServiceAccountsWrite Permission = "service_accounts.write"
+	PermSettingsWrite        Permission = "settings.write"
+	PermAuditRead            Permission = "audit.read"
+	PermReportsRead          Permission = "reports.read"
+	// PermRoomsWrite creates, edits and deletes rooms and their access lists
+	PermRoomsWrite Permission = "rooms.write"
+	// PermRoomsAccessAll sees and books restricted rooms without being on
+	// their access list
+	PermRoomsAccessAll Permission = "rooms.access_all"
+	// PermBookingsManageAny creates, changes and cancels other users'
+	// bookings
+	PermBookingsManageAny Permission = "bookings.manage_any"
+)
+
+// Permissions lists every permission a role can be granted
+var Permissions = []Permission{
+	PermUsersRead, PermUsersWrite, PermGroupsRead, PermGroupsWrite, PermRolesWrite,
+	PermInvitationsWrite, PermServiceAccountsWrite, PermSettingsWrite, PermAuditRead, PermReportsRead,
+	PermRoomsWrite, PermRoomsAccessAll, PermBookingsManageAny,
+}
+
+// Valid reports whether p is a known permission
+func (p Permission) Valid() bool {
+	return slices.Contains(Permissions, p)
+}
+
+// RoomScoped reports whether p acts on rooms, only those permissions are
+// granted by roles assigned for a site or building
+func (p Permission) RoomScoped() bool {
+	return p == PermRoomsWrite || p == PermRoomsAccessAll || p == PermBookingsManageAny
+}
+
+// Administrative reports whether p manages other users or the
+// organization, holding one falls under the admin two-factor requirement.
+// Only rooms.access_all, which lets its holder book restricted rooms for
+// themselves, does not.
+func (p Permission) Administrative() bool {
+	return p.Valid() && p != PermRoomsAccessAll
+}
+
+// PermissionList is stored as a space separated string, like ScopeList
+type PermissionList []Permission
+
+// Value implements driver.Valuer
+func (l PermissionList) Value() (driver.Value, error) {
+	parts := make([]string, len(l))
+	for i, p := range l {
+		parts[i] = string(p)
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// Scan implements sql.Scanner
+func (l *PermissionList) Scan(src any) error {
+	var raw string
+	switch v := src.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("scan permission list from %T", src)
+	}
+
+	*l = nil
+	for _, p := range strings.Fields(raw) {
+		*l = append(*l, Permission(p))
+	}
+	return nil
+}
+
+// Role is a set of permissions defined by admins, on top of the built-in
+// admin and user roles
+type Role struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID      `json:"-" gorm:"type:uuid;not null;default:'00000000-0000-0000-0000-000000000001';uniqueIndex:idx_roles_org_name,priority:1"`
+	Name           string         `json:"name" gorm:"size:50;not null;uniqueIndex:idx_roles_org_name"`
+	Description    string         `json:"description"`
+	Permissions    PermissionList `json:"permissions" gorm:"type:text;not null"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// BeforeCreate is a hook that runs before creating a role
+func (r *Role) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&r.ID)
+	return nil
+}
+
+// BuiltInRole reports whether name is one of the roles users always have,
+// custom roles cannot take those names
+func BuiltInRole(name string) bool {
+	return name == string(RoleUser) || name == string(RoleAdmin)
+}
+
+// RoleAssignment gives a user a custom role. A role assigned with a Site or
+// Building only grants its room permissions, and only for the rooms there.
+type RoleAssignment struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	RoleID    uuid.UUID `json:"role_id" gorm:"type:uuid;not null;index"`
+	Site      string    `json:"site,omitempty" gorm:"size:100;not null;default:''"`
+	Building  string    `json:"building,omitempty" gorm:"size:100;not null;default:''"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Role Role `json:"role" gorm:"constraint:OnDelete:CASCADE"`
+	User User `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate is a hook that runs before creating a role assignment
+func (a *RoleAssignment) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&a.ID)
+	return nil
+}
+
+// Scoped reports whether the assignment is limited to a site or building
+func (a *RoleAssignment) Scoped() bool {
+	return a.Site != "" || a.Building != ""
+}
+
+// Grant is a permission a user holds, limited to the rooms at Site and
+// Building when those are set
+type Grant struct {
+	Permission Permission `json:"permission"`
+	Site       string     `json:"site,omitempty"`
+	Building   string     `json:"building,omitempty"`
+}
+
+// Grants are everything a user may do
+type Grants []Grant
+
+// GrantsFor returns the permissions of a user's built-in role and of the
+// custom roles assigned to them
+func GrantsFor(role UserRole, assignments []RoleAssignment) Grants {
+	var grants Grants
+	if role == RoleAdmin {
+		for _, p := range Permissions {
+			grants = append(grants, Grant{Permission: p})
+		}
+		return grants
+	}
+
+	for _, a := range assignments {
+		for _, p := range a.Role.Permissions {
+			if a.Scoped() && !p.RoomScoped() {
+				continue
+			}
+			grants = append(grants, Grant{Permission: p, Site: a.Site, Building: a.Building})
+		}
+	}
+	return grants
+}
+
+// Has reports whether p is granted anywhere
+func (g Grants) Has(p Permission) bool {
+	return slices.ContainsFunc(g, func(grant Grant) bool { return grant.Permission == p })
+}
+
+// Covers reports whether p is granted for every room at site and building,
+// empty values meaning any
+func (g Grants) Covers(p Permission, site, building string) bool {
+	return slices.ContainsFunc(g, func(grant Grant) bool {
+		return grant.Permission == p &&
+			(grant.Site == "" || grant.Site == site) &&
+			(grant.Building == "" || grant.Building == building)
+	})
+}
+
+// Administrative reports whether any administrative permission is granted
+func (g Grants) Administrative() bool {
+	return slices.ContainsFunc(g, func(grant Grant) bool { return grant.Permission.Administrative() })
+}
+
+// Allows reports whether p is granted for room
+func (g Grants) Allows(p Permission, room *Room) bool {
+	return g.Covers(p, room.Site, room.Building)
+}
+
+type CreateRoleInput struct {
+	Name        string       `json:"name" binding:"required,max=50" example:"receptionist"`
+	Description string       `json:"description" example:"Front desk staff"`
+	Permissions []Permission `json:"permissions" binding:"required" example:"bookings.manage_any,users.read"`
+}
+
+// UpdateRoleInput changes the fields that are set and leaves the others
+type UpdateRoleInput struct {
+	Name        *string       `json:"name" binding:"omitempty,min=1,max=50" example:"receptionist"`
+	Description *string       `json:"description" example:"Front desk staff"`
+	Permissions *[]Permission `json:"permissions" example:"bookings.manage_any,users.read"`
+}
+
+type AssignRoleInput struct {
+	RoleID uuid.UUID `json:"role_id" binding:"required"`
+	// Site and Building limit the role to the rooms there, leave both out
+	// to grant it everywhere
+	Site     string `json:"site" binding:"max=100" example:"Jakarta"`
+	Building string `json:"building" binding:"max=100" example:"Tower A"`
+}
@@ -8,22 +8,45 @@ import (
 )
 
 type Room struct {
-	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Name      string         `json:"name"`
-	Capacity  int            `json:"capacity"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID `json:"-" gorm:"type:uuid;not null;default:'00000000-0000-0000-0000-000000000001';index"`
+	Name           string    `json:"name"`
+	Capacity       int       `json:"capacity"`
+	// Site, Building and Floor locate the room, roles can be assigned for
+	// a site or building
+	Site     string `json:"site" gorm:"size:100;not null;default:''"`
+	Building string `json:"building" gorm:"size:100;not null;default:''"`
+	Floor    string `json:"floor" gorm:"size:50;not null;default:''"`
+	// Restricted rooms can only be seen and booked by admins and the
+	// members of the groups on their access list
+	Restricted bool           `json:"restricted" gorm:"not null;default:false"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// BeforeCreate is a hook that runs before creating a room
+func (r *Room) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&r.ID)
+	return nil
 }
 
 type CreateRoomInput struct {
-	Name     string `json:"name" binding:"required" example:"Meeting Room 1"`
-	Capacity int    `json:"capacity" binding:"required" example:"10"`
+	Name       string `json:"name" binding:"required" example:"Meeting Room 1"`
+	Capacity   int    `json:"capacity" binding:"required" example:"10"`
+	Site       string `json:"site" binding:"max=100" example:"Jakarta"`
+	Building   string `json:"building" binding:"max=100" example:"Tower A"`
+	Floor      string `json:"floor" binding:"max=50" example:"12"`
+	Restricted bool   `json:"restricted" example:"false"`
 }
 
 type UpdateRoomInput struct {
-	Name     string `json:"name" binding:"required" example:"Meeting Room 1"`
-	Capacity int    `json:"capacity" binding:"required" example:"10"`
+	Name       string `json:"name" binding:"required" example:"Meeting Room 1"`
+	Capacity   int    `json:"capacity" binding:"required" example:"10"`
+	Site       string `json:"site" binding:"max=100" example:"Jakarta"`
+	Building   string `json:"building" binding:"max=100" example:"Tower A"`
+	Floor      string `json:"floor" binding:"max=50" example:"12"`
+	Restricted bool   `json:"restricted" example:"false"`
 }
 
 type RoomResponse struct {
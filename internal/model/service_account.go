@@ -0,0 +1,144 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIScope grants an API key access to a group of routes
+type APIScope string
+
+const (
+	ScopeRoomsRead     APIScope = "rooms:read"
+	ScopeRoomsWrite    APIScope = "rooms:write"
+	ScopeBookingsRead  APIScope = "bookings:read"
+	ScopeBookingsWrite APIScope = "bookings:write"
+)
+
+// APIScopes lists every scope a key can be granted
+var APIScopes = []APIScope{ScopeRoomsRead, ScopeRoomsWrite, ScopeBookingsRead, ScopeBookingsWrite}
+
+// Valid reports whether s is a known scope
+func (s APIScope) Valid() bool {
+	return slices.Contains(APIScopes, s)
+}
+
+// ScopeList is stored as a space separated string, like OAuth scopes
+type ScopeList []APIScope
+
+// Has reports whether the list grants scope
+func (l ScopeList) Has(scope APIScope) bool {
+	return slices.Contains(l, scope)
+}
+
+// Value implements driver.Valuer
+func (l ScopeList) Value() (driver.Value, error) {
+	parts := make([]string, len(l))
+	for i, s := range l {
+		parts[i] = string(s)
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// Scan implements sql.Scanner
+func (l *ScopeList) Scan(src any) error {
+	var raw string
+	switch v := src.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("scan scope list from %T", src)
+	}
+
+	*l = nil
+	for _, s := range strings.Fields(raw) {
+		*l = append(*l, APIScope(s))
+	}
+	return nil
+}
+
+// ServiceAccount is a non-human client, like a room display or a script,
+// that calls the API with API keys instead of a user's token
+type ServiceAccount struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID `json:"-" gorm:"type:uuid;not null;default:'00000000-0000-0000-0000-000000000001';uniqueIndex:idx_service_accounts_org_name,priority:1"`
+	Name           string    `json:"name" gorm:"size:100;not null;uniqueIndex:idx_service_accounts_org_name"`
+	Description    string    `json:"description"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// BeforeCreate is a hook that runs before creating a service account
+func (a *ServiceAccount) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&a.ID)
+	return nil
+}
+
+// APIKey authenticates a service account. Only the SHA-256 hash of the key
+// is stored, Prefix identifies it in listings.
+type APIKey struct {
+	ID               uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	ServiceAccountID uuid.UUID  `json:"service_account_id" gorm:"type:uuid;not null;index"`
+	Name             string     `json:"name" gorm:"size:100;not null"`
+	Prefix           string     `json:"prefix" gorm:"size:16;not null"`
+	KeyHash          string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	Scopes           ScopeList  `json:"scopes" gorm:"type:varchar(255);not null"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+
+	ServiceAccount ServiceAccount `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate is a hook that runs before creating an API key
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&k.ID)
+	return nil
+}
+
+// BeforeSave stores the expiry in UTC so it compares correctly on SQLite
+func (k *APIKey) BeforeSave(tx *gorm.DB) error {
+	if k.ExpiresAt != nil {
+		utc := k.ExpiresAt.UTC()
+		k.ExpiresAt = &utc
+	}
+	return nil
+}
+
+// Active reports whether the key is neither revoked nor expired at now
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+type CreateServiceAccountInput struct {
+	Name        string `json:"name" binding:"required,max=100" example:"Lobby displays"`
+	Description string `json:"description" example:"Tablets next to the meeting rooms"`
+}
+
+type CreateAPIKeyInput struct {
+	Name   string     `json:"name" binding:"required,max=100" example:"Room 101 tablet"`
+	Scopes []APIScope `json:"scopes" binding:"required,min=1" example:"bookings:read,bookings:write"`
+	// ExpiresAt is optional, keys without it work until revoked
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type ServiceAccountResponse struct {
+	ServiceAccount
+	APIKeys []APIKey `json:"api_keys"`
+}
+
+type APIKeyCreatedResponse struct {
+	APIKey
+	// Key is the secret, it is only returned once
+	Key string `json:"key" example:"mbk_9Zt0k2J8S2yq0xjzv1P7yQ6l0p3bXfYwH5nA4cR2dE8"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Setting keys
+const (
+	// SettingRequireAdmin2FA makes two-factor authentication mandatory for
+	// users with administrative permissions, admin routes are refused until
+	// they enroll
+	SettingRequireAdmin2FA = "security.require_admin_2fa"
+)
+
+// Setting is an application setting admins can change at runtime, each
+// organization has its own
+type Setting struct {
+	OrganizationID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Key            string    `gorm:"primaryKey;size:100"`
+	Value          string    `gorm:"not null"`
+	UpdatedAt      time.Time
+}
+
+type SecuritySettings struct {
+	RequireAdmin2FA bool `json:"require_admin_2fa" example:"true"`
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SettingStrikePolicy holds the organization's StrikePolicy as JSON
+const SettingStrikePolicy = "bookings.strike_policy"
+
+// StrikeReason is what earned a strike
+type StrikeReason string
+
+const (
+	// StrikeNoShow is a booking that ended without anyone checking in
+	StrikeNoShow StrikeReason = "no_show"
+	// StrikeLateCancellation is a booking its organizer, or one of their
+	// delegates, cancelled shortly before it started
+	StrikeLateCancellation StrikeReason = "late_cancellation"
+)
+
+// Strike counts a booking against its organizer. A booking earns at most
+// one strike. Cleared strikes are kept so the booking cannot earn another,
+// but no longer count.
+type Strike struct {
+	ID             uuid.UUID    `json:"id" gorm:"type:uuid;primaryKey"`
+	OrganizationID uuid.UUID    `json:"-" gorm:"type:uuid;not null;index"`
+	UserID         uuid.UUID    `json:"user_id" gorm:"type:uuid;not null;index"`
+	BookingID      uuid.UUID    `json:"booking_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Reason         StrikeReason `json:"reason" gorm:"size:30;not null"`
+	// OccurredAt is when the booking ended for no-shows and when it was
+	// cancelled for late cancellations
+	OccurredAt time.Time  `json:"occurred_at" gorm:"not null;index"`
+	ClearedAt  *time.Time `json:"cleared_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	User    User    `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Booking Booking `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (s *Strike) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&s.ID)
+	return nil
+}
+
+// StrikePolicy decides what earns strikes and what crossing the threshold
+// restricts. Users with Threshold or more strikes within the last
+// WindowDays are restricted until enough of them are older than that.
+type StrikePolicy struct {
+	Enabled bool `json:"enabled"`
+	// Since is when strikes were enabled, bookings that ended or were
+	// cancelled before never earn one. It is set by the server.
+	Since      *time.Time `json:"since,omitempty"`
+	Threshold  int        `json:"threshold" binding:"min=1,max=100" example:"3"`
+	WindowDays int        `json:"window_days" binding:"min=1,max=365" example:"30"`
+	// LateCancellationHours is how long before a booking starts cancelling
+	// it earns a strike, 0 leaves cancellations alone
+	LateCancellationHours int `json:"late_cancellation_hours" binding:"min=0,max=168" example:"2"`
+	// MaxActiveBookings limits restricted users to that many upcoming
+	// bookings, null leaves the number alone
+	MaxActiveBookings *int `json:"max_active_bookings" binding:"omitempty,min=0" example:"1"`
+	// BlockPopularRooms keeps restricted users out of rooms used for at
+	// least PopularUtilization percent of their opening hours over the last
+	// four weeks
+	BlockPopularRooms  bool    `json:"block_popular_rooms" example:"true"`
+	PopularUtilization float64 `json:"popular_utilization" binding:"min=1,max=100" example:"60"`
+}
+
+// DefaultStrikePolicy applies until an organization sets its own, strikes
+// are off until it enables them
+var DefaultStrikePolicy = StrikePolicy{
+	Threshold:             3,
+	WindowDays:            30,
+	LateCancellationHours: 2,
+	MaxActiveBookings:     ptr(1),
+	BlockPopularRooms:     true,
+	PopularUtilization:    60,
+}
+
+// PopularWindow is the time over which popular rooms are measured
+const PopularWindow = 28 * 24 * time.Hour
+
+// Validate checks that a restriction has something to restrict
+func (p StrikePolicy) Validate() error {
+	if p.Enabled && p.MaxActiveBookings == nil && !p.BlockPopularRooms {
+		return errors.New("set max_active_bookings or block_popular_rooms, or strikes restrict nothing")
+	}
+	return nil
+}
+
+// Window returns the time from which strikes count at now
+func (p StrikePolicy) Window(now time.Time) time.Time {
+	from := now.AddDate(0, 0, -p.WindowDays)
+	if p.Since != nil && p.Since.After(from) {
+		return *p.Since
+	}
+	return from
+}
+
+// RestrictedUntil returns when a user with the counting strikes, newest
+// first, may book freely again, or nil when they are not restricted
+func (p StrikePolicy) RestrictedUntil(strikes []Strike) *time.Time {
+	if !p.Enabled || p.Threshold < 1 || len(strikes) < p.Threshold {
+		return nil
+	}
+	until := strikes[p.Threshold-1].OccurredAt.AddDate(0, 0, p.WindowDays)
+	return &until
+}
+
+// StrikeStatus is where a user stands with their strikes
+type StrikeStatus struct {
+	UserID uuid.UUID `json:"user_id"`
+	// Strikes are the uncleared strikes that count, newest first
+	Strikes         []Strike   `json:"strikes"`
+	Threshold       int        `json:"threshold" example:"3"`
+	RestrictedUntil *time.Time `json:"restricted_until,omitempty"`
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStrikePolicyWindow(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	recent := now.AddDate(0, 0, -3)
+	old := now.AddDate(0, 0, -90)
+
+	cases := []struct {
+		name  string
+		since *time.Time
+		want  time.Time
+	}{
+		{"WindowDays", nil, now.AddDate(0, 0, -30)},
+		// strikes only count since they were enabled
+		{"EnabledRecently", &recent, recent},
+		{"EnabledLongAgo", &old, now.AddDate(0, 0, -30)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := StrikePolicy{Enabled: true, WindowDays: 30, Since: tc.since}
+			if got := policy.Window(now); !got.Equal(tc.want) {
+				t.Fatalf("Window = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStrikePolicyRestrictedUntil(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	// newest first, as the repository returns them
+	strikes := []Strike{
+		{OccurredAt: now.AddDate(0, 0, -1)},
+		{OccurredAt: now.AddDate(0, 0, -5)},
+		{OccurredAt: now.AddDate(0, 0, -20)},
+	}
+
+	cases := []struct {
+		name      string
+		enabled   bool
+		threshold int
+		strikes   []Strike
+		want      *time.Time
+	}{
+		{"BelowThreshold", true, 3, strikes[:2], nil},
+		// restricted until the third newest strike leaves the window
+		{"AtThreshold", true, 3, strikes, ptr(now.AddDate(0, 0, 10))},
+		{"PastThreshold", true, 2, strikes, ptr(now.AddDate(0, 0, 25))},
+		{"ThresholdOne", true, 1, strikes, ptr(now.AddDate(0, 0, 29))},
+		{"Disabled", false, 1, strikes, nil},
+		{"NoStrikes", true, 1, nil, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := StrikePolicy{Enabled: tc.enabled, Threshold: tc.threshold, WindowDays: 30}
+			got := policy.RestrictedUntil(tc.strikes)
+			if (got == nil) != (tc.want == nil) || (got != nil && !got.Equal(*tc.want)) {
+				t.Fatalf("RestrictedUntil = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStrikePolicyValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		policy  StrikePolicy
+		wantErr bool
+	}{
+		{"Default", DefaultStrikePolicy, false},
+		{"DisabledWithoutRestriction", StrikePolicy{}, false},
+		{"MaxActiveBookings", StrikePolicy{Enabled: true, MaxActiveBookings: ptr(0)}, false},
+		{"BlockPopularRooms", StrikePolicy{Enabled: true, BlockPopularRooms: true}, false},
+		{"RestrictsNothing", StrikePolicy{Enabled: true}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.policy.Validate(); (err != nil) != tc.wantErr {
+				t.Fatalf("Validate = %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type UserRole string
@@ -13,28 +14,131 @@ const (
 	RoleAdmin UserRole = "admin"
 )
 
+type UserStatus string
+
+const (
+	// UserStatusUnverified users registered themselves and have not
+	// confirmed their email yet, they can sign in but not book rooms
+	UserStatusUnverified UserStatus = "unverified"
+	UserStatusActive     UserStatus = "active"
+)
+
 type User struct {
-	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email" gorm:"unique"`
-	Password  string    `json:"-"` // don't expose password in JSON
-	Role      UserRole  `json:"role" gorm:"type:varchar(20);not null;default:'user'"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	// OrganizationID is the tenant the user belongs to, emails are unique
+	// within an organization
+	OrganizationID uuid.UUID  `json:"-" gorm:"type:uuid;not null;default:'00000000-0000-0000-0000-000000000001';uniqueIndex:idx_users_org_email,priority:1;uniqueIndex:idx_users_org_oidc,priority:1"`
+	Name           string     `json:"name"`
+	Email          string     `json:"email" gorm:"uniqueIndex:idx_users_org_email"`
+	Password       string     `json:"-"` // don't expose password in JSON
+	Role           UserRole   `json:"role" gorm:"type:varchar(20);not null;default:'user'"`
+	Status         UserStatus `json:"status" gorm:"type:varchar(20);not null;default:'active'"`
+	// TokensValidAfter revokes every access token issued before it
+	TokensValidAfter *time.Time `json:"-"`
+	// TOTPSecret is set during enrollment, two-factor authentication is
+	// only enforced once TOTPEnabled is set after the first valid code
+	TOTPSecret  string `json:"-"`
+	TOTPEnabled bool   `json:"two_factor_enabled"`
+	// TOTPLastStep is the time step of the last accepted code, so a code
+	// cannot be replayed
+	TOTPLastStep int64 `json:"-"`
+	// OIDCIssuer and OIDCSubject link the user to an identity provider
+	// account after their first single sign-on
+	OIDCIssuer  *string `json:"-" gorm:"column:oidc_issuer;uniqueIndex:idx_users_org_oidc"`
+	OIDCSubject *string `json:"-" gorm:"column:oidc_subject;uniqueIndex:idx_users_org_oidc"`
+	// PendingEmail is the address the user asked to change to, it replaces
+	// Email once they follow the link sent to it
+	PendingEmail *string `json:"pending_email,omitempty"`
+	// Timezone is an IANA zone name and Locale a BCP 47 language tag, both
+	// used to present times and emails to the user
+	Timezone      string                  `json:"timezone" gorm:"type:varchar(64);not null;default:'UTC'"`
+	Locale        string                  `json:"locale" gorm:"type:varchar(35);not null;default:'en'"`
+	Notifications NotificationPreferences `json:"notifications" gorm:"embedded;embeddedPrefix:notify_"`
+	// DeactivatedAt is set while an admin has disabled the account, the
+	// user cannot sign in and their existing tokens are rejected
+	DeactivatedAt *time.Time     `json:"deactivated_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// NotificationPreferences choose the optional emails a user receives,
+// account emails such as password resets are always sent
+type NotificationPreferences struct {
+	// BookingUpdates covers bookings someone else makes, changes or
+	// cancels for the user
+	BookingUpdates bool `json:"booking_updates" gorm:"not null;default:true"`
+	// BookingReceipts confirms bookings the user makes themselves
+	BookingReceipts bool `json:"booking_receipts" gorm:"not null;default:false"`
+}
+
+// AuditSecrets returns the fields hidden from JSON, the audit log records
+// that they changed but not their values
+func (u User) AuditSecrets() map[string]any {
+	return map[string]any{
+		"password":           u.Password,
+		"tokens_valid_after": u.TokensValidAfter,
+		"totp_secret":        u.TOTPSecret,
+		"totp_last_step":     u.TOTPLastStep,
+		"oidc_issuer":        u.OIDCIssuer,
+		"oidc_subject":       u.OIDCSubject,
+	}
+}
+
+// BeforeCreate is a hook that runs before creating a user
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&u.ID)
+	if u.Status == "" {
+		u.Status = UserStatusActive
+	}
+	if u.Timezone == "" {
+		u.Timezone = "UTC"
+	}
+	if u.Locale == "" {
+		u.Locale = "en"
+	}
+	return nil
+}
+
+// Deactivated reports whether an admin has disabled the account
+func (u *User) Deactivated() bool {
+	return u.DeactivatedAt != nil
 }
 
 type CreateUserInput struct {
 	Name     string   `json:"name" binding:"required" example:"Rifa Faruqi"`
 	Email    string   `json:"email" binding:"required,email" example:"riparuk@gmail.com"`
 	Password string   `json:"password" binding:"required" example:"strongpassword"`
-	Role     UserRole `json:"role" binding:"required" example:"user"`
+	Role     UserRole `json:"role" binding:"required,oneof=user admin" example:"user"`
+	// Verified skips email verification, otherwise a verification link is sent
+	Verified bool `json:"verified" example:"true"`
 }
 
+// UpdateUserInput changes the fields that are set and leaves the others
 type UpdateUserInput struct {
-	Name     string   `json:"name" binding:"required" example:"Rifa Faruqi"`
-	Email    string   `json:"email" binding:"required,email" example:"riparuk@gmail.com"`
-	Password string   `json:"password" binding:"required" example:"strongpassword"`
-	Role     UserRole `json:"role" binding:"required" example:"user"`
+	Name  *string   `json:"name" binding:"omitempty,min=1" example:"Rifa Faruqi"`
+	Email *string   `json:"email" binding:"omitempty,email" example:"riparuk@gmail.com"`
+	Role  *UserRole `json:"role" binding:"omitempty,oneof=user admin" example:"user"`
+}
+
+// UpdateProfileInput changes the fields that are set and leaves the others.
+// A new email only takes effect once the link sent to it is followed.
+type UpdateProfileInput struct {
+	Name          *string                        `json:"name" binding:"omitempty,min=1" example:"Rifa Faruqi"`
+	Email         *string                        `json:"email" binding:"omitempty,email" example:"riparuk@gmail.com"`
+	Timezone      *string                        `json:"timezone" binding:"omitempty,timezone" example:"Asia/Jakarta"`
+	Locale        *string                        `json:"locale" binding:"omitempty,bcp47_language_tag" example:"id-ID"`
+	Notifications *UpdateNotificationPreferences `json:"notifications"`
+}
+
+type UpdateNotificationPreferences struct {
+	BookingUpdates  *bool `json:"booking_updates" example:"true"`
+	BookingReceipts *bool `json:"booking_receipts" example:"false"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"strongpassword"`
+	NewPassword     string `json:"new_password" binding:"required,min=8" example:"newstrongpassword"`
 }
 
 type LoginRequest struct {
@@ -43,8 +147,39 @@ type LoginRequest struct {
 }
 
 type RegisterRequest struct {
-	Name           string `json:"name" binding:"required" example:"Rifa Faruqi"`
-	Email          string `json:"email" binding:"required,email" example:"riparuk@gmail.com"`
-	Password       string `json:"password" binding:"required" example:"strongpassword"`
-	MasterPassword string `json:"master_password,omitempty" example:"secret-master"`
+	Name     string `json:"name" binding:"required" example:"Rifa Faruqi"`
+	Email    string `json:"email" binding:"required,email" example:"riparuk@gmail.com"`
+	Password string `json:"password" binding:"required" example:"strongpassword"`
+	// InvitationToken registers with the invitation's role and skips email
+	// verification, the email must match the invitation
+	InvitationToken string `json:"invitation_token,omitempty"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Code is the current authenticator code or an unused recovery code
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required" example:"strongpassword"`
+	// Code is the current authenticator code or an unused recovery code
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/Meet%20Book:riparuk@gmail.com?secret=JBSWY3DPEHPK3PXP&issuer=Meet+Book"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
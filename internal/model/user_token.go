@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TokenPurpose string
+
+const (
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	// TokenPurposeEmailChange tokens are sent to a user's pending email
+	TokenPurposeEmailChange TokenPurpose = "email_change"
+	// TokenPurposeRecoveryCode tokens are two-factor recovery codes, they
+	// don't expire but each works once
+	TokenPurposeRecoveryCode TokenPurpose = "recovery_code"
+)
+
+// UserToken is a single-use token sent to a user, like a password reset
+// link. Only the SHA-256 hash of the token is stored.
+type UserToken struct {
+	ID        uuid.UUID    `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID    `gorm:"type:uuid;not null;index"`
+	Purpose   TokenPurpose `gorm:"type:varchar(32);not null"`
+	TokenHash string       `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time    `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User User `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// BeforeCreate is a hook that runs before creating a token
+func (t *UserToken) BeforeCreate(tx *gorm.DB) error {
+	ensureID(&t.ID)
+	return nil
+}
+
+// BeforeSave stores the expiry in UTC so it compares correctly on SQLite
+func (t *UserToken) BeforeSave(tx *gorm.DB) error {
+	t.ExpiresAt = t.ExpiresAt.UTC()
+	return nil
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" example:"riparuk@gmail.com"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8" example:"newstrongpassword"`
+}
@@ -0,0 +1,147 @@
+// Package oidc signs users in with an OpenID Connect provider using the
+// authorization code flow with PKCE. ID tokens are verified against the
+// keys published in the provider's discovery document.
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/riparuk/meet-book-api/internal/config"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"golang.org/x/oauth2"
+)
+
+// Identity is the verified identity of a user returned by the provider
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	// Role is the role mapped from the groups claim, empty when the ID
+	// token has no groups claim
+	Role model.UserRole
+}
+
+// Provider talks to one OpenID Connect provider. Discovery happens on
+// first use so the API starts even while the provider is unreachable.
+type Provider struct {
+	cfg config.OIDCConfig
+
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+func New(cfg config.OIDCConfig) *Provider {
+	return &Provider{cfg: cfg}
+}
+
+// discover fetches the discovery document once it succeeds
+func (p *Provider) discover(ctx context.Context) (*oauth2.Config, *gooidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth2 != nil {
+		return p.oauth2, p.verifier, nil
+	}
+
+	provider, err := gooidc.NewProvider(ctx, p.cfg.IssuerURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.cfg.Scopes,
+	}
+	p.verifier = provider.Verifier(&gooidc.Config{ClientID: p.cfg.ClientID})
+	return p.oauth2, p.verifier, nil
+}
+
+// AuthCodeURL returns the provider URL to send the browser to. verifier is
+// the PKCE code verifier, only its S256 challenge is sent.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	cfg, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return cfg.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange redeems the authorization code and verifies the ID token
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	cfg, idVerifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := cfg.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	idToken, err := idVerifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("verify id_token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("id_token nonce does not match")
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("decode id_token claims: %w", err)
+	}
+
+	identity := &Identity{
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+	}
+	identity.Email, _ = claims["email"].(string)
+	identity.EmailVerified, _ = claims["email_verified"].(bool)
+	identity.Name, _ = claims["name"].(string)
+	if groups, ok := claims[p.cfg.GroupsClaim]; ok {
+		identity.Role = p.mapRole(groups)
+	}
+
+	if identity.Email == "" {
+		return nil, errors.New("id_token has no email claim, request the email scope")
+	}
+	return identity, nil
+}
+
+// mapRole returns admin when any group is one of the admin groups
+func (p *Provider) mapRole(claim any) model.UserRole {
+	var groups []string
+	switch v := claim.(type) {
+	case []any:
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+	case string:
+		groups = []string{v}
+	}
+
+	for _, group := range groups {
+		if slices.Contains(p.cfg.AdminGroups, group) {
+			return model.RoleAdmin
+		}
+	}
+	return model.RoleUser
+}
@@ -0,0 +1,154 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/riparuk/meet-book-api/internal/config"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/oidc"
+	"github.com/riparuk/meet-book-api/internal/oidc/oidctest"
+	"golang.org/x/oauth2"
+)
+
+func newProvider(t *testing.T, user oidctest.User) (*oidc.Provider, *oidctest.Provider) {
+	t.Helper()
+	mock, srv := oidctest.NewServer(user)
+	t.Cleanup(srv.Close)
+
+	cfg := config.Default().OIDC
+	cfg.IssuerURL = srv.URL
+	cfg.ClientID = "meet-book"
+	cfg.ClientSecret = "secret"
+	cfg.RedirectURL = "http://localhost:8080/api/auth/oidc/callback"
+	cfg.AdminGroups = []string{"facilities"}
+	return oidc.New(cfg), mock
+}
+
+// authorize follows the provider redirect and returns the code
+func authorize(t *testing.T, p *oidc.Provider, state, nonce, verifier string) string {
+	t.Helper()
+	authURL, err := p.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want 302", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse redirect: %v", err)
+	}
+	if got := location.Query().Get("state"); got != state {
+		t.Fatalf("state = %q, want %q", got, state)
+	}
+	return location.Query().Get("code")
+}
+
+func TestExchange(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("MapsClaims", func(t *testing.T) {
+		p, mock := newProvider(t, oidctest.User{
+			Subject: "user-1", Email: "ana@example.com", EmailVerified: true, Name: "Ana", Groups: []string{"staff"},
+		})
+		verifier := oauth2.GenerateVerifier()
+		code := authorize(t, p, "state", "nonce", verifier)
+
+		identity, err := p.Exchange(ctx, code, verifier, "nonce")
+		if err != nil {
+			t.Fatalf("Exchange: %v", err)
+		}
+		if identity.Issuer != mock.Issuer || identity.Subject != "user-1" {
+			t.Errorf("identity = %s %s, want %s user-1", identity.Issuer, identity.Subject, mock.Issuer)
+		}
+		if identity.Email != "ana@example.com" || !identity.EmailVerified || identity.Name != "Ana" {
+			t.Errorf("identity = %+v", identity)
+		}
+		if identity.Role != model.RoleUser {
+			t.Errorf("Role = %q, want user", identity.Role)
+		}
+	})
+
+	t.Run("AdminGroup", func(t *testing.T) {
+		p, _ := newProvider(t, oidctest.User{Subject: "user-2", Email: "bo@example.com", Groups: []string{"staff", "facilities"}})
+		verifier := oauth2.GenerateVerifier()
+		code := authorize(t, p, "state", "nonce", verifier)
+
+		identity, err := p.Exchange(ctx, code, verifier, "nonce")
+		if err != nil {
+			t.Fatalf("Exchange: %v", err)
+		}
+		if identity.Role != model.RoleAdmin {
+			t.Errorf("Role = %q, want admin", identity.Role)
+		}
+	})
+
+	t.Run("NoGroupsClaim", func(t *testing.T) {
+		p, _ := newProvider(t, oidctest.User{Subject: "user-3", Email: "cy@example.com"})
+		verifier := oauth2.GenerateVerifier()
+		code := authorize(t, p, "state", "nonce", verifier)
+
+		identity, err := p.Exchange(ctx, code, verifier, "nonce")
+		if err != nil {
+			t.Fatalf("Exchange: %v", err)
+		}
+		if identity.Role != "" {
+			t.Errorf("Role = %q, want empty so the stored role is kept", identity.Role)
+		}
+	})
+
+	t.Run("WrongVerifier", func(t *testing.T) {
+		p, _ := newProvider(t, oidctest.User{Subject: "user-4", Email: "di@example.com"})
+		code := authorize(t, p, "state", "nonce", oauth2.GenerateVerifier())
+
+		if _, err := p.Exchange(ctx, code, oauth2.GenerateVerifier(), "nonce"); err == nil {
+			t.Error("Exchange succeeded with the wrong PKCE verifier")
+		}
+	})
+
+	t.Run("WrongNonce", func(t *testing.T) {
+		p, _ := newProvider(t, oidctest.User{Subject: "user-5", Email: "ed@example.com"})
+		verifier := oauth2.GenerateVerifier()
+		code := authorize(t, p, "state", "nonce", verifier)
+
+		if _, err := p.Exchange(ctx, code, verifier, "other"); err == nil {
+			t.Error("Exchange accepted an ID token with another nonce")
+		}
+	})
+
+	t.Run("CodeIsSingleUse", func(t *testing.T) {
+		p, _ := newProvider(t, oidctest.User{Subject: "user-6", Email: "fu@example.com"})
+		verifier := oauth2.GenerateVerifier()
+		code := authorize(t, p, "state", "nonce", verifier)
+
+		if _, err := p.Exchange(ctx, code, verifier, "nonce"); err != nil {
+			t.Fatalf("Exchange: %v", err)
+		}
+		if _, err := p.Exchange(ctx, code, verifier, "nonce"); err == nil {
+			t.Error("Exchange redeemed the same code twice")
+		}
+	})
+
+	t.Run("MissingEmail", func(t *testing.T) {
+		p, _ := newProvider(t, oidctest.User{Subject: "user-7"})
+		verifier := oauth2.GenerateVerifier()
+		code := authorize(t, p, "state", "nonce", verifier)
+
+		if _, err := p.Exchange(ctx, code, verifier, "nonce"); err == nil {
+			t.Error("Exchange accepted an ID token without email")
+		}
+	})
+}
@@ -0,0 +1,223 @@
+// Package oidctest is a minimal OpenID Connect provider for tests and local
+// development. It signs every authorization request in as User without a
+// login page and checks the PKCE verifier when the code is redeemed.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "oidctest"
+
+// User is the identity the provider signs in
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	// Groups is omitted from the ID token when nil
+	Groups []string
+}
+
+// authorization is an issued code waiting to be redeemed
+type authorization struct {
+	user          User
+	clientID      string
+	nonce         string
+	codeChallenge string
+}
+
+// Provider is an http.Handler serving discovery, authorize, token and JWKS
+// endpoints
+type Provider struct {
+	Issuer string
+
+	mu    sync.Mutex
+	user  User
+	key   *rsa.PrivateKey
+	codes map[string]authorization
+}
+
+// New returns a provider whose endpoints live under issuer
+func New(issuer string, user User) *Provider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return &Provider{Issuer: issuer, user: user, key: key, codes: map[string]authorization{}}
+}
+
+// NewServer starts a provider on a local test server, close it when done
+func NewServer(user User) (*Provider, *httptest.Server) {
+	p := New("", user)
+	srv := httptest.NewServer(p)
+	p.Issuer = srv.URL
+	return p, srv
+}
+
+// SetUser changes the identity signed in by later authorization requests
+func (p *Provider) SetUser(user User) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.user = user
+}
+
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		p.discovery(w)
+	case "/authorize":
+		p.authorize(w, r)
+	case "/token":
+		p.token(w, r)
+	case "/jwks":
+		p.jwks(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (p *Provider) discovery(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"jwks_uri":                              p.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize skips the login page and redirects straight back with a code
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = authorization{
+		user:          p.user,
+		clientID:      q.Get("client_id"),
+		nonce:         q.Get("nonce"),
+		codeChallenge: q.Get("code_challenge"),
+	}
+	p.mu.Unlock()
+
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	p.mu.Lock()
+	auth, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	clientID := auth.clientID
+	if id, _, ok := r.BasicAuth(); ok {
+		clientID = id
+	} else if id := r.PostForm.Get("client_id"); id != "" {
+		clientID = id
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            p.Issuer,
+		"sub":            auth.user.Subject,
+		"aud":            clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"email":          auth.user.Email,
+		"email_verified": auth.user.EmailVerified,
+		"name":           auth.user.Name,
+	}
+	if auth.nonce != "" {
+		claims["nonce"] = auth.nonce
+	}
+	if auth.user.Groups != nil {
+		claims["groups"] = auth.user.Groups
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter) {
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
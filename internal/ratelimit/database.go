@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/riparuk/meet-book-api/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DatabaseStore keeps state in the rate_limit_counters and login_lockouts
+// tables so every replica enforces the same limits
+type DatabaseStore struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+func NewDatabaseStore(db *gorm.DB) *DatabaseStore {
+	return &DatabaseStore{db: db, now: time.Now}
+}
+
+// Increment upserts the counter in a single statement, restarting the
+// window when it has expired, so concurrent replicas don't lose hits
+func (s *DatabaseStore) Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	now := s.now().UTC()
+
+	var result model.RateLimitCounter
+	err := s.db.WithContext(ctx).Raw(`
+		INSERT INTO rate_limit_counters (bucket, count, window_ends_at) VALUES (?, 1, ?)
+		ON CONFLICT (bucket) DO UPDATE SET
+			count = CASE WHEN rate_limit_counters.window_ends_at <= ? THEN 1 ELSE rate_limit_counters.count + 1 END,
+			window_ends_at = CASE WHEN rate_limit_counters.window_ends_at <= ? THEN excluded.window_ends_at ELSE rate_limit_counters.window_ends_at END
+		RETURNING bucket, count, window_ends_at`,
+		key, now.Add(window), now, now,
+	).Scan(&result).Error
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return result.Count, result.WindowEndsAt, nil
+}
+
+func (s *DatabaseStore) GetLockout(ctx context.Context, account string) (Lockout, error) {
+	var lockout model.LoginLockout
+	err := s.db.WithContext(ctx).First(&lockout, "account = ?", account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Lockout{}, nil
+	}
+	if err != nil {
+		return Lockout{}, err
+	}
+	return Lockout{Failures: lockout.Failures, LockedUntil: lockout.LockedUntil}, nil
+}
+
+func (s *DatabaseStore) SetLockout(ctx context.Context, account string, lockout Lockout) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&model.LoginLockout{
+		Account:     account,
+		Failures:    lockout.Failures,
+		LockedUntil: lockout.LockedUntil.UTC(),
+	}).Error
+}
+
+func (s *DatabaseStore) DeleteLockout(ctx context.Context, account string) error {
+	return s.db.WithContext(ctx).Delete(&model.LoginLockout{}, "account = ?", account).Error
+}
+
+// Cleanup deletes expired counters every interval until ctx is cancelled
+func (s *DatabaseStore) Cleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.db.WithContext(ctx).Delete(&model.RateLimitCounter{}, "window_ends_at <= ?", s.now().UTC())
+		}
+	}
+}
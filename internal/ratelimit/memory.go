@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type counter struct {
+	count        int
+	windowEndsAt time.Time
+}
+
+// MemoryStore keeps state in process, suitable for a single instance
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]counter
+	lockouts map[string]Lockout
+	now      func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counters: make(map[string]counter),
+		lockouts: make(map[string]Lockout),
+		now:      time.Now,
+	}
+}
+
+func (s *MemoryStore) Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	c := s.counters[key]
+	if !c.windowEndsAt.After(now) {
+		c = counter{windowEndsAt: now.Add(window)}
+	}
+	c.count++
+	s.counters[key] = c
+	return c.count, c.windowEndsAt, nil
+}
+
+func (s *MemoryStore) GetLockout(ctx context.Context, account string) (Lockout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lockouts[account], nil
+}
+
+func (s *MemoryStore) SetLockout(ctx context.Context, account string, lockout Lockout) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lockouts[account] = lockout
+	return nil
+}
+
+func (s *MemoryStore) DeleteLockout(ctx context.Context, account string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.lockouts, account)
+	return nil
+}
+
+// Cleanup drops expired counters every interval until ctx is cancelled.
+// Lockouts are kept so failures keep counting towards longer lockouts.
+func (s *MemoryStore) Cleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			now := s.now()
+			for key, c := range s.counters {
+				if !c.windowEndsAt.After(now) {
+					delete(s.counters, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package ratelimit throttles requests with fixed window counters and locks
+// accounts out with exponential backoff after repeated failed logins.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Lockout is the failed login state of an account
+type Lockout struct {
+	Failures    int
+	LockedUntil time.Time
+}
+
+// Store keeps counters and lockouts. The memory store suits a single
+// instance, the database store shares state between replicas.
+type Store interface {
+	// Increment counts a hit for key in a window of the given length that
+	// starts with the first hit. It returns the hits so far and when the
+	// window ends.
+	Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+	GetLockout(ctx context.Context, account string) (Lockout, error)
+	SetLockout(ctx context.Context, account string, lockout Lockout) error
+	DeleteLockout(ctx context.Context, account string) error
+}
+
+// Policy configures the limits and the lockout backoff
+type Policy struct {
+	// Window is the length of the counting window for every limit
+	Window time.Duration
+	// Threshold is the number of consecutive failures that locks an account
+	Threshold int
+	// BaseLockout is the first lockout, it doubles with each further failure
+	BaseLockout time.Duration
+	// MaxLockout caps the lockout duration
+	MaxLockout time.Duration
+}
+
+type Limiter struct {
+	store  Store
+	policy Policy
+	now    func() time.Time
+}
+
+func New(store Store, policy Policy) *Limiter {
+	return &Limiter{store: store, policy: policy, now: time.Now}
+}
+
+// Allow counts a hit for key and reports whether it is within limit. When
+// it is not, the returned duration is how long until the window resets.
+func (l *Limiter) Allow(ctx context.Context, key string, limit int) (bool, time.Duration, error) {
+	count, windowEndsAt, err := l.store.Increment(ctx, key, l.policy.Window)
+	if err != nil {
+		return false, 0, err
+	}
+	if count > limit {
+		return false, retryAfter(l.now(), windowEndsAt), nil
+	}
+	return true, 0, nil
+}
+
+// Locked reports whether account is locked out and for how long
+func (l *Limiter) Locked(ctx context.Context, account string) (bool, time.Duration, error) {
+	lockout, err := l.store.GetLockout(ctx, account)
+	if err != nil {
+		return false, 0, err
+	}
+	now := l.now()
+	if lockout.LockedUntil.After(now) {
+		return true, retryAfter(now, lockout.LockedUntil), nil
+	}
+	return false, 0, nil
+}
+
+// RecordFailure counts a failed login. Once failures reach the threshold
+// the account is locked, doubling the lockout for every further failure.
+// It returns the lockout duration, zero when the account is not locked.
+func (l *Limiter) RecordFailure(ctx context.Context, account string) (time.Duration, error) {
+	lockout, err := l.store.GetLockout(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+
+	lockout.Failures++
+	var duration time.Duration
+	if lockout.Failures >= l.policy.Threshold {
+		duration = l.lockoutDuration(lockout.Failures - l.policy.Threshold)
+		lockout.LockedUntil = l.now().Add(duration)
+	}
+
+	return duration, l.store.SetLockout(ctx, account, lockout)
+}
+
+// RecordSuccess clears the failures of account after a successful login
+func (l *Limiter) RecordSuccess(ctx context.Context, account string) error {
+	return l.store.DeleteLockout(ctx, account)
+}
+
+// Unlock lifts a lockout and clears the failures of account
+func (l *Limiter) Unlock(ctx context.Context, account string) error {
+	return l.store.DeleteLockout(ctx, account)
+}
+
+func (l *Limiter) lockoutDuration(step int) time.Duration {
+	duration := l.policy.BaseLockout
+	for i := 0; i < step && duration < l.policy.MaxLockout; i++ {
+		duration *= 2
+	}
+	if duration > l.policy.MaxLockout {
+		duration = l.policy.MaxLockout
+	}
+	return duration
+}
+
+// retryAfter rounds up to whole seconds for the Retry-After header
+func retryAfter(now, until time.Time) time.Duration {
+	d := until.Sub(now)
+	if d <= 0 {
+		return time.Second
+	}
+	if rounded := d.Truncate(time.Second); rounded < d {
+		return rounded + time.Second
+	}
+	return d
+}
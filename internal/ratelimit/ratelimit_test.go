@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/riparuk/meet-book-api/internal/database"
+)
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func stores(t *testing.T, clk *clock) map[string]Store {
+	mem := NewMemoryStore()
+	mem.now = clk.now
+
+	db, err := database.Open(database.DriverSQLite, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	dbStore := NewDatabaseStore(db)
+	dbStore.now = clk.now
+
+	return map[string]Store{"memory": mem, "database": dbStore}
+}
+
+var policy = Policy{Window: time.Minute, Threshold: 3, BaseLockout: time.Minute, MaxLockout: 5 * time.Minute}
+
+func TestAllow(t *testing.T) {
+	clk := &clock{t: time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)}
+	for name, store := range stores(t, clk) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			l := New(store, policy)
+			l.now = clk.now
+
+			for i := 0; i < 2; i++ {
+				if ok, _, err := l.Allow(ctx, "ip:1", 2); err != nil || !ok {
+					t.Fatalf("hit %d: allowed = %v, err = %v", i, ok, err)
+				}
+			}
+
+			clk.advance(20 * time.Second)
+			ok, retryAfter, err := l.Allow(ctx, "ip:1", 2)
+			if err != nil || ok {
+				t.Fatalf("third hit: allowed = %v, err = %v", ok, err)
+			}
+			if retryAfter != 40*time.Second {
+				t.Errorf("retry after = %v, want 40s", retryAfter)
+			}
+			if ok, _, _ := l.Allow(ctx, "ip:2", 2); !ok {
+				t.Error("other keys must not share the counter")
+			}
+
+			clk.advance(time.Minute)
+			if ok, _, _ := l.Allow(ctx, "ip:1", 2); !ok {
+				t.Error("counter should reset after the window")
+			}
+		})
+	}
+}
+
+func TestLockout(t *testing.T) {
+	clk := &clock{t: time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)}
+	for name, store := range stores(t, clk) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			l := New(store, policy)
+			l.now = clk.now
+
+			var lockouts []time.Duration
+			for i := 0; i < 6; i++ {
+				d, err := l.RecordFailure(ctx, "a@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+				lockouts = append(lockouts, d)
+			}
+			want := []time.Duration{0, 0, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute}
+			for i := range want {
+				if lockouts[i] != want[i] {
+					t.Errorf("failure %d: lockout = %v, want %v", i+1, lockouts[i], want[i])
+				}
+			}
+
+			if locked, retryAfter, _ := l.Locked(ctx, "a@example.com"); !locked || retryAfter != 5*time.Minute {
+				t.Errorf("locked = %v, retry after = %v", locked, retryAfter)
+			}
+			clk.advance(5 * time.Minute)
+			if locked, _, _ := l.Locked(ctx, "a@example.com"); locked {
+				t.Error("lockout should expire")
+			}
+
+			if _, err := l.RecordFailure(ctx, "a@example.com"); err != nil {
+				t.Fatal(err)
+			}
+			if locked, _, _ := l.Locked(ctx, "a@example.com"); !locked {
+				t.Error("failures should keep counting after a lockout expires")
+			}
+			if err := l.Unlock(ctx, "a@example.com"); err != nil {
+				t.Fatal(err)
+			}
+			if locked, _, _ := l.Locked(ctx, "a@example.com"); locked {
+				t.Error("unlock should lift the lockout")
+			}
+			if d, _ := l.RecordFailure(ctx, "a@example.com"); d != 0 {
+				t.Errorf("unlock should reset failures, got lockout %v", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+// AuditLogRepository stores the audit log. It can only add and read
+// entries, there is deliberately no way to change or remove one.
+type AuditLogRepository interface {
+	WithContext(ctx context.Context) AuditLogRepository
+	Create(entry *model.AuditLog) error
+	// Find returns the entries matching filter, newest first
+	Find(filter model.AuditLogFilter) ([]model.AuditLog, error)
+}
+
+type auditLogRepository struct {
+	db  *gorm.DB
+	org uuid.UUID
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository whose queries run with ctx, limited to
+// the organization ctx acts for
+func (r *auditLogRepository) WithContext(ctx context.Context) AuditLogRepository {
+	return &auditLogRepository{db: r.db.WithContext(ctx), org: tenant.FromContext(ctx)}
+}
+
+func (r *auditLogRepository) Create(entry *model.AuditLog) error {
+	entry.OrganizationID = r.org
+	return r.db.Create(entry).Error
+}
+
+func (r *auditLogRepository) Find(filter model.AuditLogFilter) ([]model.AuditLog, error) {
+	query := r.db.Where("organization_id = ?", r.org)
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		query = query.Where("entity_id = ?", *filter.EntityID)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", filter.From.UTC())
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", filter.To.UTC())
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var entries []model.AuditLog
+	err := query.Order("created_at DESC").Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/audit"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/model"
+)
+
+// The audited repositories wrap another implementation and add an audit
+// log entry for every change they make, with the actor and source of the
+// context they run with. Reads pass straight through to the embedded
+// repository.
+
+// recordAudit adds the change of one record to the audit log. Updates that
+// changed nothing are skipped. A failure is returned so the request fails
+// rather than leaving a change nobody can trace, even though the change
+// itself has already been made.
+func recordAudit(ctx context.Context, log AuditLogRepository, action model.AuditAction, entity model.AuditEntity, id uuid.UUID, before, after any, ignore ...string) error {
+	changes, err := model.DiffAudit(before, after, ignore...)
+	if err == nil {
+		if action == model.AuditActionUpdate && len(changes) == 0 {
+			return nil
+		}
+
+		actor := audit.ActorFromContext(ctx)
+		source := audit.SourceFromContext(ctx)
+		entry := model.AuditLog{
+			ActorType:  actor.Type,
+			Action:     action,
+			EntityType: entity,
+			EntityID:   id,
+			Changes:    changes,
+			IP:         source.IP,
+			RequestID:  source.RequestID,
+		}
+		if actor.ID != uuid.Nil {
+			entry.ActorID = &actor.ID
+		}
+		err = log.WithContext(ctx).Create(&entry)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to write audit log", "error", err, "entity", entity, "entity_id", id)
+	}
+	return err
+}
+
+type auditedUserRepository struct {
+	UserRepository
+	ctx context.Context
+	log AuditLogRepository
+}
+
+func NewAuditedUserRepository(inner UserRepository, log AuditLogRepository) UserRepository {
+	return &auditedUserRepository{UserRepository: inner, ctx: context.Background(), log: log}
+}
+
+func (r *auditedUserRepository) WithContext(ctx context.Context) UserRepository {
+	return &auditedUserRepository{UserRepository: r.UserRepository.WithContext(ctx), ctx: ctx, log: r.log}
+}
+
+func (r *auditedUserRepository) Create(user *model.User) error {
+	if err := r.UserRepository.Create(user); err != nil {
+		return err
+	}
+	return recordAudit(r.ctx, r.log, model.AuditActionCreate, model.AuditEntityUser, user.ID, nil, user)
+}
+
+func (r *auditedUserRepository) Update(user *model.User) error {
+	before, err := r.UserRepository.FindByID(user.ID.String())
+	if err != nil {
+		before = nil
+	}
+	if err := r.UserRepository.Update(user); err != nil {
+		return err
+	}
+	return recordAudit(r.ctx, r.log, model.AuditActionUpdate, model.AuditEntityUser, user.ID, before, user)
+}
+
+func (r *auditedUserRepository) Delete(id string) error {
+	before, err := r.UserRepository.FindByID(id)
+	if err != nil {
+		before = nil
+	}
+	if err := r.UserRepository.Delete(id); err != nil {
+		return err
+	}
+	if before == nil {
+		return nil
+	}
+	return recordAudit(r.ctx, r.log, model.AuditActionDelete, model.AuditEntityUser, before.ID, before, nil)
+}
+
+type auditedRoomRepository struct {
+	RoomRepository
+	ctx context.Context
+	log AuditLogRepository
+}
+
+func NewAuditedRoomRepository(inner RoomRepository, log AuditLogRepository) RoomRepository {
+	return &auditedRoomRepository{RoomRepository: inner, ctx: context.Background(), log: log}
+}
+
+func (r *auditedRoomRepository) WithContext(ctx context.Context) RoomRepository {
+	return &auditedRoomRepository{RoomRepository: r.RoomRepository.WithContext(ctx), ctx: ctx, log: r.log}
+}
+
+func (r *auditedRoomRepository) Create(room *model.Room) error {
+	if err := r.RoomRepository.Create(room); err != nil {
+		return err
+	}
+	return recordAudit(r.ctx, r.log, model.AuditActionCreate, model.AuditEntityRoom, room.ID, nil, room)
+}
+
+func (r *auditedRoomRepository) Update(room *model.Room) error {
+	before, _ := r.RoomRepository.FindByID(room.ID)
+	if err := r.RoomRepository.Update(room); err != nil {
+		return err
+	}
+	return recordAudit(r.ctx, r.log, model.AuditActionUpdate, model.AuditEntityRoom, room.ID, before, room)
+}
+
+func (r *auditedRoomRepository) Delete(id uuid.UUID) error {
+	before, _ := r.RoomRepository.FindByID(id)
+	if err := r.RoomRepository.Delete(id); err != nil {
+		return err
+	}
+	if before == nil {
+		return nil
+	}
+	return recordAudit(r.ctx, r.log, model.AuditActionDelete, model.AuditEntityRoom, id, before, nil)
+}
+
+// roomAccessAudit is how access list changes show up in the audit log of
+// their room
+type roomAccessAudit struct {
+	GroupIDs []string `json:"access_group_ids"`
+}
+
+func (r *auditedRoomRepository) SetAccess(roomID uuid.UUID, groupIDs []uuid.UUID) error {
+	groups, _ := r.RoomRepository.FindAccess(roomID)
+	if err := r.RoomRepository.SetAccess(roomID, groupIDs); err != nil {
+		return err
+	}
+
+	before := roomAccessAudit{GroupIDs: []string{}}
+	for _, g := range groups {
+		before.GroupIDs = append(before.GroupIDs, g.ID.String())
+	}
+	after := roomAccessAudit{GroupIDs: []string{}}
+	for _, id := range groupIDs {
+		if s := id.String(); !slices.Contains(after.GroupIDs, s) {
+			after.GroupIDs = append(after.GroupIDs, s)
+		}
+	}
+	slices.Sort(before.GroupIDs)
+	slices.Sort(after.GroupIDs)
+	return recordAudit(r.ctx, r.log, model.AuditActionUpdate, model.AuditEntityRoom, roomID, before, after)
+}
+
+// bookingAuditIgnored are the preloaded relationships of a booking, their
+// changes are recorded on their own records
+var bookingAuditIgnored = []string{"room", "user"}
+
+type auditedBookingRepository struct {
+	BookingRepository
+	ctx context.Context
+	log AuditLogRepository
+}
+
+func NewAuditedBookingRepository(inner BookingRepository, log AuditLogRepository) BookingRepository {
+	return &auditedBookingRepository{BookingRepository: inner, ctx: context.Background(), log: log}
+}
+
+func (r *auditedBookingRepository) WithContext(ctx context.Context) BookingRepository {
+	return &auditedBookingRepository{BookingRepository: r.BookingRepository.WithContext(ctx), ctx: ctx, log: r.log}
+}
+
+func (r *auditedBookingRepository) Create(booking *model.Booking) error {
+	if err := r.BookingRepository.Create(booking); err != nil {
+		return err
+	}
+	return r.record(model.AuditActionCreate, booking.ID, nil, booking)
+}
+
+// Update records cancelling an active booking as a cancellation, like
+// Cancel does
+func (r *auditedBookingRepository) Update(booking *model.Booking) error {
+	before, _ := r.BookingRepository.FindByID(booking.ID)
+	if err := r.BookingRepository.Update(booking); err != nil {
+		return err
+	}
+
+	action := model.AuditActionUpdate
+	if before != nil && before.Status == model.BookingStatusActive && booking.Status == model.BookingStatusCancelled {
+		action = model.AuditActionCancel
+	}
+	return r.record(action, booking.ID, before, booking)
+}
+
+func (r *auditedBookingRepository) Cancel(id uuid.UUID) error {
+	before, _ := r.BookingRepository.FindByID(id)
+	if err := r.BookingRepository.Cancel(id); err != nil {
+		return err
+	}
+	if before != nil && before.Status != model.BookingStatusCancelled {
+		after := *before
+		after.Status = model.BookingStatusCancelled
+		return r.record(model.AuditActionCancel, id, before, &after)
+	}
+	return nil
+}
+
+func (r *auditedBookingRepository) CreateGroup(group *model.BookingGroup, bookings []model.Booking) ([]int, error) {
+	conflicts, err := r.BookingRepository.CreateGroup(group, bookings)
+	if err != nil || len(conflicts) > 0 {
+		return conflicts, err
+	}
+	for i := range bookings {
+		err = errors.Join(err, r.record(model.AuditActionCreate, bookings[i].ID, nil, &bookings[i]))
+	}
+	return nil, err
+}
+
+func (r *auditedBookingRepository) CancelGroup(id uuid.UUID) error {
+	group, _ := r.BookingRepository.FindGroupByID(id)
+	if err := r.BookingRepository.CancelGroup(id); err != nil {
+		return err
+	}
+	if group == nil {
+		return nil
+	}
+	var err error
+	for i := range group.Bookings {
+		before := &group.Bookings[i]
+		if before.Status != model.BookingStatusActive {
+			continue
+		}
+		after := *before
+		after.Status = model.BookingStatusCancelled
+		err = errors.Join(err, r.record(model.AuditActionCancel, before.ID, before, &after))
+	}
+	return err
+}
+
+func (r *auditedBookingRepository) record(action model.AuditAction, id uuid.UUID, before, after *model.Booking) error {
+	return recordAudit(r.ctx, r.log, action, model.AuditEntityBooking, id, before, after, bookingAuditIgnored...)
+}
@@ -1,14 +1,18 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tenant"
 	"gorm.io/gorm"
 )
 
 type BookingRepository interface {
+	WithContext(ctx context.Context) BookingRepository
 	Create(booking *model.Booking) error
 	FindByID(id uuid.UUID) (*model.Booking, error)
 	FindByUserID(userID uuid.UUID) ([]model.Booking, error)
@@ -18,23 +22,48 @@ type BookingRepository interface {
 	Cancel(id uuid.UUID) error
 	IsRoomAvailable(roomID uuid.UUID, startTime, endTime time.Time, excludeID *uuid.UUID) (bool, error)
 	GetUpcomingBookings() ([]model.Booking, error)
+	CreateGroup(group *model.BookingGroup, bookings []model.Booking) ([]int, error)
+	FindGroupByID(id uuid.UUID) (*model.BookingGroup, error)
+	CancelGroup(id uuid.UUID) error
+	// CountActiveAt is not limited to one organization, it counts the
+	// bookings of every organization
+	CountActiveAt(at time.Time) (int64, error)
+	// CountActiveByUserID counts the active bookings userID organizes that
+	// have not ended at the given time
+	CountActiveByUserID(userID uuid.UUID, at time.Time) (int64, error)
 }
 
+// errGroupConflict rolls back a group transaction when a booking is unavailable
+var errGroupConflict = errors.New("booking group has conflicts")
+
 type bookingRepository struct {
-	db *gorm.DB
+	db  *gorm.DB
+	org uuid.UUID
 }
 
 func NewBookingRepository(db *gorm.DB) BookingRepository {
-	return &bookingRepository{db: db}
+	return &bookingRepository{db: db, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository whose queries run with ctx, limited to
+// the organization ctx acts for
+func (r *bookingRepository) WithContext(ctx context.Context) BookingRepository {
+	return &bookingRepository{db: r.db.WithContext(ctx), org: tenant.FromContext(ctx)}
+}
+
+// scoped limits a query on bookings to the repository's organization
+func (r *bookingRepository) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("bookings.organization_id = ?", r.org)
 }
 
 func (r *bookingRepository) Create(booking *model.Booking) error {
+	booking.OrganizationID = r.org
 	return r.db.Create(booking).Error
 }
 
 func (r *bookingRepository) FindByID(id uuid.UUID) (*model.Booking, error) {
 	var booking model.Booking
-	err := r.db.
+	err := r.scoped(r.db).
 		Preload("Room").
 		Preload("User").
 		First(&booking, "id = ?", id).Error
@@ -50,7 +79,7 @@ func (r *bookingRepository) FindByID(id uuid.UUID) (*model.Booking, error) {
 
 func (r *bookingRepository) FindByUserID(userID uuid.UUID) ([]model.Booking, error) {
 	var bookings []model.Booking
-	err := r.db.
+	err := r.scoped(r.db).
 		Preload("Room").
 		Preload("User").
 		Where("user_id = ?", userID).
@@ -61,7 +90,7 @@ func (r *bookingRepository) FindByUserID(userID uuid.UUID) ([]model.Booking, err
 
 func (r *bookingRepository) FindByRoomID(roomID uuid.UUID) ([]model.Booking, error) {
 	var bookings []model.Booking
-	err := r.db.
+	err := r.scoped(r.db).
 		Preload("Room").
 		Preload("User").
 		Where("room_id = ?", roomID).
@@ -71,22 +100,28 @@ func (r *bookingRepository) FindByRoomID(roomID uuid.UUID) ([]model.Booking, err
 }
 
 func (r *bookingRepository) Update(booking *model.Booking) error {
-	return r.db.Save(booking).Error
+	booking.OrganizationID = r.org
+	return updateAll(r.scoped(r.db), booking)
 }
 
 func (r *bookingRepository) Cancel(id uuid.UUID) error {
-	return r.db.Model(&model.Booking{}).
+	return r.scoped(r.db.Model(&model.Booking{})).
 		Where("id = ?", id).
 		Update("status", model.BookingStatusCancelled).
 		Error
 }
 
 func (r *bookingRepository) IsRoomAvailable(roomID uuid.UUID, startTime, endTime time.Time, excludeID *uuid.UUID) (bool, error) {
+	return isRoomAvailable(r.scoped(r.db), roomID, startTime, endTime, excludeID)
+}
+
+func isRoomAvailable(db *gorm.DB, roomID uuid.UUID, startTime, endTime time.Time, excludeID *uuid.UUID) (bool, error) {
 	var count int64
-	query := r.db.Model(&model.Booking{}).
+	query := db.Model(&model.Booking{}).
 		Where("room_id = ?", roomID).
 		Where("status = ?", model.BookingStatusActive).
-		Where("(start_time, end_time) OVERLAPS (?, ?)", startTime, endTime)
+		// Portable equivalent of (start_time, end_time) OVERLAPS (?, ?)
+		Where("start_time < ? AND end_time > ?", endTime.UTC(), startTime.UTC())
 
 	if excludeID != nil {
 		query = query.Where("id != ?", *excludeID)
@@ -98,10 +133,10 @@ func (r *bookingRepository) IsRoomAvailable(roomID uuid.UUID, startTime, endTime
 
 func (r *bookingRepository) GetUpcomingBookings() ([]model.Booking, error) {
 	var bookings []model.Booking
-	err := r.db.
+	err := r.scoped(r.db).
 		Preload("Room").
 		Preload("User").
-		Where("start_time > ?", time.Now()).
+		Where("start_time > ?", time.Now().UTC()).
 		Where("status = ?", model.BookingStatusActive).
 		Order("start_time ASC").
 		Find(&bookings).Error
@@ -117,11 +152,11 @@ func (r *bookingRepository) FindByRoomIDAndDate(roomID uuid.UUID, date time.Time
 	// End of the day (23:59:59.999999999)
 	endOfDay := startOfDay.Add(24*time.Hour - time.Nanosecond)
 
-	query := r.db.
+	query := r.scoped(r.db).
 		Preload("Room").
 		Preload("User").
 		Where("room_id = ?", roomID).
-		Where("start_time >= ? AND end_time <= ?", startOfDay, endOfDay)
+		Where("start_time >= ? AND end_time <= ?", startOfDay.UTC(), endOfDay.UTC())
 
 	// Add status filter if provided
 	if status != nil && *status != "" {
@@ -136,3 +171,95 @@ func (r *bookingRepository) FindByRoomIDAndDate(roomID uuid.UUID, date time.Time
 
 	return bookings, nil
 }
+
+// CreateGroup creates the group and its bookings in a single transaction.
+// If any booking overlaps an active booking nothing is written and the
+// indexes of the conflicting bookings are returned.
+func (r *bookingRepository) CreateGroup(group *model.BookingGroup, bookings []model.Booking) ([]int, error) {
+	var conflicts []int
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		group.OrganizationID = r.org
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+
+		for i := range bookings {
+			available, err := isRoomAvailable(r.scoped(tx), bookings[i].RoomID, bookings[i].StartTime, bookings[i].EndTime, nil)
+			if err != nil {
+				return err
+			}
+			if !available {
+				conflicts = append(conflicts, i)
+				continue
+			}
+
+			bookings[i].GroupID = &group.ID
+			bookings[i].OrganizationID = r.org
+			if err := tx.Create(&bookings[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(conflicts) > 0 {
+			return errGroupConflict
+		}
+		return nil
+	})
+
+	if errors.Is(err, errGroupConflict) {
+		return conflicts, nil
+	}
+	return nil, err
+}
+
+func (r *bookingRepository) FindGroupByID(id uuid.UUID) (*model.BookingGroup, error) {
+	var group model.BookingGroup
+	err := r.db.
+		Where("booking_groups.organization_id = ?", r.org).
+		Preload("User").
+		Preload("Bookings", func(db *gorm.DB) *gorm.DB {
+			return db.Order("start_time ASC")
+		}).
+		Preload("Bookings.Room").
+		Preload("Bookings.User").
+		First(&group, "id = ?", id).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// CancelGroup cancels every active booking in the group
+func (r *bookingRepository) CancelGroup(id uuid.UUID) error {
+	return r.scoped(r.db.Model(&model.Booking{})).
+		Where("group_id = ?", id).
+		Where("status = ?", model.BookingStatusActive).
+		Update("status", model.BookingStatusCancelled).
+		Error
+}
+
+// CountActiveAt counts active bookings in progress at the given time, for
+// the instance wide active bookings metric
+func (r *bookingRepository) CountActiveAt(at time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Booking{}).
+		Where("status = ?", model.BookingStatusActive).
+		Where("start_time <= ? AND end_time > ?", at.UTC(), at.UTC()).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *bookingRepository) CountActiveByUserID(userID uuid.UUID, at time.Time) (int64, error) {
+	var count int64
+	err := r.scoped(r.db.Model(&model.Booking{})).
+		Where("user_id = ?", userID).
+		Where("status = ?", model.BookingStatusActive).
+		Where("end_time > ?", at.UTC()).
+		Count(&count).Error
+	return count, err
+}
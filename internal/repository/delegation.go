@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DelegationRepository interface {
+	WithContext(ctx context.Context) DelegationRepository
+	// Grant lets delegateID book for principalID, granting again is not an
+	// error. It returns gorm.ErrForeignKeyViolated when either user is not
+	// in the organization.
+	Grant(principalID, delegateID uuid.UUID) error
+	// Revoke withdraws a delegation, it returns gorm.ErrRecordNotFound when
+	// there was none
+	Revoke(principalID, delegateID uuid.UUID) error
+	// IsDelegate reports whether delegateID may book for principalID
+	IsDelegate(principalID, delegateID uuid.UUID) (bool, error)
+	// FindDelegates returns the users who may book for principalID ordered
+	// by name
+	FindDelegates(principalID uuid.UUID) ([]model.User, error)
+	// FindPrincipals returns the users delegateID may book for ordered by
+	// name
+	FindPrincipals(delegateID uuid.UUID) ([]model.User, error)
+}
+
+type delegationRepository struct {
+	db  *gorm.DB
+	org uuid.UUID
+}
+
+func NewDelegationRepository(db *gorm.DB) DelegationRepository {
+	return &delegationRepository{db: db, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository whose queries run with ctx, limited to
+// the organization ctx acts for
+func (r *delegationRepository) WithContext(ctx context.Context) DelegationRepository {
+	return &delegationRepository{db: r.db.WithContext(ctx), org: tenant.FromContext(ctx)}
+}
+
+// principals limits a query on delegations to those of the organization's
+// users, the delegate is always in the same organization
+func (r *delegationRepository) principals(db *gorm.DB) *gorm.DB {
+	return db.Where("principal_id IN (?)", r.db.Model(&model.User{}).Select("id").Where("organization_id = ?", r.org))
+}
+
+func (r *delegationRepository) Grant(principalID, delegateID uuid.UUID) error {
+	var users int64
+	err := r.db.Model(&model.User{}).Where("organization_id = ? AND id IN ?", r.org, []uuid.UUID{principalID, delegateID}).Count(&users).Error
+	if err != nil {
+		return err
+	}
+	if users < int64(len(distinct([]uuid.UUID{principalID, delegateID}))) {
+		return gorm.ErrForeignKeyViolated
+	}
+
+	delegation := model.Delegation{PrincipalID: principalID, DelegateID: delegateID}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Omit("Principal", "Delegate").Create(&delegation).Error
+}
+
+func (r *delegationRepository) Revoke(principalID, delegateID uuid.UUID) error {
+	result := r.principals(r.db).Where("principal_id = ? AND delegate_id = ?", principalID, delegateID).Delete(&model.Delegation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *delegationRepository) IsDelegate(principalID, delegateID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.principals(r.db.Model(&model.Delegation{})).
+		Where("principal_id = ? AND delegate_id = ?", principalID, delegateID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *delegationRepository) FindDelegates(principalID uuid.UUID) ([]model.User, error) {
+	var users []model.User
+	err := r.db.
+		Joins("JOIN delegations ON delegations.delegate_id = users.id").
+		Where("delegations.principal_id = ?", principalID).
+		Where("users.organization_id = ?", r.org).
+		Order("users.name").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *delegationRepository) FindPrincipals(delegateID uuid.UUID) ([]model.User, error) {
+	var users []model.User
+	err := r.db.
+		Joins("JOIN delegations ON delegations.principal_id = users.id").
+		Where("delegations.delegate_id = ?", delegateID).
+		Where("users.organization_id = ?", r.org).
+		Order("users.name").
+		Find(&users).Error
+	return users, err
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GroupRepository interface {
+	WithContext(ctx context.Context) GroupRepository
+	Create(group *model.Group) error
+	// FindAll returns every group ordered by name
+	FindAll() ([]model.Group, error)
+	FindByID(id uuid.UUID) (*model.Group, error)
+	Update(group *model.Group) error
+	// Delete removes the group together with its memberships and the room
+	// access it granted
+	Delete(id uuid.UUID) error
+	// AddMember puts the user in the group, adding a member again is not
+	// an error
+	AddMember(groupID, userID uuid.UUID) error
+	// RemoveMember takes the user out of the group, it returns
+	// gorm.ErrRecordNotFound when they were not a member
+	RemoveMember(groupID, userID uuid.UUID) error
+	// FindMembers returns the members of a group ordered by name
+	FindMembers(groupID uuid.UUID) ([]model.User, error)
+	// FindByUserID returns the groups a user belongs to ordered by name
+	FindByUserID(userID uuid.UUID) ([]model.Group, error)
+}
+
+type groupRepository struct {
+	db  *gorm.DB
+	org uuid.UUID
+}
+
+func NewGroupRepository(db *gorm.DB) GroupRepository {
+	return &groupRepository{db: db, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository whose queries run with ctx, limited to
+// the organization ctx acts for
+func (r *groupRepository) WithContext(ctx context.Context) GroupRepository {
+	return &groupRepository{db: r.db.WithContext(ctx), org: tenant.FromContext(ctx)}
+}
+
+// scoped limits a query on groups to the repository's organization
+func (r *groupRepository) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("groups.organization_id = ?", r.org)
+}
+
+// owns checks that the group belongs to the repository's organization
+func (r *groupRepository) owns(db *gorm.DB, id uuid.UUID) error {
+	var count int64
+	if err := r.scoped(db.Model(&model.Group{})).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *groupRepository) Create(group *model.Group) error {
+	group.OrganizationID = r.org
+	return r.db.Create(group).Error
+}
+
+func (r *groupRepository) FindAll() ([]model.Group, error) {
+	var groups []model.Group
+	err := r.scoped(r.db).Order("name").Find(&groups).Error
+	return groups, err
+}
+
+func (r *groupRepository) FindByID(id uuid.UUID) (*model.Group, error) {
+	var group model.Group
+	err := r.scoped(r.db).First(&group, "id = ?", id).Error
+	return &group, err
+}
+
+func (r *groupRepository) Update(group *model.Group) error {
+	group.OrganizationID = r.org
+	return updateAll(r.scoped(r.db), group)
+}
+
+func (r *groupRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.owns(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&model.GroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&model.RoomAccess{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Group{}, "id = ?", id).Error
+	})
+}
+
+func (r *groupRepository) AddMember(groupID, userID uuid.UUID) error {
+	if err := r.owns(r.db, groupID); err != nil {
+		return err
+	}
+	member := model.GroupMember{GroupID: groupID, UserID: userID}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Omit("Group", "User").Create(&member).Error
+}
+
+func (r *groupRepository) RemoveMember(groupID, userID uuid.UUID) error {
+	if err := r.owns(r.db, groupID); err != nil {
+		return err
+	}
+	result := r.db.Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&model.GroupMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *groupRepository) FindMembers(groupID uuid.UUID) ([]model.User, error) {
+	var users []model.User
+	err := r.db.
+		Joins("JOIN group_members ON group_members.user_id = users.id").
+		Where("group_members.group_id = ?", groupID).
+		Where("users.organization_id = ?", r.org).
+		Order("users.name").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *groupRepository) FindByUserID(userID uuid.UUID) ([]model.Group, error) {
+	var groups []model.Group
+	err := r.scoped(r.db).
+		Where("id IN (?)", r.db.Model(&model.GroupMember{}).Select("group_id").Where("user_id = ?", userID)).
+		Order("name").
+		Find(&groups).Error
+	return groups, err
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type InvitationRepository interface {
+	WithContext(ctx context.Context) InvitationRepository
+	Create(invitation *model.Invitation) error
+	// FindAll returns every invitation, newest first
+	FindAll() ([]model.Invitation, error)
+	// FindPending returns the pending invitation with the given token hash,
+	// or gorm.ErrRecordNotFound
+	FindPending(tokenHash string, now time.Time) (*model.Invitation, error)
+	// Accept marks a pending invitation as accepted. It returns
+	// gorm.ErrRecordNotFound when it is no longer pending, so an invitation
+	// is accepted only once even by concurrent requests.
+	Accept(id uuid.UUID, now time.Time) error
+	// Revoke withdraws a pending invitation, it returns
+	// gorm.ErrRecordNotFound when there is none with id
+	Revoke(id uuid.UUID, now time.Time) error
+	// RevokePending withdraws every pending invitation for email
+	RevokePending(email string, now time.Time) error
+}
+
+type invitationRepository struct {
+	db  *gorm.DB
+	org uuid.UUID
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: db, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository whose queries run with ctx, limited to
+// the organization ctx acts for
+func (r *invitationRepository) WithContext(ctx context.Context) InvitationRepository {
+	return &invitationRepository{db: r.db.WithContext(ctx), org: tenant.FromContext(ctx)}
+}
+
+// scoped limits a query on invitations to the repository's organization
+func (r *invitationRepository) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("invitations.organization_id = ?", r.org)
+}
+
+func (r *invitationRepository) Create(invitation *model.Invitation) error {
+	invitation.OrganizationID = r.org
+	return r.db.Omit("InvitedBy").Create(invitation).Error
+}
+
+func (r *invitationRepository) FindAll() ([]model.Invitation, error) {
+	var invitations []model.Invitation
+	err := r.scoped(r.db).Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r *invitationRepository) FindPending(tokenHash string, now time.Time) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.scoped(r.db).First(&invitation, "token_hash = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", tokenHash, now.UTC()).Error
+	return &invitation, err
+}
+
+func (r *invitationRepository) Accept(id uuid.UUID, now time.Time) error {
+	now = now.UTC()
+	result := r.scoped(r.db.Model(&model.Invitation{})).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", id, now).
+		Update("accepted_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *invitationRepository) Revoke(id uuid.UUID, now time.Time) error {
+	result := r.scoped(r.db.Model(&model.Invitation{})).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", now.UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *invitationRepository) RevokePending(email string, now time.Time) error {
+	return r.scoped(r.db.Model(&model.Invitation{})).
+		Where("email = ? AND accepted_at IS NULL AND revoked_at IS NULL", email).
+		Update("revoked_at", now.UTC()).Error
+}
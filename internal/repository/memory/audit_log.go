@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+)
+
+type auditLogRepository struct {
+	store *Store
+	org   uuid.UUID
+}
+
+func NewAuditLogRepository(store *Store) repository.AuditLogRepository {
+	return &auditLogRepository{store: store, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository limited to the organization ctx acts
+// for, the store has no other use for ctx
+func (r *auditLogRepository) WithContext(ctx context.Context) repository.AuditLogRepository {
+	return &auditLogRepository{store: r.store, org: tenant.FromContext(ctx)}
+}
+
+func (r *auditLogRepository) Create(entry *model.AuditLog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ensureID(&entry.ID)
+	entry.OrganizationID = r.org
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = r.store.now()
+	}
+	entry.CreatedAt = entry.CreatedAt.UTC()
+	r.store.auditLogs = append(r.store.auditLogs, *entry)
+	return nil
+}
+
+func (r *auditLogRepository) Find(filter model.AuditLogFilter) ([]model.AuditLog, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	entries := []model.AuditLog{}
+	for i := len(r.store.auditLogs) - 1; i >= 0; i-- {
+		e := r.store.auditLogs[i]
+		switch {
+		case e.OrganizationID != r.org,
+			filter.EntityType != "" && e.EntityType != filter.EntityType,
+			filter.EntityID != nil && e.EntityID != *filter.EntityID,
+			filter.ActorID != nil && (e.ActorID == nil || *e.ActorID != *filter.ActorID),
+			filter.From != nil && e.CreatedAt.Before(*filter.From),
+			filter.To != nil && !e.CreatedAt.Before(*filter.To):
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(a, b int) bool { return entries[a].CreatedAt.After(entries[b].CreatedAt) })
+
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
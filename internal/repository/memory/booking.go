@@ -0,0 +1,308 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type bookingRepository struct {
+	store *Store
+	org   uuid.UUID
+}
+
+func NewBookingRepository(store *Store) repository.BookingRepository {
+	return &bookingRepository{store: store, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository limited to the organization ctx acts
+// for, the store has no other use for ctx
+func (r *bookingRepository) WithContext(ctx context.Context) repository.BookingRepository {
+	return &bookingRepository{store: r.store, org: tenant.FromContext(ctx)}
+}
+
+func (r *bookingRepository) Create(booking *model.Booking) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	booking.OrganizationID = r.org
+	return r.store.createBooking(booking)
+}
+
+func (r *bookingRepository) FindByID(id uuid.UUID) (*model.Booking, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	booking, ok := r.store.bookings[id]
+	if !ok || booking.DeletedAt.Valid || booking.OrganizationID != r.org {
+		return nil, nil
+	}
+	booking = r.store.preload(booking)
+	return &booking, nil
+}
+
+func (r *bookingRepository) FindByUserID(userID uuid.UUID) ([]model.Booking, error) {
+	bookings := r.filter(func(b model.Booking) bool {
+		return b.UserID == userID
+	})
+	sortByStartTime(bookings, true)
+	return bookings, nil
+}
+
+func (r *bookingRepository) FindByRoomID(roomID uuid.UUID) ([]model.Booking, error) {
+	bookings := r.filter(func(b model.Booking) bool {
+		return b.RoomID == roomID
+	})
+	sortByStartTime(bookings, true)
+	return bookings, nil
+}
+
+func (r *bookingRepository) FindByRoomIDAndDate(roomID uuid.UUID, date time.Time, status *string) ([]model.Booking, error) {
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endOfDay := startOfDay.Add(24*time.Hour - time.Nanosecond)
+
+	bookings := r.filter(func(b model.Booking) bool {
+		if b.RoomID != roomID {
+			return false
+		}
+		if b.StartTime.Before(startOfDay) || b.EndTime.After(endOfDay) {
+			return false
+		}
+		return status == nil || *status == "" || string(b.Status) == *status
+	})
+	sortByStartTime(bookings, false)
+	return bookings, nil
+}
+
+func (r *bookingRepository) Update(booking *model.Booking) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if existing, ok := r.store.bookings[booking.ID]; !ok || existing.DeletedAt.Valid || existing.OrganizationID != r.org {
+		return gorm.ErrRecordNotFound
+	}
+	booking.OrganizationID = r.org
+	normalizeTimes(booking)
+	booking.UpdatedAt = r.store.now()
+
+	r.store.bookings[booking.ID] = stripRelations(*booking)
+	return nil
+}
+
+func (r *bookingRepository) Cancel(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	booking, ok := r.store.bookings[id]
+	if !ok || booking.DeletedAt.Valid || booking.OrganizationID != r.org {
+		return nil
+	}
+	booking.Status = model.BookingStatusCancelled
+	booking.UpdatedAt = r.store.now()
+	r.store.bookings[id] = booking
+	return nil
+}
+
+func (r *bookingRepository) IsRoomAvailable(roomID uuid.UUID, startTime, endTime time.Time, excludeID *uuid.UUID) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.isRoomAvailable(r.org, roomID, startTime, endTime, excludeID), nil
+}
+
+func (r *bookingRepository) GetUpcomingBookings() ([]model.Booking, error) {
+	now := r.store.now()
+	bookings := r.filter(func(b model.Booking) bool {
+		return b.StartTime.After(now) && b.Status == model.BookingStatusActive
+	})
+	sortByStartTime(bookings, false)
+	return bookings, nil
+}
+
+func (r *bookingRepository) CreateGroup(group *model.BookingGroup, bookings []model.Booking) ([]int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Check every booking first so a conflict leaves the store untouched,
+	// bookings earlier in the batch count as taken like inside a transaction
+	var conflicts []int
+	var accepted []model.Booking
+	for i, b := range bookings {
+		available := r.store.isRoomAvailable(r.org, b.RoomID, b.StartTime, b.EndTime, nil)
+		for _, a := range accepted {
+			if a.RoomID == b.RoomID && a.Overlaps(b.StartTime, b.EndTime) {
+				available = false
+				break
+			}
+		}
+		if !available {
+			conflicts = append(conflicts, i)
+			continue
+		}
+		accepted = append(accepted, b)
+	}
+	if len(conflicts) > 0 {
+		return conflicts, nil
+	}
+
+	ensureID(&group.ID)
+	group.OrganizationID = r.org
+	r.store.stamp(&group.CreatedAt, &group.UpdatedAt)
+	stored := *group
+	stored.User = model.User{}
+	stored.Bookings = nil
+	r.store.groups[group.ID] = stored
+
+	for i := range bookings {
+		bookings[i].GroupID = &group.ID
+		bookings[i].OrganizationID = r.org
+		if err := r.store.createBooking(&bookings[i]); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (r *bookingRepository) FindGroupByID(id uuid.UUID) (*model.BookingGroup, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	group, ok := r.store.groups[id]
+	if !ok || group.DeletedAt.Valid || group.OrganizationID != r.org {
+		return nil, nil
+	}
+
+	group.User = r.store.users[group.UserID]
+	group.Bookings = nil
+	for _, b := range r.store.bookings {
+		if b.GroupID != nil && *b.GroupID == id && !b.DeletedAt.Valid {
+			group.Bookings = append(group.Bookings, r.store.preload(b))
+		}
+	}
+	sortByStartTime(group.Bookings, false)
+	return &group, nil
+}
+
+func (r *bookingRepository) CancelGroup(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.now()
+	for bookingID, b := range r.store.bookings {
+		if b.GroupID != nil && *b.GroupID == id && b.Status == model.BookingStatusActive && b.OrganizationID == r.org {
+			b.Status = model.BookingStatusCancelled
+			b.UpdatedAt = now
+			r.store.bookings[bookingID] = b
+		}
+	}
+	return nil
+}
+
+func (r *bookingRepository) CountActiveAt(at time.Time) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// Every organization counts, like the GORM repository
+	var count int64
+	for _, b := range r.store.bookings {
+		if !b.DeletedAt.Valid && b.Status == model.BookingStatusActive && !b.StartTime.After(at) && b.EndTime.After(at) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *bookingRepository) CountActiveByUserID(userID uuid.UUID, at time.Time) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, b := range r.store.bookings {
+		if b.OrganizationID == r.org && !b.DeletedAt.Valid && b.UserID == userID && b.Status == model.BookingStatusActive && b.EndTime.After(at) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// filter returns preloaded copies of the organization's bookings matching
+// keep
+func (r *bookingRepository) filter(keep func(model.Booking) bool) []model.Booking {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var bookings []model.Booking
+	for _, b := range r.store.bookings {
+		if b.DeletedAt.Valid || b.OrganizationID != r.org || !keep(b) {
+			continue
+		}
+		bookings = append(bookings, r.store.preload(b))
+	}
+	return bookings
+}
+
+// createBooking mirrors the model hooks and inserts the booking.
+// Callers must hold the store write lock.
+func (s *Store) createBooking(booking *model.Booking) error {
+	ensureID(&booking.ID)
+	if _, exists := s.bookings[booking.ID]; exists {
+		return gorm.ErrDuplicatedKey
+	}
+	booking.Status = model.BookingStatusActive
+	normalizeTimes(booking)
+	s.stamp(&booking.CreatedAt, &booking.UpdatedAt)
+
+	s.bookings[booking.ID] = stripRelations(*booking)
+	return nil
+}
+
+// isRoomAvailable looks at the bookings of org, callers must hold the store
+// lock
+func (s *Store) isRoomAvailable(org, roomID uuid.UUID, startTime, endTime time.Time, excludeID *uuid.UUID) bool {
+	for _, b := range s.bookings {
+		if b.DeletedAt.Valid || b.OrganizationID != org || b.RoomID != roomID || b.Status != model.BookingStatusActive {
+			continue
+		}
+		if excludeID != nil && b.ID == *excludeID {
+			continue
+		}
+		if b.Overlaps(startTime, endTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// preload fills the Room and User relationships like GORM's Preload,
+// soft deleted rooms are left empty. Callers must hold the store lock.
+func (s *Store) preload(b model.Booking) model.Booking {
+	b.Room, _ = s.activeRoom(b.RoomID)
+	b.User = s.users[b.UserID]
+	return b
+}
+
+func stripRelations(b model.Booking) model.Booking {
+	b.Room = model.Room{}
+	b.User = model.User{}
+	return b
+}
+
+func normalizeTimes(b *model.Booking) {
+	b.StartTime = b.StartTime.UTC()
+	b.EndTime = b.EndTime.UTC()
+}
+
+func sortByStartTime(bookings []model.Booking, desc bool) {
+	sort.SliceStable(bookings, func(i, j int) bool {
+		if desc {
+			return bookings[i].StartTime.After(bookings[j].StartTime)
+		}
+		return bookings[i].StartTime.Before(bookings[j].StartTime)
+	})
+}
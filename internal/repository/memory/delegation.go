@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type delegationRepository struct {
+	store *Store
+	org   uuid.UUID
+}
+
+func NewDelegationRepository(store *Store) repository.DelegationRepository {
+	return &delegationRepository{store: store, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository limited to the organization ctx acts
+// for, the store has no other use for ctx
+func (r *delegationRepository) WithContext(ctx context.Context) repository.DelegationRepository {
+	return &delegationRepository{store: r.store, org: tenant.FromContext(ctx)}
+}
+
+func (r *delegationRepository) Grant(principalID, delegateID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.user(principalID); !ok {
+		return gorm.ErrForeignKeyViolated
+	}
+	if _, ok := r.user(delegateID); !ok {
+		return gorm.ErrForeignKeyViolated
+	}
+
+	key := delegation{principal: principalID, delegate: delegateID}
+	if _, exists := r.store.delegations[key]; !exists {
+		r.store.delegations[key] = model.Delegation{PrincipalID: principalID, DelegateID: delegateID, CreatedAt: r.store.now()}
+	}
+	return nil
+}
+
+func (r *delegationRepository) Revoke(principalID, delegateID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := delegation{principal: principalID, delegate: delegateID}
+	if _, ok := r.store.delegations[key]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if _, ok := r.user(principalID); !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.store.delegations, key)
+	return nil
+}
+
+func (r *delegationRepository) IsDelegate(principalID, delegateID uuid.UUID) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.delegations[delegation{principal: principalID, delegate: delegateID}]
+	if _, inOrg := r.user(principalID); !inOrg {
+		return false, nil
+	}
+	return ok, nil
+}
+
+func (r *delegationRepository) FindDelegates(principalID uuid.UUID) ([]model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := []model.User{}
+	for key := range r.store.delegations {
+		if user, ok := r.user(key.delegate); ok && key.principal == principalID {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return users, nil
+}
+
+func (r *delegationRepository) FindPrincipals(delegateID uuid.UUID) ([]model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := []model.User{}
+	for key := range r.store.delegations {
+		if user, ok := r.user(key.principal); ok && key.delegate == delegateID {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return users, nil
+}
+
+// user returns the user unless they are missing, deleted or in another
+// organization. Callers must hold the store lock.
+func (r *delegationRepository) user(id uuid.UUID) (model.User, bool) {
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt.Valid || user.OrganizationID != r.org {
+		return model.User{}, false
+	}
+	return user, true
+}
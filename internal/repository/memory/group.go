@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type groupRepository struct {
+	store *Store
+	org   uuid.UUID
+}
+
+func NewGroupRepository(store *Store) repository.GroupRepository {
+	return &groupRepository{store: store, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository limited to the organization ctx acts
+// for, the store has no other use for ctx
+func (r *groupRepository) WithContext(ctx context.Context) repository.GroupRepository {
+	return &groupRepository{store: r.store, org: tenant.FromContext(ctx)}
+}
+
+func (r *groupRepository) Create(group *model.Group) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, g := range r.store.userGroups {
+		if g.OrganizationID == r.org && g.Name == group.Name {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	ensureID(&group.ID)
+	group.OrganizationID = r.org
+	if group.Kind == "" {
+		group.Kind = model.GroupKindGroup
+	}
+	r.store.stamp(&group.CreatedAt, &group.UpdatedAt)
+	r.store.userGroups[group.ID] = *group
+	return nil
+}
+
+func (r *groupRepository) FindAll() ([]model.Group, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	groups := make([]model.Group, 0, len(r.store.userGroups))
+	for _, g := range r.store.userGroups {
+		if g.OrganizationID == r.org {
+			groups = append(groups, g)
+		}
+	}
+	sortGroups(groups)
+	return groups, nil
+}
+
+func (r *groupRepository) FindByID(id uuid.UUID) (*model.Group, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	group, ok := r.group(id)
+	if !ok {
+		return &model.Group{}, gorm.ErrRecordNotFound
+	}
+	return &group, nil
+}
+
+func (r *groupRepository) Update(group *model.Group) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.group(group.ID); !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for id, g := range r.store.userGroups {
+		if id != group.ID && g.OrganizationID == r.org && g.Name == group.Name {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	group.OrganizationID = r.org
+
+	group.UpdatedAt = r.store.now()
+	r.store.userGroups[group.ID] = *group
+	return nil
+}
+
+func (r *groupRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.group(id); !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for key := range r.store.members {
+		if key.group == id {
+			delete(r.store.members, key)
+		}
+	}
+	for key := range r.store.access {
+		if key.group == id {
+			delete(r.store.access, key)
+		}
+	}
+	delete(r.store.userGroups, id)
+	return nil
+}
+
+func (r *groupRepository) AddMember(groupID, userID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.group(groupID); !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if _, ok := r.store.users[userID]; !ok {
+		return gorm.ErrForeignKeyViolated
+	}
+
+	key := membership{group: groupID, user: userID}
+	if _, exists := r.store.members[key]; !exists {
+		r.store.members[key] = model.GroupMember{GroupID: groupID, UserID: userID, CreatedAt: r.store.now()}
+	}
+	return nil
+}
+
+func (r *groupRepository) RemoveMember(groupID, userID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := membership{group: groupID, user: userID}
+	if _, ok := r.group(groupID); !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if _, ok := r.store.members[key]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.store.members, key)
+	return nil
+}
+
+func (r *groupRepository) FindMembers(groupID uuid.UUID) ([]model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := []model.User{}
+	for key := range r.store.members {
+		if user, ok := r.store.users[key.user]; ok && key.group == groupID && !user.DeletedAt.Valid && user.OrganizationID == r.org {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return users, nil
+}
+
+func (r *groupRepository) FindByUserID(userID uuid.UUID) ([]model.Group, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	groups := []model.Group{}
+	for key := range r.store.members {
+		if group, ok := r.group(key.group); ok && key.user == userID {
+			groups = append(groups, group)
+		}
+	}
+	sortGroups(groups)
+	return groups, nil
+}
+
+// group returns the group unless it is missing or in another
+// organization. Callers must hold the store lock.
+func (r *groupRepository) group(id uuid.UUID) (model.Group, bool) {
+	group, ok := r.store.userGroups[id]
+	if !ok || group.OrganizationID != r.org {
+		return model.Group{}, false
+	}
+	return group, true
+}
+
+func sortGroups(groups []model.Group) {
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+}
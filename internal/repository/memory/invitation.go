@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type invitationRepository struct {
+	store *Store
+	org   uuid.UUID
+}
+
+func NewInvitationRepository(store *Store) repository.InvitationRepository {
+	return &invitationRepository{store: store, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository limited to the organization ctx acts
+// for, the store has no other use for ctx
+func (r *invitationRepository) WithContext(ctx context.Context) repository.InvitationRepository {
+	return &invitationRepository{store: r.store, org: tenant.FromContext(ctx)}
+}
+
+func (r *invitationRepository) Create(invitation *model.Invitation) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, i := range r.store.invites {
+		if i.TokenHash == invitation.TokenHash {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	ensureID(&invitation.ID)
+	invitation.OrganizationID = r.org
+	invitation.ExpiresAt = invitation.ExpiresAt.UTC()
+	if invitation.CreatedAt.IsZero() {
+		invitation.CreatedAt = r.store.now()
+	}
+	r.store.invites[invitation.ID] = *invitation
+	return nil
+}
+
+func (r *invitationRepository) FindAll() ([]model.Invitation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	invitations := make([]model.Invitation, 0, len(r.store.invites))
+	for _, i := range r.store.invites {
+		if i.OrganizationID == r.org {
+			invitations = append(invitations, i)
+		}
+	}
+	sort.Slice(invitations, func(a, b int) bool { return invitations[a].CreatedAt.After(invitations[b].CreatedAt) })
+	return invitations, nil
+}
+
+func (r *invitationRepository) FindPending(tokenHash string, now time.Time) (*model.Invitation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, i := range r.store.invites {
+		if i.TokenHash == tokenHash && i.Pending(now) && i.OrganizationID == r.org {
+			return &i, nil
+		}
+	}
+	return &model.Invitation{}, gorm.ErrRecordNotFound
+}
+
+func (r *invitationRepository) Accept(id uuid.UUID, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	invitation, ok := r.store.invites[id]
+	if !ok || !invitation.Pending(now) || invitation.OrganizationID != r.org {
+		return gorm.ErrRecordNotFound
+	}
+	now = now.UTC()
+	invitation.AcceptedAt = &now
+	r.store.invites[id] = invitation
+	return nil
+}
+
+func (r *invitationRepository) Revoke(id uuid.UUID, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	invitation, ok := r.store.invites[id]
+	if !ok || invitation.AcceptedAt != nil || invitation.RevokedAt != nil || invitation.OrganizationID != r.org {
+		return gorm.ErrRecordNotFound
+	}
+	now = now.UTC()
+	invitation.RevokedAt = &now
+	r.store.invites[id] = invitation
+	return nil
+}
+
+func (r *invitationRepository) RevokePending(email string, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now = now.UTC()
+	for id, i := range r.store.invites {
+		if i.OrganizationID == r.org && i.Email == email && i.AcceptedAt == nil && i.RevokedAt == nil {
+			i.RevokedAt = &now
+			r.store.invites[id] = i
+		}
+	}
+	return nil
+}
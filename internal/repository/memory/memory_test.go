@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/riparuk/meet-book-api/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store := NewStore()
+		return repotest.Repositories{
+			Users:       NewUserRepository(store),
+			Rooms:       NewRoomRepository(store),
+			Bookings:    NewBookingRepository(store),
+			Tokens:      NewUserTokenRepository(store),
+			Settings:    NewSettingRepository(store),
+			Accounts:    NewServiceAccountRepository(store),
+			Invites:     NewInvitationRepository(store),
+			Groups:      NewGroupRepository(store),
+			Roles:       NewRoleRepository(store),
+			Orgs:        NewOrganizationRepository(store),
+			Audit:       NewAuditLogRepository(store),
+			Delegations: NewDelegationRepository(store),
+			Reports:     NewReportRepository(store),
+			Strikes:     NewStrikeRepository(store),
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"gorm.io/gorm"
+)
+
+type organizationRepository struct {
+	store *Store
+}
+
+func NewOrganizationRepository(store *Store) repository.OrganizationRepository {
+	return &organizationRepository{store: store}
+}
+
+// WithContext returns the repository unchanged, the store has no use for ctx
+func (r *organizationRepository) WithContext(ctx context.Context) repository.OrganizationRepository {
+	return r
+}
+
+func (r *organizationRepository) Create(org *model.Organization) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, o := range r.store.orgs {
+		if o.Slug == org.Slug || (o.Domain != nil && org.Domain != nil && *o.Domain == *org.Domain) {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	ensureID(&org.ID)
+	if _, exists := r.store.orgs[org.ID]; exists {
+		return gorm.ErrDuplicatedKey
+	}
+	r.store.stamp(&org.CreatedAt, &org.UpdatedAt)
+	r.store.orgs[org.ID] = *org
+	return nil
+}
+
+func (r *organizationRepository) FindAll() ([]model.Organization, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	orgs := make([]model.Organization, 0, len(r.store.orgs))
+	for _, o := range r.store.orgs {
+		orgs = append(orgs, o)
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].Name < orgs[j].Name })
+	return orgs, nil
+}
+
+func (r *organizationRepository) FindByID(id uuid.UUID) (*model.Organization, error) {
+	return r.find(func(o model.Organization) bool { return o.ID == id })
+}
+
+func (r *organizationRepository) FindBySlug(slug string) (*model.Organization, error) {
+	return r.find(func(o model.Organization) bool { return o.Slug == slug })
+}
+
+func (r *organizationRepository) FindByDomain(host string) (*model.Organization, error) {
+	return r.find(func(o model.Organization) bool { return o.Domain != nil && *o.Domain == host })
+}
+
+func (r *organizationRepository) find(match func(model.Organization) bool) (*model.Organization, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, o := range r.store.orgs {
+		if match(o) {
+			return &o, nil
+		}
+	}
+	return &model.Organization{}, gorm.ErrRecordNotFound
+}
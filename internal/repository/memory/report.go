@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+)
+
+type reportRepository struct {
+	store *Store
+	org   uuid.UUID
+}
+
+func NewReportRepository(store *Store) repository.ReportRepository {
+	return &reportRepository{store: store, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository limited to the organization ctx acts
+// for, the store has no other use for ctx
+func (r *reportRepository) WithContext(ctx context.Context) repository.ReportRepository {
+	return &reportRepository{store: r.store, org: tenant.FromContext(ctx)}
+}
+
+// utilizationKey identifies the group of an aggregate, only the fields of
+// the grouping are set
+type utilizationKey struct {
+	room     uuid.UUID
+	building string
+	floor    string
+	weekday  int
+	hour     int
+}
+
+func (r *reportRepository) Utilization(filter model.UtilizationFilter) ([]model.UtilizationAggregate, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	loc := filter.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	now := r.store.now()
+
+	var keys []utilizationKey
+	groups := make(map[utilizationKey]*model.UtilizationAggregate)
+	add := func(key utilizationKey, b model.Booking, room model.Room, booked time.Duration) {
+		a, ok := groups[key]
+		if !ok {
+			a = &model.UtilizationAggregate{RoomID: key.room, Building: key.building, Floor: key.floor, Weekday: key.weekday, Hour: key.hour}
+			groups[key] = a
+			keys = append(keys, key)
+		}
+		a.Bookings++
+		if b.Status == model.BookingStatusCancelled {
+			a.Cancelled++
+		} else {
+			a.BookedSeconds += booked.Seconds()
+			if !b.EndTime.After(now) {
+				a.Held++
+				if b.CheckedInAt == nil {
+					a.NoShows++
+				}
+			}
+		}
+		a.LeadSeconds += b.StartTime.Sub(b.CreatedAt).Seconds()
+		if b.Attendees != nil {
+			a.Attended++
+			a.Attendees += int64(*b.Attendees)
+			if room.Capacity > 0 {
+				a.CapacityShare += float64(*b.Attendees) / float64(room.Capacity)
+			}
+		}
+	}
+
+	for _, b := range r.store.bookings {
+		if b.OrganizationID != r.org || b.DeletedAt.Valid || b.StartTime.Before(filter.From) || !b.StartTime.Before(filter.To) {
+			continue
+		}
+		room, ok := r.store.rooms[b.RoomID]
+		if !ok || room.DeletedAt.Valid {
+			continue
+		}
+
+		duration := b.EndTime.Sub(b.StartTime)
+		switch filter.GroupBy {
+		case model.GroupByFloor:
+			add(utilizationKey{building: room.Building, floor: room.Floor}, b, room, duration)
+		case model.GroupByBuilding:
+			add(utilizationKey{building: room.Building}, b, room, duration)
+		case model.GroupByWeekday:
+			add(utilizationKey{weekday: int(b.StartTime.In(loc).Weekday())}, b, room, duration)
+		case model.GroupByHour:
+			// Like the SQL, count the hours of the day the booking starts
+			// and the next
+			start := b.StartTime.In(loc)
+			midnight := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+			from := start.Sub(midnight).Seconds()
+			to := from + duration.Seconds()
+			for h := 0; h < 48; h++ {
+				within := math.Min(to, float64(h+1)*3600) - math.Max(from, float64(h)*3600)
+				if within > 0 {
+					add(utilizationKey{hour: h % 24}, b, room, time.Duration(within*float64(time.Second)))
+				}
+			}
+		default:
+			add(utilizationKey{room: room.ID}, b, room, duration)
+		}
+	}
+
+	aggregates := make([]model.UtilizationAggregate, len(keys))
+	for i, key := range keys {
+		aggregates[i] = *groups[key]
+	}
+	return aggregates, nil
+}
@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type roleRepository struct {
+	store *Store
+	org   uuid.UUID
+}
+
+func NewRoleRepository(store *Store) repository.RoleRepository {
+	return &roleRepository{store: store, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository limited to the organization ctx acts
+// for, the store has no other use for ctx
+func (r *roleRepository) WithContext(ctx context.Context) repository.RoleRepository {
+	return &roleRepository{store: r.store, org: tenant.FromContext(ctx)}
+}
+
+func (r *roleRepository) Create(role *model.Role) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.roles {
+		if existing.OrganizationID == r.org && existing.Name == role.Name {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	ensureID(&role.ID)
+	role.OrganizationID = r.org
+	r.store.stamp(&role.CreatedAt, &role.UpdatedAt)
+	r.store.roles[role.ID] = copyRole(*role)
+	return nil
+}
+
+func (r *roleRepository) FindAll() ([]model.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	roles := make([]model.Role, 0, len(r.store.roles))
+	for _, role := range r.store.roles {
+		if role.OrganizationID == r.org {
+			roles = append(roles, copyRole(role))
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (r *roleRepository) FindByID(id uuid.UUID) (*model.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	role, ok := r.role(id)
+	if !ok {
+		return &model.Role{}, gorm.ErrRecordNotFound
+	}
+	role = copyRole(role)
+	return &role, nil
+}
+
+func (r *roleRepository) Update(role *model.Role) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.role(role.ID); !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for id, existing := range r.store.roles {
+		if id != role.ID && existing.OrganizationID == r.org && existing.Name == role.Name {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	role.OrganizationID = r.org
+
+	role.UpdatedAt = r.store.now()
+	r.store.roles[role.ID] = copyRole(*role)
+	return nil
+}
+
+func (r *roleRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.role(id); !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for assignmentID, a := range r.store.assignments {
+		if a.RoleID == id {
+			delete(r.store.assignments, assignmentID)
+		}
+	}
+	delete(r.store.roles, id)
+	return nil
+}
+
+func (r *roleRepository) Assign(assignment *model.RoleAssignment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.role(assignment.RoleID); !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if _, ok := r.store.users[assignment.UserID]; !ok {
+		return gorm.ErrForeignKeyViolated
+	}
+
+	ensureID(&assignment.ID)
+	if assignment.CreatedAt.IsZero() {
+		assignment.CreatedAt = r.store.now()
+	}
+	stored := *assignment
+	stored.Role, stored.User = model.Role{}, model.User{}
+	r.store.assignments[assignment.ID] = stored
+	return nil
+}
+
+func (r *roleRepository) Unassign(userID, assignmentID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	a, ok := r.store.assignments[assignmentID]
+	if _, owned := r.role(a.RoleID); !ok || !owned || a.UserID != userID {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.store.assignments, assignmentID)
+	return nil
+}
+
+func (r *roleRepository) FindAssignments(userID uuid.UUID) ([]model.RoleAssignment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	assignments := []model.RoleAssignment{}
+	for _, a := range r.store.assignments {
+		if role, ok := r.role(a.RoleID); ok && a.UserID == userID {
+			a.Role = copyRole(role)
+			assignments = append(assignments, a)
+		}
+	}
+	sort.SliceStable(assignments, func(i, j int) bool { return assignments[i].CreatedAt.Before(assignments[j].CreatedAt) })
+	return assignments, nil
+}
+
+// role returns the role unless it is missing or in another organization.
+// Callers must hold the store lock.
+func (r *roleRepository) role(id uuid.UUID) (model.Role, bool) {
+	role, ok := r.store.roles[id]
+	if !ok || role.OrganizationID != r.org {
+		return model.Role{}, false
+	}
+	return role, true
+}
+
+// copyRole keeps callers from changing stored permissions through the
+// shared slice
+func copyRole(role model.Role) model.Role {
+	role.Permissions = slices.Clone(role.Permissions)
+	return role
+}
@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type roomRepository struct {
+	store *Store
+	org   uuid.UUID
+}
+
+func NewRoomRepository(store *Store) repository.RoomRepository {
+	return &roomRepository{store: store, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository limited to the organization ctx acts
+// for, the store has no other use for ctx
+func (r *roomRepository) WithContext(ctx context.Context) repository.RoomRepository {
+	return &roomRepository{store: r.store, org: tenant.FromContext(ctx)}
+}
+
+func (r *roomRepository) FindAll() ([]model.Room, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rooms := make([]model.Room, 0, len(r.store.roomOrder))
+	for _, id := range r.store.roomOrder {
+		if room, ok := r.room(id); ok {
+			rooms = append(rooms, room)
+		}
+	}
+	return rooms, nil
+}
+
+func (r *roomRepository) Create(room *model.Room) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	ensureID(&room.ID)
+	if _, exists := r.store.rooms[room.ID]; exists {
+		return gorm.ErrDuplicatedKey
+	}
+	room.OrganizationID = r.org
+	r.store.stamp(&room.CreatedAt, &room.UpdatedAt)
+
+	r.store.rooms[room.ID] = *room
+	r.store.roomOrder = append(r.store.roomOrder, room.ID)
+	return nil
+}
+
+func (r *roomRepository) FindByID(id uuid.UUID) (*model.Room, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	room, ok := r.room(id)
+	if !ok {
+		return nil, nil
+	}
+	return &room, nil
+}
+
+func (r *roomRepository) Update(room *model.Room) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.room(room.ID); !ok {
+		return gorm.ErrRecordNotFound
+	}
+	room.OrganizationID = r.org
+	room.UpdatedAt = r.store.now()
+	r.store.rooms[room.ID] = *room
+	return nil
+}
+
+func (r *roomRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	room, ok := r.room(id)
+	if !ok {
+		return nil
+	}
+	room.DeletedAt = gorm.DeletedAt{Time: r.store.now(), Valid: true}
+	r.store.rooms[id] = room
+	return nil
+}
+
+func (r *roomRepository) FindAllForUser(userID uuid.UUID) ([]model.Room, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rooms := make([]model.Room, 0, len(r.store.roomOrder))
+	for _, id := range r.store.roomOrder {
+		room, ok := r.room(id)
+		if ok && (!room.Restricted || r.store.granted(id, userID)) {
+			rooms = append(rooms, room)
+		}
+	}
+	return rooms, nil
+}
+
+func (r *roomRepository) HasAccess(roomID, userID uuid.UUID) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if _, ok := r.room(roomID); !ok {
+		return false, nil
+	}
+	return r.store.granted(roomID, userID), nil
+}
+
+func (r *roomRepository) FindAccess(roomID uuid.UUID) ([]model.Group, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	groups := []model.Group{}
+	if _, ok := r.room(roomID); !ok {
+		return groups, nil
+	}
+	for key := range r.store.access {
+		if key.room == roomID {
+			groups = append(groups, r.store.userGroups[key.group])
+		}
+	}
+	sortGroups(groups)
+	return groups, nil
+}
+
+func (r *roomRepository) SetAccess(roomID uuid.UUID, groupIDs []uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if room, ok := r.store.rooms[roomID]; !ok || room.OrganizationID != r.org {
+		return gorm.ErrForeignKeyViolated
+	}
+	for _, groupID := range groupIDs {
+		if group, ok := r.store.userGroups[groupID]; !ok || group.OrganizationID != r.org {
+			return gorm.ErrForeignKeyViolated
+		}
+	}
+
+	for key := range r.store.access {
+		if key.room == roomID {
+			delete(r.store.access, key)
+		}
+	}
+	now := r.store.now()
+	for _, groupID := range groupIDs {
+		key := grant{room: roomID, group: groupID}
+		if _, exists := r.store.access[key]; exists {
+			return gorm.ErrDuplicatedKey
+		}
+		r.store.access[key] = model.RoomAccess{RoomID: roomID, GroupID: groupID, CreatedAt: now}
+	}
+	return nil
+}
+
+// granted reports whether one of the user's groups may book the room.
+// Callers must hold the store lock.
+func (s *Store) granted(roomID, userID uuid.UUID) bool {
+	for key := range s.access {
+		if key.room != roomID {
+			continue
+		}
+		if _, ok := s.members[membership{group: key.group, user: userID}]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// room returns the room unless it is missing, soft deleted or in another
+// organization. Callers must hold the store lock.
+func (r *roomRepository) room(id uuid.UUID) (model.Room, bool) {
+	room, ok := r.store.activeRoom(id)
+	if !ok || room.OrganizationID != r.org {
+		return model.Room{}, false
+	}
+	return room, true
+}
+
+// activeRoom returns the room unless it is missing or soft deleted.
+// Callers must hold the store lock.
+func (s *Store) activeRoom(id uuid.UUID) (model.Room, bool) {
+	room, ok := s.rooms[id]
+	if !ok || room.DeletedAt.Valid {
+		return model.Room{}, false
+	}
+	return room, true
+}
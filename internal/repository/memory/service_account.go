@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type serviceAccountRepository struct {
+	store *Store
+	org   uuid.UUID
+}
+
+func NewServiceAccountRepository(store *Store) repository.ServiceAccountRepository {
+	return &serviceAccountRepository{store: store, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository limited to the organization ctx acts
+// for, the store has no other use for ctx
+func (r *serviceAccountRepository) WithContext(ctx context.Context) repository.ServiceAccountRepository {
+	return &serviceAccountRepository{store: r.store, org: tenant.FromContext(ctx)}
+}
+
+func (r *serviceAccountRepository) Create(account *model.ServiceAccount) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, a := range r.store.accounts {
+		if a.OrganizationID == r.org && a.Name == account.Name {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	ensureID(&account.ID)
+	account.OrganizationID = r.org
+	r.store.stamp(&account.CreatedAt, &account.UpdatedAt)
+	r.store.accounts[account.ID] = *account
+	return nil
+}
+
+func (r *serviceAccountRepository) FindAll() ([]model.ServiceAccount, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	accounts := make([]model.ServiceAccount, 0, len(r.store.accounts))
+	for _, a := range r.store.accounts {
+		if a.OrganizationID == r.org {
+			accounts = append(accounts, a)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Name < accounts[j].Name })
+	return accounts, nil
+}
+
+func (r *serviceAccountRepository) FindByID(id uuid.UUID) (*model.ServiceAccount, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	account, ok := r.account(id)
+	if !ok {
+		return &model.ServiceAccount{}, gorm.ErrRecordNotFound
+	}
+	return &account, nil
+}
+
+func (r *serviceAccountRepository) Delete(id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.account(id); !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for keyID, k := range r.store.apiKeys {
+		if k.ServiceAccountID == id {
+			delete(r.store.apiKeys, keyID)
+		}
+	}
+	delete(r.store.accounts, id)
+	return nil
+}
+
+func (r *serviceAccountRepository) CreateKey(key *model.APIKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.account(key.ServiceAccountID); !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for _, k := range r.store.apiKeys {
+		if k.KeyHash == key.KeyHash {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	ensureID(&key.ID)
+	if key.ExpiresAt != nil {
+		utc := key.ExpiresAt.UTC()
+		key.ExpiresAt = &utc
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = r.store.now()
+	}
+	r.store.apiKeys[key.ID] = *key
+	return nil
+}
+
+func (r *serviceAccountRepository) FindKeys(accountID uuid.UUID) ([]model.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var keys []model.APIKey
+	if _, ok := r.account(accountID); !ok {
+		return keys, nil
+	}
+	for _, k := range r.store.apiKeys {
+		if k.ServiceAccountID == accountID {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+func (r *serviceAccountRepository) FindKeyByHash(keyHash string) (*model.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, k := range r.store.apiKeys {
+		if k.KeyHash == keyHash {
+			k.ServiceAccount = r.store.accounts[k.ServiceAccountID]
+			return &k, nil
+		}
+	}
+	return &model.APIKey{}, gorm.ErrRecordNotFound
+}
+
+func (r *serviceAccountRepository) RevokeKey(accountID, keyID uuid.UUID, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key, ok := r.store.apiKeys[keyID]
+	if _, owned := r.account(accountID); !ok || !owned || key.ServiceAccountID != accountID {
+		return gorm.ErrRecordNotFound
+	}
+	if key.RevokedAt == nil {
+		now = now.UTC()
+		key.RevokedAt = &now
+		r.store.apiKeys[keyID] = key
+	}
+	return nil
+}
+
+func (r *serviceAccountRepository) TouchKey(keyID uuid.UUID, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key, ok := r.store.apiKeys[keyID]
+	if _, owned := r.account(key.ServiceAccountID); ok && owned {
+		now = now.UTC()
+		key.LastUsedAt = &now
+		r.store.apiKeys[keyID] = key
+	}
+	return nil
+}
+
+// account returns the service account unless it is missing or in another
+// organization. Callers must hold the store lock.
+func (r *serviceAccountRepository) account(id uuid.UUID) (model.ServiceAccount, bool) {
+	account, ok := r.store.accounts[id]
+	if !ok || account.OrganizationID != r.org {
+		return model.ServiceAccount{}, false
+	}
+	return account, true
+}
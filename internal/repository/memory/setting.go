@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+)
+
+type settingRepository struct {
+	store *Store
+	org   uuid.UUID
+}
+
+func NewSettingRepository(store *Store) repository.SettingRepository {
+	return &settingRepository{store: store, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository limited to the organization ctx acts
+// for, the store has no other use for ctx
+func (r *settingRepository) WithContext(ctx context.Context) repository.SettingRepository {
+	return &settingRepository{store: r.store, org: tenant.FromContext(ctx)}
+}
+
+func (r *settingRepository) Get(key, def string) (string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if value, ok := r.store.settings[settingKey{org: r.org, key: key}]; ok {
+		return value, nil
+	}
+	return def, nil
+}
+
+func (r *settingRepository) Set(key, value string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.settings[settingKey{org: r.org, key: key}] = value
+	return nil
+}
@@ -0,0 +1,109 @@
+// Package memory implements the repository interfaces on top of in-memory
+// maps. It follows the same semantics as the GORM repositories, including
+// overlap checks, soft deletes and ordering, and is meant for tests and
+// demos where running a database is not practical.
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+)
+
+// Store holds the data shared by the memory repositories. Repositories
+// created from the same Store see each other's records, which is needed to
+// resolve booking relationships.
+type Store struct {
+	mu       sync.RWMutex
+	users    map[uuid.UUID]model.User
+	rooms    map[uuid.UUID]model.Room
+	groups   map[uuid.UUID]model.BookingGroup
+	bookings map[uuid.UUID]model.Booking
+	tokens   map[uuid.UUID]model.UserToken
+	settings map[settingKey]string
+	accounts map[uuid.UUID]model.ServiceAccount
+	apiKeys  map[uuid.UUID]model.APIKey
+	invites  map[uuid.UUID]model.Invitation
+	// userGroups are the groups of users, groups are booking groups
+	userGroups  map[uuid.UUID]model.Group
+	members     map[membership]model.GroupMember
+	access      map[grant]model.RoomAccess
+	roles       map[uuid.UUID]model.Role
+	assignments map[uuid.UUID]model.RoleAssignment
+	orgs        map[uuid.UUID]model.Organization
+	delegations map[delegation]model.Delegation
+	strikes     map[uuid.UUID]model.Strike
+	// auditLogs are only ever appended to, oldest first
+	auditLogs []model.AuditLog
+
+	// insertion order, FindAll returns records in the order they were created
+	userOrder []uuid.UUID
+	roomOrder []uuid.UUID
+
+	// now is overridable so tests can control timestamps
+	now func() time.Time
+}
+
+// NewStore returns an empty store holding only the default organization,
+// like a freshly migrated database
+func NewStore() *Store {
+	s := &Store{
+		users:       make(map[uuid.UUID]model.User),
+		rooms:       make(map[uuid.UUID]model.Room),
+		groups:      make(map[uuid.UUID]model.BookingGroup),
+		bookings:    make(map[uuid.UUID]model.Booking),
+		tokens:      make(map[uuid.UUID]model.UserToken),
+		settings:    make(map[settingKey]string),
+		accounts:    make(map[uuid.UUID]model.ServiceAccount),
+		apiKeys:     make(map[uuid.UUID]model.APIKey),
+		invites:     make(map[uuid.UUID]model.Invitation),
+		userGroups:  make(map[uuid.UUID]model.Group),
+		members:     make(map[membership]model.GroupMember),
+		access:      make(map[grant]model.RoomAccess),
+		roles:       make(map[uuid.UUID]model.Role),
+		assignments: make(map[uuid.UUID]model.RoleAssignment),
+		orgs:        make(map[uuid.UUID]model.Organization),
+		delegations: make(map[delegation]model.Delegation),
+		strikes:     make(map[uuid.UUID]model.Strike),
+		now:         time.Now,
+	}
+	s.orgs[model.DefaultOrganizationID] = model.Organization{
+		ID:        model.DefaultOrganizationID,
+		Name:      model.DefaultOrganizationName,
+		Slug:      model.DefaultOrganizationSlug,
+		CreatedAt: s.now(),
+		UpdatedAt: s.now(),
+	}
+	return s
+}
+
+// membership, grant and delegation key the composite primary keys of
+// group members, room access and delegations
+type membership struct{ group, user uuid.UUID }
+type grant struct{ room, group uuid.UUID }
+type delegation struct{ principal, delegate uuid.UUID }
+
+// settingKey is the primary key of settings, each organization has its own
+type settingKey struct {
+	org uuid.UUID
+	key string
+}
+
+func ensureID(id *uuid.UUID) {
+	if *id == uuid.Nil {
+		*id = uuid.New()
+	}
+}
+
+// stamp sets CreatedAt and UpdatedAt the way GORM does on create
+func (s *Store) stamp(createdAt, updatedAt *time.Time) {
+	now := s.now()
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	if updatedAt.IsZero() {
+		*updatedAt = now
+	}
+}
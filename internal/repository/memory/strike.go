@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type strikeRepository struct {
+	store *Store
+	org   uuid.UUID
+}
+
+func NewStrikeRepository(store *Store) repository.StrikeRepository {
+	return &strikeRepository{store: store, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository limited to the organization ctx acts
+// for, the store has no other use for ctx
+func (r *strikeRepository) WithContext(ctx context.Context) repository.StrikeRepository {
+	return &strikeRepository{store: r.store, org: tenant.FromContext(ctx)}
+}
+
+func (r *strikeRepository) Create(strike *model.Strike) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, s := range r.store.strikes {
+		if s.BookingID == strike.BookingID {
+			return false, nil
+		}
+	}
+	if _, ok := r.store.bookings[strike.BookingID]; !ok {
+		return false, gorm.ErrForeignKeyViolated
+	}
+	if _, ok := r.store.users[strike.UserID]; !ok {
+		return false, gorm.ErrForeignKeyViolated
+	}
+
+	ensureID(&strike.ID)
+	strike.OrganizationID = r.org
+	if strike.CreatedAt.IsZero() {
+		strike.CreatedAt = r.store.now()
+	}
+	stored := *strike
+	stored.User, stored.Booking = model.User{}, model.Booking{}
+	r.store.strikes[strike.ID] = stored
+	return true, nil
+}
+
+func (r *strikeRepository) FindByUserID(userID uuid.UUID, since time.Time) ([]model.Strike, error) {
+	return r.find(func(s model.Strike) bool {
+		return s.UserID == userID && !s.OccurredAt.Before(since)
+	}), nil
+}
+
+func (r *strikeRepository) FindSince(since time.Time) ([]model.Strike, error) {
+	return r.find(func(s model.Strike) bool {
+		return !s.OccurredAt.Before(since)
+	}), nil
+}
+
+// find returns the organization's uncleared strikes matching keep, newest
+// first
+func (r *strikeRepository) find(keep func(model.Strike) bool) []model.Strike {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var strikes []model.Strike
+	for _, s := range r.store.strikes {
+		if s.OrganizationID == r.org && s.ClearedAt == nil && keep(s) {
+			strikes = append(strikes, s)
+		}
+	}
+	sort.Slice(strikes, func(i, j int) bool { return strikes[i].OccurredAt.After(strikes[j].OccurredAt) })
+	return strikes
+}
+
+func (r *strikeRepository) Clear(userID, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	s, ok := r.store.strikes[id]
+	if !ok || s.OrganizationID != r.org || s.UserID != userID || s.ClearedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+	now := r.store.now()
+	s.ClearedAt = &now
+	r.store.strikes[id] = s
+	return nil
+}
+
+func (r *strikeRepository) ClearAll(userID uuid.UUID) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.now()
+	var cleared int64
+	for id, s := range r.store.strikes {
+		if s.OrganizationID == r.org && s.UserID == userID && s.ClearedAt == nil {
+			s.ClearedAt = &now
+			r.store.strikes[id] = s
+			cleared++
+		}
+	}
+	return cleared, nil
+}
+
+func (r *strikeRepository) FindNoShows(from, to time.Time) ([]model.Booking, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	struck := make(map[uuid.UUID]bool)
+	for _, s := range r.store.strikes {
+		struck[s.BookingID] = true
+	}
+
+	var bookings []model.Booking
+	for _, b := range r.store.bookings {
+		if b.OrganizationID != r.org || b.DeletedAt.Valid || b.Status != model.BookingStatusActive || b.CheckedInAt != nil || struck[b.ID] {
+			continue
+		}
+		if b.EndTime.Before(from) || !b.EndTime.Before(to) {
+			continue
+		}
+		if _, ok := r.store.activeRoom(b.RoomID); !ok {
+			continue
+		}
+		bookings = append(bookings, r.store.preload(b))
+	}
+	sort.Slice(bookings, func(i, j int) bool { return bookings[i].EndTime.Before(bookings[j].EndTime) })
+	return bookings, nil
+}
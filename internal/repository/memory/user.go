@@ -0,0 +1,206 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type userRepository struct {
+	store *Store
+	org   uuid.UUID
+}
+
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &userRepository{store: store, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository limited to the organization ctx acts
+// for, the store has no other use for ctx
+func (r *userRepository) WithContext(ctx context.Context) repository.UserRepository {
+	return &userRepository{store: r.store, org: tenant.FromContext(ctx)}
+}
+
+func (r *userRepository) FindAll() ([]model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]model.User, 0, len(r.store.userOrder))
+	for _, id := range r.store.userOrder {
+		if u := r.store.users[id]; !u.DeletedAt.Valid && u.OrganizationID == r.org {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (r *userRepository) Create(user *model.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, u := range r.store.users {
+		if u.OrganizationID == r.org && u.Email == user.Email {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	ensureID(&user.ID)
+	user.OrganizationID = r.org
+	if _, exists := r.store.users[user.ID]; exists || r.oidcTaken(user) {
+		return gorm.ErrDuplicatedKey
+	}
+	if user.Role == "" {
+		user.Role = model.RoleUser
+	}
+	if user.Status == "" {
+		user.Status = model.UserStatusActive
+	}
+	if user.Timezone == "" {
+		user.Timezone = "UTC"
+	}
+	if user.Locale == "" {
+		user.Locale = "en"
+	}
+	// A false bool is a zero value, so like GORM the column default wins
+	user.Notifications.BookingUpdates = true
+	r.store.stamp(&user.CreatedAt, &user.UpdatedAt)
+
+	r.store.users[user.ID] = *user
+	r.store.userOrder = append(r.store.userOrder, user.ID)
+	return nil
+}
+
+func (r *userRepository) FindByID(id string) (*model.User, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return &model.User{}, gorm.ErrRecordNotFound
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[uid]
+	if !ok || user.DeletedAt.Valid || user.OrganizationID != r.org {
+		return &model.User{}, gorm.ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmail(email string) (*model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, u := range r.store.users {
+		if strings.EqualFold(u.Email, email) && !u.DeletedAt.Valid && u.OrganizationID == r.org {
+			user := u
+			return &user, nil
+		}
+	}
+	return &model.User{}, gorm.ErrRecordNotFound
+}
+
+func (r *userRepository) FindByOIDCSubject(issuer, subject string) (*model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, u := range r.store.users {
+		if sameOIDCSubject(u, issuer, subject) && !u.DeletedAt.Valid && u.OrganizationID == r.org {
+			user := u
+			return &user, nil
+		}
+	}
+	return &model.User{}, gorm.ErrRecordNotFound
+}
+
+func sameOIDCSubject(u model.User, issuer, subject string) bool {
+	return u.OIDCIssuer != nil && u.OIDCSubject != nil && *u.OIDCIssuer == issuer && *u.OIDCSubject == subject
+}
+
+// oidcTaken reports whether another user of the organization is linked to
+// the same identity
+func (r *userRepository) oidcTaken(user *model.User) bool {
+	if user.OIDCIssuer == nil || user.OIDCSubject == nil {
+		return false
+	}
+	for id, u := range r.store.users {
+		if id != user.ID && u.OrganizationID == r.org && sameOIDCSubject(u, *user.OIDCIssuer, *user.OIDCSubject) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *userRepository) Update(user *model.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if existing, ok := r.store.users[user.ID]; !ok || existing.DeletedAt.Valid || existing.OrganizationID != r.org {
+		return gorm.ErrRecordNotFound
+	}
+	user.OrganizationID = r.org
+	for id, u := range r.store.users {
+		if id != user.ID && u.OrganizationID == r.org && u.Email == user.Email {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	if r.oidcTaken(user) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	user.UpdatedAt = r.store.now()
+	r.store.users[user.ID] = *user
+	return nil
+}
+
+func (r *userRepository) CountActiveAdmins() (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, u := range r.store.users {
+		if u.OrganizationID == r.org && u.Role == model.RoleAdmin && !u.Deactivated() && !u.DeletedAt.Valid {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *userRepository) Delete(id string) error {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[uid]
+	if !ok || user.DeletedAt.Valid || user.OrganizationID != r.org {
+		return gorm.ErrRecordNotFound
+	}
+	user.Email = repository.DeletedUserEmail(id)
+	user.OIDCIssuer, user.OIDCSubject = nil, nil
+	user.DeletedAt = gorm.DeletedAt{Time: r.store.now(), Valid: true}
+	r.store.users[uid] = user
+	for key := range r.store.members {
+		if key.user == uid {
+			delete(r.store.members, key)
+		}
+	}
+	for assignmentID, a := range r.store.assignments {
+		if a.UserID == uid {
+			delete(r.store.assignments, assignmentID)
+		}
+	}
+	for key := range r.store.delegations {
+		if key.principal == uid || key.delegate == uid {
+			delete(r.store.delegations, key)
+		}
+	}
+	return nil
+}
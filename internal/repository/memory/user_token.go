@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"gorm.io/gorm"
+)
+
+type userTokenRepository struct {
+	store *Store
+}
+
+func NewUserTokenRepository(store *Store) repository.UserTokenRepository {
+	return &userTokenRepository{store: store}
+}
+
+// WithContext returns the repository unchanged, the store has no use for ctx
+func (r *userTokenRepository) WithContext(ctx context.Context) repository.UserTokenRepository {
+	return r
+}
+
+func (r *userTokenRepository) Create(token *model.UserToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[token.UserID]; !ok {
+		return gorm.ErrForeignKeyViolated
+	}
+	for _, t := range r.store.tokens {
+		if t.TokenHash == token.TokenHash {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	ensureID(&token.ID)
+	token.ExpiresAt = token.ExpiresAt.UTC()
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = r.store.now()
+	}
+	r.store.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *userTokenRepository) Consume(purpose model.TokenPurpose, tokenHash string, now time.Time) (*model.UserToken, error) {
+	return r.consume(nil, purpose, tokenHash, now)
+}
+
+func (r *userTokenRepository) ConsumeFor(userID uuid.UUID, purpose model.TokenPurpose, tokenHash string, now time.Time) (*model.UserToken, error) {
+	return r.consume(&userID, purpose, tokenHash, now)
+}
+
+func (r *userTokenRepository) consume(userID *uuid.UUID, purpose model.TokenPurpose, tokenHash string, now time.Time) (*model.UserToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now = now.UTC()
+	for id, t := range r.store.tokens {
+		if t.TokenHash != tokenHash || t.Purpose != purpose || t.UsedAt != nil || !t.ExpiresAt.After(now) {
+			continue
+		}
+		if userID != nil && t.UserID != *userID {
+			continue
+		}
+		t.UsedAt = &now
+		r.store.tokens[id] = t
+		return &t, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *userTokenRepository) RevokeAll(userID uuid.UUID, purpose model.TokenPurpose) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.now().UTC()
+	for id, t := range r.store.tokens {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			t.UsedAt = &now
+			r.store.tokens[id] = t
+		}
+	}
+	return nil
+}
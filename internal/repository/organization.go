@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"gorm.io/gorm"
+)
+
+// OrganizationRepository manages the tenants themselves, unlike the other
+// repositories it is not limited to one organization
+type OrganizationRepository interface {
+	WithContext(ctx context.Context) OrganizationRepository
+	Create(org *model.Organization) error
+	// FindAll returns every organization ordered by name
+	FindAll() ([]model.Organization, error)
+	FindByID(id uuid.UUID) (*model.Organization, error)
+	FindBySlug(slug string) (*model.Organization, error)
+	// FindByDomain returns the organization served on host, or
+	// gorm.ErrRecordNotFound
+	FindByDomain(host string) (*model.Organization, error)
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx
+func (r *organizationRepository) WithContext(ctx context.Context) OrganizationRepository {
+	return &organizationRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *organizationRepository) Create(org *model.Organization) error {
+	return r.db.Create(org).Error
+}
+
+func (r *organizationRepository) FindAll() ([]model.Organization, error) {
+	var orgs []model.Organization
+	err := r.db.Order("name").Find(&orgs).Error
+	return orgs, err
+}
+
+func (r *organizationRepository) FindByID(id uuid.UUID) (*model.Organization, error) {
+	var org model.Organization
+	err := r.db.First(&org, "id = ?", id).Error
+	return &org, err
+}
+
+func (r *organizationRepository) FindBySlug(slug string) (*model.Organization, error) {
+	var org model.Organization
+	err := r.db.First(&org, "slug = ?", slug).Error
+	return &org, err
+}
+
+func (r *organizationRepository) FindByDomain(host string) (*model.Organization, error) {
+	var org model.Organization
+	err := r.db.First(&org, "domain = ?", host).Error
+	return &org, err
+}
+
+// updateAll writes every field of value to its row. Unlike Save it never
+// falls back to an upsert, so a query limited to one organization cannot
+// overwrite a row of another. It returns gorm.ErrRecordNotFound when no row
+// matched.
+func updateAll(db *gorm.DB, value any) error {
+	result := db.Select("*").Updates(value)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
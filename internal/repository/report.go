@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type ReportRepository interface {
+	WithContext(ctx context.Context) ReportRepository
+	// Utilization sums up the bookings starting in the filter's range by
+	// its grouping, leaving out deleted bookings and rooms
+	Utilization(filter model.UtilizationFilter) ([]model.UtilizationAggregate, error)
+}
+
+type reportRepository struct {
+	db  *gorm.DB
+	org uuid.UUID
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{db: db, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository whose queries run with ctx, limited to
+// the organization ctx acts for
+func (r *reportRepository) WithContext(ctx context.Context) ReportRepository {
+	return &reportRepository{db: r.db.WithContext(ctx), org: tenant.FromContext(ctx)}
+}
+
+// reportSQL holds the expressions that differ between Postgres and SQLite
+type reportSQL struct {
+	// seconds is the number of seconds from one timestamp to another
+	seconds func(from, to string) string
+	// weekday and secondOfDay read a timestamp in the report's timezone
+	weekday     func(ts string) string
+	secondOfDay func(ts string) string
+	least       string
+	greatest    string
+}
+
+// reportDialect returns the expressions for db. SQLite has no timezone
+// database, it counts weekdays and hours with the UTC offset loc has at
+// the start of the report.
+func reportDialect(db *gorm.DB, loc *time.Location, from time.Time) reportSQL {
+	if db.Dialector.Name() == "postgres" {
+		zone := "'" + strings.ReplaceAll(loc.String(), "'", "''") + "'"
+		return reportSQL{
+			seconds: func(from, to string) string {
+				return fmt.Sprintf("EXTRACT(EPOCH FROM (%s - %s))", to, from)
+			},
+			weekday: func(ts string) string {
+				return fmt.Sprintf("CAST(EXTRACT(DOW FROM %s AT TIME ZONE %s) AS INTEGER)", ts, zone)
+			},
+			secondOfDay: func(ts string) string {
+				return fmt.Sprintf("EXTRACT(EPOCH FROM CAST(%s AT TIME ZONE %s AS time))", ts, zone)
+			},
+			least:    "LEAST",
+			greatest: "GREATEST",
+		}
+	}
+
+	_, offset := from.In(loc).Zone()
+	shift := fmt.Sprintf("'%+d seconds'", offset)
+	return reportSQL{
+		seconds: func(from, to string) string {
+			// julianday is a float, whole seconds keep bookings that end on
+			// the hour out of the next one
+			return fmt.Sprintf("ROUND((julianday(%s) - julianday(%s)) * 86400)", to, from)
+		},
+		weekday: func(ts string) string {
+			return fmt.Sprintf("CAST(strftime('%%w', %s, %s) AS INTEGER)", ts, shift)
+		},
+		secondOfDay: func(ts string) string {
+			return fmt.Sprintf("(CAST(strftime('%%s', %s, %s) AS INTEGER) %% 86400)", ts, shift)
+		},
+		least:    "MIN",
+		greatest: "MAX",
+	}
+}
+
+func (r *reportRepository) Utilization(filter model.UtilizationFilter) ([]model.UtilizationAggregate, error) {
+	loc := filter.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	d := reportDialect(r.db, loc, filter.From)
+	now := time.Now().UTC()
+
+	// booked is the time a booking takes up in its group, within the hour
+	// of day when grouped by hour
+	booked := d.seconds("b.start_time", "b.end_time")
+	from := "bookings b JOIN rooms r ON r.id = b.room_id AND r.deleted_at IS NULL"
+	with := ""
+	var keys []string
+	switch filter.GroupBy {
+	case model.GroupByFloor:
+		keys = []string{"r.building", "r.floor"}
+	case model.GroupByBuilding:
+		keys = []string{"r.building"}
+	case model.GroupByWeekday:
+		keys = []string{d.weekday("b.start_time") + " AS weekday"}
+	case model.GroupByHour:
+		// hours counts the hours of the day a booking starts and the next,
+		// so bookings past midnight count in the early hours
+		with = "WITH RECURSIVE hours(h) AS (SELECT 0 UNION ALL SELECT h + 1 FROM hours WHERE h < 47) "
+		start := d.secondOfDay("b.start_time")
+		end := fmt.Sprintf("(%s + %s)", start, d.seconds("b.start_time", "b.end_time"))
+		booked = fmt.Sprintf("(%s(%s, (hours.h + 1) * 3600) - %s(%s, hours.h * 3600))", d.least, end, d.greatest, start)
+		from += " JOIN hours ON " + booked + " > 0"
+		keys = []string{"hours.h % 24 AS hour"}
+	default:
+		keys = []string{"b.room_id"}
+	}
+
+	groups := make([]string, len(keys))
+	for i, key := range keys {
+		groups[i] = key
+		if alias := strings.LastIndex(key, " AS "); alias >= 0 {
+			groups[i] = key[:alias]
+		}
+	}
+
+	query := with + "SELECT " + strings.Join(keys, ", ") + `,
+		COUNT(*) AS bookings,
+		SUM(CASE WHEN b.status = @cancelled THEN 1 ELSE 0 END) AS cancelled,
+		SUM(CASE WHEN b.status <> @cancelled AND b.end_time <= @now THEN 1 ELSE 0 END) AS held,
+		SUM(CASE WHEN b.status <> @cancelled AND b.end_time <= @now AND b.checked_in_at IS NULL THEN 1 ELSE 0 END) AS no_shows,
+		SUM(CASE WHEN b.status <> @cancelled THEN ` + booked + ` ELSE 0 END) AS booked_seconds,
+		SUM(` + d.seconds("b.created_at", "b.start_time") + `) AS lead_seconds,
+		SUM(CASE WHEN b.attendees IS NOT NULL THEN 1 ELSE 0 END) AS attended,
+		SUM(COALESCE(b.attendees, 0)) AS attendees,
+		SUM(CASE WHEN b.attendees IS NOT NULL AND r.capacity > 0 THEN 1.0 * b.attendees / r.capacity ELSE 0 END) AS capacity_share
+	FROM ` + from + `
+	WHERE b.organization_id = @org AND b.deleted_at IS NULL
+		AND b.start_time >= @from AND b.start_time < @to
+	GROUP BY ` + strings.Join(groups, ", ")
+
+	var aggregates []model.UtilizationAggregate
+	err := r.db.Raw(query, map[string]any{
+		"cancelled": model.BookingStatusCancelled,
+		"now":       now,
+		"org":       r.org,
+		"from":      filter.From.UTC(),
+		"to":        filter.To.UTC(),
+	}).Scan(&aggregates).Error
+	return aggregates, err
+}
@@ -0,0 +1,110 @@
+package repository_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/database"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/repository/repotest"
+	"gorm.io/gorm"
+)
+
+func gormRepositories(db *gorm.DB) repotest.Repositories {
+	return repotest.Repositories{
+		Users:       repository.NewUserRepository(db),
+		Rooms:       repository.NewRoomRepository(db),
+		Bookings:    repository.NewBookingRepository(db),
+		Tokens:      repository.NewUserTokenRepository(db),
+		Settings:    repository.NewSettingRepository(db),
+		Accounts:    repository.NewServiceAccountRepository(db),
+		Invites:     repository.NewInvitationRepository(db),
+		Groups:      repository.NewGroupRepository(db),
+		Roles:       repository.NewRoleRepository(db),
+		Orgs:        repository.NewOrganizationRepository(db),
+		Audit:       repository.NewAuditLogRepository(db),
+		Delegations: repository.NewDelegationRepository(db),
+		Reports:     repository.NewReportRepository(db),
+		Strikes:     repository.NewStrikeRepository(db),
+	}
+}
+
+// openSQLite returns a migrated database in a temporary file
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(database.DriverSQLite, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestConformanceSQLite(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		return gormRepositories(openSQLite(t))
+	})
+}
+
+// TestAuditLogAppendOnly checks the database itself refuses to change audit
+// log entries, whatever query tries
+func TestAuditLogAppendOnly(t *testing.T) {
+	db := openSQLite(t)
+	entry := model.AuditLog{
+		ActorType:  model.AuditActorSystem,
+		Action:     model.AuditActionCreate,
+		EntityType: model.AuditEntityRoom,
+		EntityID:   uuid.New(),
+	}
+	if err := repository.NewAuditLogRepository(db).Create(&entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Model(&model.AuditLog{}).Where("id = ?", entry.ID).Update("action", model.AuditActionDelete).Error; err == nil {
+		t.Fatal("audit log entry updated")
+	}
+	if err := db.Where("id = ?", entry.ID).Delete(&model.AuditLog{}).Error; err == nil {
+		t.Fatal("audit log entry deleted")
+	}
+	// Migrating again keeps the protection in place
+	if err := database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("DELETE FROM audit_logs").Error; err == nil {
+		t.Fatal("audit log cleared after migrating again")
+	}
+}
+
+// TestConformancePostgres runs against the database in TEST_DATABASE_URL.
+// Every table is dropped between tests, never point it at real data.
+func TestConformancePostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := database.Open(database.DriverPostgres, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		if err := database.DropAll(db); err != nil {
+			t.Fatal(err)
+		}
+		if err := database.Migrate(db); err != nil {
+			t.Fatal(err)
+		}
+		return gormRepositories(db)
+	})
+}
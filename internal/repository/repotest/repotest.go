@@ -0,0 +1,2175 @@
+// Package repotest is a conformance suite for the repository interfaces.
+// Every implementation runs the same tests so they stay interchangeable.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/audit"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+// Repositories bundles the implementations under test, they must share
+// the same underlying storage
+type Repositories struct {
+	Users       repository.UserRepository
+	Rooms       repository.RoomRepository
+	Bookings    repository.BookingRepository
+	Tokens      repository.UserTokenRepository
+	Settings    repository.SettingRepository
+	Accounts    repository.ServiceAccountRepository
+	Invites     repository.InvitationRepository
+	Groups      repository.GroupRepository
+	Roles       repository.RoleRepository
+	Orgs        repository.OrganizationRepository
+	Audit       repository.AuditLogRepository
+	Delegations repository.DelegationRepository
+	Reports     repository.ReportRepository
+	Strikes     repository.StrikeRepository
+}
+
+// WithContext returns the repositories acting with ctx
+func (r Repositories) WithContext(ctx context.Context) Repositories {
+	return Repositories{
+		Users:       r.Users.WithContext(ctx),
+		Rooms:       r.Rooms.WithContext(ctx),
+		Bookings:    r.Bookings.WithContext(ctx),
+		Tokens:      r.Tokens.WithContext(ctx),
+		Settings:    r.Settings.WithContext(ctx),
+		Accounts:    r.Accounts.WithContext(ctx),
+		Invites:     r.Invites.WithContext(ctx),
+		Groups:      r.Groups.WithContext(ctx),
+		Roles:       r.Roles.WithContext(ctx),
+		Orgs:        r.Orgs.WithContext(ctx),
+		Audit:       r.Audit.WithContext(ctx),
+		Delegations: r.Delegations.WithContext(ctx),
+		Reports:     r.Reports.WithContext(ctx),
+		Strikes:     r.Strikes.WithContext(ctx),
+	}
+}
+
+// Factory returns repositories backed by fresh, empty storage
+type Factory func(t *testing.T) Repositories
+
+// Run runs the conformance suite against the repositories returned by newRepos
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, newRepos) })
+	t.Run("Rooms", func(t *testing.T) { testRooms(t, newRepos) })
+	t.Run("Bookings", func(t *testing.T) { testBookings(t, newRepos) })
+	t.Run("BookingGroups", func(t *testing.T) { testBookingGroups(t, newRepos) })
+	t.Run("UserTokens", func(t *testing.T) { testUserTokens(t, newRepos) })
+	t.Run("Settings", func(t *testing.T) { testSettings(t, newRepos) })
+	t.Run("ServiceAccounts", func(t *testing.T) { testServiceAccounts(t, newRepos) })
+	t.Run("Invitations", func(t *testing.T) { testInvitations(t, newRepos) })
+	t.Run("Groups", func(t *testing.T) { testGroups(t, newRepos) })
+	t.Run("RoomAccess", func(t *testing.T) { testRoomAccess(t, newRepos) })
+	t.Run("Roles", func(t *testing.T) { testRoles(t, newRepos) })
+	t.Run("Delegations", func(t *testing.T) { testDelegations(t, newRepos) })
+	t.Run("Organizations", func(t *testing.T) { testOrganizations(t, newRepos) })
+	t.Run("TenantIsolation", func(t *testing.T) { testTenantIsolation(t, newRepos) })
+	t.Run("AuditLogs", func(t *testing.T) { testAuditLogs(t, newRepos) })
+	t.Run("Audited", func(t *testing.T) { testAudited(t, newRepos) })
+	t.Run("Reports", func(t *testing.T) { testReports(t, newRepos) })
+	t.Run("Strikes", func(t *testing.T) { testStrikes(t, newRepos) })
+}
+
+// base is a fixed hour in the future so upcoming queries are predictable
+var base = time.Now().UTC().Truncate(time.Hour).Add(48 * time.Hour)
+
+func at(hours int) time.Time {
+	return base.Add(time.Duration(hours) * time.Hour)
+}
+
+func mustCreateUser(t *testing.T, repos Repositories, email string) model.User {
+	t.Helper()
+	user := model.User{Name: email, Email: email, Password: "hash", Role: model.RoleUser}
+	if err := repos.Users.Create(&user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func mustCreateRoom(t *testing.T, repos Repositories, name string) model.Room {
+	t.Helper()
+	room := model.Room{Name: name, Capacity: 10}
+	if err := repos.Rooms.Create(&room); err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	return room
+}
+
+func mustCreateBooking(t *testing.T, repos Repositories, room model.Room, user model.User, start, end int) model.Booking {
+	t.Helper()
+	booking := model.Booking{RoomID: room.ID, UserID: user.ID, StartTime: at(start), EndTime: at(end)}
+	if err := repos.Bookings.Create(&booking); err != nil {
+		t.Fatalf("create booking: %v", err)
+	}
+	return booking
+}
+
+func bookingIDs(bookings []model.Booking) []uuid.UUID {
+	ids := make([]uuid.UUID, len(bookings))
+	for i, b := range bookings {
+		ids[i] = b.ID
+	}
+	return ids
+}
+
+func assertIDs(t *testing.T, got []model.Booking, want ...model.Booking) {
+	t.Helper()
+	gotIDs := bookingIDs(got)
+	wantIDs := bookingIDs(want)
+	if len(gotIDs) != len(wantIDs) {
+		t.Fatalf("got %d bookings %v, want %d %v", len(gotIDs), gotIDs, len(wantIDs), wantIDs)
+	}
+	for i := range gotIDs {
+		if gotIDs[i] != wantIDs[i] {
+			t.Fatalf("booking %d: got %v, want %v (order %v, want %v)", i, gotIDs[i], wantIDs[i], gotIDs, wantIDs)
+		}
+	}
+}
+
+func testUsers(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		if user.ID == uuid.Nil {
+			t.Fatal("expected ID to be assigned")
+		}
+		if user.CreatedAt.IsZero() {
+			t.Fatal("expected CreatedAt to be set")
+		}
+
+		byID, err := repos.Users.FindByID(user.ID.String())
+		if err != nil || byID.Email != user.Email {
+			t.Fatalf("FindByID = %+v, %v", byID, err)
+		}
+
+		for _, email := range []string{"alice@example.com", "Alice@Example.COM"} {
+			byEmail, err := repos.Users.FindByEmail(email)
+			if err != nil || byEmail.ID != user.ID {
+				t.Fatalf("FindByEmail(%q) = %+v, %v", email, byEmail, err)
+			}
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repos := newRepos(t)
+		if _, err := repos.Users.FindByID(uuid.NewString()); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("FindByID error = %v, want ErrRecordNotFound", err)
+		}
+		if _, err := repos.Users.FindByEmail("nobody@example.com"); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("FindByEmail error = %v, want ErrRecordNotFound", err)
+		}
+	})
+
+	t.Run("DuplicateEmail", func(t *testing.T) {
+		repos := newRepos(t)
+		mustCreateUser(t, repos, "alice@example.com")
+		dup := model.User{Name: "Other", Email: "alice@example.com"}
+		if err := repos.Users.Create(&dup); err == nil {
+			t.Fatal("expected duplicate email to fail")
+		}
+	})
+
+	t.Run("FindAll", func(t *testing.T) {
+		repos := newRepos(t)
+		mustCreateUser(t, repos, "alice@example.com")
+		mustCreateUser(t, repos, "bob@example.com")
+
+		users, err := repos.Users.FindAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(users) != 2 {
+			t.Fatalf("got %d users, want 2", len(users))
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+
+		revokedAt := base.Truncate(time.Second)
+		user.Password = "new-hash"
+		user.TokensValidAfter = &revokedAt
+		if err := repos.Users.Update(&user); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repos.Users.FindByID(user.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Password != "new-hash" || got.TokensValidAfter == nil || !got.TokensValidAfter.Equal(revokedAt) {
+			t.Fatalf("update not persisted: %+v", got)
+		}
+	})
+
+	t.Run("Preferences", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+
+		got, err := repos.Users.FindByID(user.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Timezone != "UTC" || got.Locale != "en" || !got.Notifications.BookingUpdates || got.Notifications.BookingReceipts {
+			t.Fatalf("defaults = %q %q %+v", got.Timezone, got.Locale, got.Notifications)
+		}
+
+		pending := "alice@new.example.com"
+		got.Timezone, got.Locale = "Asia/Jakarta", "id-ID"
+		got.Notifications = model.NotificationPreferences{BookingUpdates: false, BookingReceipts: true}
+		got.PendingEmail = &pending
+		if err := repos.Users.Update(got); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err = repos.Users.FindByID(user.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Timezone != "Asia/Jakarta" || got.Locale != "id-ID" || got.Notifications.BookingUpdates || !got.Notifications.BookingReceipts {
+			t.Fatalf("preferences not persisted: %q %q %+v", got.Timezone, got.Locale, got.Notifications)
+		}
+		if got.PendingEmail == nil || *got.PendingEmail != pending || got.Email != "alice@example.com" {
+			t.Fatalf("pending email = %v, email = %q", got.PendingEmail, got.Email)
+		}
+	})
+
+	t.Run("OIDCSubject", func(t *testing.T) {
+		repos := newRepos(t)
+		issuer, subject := "https://idp.example.com", "sub-1"
+
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		alice.OIDCIssuer, alice.OIDCSubject = &issuer, &subject
+		if err := repos.Users.Update(&alice); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repos.Users.FindByOIDCSubject(issuer, subject)
+		if err != nil || got.ID != alice.ID {
+			t.Fatalf("FindByOIDCSubject = %+v, %v", got, err)
+		}
+		if _, err := repos.Users.FindByOIDCSubject("https://other.example.com", subject); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("other issuer error = %v, want ErrRecordNotFound", err)
+		}
+
+		bob := model.User{Name: "Bob", Email: "bob@example.com", OIDCIssuer: &issuer, OIDCSubject: &subject}
+		if err := repos.Users.Create(&bob); err == nil {
+			t.Fatal("expected a second user with the same identity to fail")
+		}
+		mustCreateUser(t, repos, "carol@example.com")
+	})
+
+	t.Run("CountActiveAdmins", func(t *testing.T) {
+		repos := newRepos(t)
+		mustCreateUser(t, repos, "alice@example.com")
+		for _, email := range []string{"bob@example.com", "carol@example.com"} {
+			admin := model.User{Name: email, Email: email, Role: model.RoleAdmin}
+			if err := repos.Users.Create(&admin); err != nil {
+				t.Fatal(err)
+			}
+		}
+		carol, err := repos.Users.FindByEmail("carol@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		deactivatedAt := base
+		carol.DeactivatedAt = &deactivatedAt
+		if err := repos.Users.Update(carol); err != nil {
+			t.Fatal(err)
+		}
+
+		if count, err := repos.Users.CountActiveAdmins(); err != nil || count != 1 {
+			t.Fatalf("CountActiveAdmins = %d, %v, want 1", count, err)
+		}
+	})
+
+	t.Run("SoftDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		issuer, subject := "https://idp.example.com", "sub-1"
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		alice.Role = model.RoleAdmin
+		alice.OIDCIssuer, alice.OIDCSubject = &issuer, &subject
+		if err := repos.Users.Update(&alice); err != nil {
+			t.Fatal(err)
+		}
+		room := mustCreateRoom(t, repos, "Orion")
+		booking := mustCreateBooking(t, repos, room, alice, 1, 2)
+
+		if err := repos.Users.Delete(alice.ID.String()); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repos.Users.FindByID(alice.ID.String()); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("FindByID error = %v, want ErrRecordNotFound", err)
+		}
+		if _, err := repos.Users.FindByOIDCSubject(issuer, subject); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("FindByOIDCSubject error = %v, want ErrRecordNotFound", err)
+		}
+		if users, err := repos.Users.FindAll(); err != nil || len(users) != 0 {
+			t.Fatalf("FindAll = %d users, %v, want none", len(users), err)
+		}
+		if count, err := repos.Users.CountActiveAdmins(); err != nil || count != 0 {
+			t.Fatalf("CountActiveAdmins = %d, %v, want 0", count, err)
+		}
+		if err := repos.Users.Delete(alice.ID.String()); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("second Delete error = %v, want ErrRecordNotFound", err)
+		}
+
+		// bookings keep their user, and the email and identity are free again
+		if _, err := repos.Bookings.FindByID(booking.ID); err != nil {
+			t.Fatalf("booking of deleted user: %v", err)
+		}
+		again := model.User{Name: "Alice", Email: "alice@example.com", OIDCIssuer: &issuer, OIDCSubject: &subject}
+		if err := repos.Users.Create(&again); err != nil {
+			t.Fatalf("recreate after delete: %v", err)
+		}
+	})
+}
+
+func testRooms(t *testing.T, newRepos Factory) {
+	t.Run("CreateUpdateFind", func(t *testing.T) {
+		repos := newRepos(t)
+		room := mustCreateRoom(t, repos, "Hall")
+
+		room.Name = "Main Hall"
+		room.Capacity = 50
+		if err := repos.Rooms.Update(&room); err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := repos.Rooms.FindByID(room.ID)
+		if err != nil || found == nil {
+			t.Fatalf("FindByID = %v, %v", found, err)
+		}
+		if found.Name != "Main Hall" || found.Capacity != 50 {
+			t.Fatalf("update not persisted: %+v", found)
+		}
+	})
+
+	t.Run("MissingReturnsNil", func(t *testing.T) {
+		repos := newRepos(t)
+		room, err := repos.Rooms.FindByID(uuid.New())
+		if err != nil || room != nil {
+			t.Fatalf("FindByID = %v, %v, want nil, nil", room, err)
+		}
+	})
+
+	t.Run("SoftDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		kept := mustCreateRoom(t, repos, "Kept")
+		deleted := mustCreateRoom(t, repos, "Deleted")
+
+		if err := repos.Rooms.Delete(deleted.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		room, err := repos.Rooms.FindByID(deleted.ID)
+		if err != nil || room != nil {
+			t.Fatalf("deleted room FindByID = %v, %v, want nil, nil", room, err)
+		}
+
+		rooms, err := repos.Rooms.FindAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rooms) != 1 || rooms[0].ID != kept.ID {
+			t.Fatalf("FindAll = %+v, want only %v", rooms, kept.ID)
+		}
+	})
+}
+
+func testBookings(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		room := mustCreateRoom(t, repos, "Hall")
+		booking := mustCreateBooking(t, repos, room, user, 0, 2)
+
+		if booking.ID == uuid.Nil {
+			t.Fatal("expected ID to be assigned")
+		}
+		if booking.Status != model.BookingStatusActive {
+			t.Fatalf("status = %q, want active", booking.Status)
+		}
+
+		found, err := repos.Bookings.FindByID(booking.ID)
+		if err != nil || found == nil {
+			t.Fatalf("FindByID = %v, %v", found, err)
+		}
+		if found.Room.ID != room.ID || found.User.ID != user.ID {
+			t.Fatalf("relationships not preloaded: room %v user %v", found.Room.ID, found.User.ID)
+		}
+		if !found.StartTime.Equal(at(0)) || !found.EndTime.Equal(at(2)) {
+			t.Fatalf("times = %v - %v, want %v - %v", found.StartTime, found.EndTime, at(0), at(2))
+		}
+	})
+
+	t.Run("MissingReturnsNil", func(t *testing.T) {
+		repos := newRepos(t)
+		booking, err := repos.Bookings.FindByID(uuid.New())
+		if err != nil || booking != nil {
+			t.Fatalf("FindByID = %v, %v, want nil, nil", booking, err)
+		}
+	})
+
+	t.Run("Ordering", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		room := mustCreateRoom(t, repos, "Hall")
+		second := mustCreateBooking(t, repos, room, user, 3, 4)
+		first := mustCreateBooking(t, repos, room, user, 1, 2)
+		third := mustCreateBooking(t, repos, room, user, 5, 6)
+
+		byUser, err := repos.Bookings.FindByUserID(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertIDs(t, byUser, third, second, first)
+
+		byRoom, err := repos.Bookings.FindByRoomID(room.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertIDs(t, byRoom, third, second, first)
+
+		upcoming, err := repos.Bookings.GetUpcomingBookings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertIDs(t, upcoming, first, second, third)
+	})
+
+	t.Run("UpcomingExcludesPastAndCancelled", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		room := mustCreateRoom(t, repos, "Hall")
+		past := mustCreateBooking(t, repos, room, user, -96, -95)
+		cancelled := mustCreateBooking(t, repos, room, user, 1, 2)
+		active := mustCreateBooking(t, repos, room, user, 3, 4)
+		_ = past
+
+		if err := repos.Bookings.Cancel(cancelled.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		upcoming, err := repos.Bookings.GetUpcomingBookings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertIDs(t, upcoming, active)
+	})
+
+	t.Run("Availability", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		room := mustCreateRoom(t, repos, "Hall")
+		other := mustCreateRoom(t, repos, "Other")
+		booking := mustCreateBooking(t, repos, room, user, 2, 4)
+
+		cases := []struct {
+			name       string
+			room       model.Room
+			start, end int
+			exclude    *uuid.UUID
+			want       bool
+		}{
+			{"same slot", room, 2, 4, nil, false},
+			{"overlaps start", room, 1, 3, nil, false},
+			{"overlaps end", room, 3, 5, nil, false},
+			{"contains", room, 1, 5, nil, false},
+			{"inside", room, 2, 3, nil, false},
+			{"adjacent before", room, 0, 2, nil, true},
+			{"adjacent after", room, 4, 6, nil, true},
+			{"other room", other, 2, 4, nil, true},
+			{"excluded", room, 2, 4, &booking.ID, true},
+		}
+		for _, tc := range cases {
+			got, err := repos.Bookings.IsRoomAvailable(tc.room.ID, at(tc.start), at(tc.end), tc.exclude)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			if got != tc.want {
+				t.Errorf("%s: available = %v, want %v", tc.name, got, tc.want)
+			}
+		}
+
+		if err := repos.Bookings.Cancel(booking.ID); err != nil {
+			t.Fatal(err)
+		}
+		available, err := repos.Bookings.IsRoomAvailable(room.ID, at(2), at(4), nil)
+		if err != nil || !available {
+			t.Fatalf("cancelled booking blocks room: %v, %v", available, err)
+		}
+	})
+
+	t.Run("AvailabilityAcrossTimezones", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		room := mustCreateRoom(t, repos, "Hall")
+		mustCreateBooking(t, repos, room, user, 2, 4)
+
+		zone := time.FixedZone("UTC+7", 7*60*60)
+		available, err := repos.Bookings.IsRoomAvailable(room.ID, at(3).In(zone), at(5).In(zone), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if available {
+			t.Fatal("overlap expressed in another timezone was not detected")
+		}
+	})
+
+	t.Run("FindByRoomIDAndDate", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		room := mustCreateRoom(t, repos, "Hall")
+
+		day := time.Date(base.Year(), base.Month(), base.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+		morning := model.Booking{RoomID: room.ID, UserID: user.ID, StartTime: day.Add(9 * time.Hour), EndTime: day.Add(10 * time.Hour)}
+		noon := model.Booking{RoomID: room.ID, UserID: user.ID, StartTime: day.Add(12 * time.Hour), EndTime: day.Add(13 * time.Hour)}
+		nextDay := model.Booking{RoomID: room.ID, UserID: user.ID, StartTime: day.Add(33 * time.Hour), EndTime: day.Add(34 * time.Hour)}
+		for _, b := range []*model.Booking{&noon, &morning, &nextDay} {
+			if err := repos.Bookings.Create(b); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := repos.Bookings.Cancel(noon.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		all, err := repos.Bookings.FindByRoomIDAndDate(room.ID, day, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertIDs(t, all, morning, noon)
+
+		status := string(model.BookingStatusCancelled)
+		cancelled, err := repos.Bookings.FindByRoomIDAndDate(room.ID, day, &status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertIDs(t, cancelled, noon)
+	})
+
+	t.Run("UpdateAndCancel", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		room := mustCreateRoom(t, repos, "Hall")
+		booking := mustCreateBooking(t, repos, room, user, 0, 1)
+
+		booking.StartTime = at(2)
+		booking.EndTime = at(3)
+		if err := repos.Bookings.Update(&booking); err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := repos.Bookings.FindByID(booking.ID)
+		if err != nil || found == nil {
+			t.Fatalf("FindByID = %v, %v", found, err)
+		}
+		if !found.StartTime.Equal(at(2)) || !found.EndTime.Equal(at(3)) {
+			t.Fatalf("update not persisted: %v - %v", found.StartTime, found.EndTime)
+		}
+
+		if err := repos.Bookings.Cancel(booking.ID); err != nil {
+			t.Fatal(err)
+		}
+		found, err = repos.Bookings.FindByID(booking.ID)
+		if err != nil || found.Status != model.BookingStatusCancelled {
+			t.Fatalf("status after cancel = %v, %v", found.Status, err)
+		}
+	})
+
+	t.Run("CountActiveAt", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		hall := mustCreateRoom(t, repos, "Hall")
+		breakout := mustCreateRoom(t, repos, "Breakout")
+		mustCreateBooking(t, repos, hall, user, 0, 2)
+		mustCreateBooking(t, repos, breakout, user, 1, 3)
+		cancelled := mustCreateBooking(t, repos, hall, user, 2, 4)
+		if err := repos.Bookings.Cancel(cancelled.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		cases := map[int]int64{-1: 0, 0: 1, 1: 2, 2: 1, 3: 0}
+		for hour, want := range cases {
+			got, err := repos.Bookings.CountActiveAt(at(hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("CountActiveAt(+%dh) = %d, want %d", hour, got, want)
+			}
+		}
+	})
+
+	t.Run("CountActiveByUserID", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		bob := mustCreateUser(t, repos, "bob@example.com")
+		hall := mustCreateRoom(t, repos, "Hall")
+		mustCreateBooking(t, repos, hall, alice, 0, 2)
+		mustCreateBooking(t, repos, hall, alice, 2, 3)
+		mustCreateBooking(t, repos, hall, bob, 3, 4)
+		cancelled := mustCreateBooking(t, repos, hall, alice, 4, 5)
+		if err := repos.Bookings.Cancel(cancelled.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		// bookings in progress still count, those that ended do not
+		cases := map[int]int64{-1: 2, 1: 2, 2: 1, 3: 0}
+		for hour, want := range cases {
+			got, err := repos.Bookings.CountActiveByUserID(alice.ID, at(hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("CountActiveByUserID(+%dh) = %d, want %d", hour, got, want)
+			}
+		}
+	})
+
+	t.Run("PreloadSkipsDeletedRoom", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		room := mustCreateRoom(t, repos, "Hall")
+		booking := mustCreateBooking(t, repos, room, user, 0, 1)
+
+		if err := repos.Rooms.Delete(room.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := repos.Bookings.FindByID(booking.ID)
+		if err != nil || found == nil {
+			t.Fatalf("FindByID = %v, %v", found, err)
+		}
+		if found.Room.ID != uuid.Nil {
+			t.Fatalf("soft deleted room was preloaded: %+v", found.Room)
+		}
+	})
+}
+
+func testBookingGroups(t *testing.T, newRepos Factory) {
+	t.Run("CreateFindCancel", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		hall := mustCreateRoom(t, repos, "Hall")
+		breakout := mustCreateRoom(t, repos, "Breakout")
+
+		group := model.BookingGroup{UserID: user.ID, Title: "Offsite"}
+		bookings := []model.Booking{
+			{RoomID: breakout.ID, UserID: user.ID, StartTime: at(2), EndTime: at(3)},
+			{RoomID: hall.ID, UserID: user.ID, StartTime: at(0), EndTime: at(2)},
+		}
+		conflicts, err := repos.Bookings.CreateGroup(&group, bookings)
+		if err != nil || len(conflicts) != 0 {
+			t.Fatalf("CreateGroup = %v, %v", conflicts, err)
+		}
+
+		found, err := repos.Bookings.FindGroupByID(group.ID)
+		if err != nil || found == nil {
+			t.Fatalf("FindGroupByID = %v, %v", found, err)
+		}
+		if found.Title != "Offsite" || found.User.ID != user.ID {
+			t.Fatalf("unexpected group %+v", found)
+		}
+		assertIDs(t, found.Bookings, bookings[1], bookings[0])
+		for _, b := range found.Bookings {
+			if b.GroupID == nil || *b.GroupID != group.ID {
+				t.Fatalf("booking %v not linked to group", b.ID)
+			}
+			if b.Room.ID != b.RoomID {
+				t.Fatalf("booking %v room not preloaded", b.ID)
+			}
+		}
+
+		if err := repos.Bookings.CancelGroup(group.ID); err != nil {
+			t.Fatal(err)
+		}
+		found, err = repos.Bookings.FindGroupByID(group.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range found.Bookings {
+			if b.Status != model.BookingStatusCancelled {
+				t.Fatalf("booking %v status = %q after group cancel", b.ID, b.Status)
+			}
+		}
+	})
+
+	t.Run("ConflictRollsBack", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		hall := mustCreateRoom(t, repos, "Hall")
+		breakout := mustCreateRoom(t, repos, "Breakout")
+		mustCreateBooking(t, repos, hall, user, 0, 2)
+
+		group := model.BookingGroup{UserID: user.ID}
+		bookings := []model.Booking{
+			{RoomID: breakout.ID, UserID: user.ID, StartTime: at(0), EndTime: at(1)},
+			{RoomID: hall.ID, UserID: user.ID, StartTime: at(1), EndTime: at(3)},
+			{RoomID: breakout.ID, UserID: user.ID, StartTime: at(0), EndTime: at(2)},
+		}
+		conflicts, err := repos.Bookings.CreateGroup(&group, bookings)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(conflicts) != 2 || conflicts[0] != 1 || conflicts[1] != 2 {
+			t.Fatalf("conflicts = %v, want [1 2]", conflicts)
+		}
+
+		breakoutBookings, err := repos.Bookings.FindByRoomID(breakout.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(breakoutBookings) != 0 {
+			t.Fatalf("conflicting batch left %d bookings behind", len(breakoutBookings))
+		}
+		if group.ID != uuid.Nil {
+			if found, err := repos.Bookings.FindGroupByID(group.ID); err != nil || found != nil {
+				t.Fatalf("conflicting batch left group behind: %v, %v", found, err)
+			}
+		}
+	})
+}
+
+func testUserTokens(t *testing.T, newRepos Factory) {
+	mustCreateToken := func(t *testing.T, repos Repositories, user model.User, hash string, expiresAt time.Time) {
+		t.Helper()
+		token := model.UserToken{UserID: user.ID, Purpose: model.TokenPurposePasswordReset, TokenHash: hash, ExpiresAt: expiresAt}
+		if err := repos.Tokens.Create(&token); err != nil {
+			t.Fatalf("create token: %v", err)
+		}
+	}
+	now := time.Now()
+
+	t.Run("ConsumeOnce", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		mustCreateToken(t, repos, user, "hash-1", now.Add(time.Hour))
+
+		token, err := repos.Tokens.Consume(model.TokenPurposePasswordReset, "hash-1", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.UserID != user.ID || token.UsedAt == nil {
+			t.Fatalf("consumed token = %+v", token)
+		}
+		if _, err := repos.Tokens.Consume(model.TokenPurposePasswordReset, "hash-1", now); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("second consume error = %v, want ErrRecordNotFound", err)
+		}
+	})
+
+	t.Run("ExpiredOrWrongPurpose", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustCreateUser(t, repos, "alice@example.com")
+		mustCreateToken(t, repos, user, "expired", now.Add(-time.Minute))
+		mustCreateToken(t, repos, user, "valid", now.Add(time.Hour))
+
+		if _, err := repos.Tokens.Consume(model.TokenPurposePasswordReset, "expired", now); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expired consume error = %v, want ErrRecordNotFound", err)
+		}
+		if _, err := repos.Tokens.Consume("other", "valid", now); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("wrong purpose consume error = %v, want ErrRecordNotFound", err)
+		}
+	})
+
+	t.Run("ConsumeFor", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		bob := mustCreateUser(t, repos, "bob@example.com")
+		mustCreateToken(t, repos, bob, "bob-1", now.Add(time.Hour))
+
+		// another user's token is not used up by trying it
+		if _, err := repos.Tokens.ConsumeFor(alice.ID, model.TokenPurposePasswordReset, "bob-1", now); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("consume for another user error = %v, want ErrRecordNotFound", err)
+		}
+		token, err := repos.Tokens.ConsumeFor(bob.ID, model.TokenPurposePasswordReset, "bob-1", now)
+		if err != nil || token.UserID != bob.ID || token.UsedAt == nil {
+			t.Fatalf("consume for its user = %+v, %v", token, err)
+		}
+	})
+
+	t.Run("RevokeAll", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		bob := mustCreateUser(t, repos, "bob@example.com")
+		mustCreateToken(t, repos, alice, "alice-1", now.Add(time.Hour))
+		mustCreateToken(t, repos, alice, "alice-2", now.Add(time.Hour))
+		mustCreateToken(t, repos, bob, "bob-1", now.Add(time.Hour))
+
+		if err := repos.Tokens.RevokeAll(alice.ID, model.TokenPurposePasswordReset); err != nil {
+			t.Fatal(err)
+		}
+		for _, hash := range []string{"alice-1", "alice-2"} {
+			if _, err := repos.Tokens.Consume(model.TokenPurposePasswordReset, hash, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Fatalf("%s consume error = %v, want ErrRecordNotFound", hash, err)
+			}
+		}
+		if _, err := repos.Tokens.Consume(model.TokenPurposePasswordReset, "bob-1", now); err != nil {
+			t.Fatalf("other users' tokens must survive: %v", err)
+		}
+	})
+}
+
+func testSettings(t *testing.T, newRepos Factory) {
+	repos := newRepos(t)
+
+	if got, err := repos.Settings.Get("missing", "default"); err != nil || got != "default" {
+		t.Fatalf("Get missing = %q, %v", got, err)
+	}
+	for _, value := range []string{"true", "false"} {
+		if err := repos.Settings.Set("feature", value); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := repos.Settings.Get("feature", ""); err != nil || got != value {
+			t.Fatalf("Get after Set(%q) = %q, %v", value, got, err)
+		}
+	}
+}
+
+func testServiceAccounts(t *testing.T, newRepos Factory) {
+	mustCreateAccount := func(t *testing.T, repos Repositories, name string) model.ServiceAccount {
+		t.Helper()
+		account := model.ServiceAccount{Name: name}
+		if err := repos.Accounts.Create(&account); err != nil {
+			t.Fatalf("create service account: %v", err)
+		}
+		return account
+	}
+	mustCreateKey := func(t *testing.T, repos Repositories, account model.ServiceAccount, hash string) model.APIKey {
+		t.Helper()
+		key := model.APIKey{
+			ServiceAccountID: account.ID,
+			Name:             hash,
+			Prefix:           "mbk_test",
+			KeyHash:          hash,
+			Scopes:           model.ScopeList{model.ScopeBookingsRead, model.ScopeBookingsWrite},
+		}
+		if err := repos.Accounts.CreateKey(&key); err != nil {
+			t.Fatalf("create api key: %v", err)
+		}
+		return key
+	}
+	now := time.Now()
+
+	t.Run("CreateFindDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		displays := mustCreateAccount(t, repos, "displays")
+		mustCreateAccount(t, repos, "backup")
+
+		if dup := (model.ServiceAccount{Name: "displays"}); repos.Accounts.Create(&dup) == nil {
+			t.Fatal("expected duplicate name to fail")
+		}
+
+		accounts, err := repos.Accounts.FindAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(accounts) != 2 || accounts[0].Name != "backup" || accounts[1].Name != "displays" {
+			t.Fatalf("FindAll = %+v, want ordered by name", accounts)
+		}
+
+		found, err := repos.Accounts.FindByID(displays.ID)
+		if err != nil || found.Name != "displays" {
+			t.Fatalf("FindByID = %+v, %v", found, err)
+		}
+
+		mustCreateKey(t, repos, displays, "hash-1")
+		if err := repos.Accounts.Delete(displays.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repos.Accounts.FindByID(displays.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("FindByID after delete error = %v, want ErrRecordNotFound", err)
+		}
+		if _, err := repos.Accounts.FindKeyByHash("hash-1"); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("keys must be deleted with the account, got %v", err)
+		}
+		if err := repos.Accounts.Delete(displays.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("second delete error = %v, want ErrRecordNotFound", err)
+		}
+	})
+
+	t.Run("Keys", func(t *testing.T) {
+		repos := newRepos(t)
+		account := mustCreateAccount(t, repos, "displays")
+		other := mustCreateAccount(t, repos, "scripts")
+		key := mustCreateKey(t, repos, account, "hash-1")
+
+		found, err := repos.Accounts.FindKeyByHash("hash-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.ID != key.ID || !found.Scopes.Has(model.ScopeBookingsWrite) || found.Scopes.Has(model.ScopeRoomsWrite) {
+			t.Fatalf("FindKeyByHash = %+v", found)
+		}
+
+		if err := repos.Accounts.TouchKey(key.ID, now); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Accounts.RevokeKey(other.ID, key.ID, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("revoking another account's key error = %v, want ErrRecordNotFound", err)
+		}
+		if err := repos.Accounts.RevokeKey(account.ID, key.ID, now); err != nil {
+			t.Fatal(err)
+		}
+
+		keys, err := repos.Accounts.FindKeys(account.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(keys) != 1 || keys[0].LastUsedAt == nil || keys[0].RevokedAt == nil || keys[0].Active(now) {
+			t.Fatalf("FindKeys = %+v, want one used and revoked key", keys)
+		}
+		if keys, _ := repos.Accounts.FindKeys(other.ID); len(keys) != 0 {
+			t.Fatalf("FindKeys of another account = %+v", keys)
+		}
+	})
+
+	t.Run("KeyRequiresAccount", func(t *testing.T) {
+		repos := newRepos(t)
+		key := model.APIKey{ServiceAccountID: uuid.New(), Name: "orphan", Prefix: "mbk_test", KeyHash: "orphan"}
+		if err := repos.Accounts.CreateKey(&key); err == nil {
+			t.Fatal("expected a key without service account to fail")
+		}
+	})
+}
+
+func testInvitations(t *testing.T, newRepos Factory) {
+	mustInvite := func(t *testing.T, repos Repositories, email, hash string, expiresAt time.Time) model.Invitation {
+		t.Helper()
+		invitation := model.Invitation{Email: email, Role: model.RoleAdmin, TokenHash: hash, ExpiresAt: expiresAt}
+		if err := repos.Invites.Create(&invitation); err != nil {
+			t.Fatalf("create invitation: %v", err)
+		}
+		return invitation
+	}
+	now := time.Now()
+
+	t.Run("AcceptOnce", func(t *testing.T) {
+		repos := newRepos(t)
+		invitation := mustInvite(t, repos, "new@example.com", "hash-1", now.Add(time.Hour))
+
+		found, err := repos.Invites.FindPending("hash-1", now)
+		if err != nil || found.ID != invitation.ID || found.Role != model.RoleAdmin {
+			t.Fatalf("FindPending = %+v, %v", found, err)
+		}
+		if err := repos.Invites.Accept(invitation.ID, now); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Invites.Accept(invitation.ID, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("second accept error = %v, want ErrRecordNotFound", err)
+		}
+		if _, err := repos.Invites.FindPending("hash-1", now); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("accepted invitation still pending: %v", err)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		repos := newRepos(t)
+		invitation := mustInvite(t, repos, "new@example.com", "hash-1", now.Add(-time.Minute))
+
+		if _, err := repos.Invites.FindPending("hash-1", now); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expired FindPending error = %v, want ErrRecordNotFound", err)
+		}
+		if err := repos.Invites.Accept(invitation.ID, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expired accept error = %v, want ErrRecordNotFound", err)
+		}
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		repos := newRepos(t)
+		first := mustInvite(t, repos, "new@example.com", "hash-1", now.Add(time.Hour))
+		mustInvite(t, repos, "new@example.com", "hash-2", now.Add(time.Hour))
+		mustInvite(t, repos, "other@example.com", "hash-3", now.Add(time.Hour))
+
+		if err := repos.Invites.Revoke(first.ID, now); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Invites.Revoke(first.ID, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("second revoke error = %v, want ErrRecordNotFound", err)
+		}
+		if err := repos.Invites.RevokePending("new@example.com", now); err != nil {
+			t.Fatal(err)
+		}
+		for _, hash := range []string{"hash-1", "hash-2"} {
+			if _, err := repos.Invites.FindPending(hash, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Fatalf("%s still pending after revoke: %v", hash, err)
+			}
+		}
+		if _, err := repos.Invites.FindPending("hash-3", now); err != nil {
+			t.Fatalf("other emails' invitations must survive: %v", err)
+		}
+
+		all, err := repos.Invites.FindAll()
+		if err != nil || len(all) != 3 {
+			t.Fatalf("FindAll = %d invitations, %v", len(all), err)
+		}
+	})
+}
+
+func mustCreateGroup(t *testing.T, repos Repositories, name string, members ...model.User) model.Group {
+	t.Helper()
+	group := model.Group{Name: name}
+	if err := repos.Groups.Create(&group); err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	for _, m := range members {
+		if err := repos.Groups.AddMember(group.ID, m.ID); err != nil {
+			t.Fatalf("add member: %v", err)
+		}
+	}
+	return group
+}
+
+func groupNames(groups []model.Group) []string {
+	names := make([]string, len(groups))
+	for i, g := range groups {
+		names[i] = g.Name
+	}
+	return names
+}
+
+func testGroups(t *testing.T, newRepos Factory) {
+	t.Run("CreateUpdateFind", func(t *testing.T) {
+		repos := newRepos(t)
+		group := mustCreateGroup(t, repos, "Lab")
+		if group.Kind != model.GroupKindGroup {
+			t.Fatalf("kind = %q, want default %q", group.Kind, model.GroupKindGroup)
+		}
+		mustCreateGroup(t, repos, "Facilities")
+
+		dup := model.Group{Name: "Lab"}
+		if err := repos.Groups.Create(&dup); err == nil {
+			t.Fatal("expected duplicate name to fail")
+		}
+
+		group.Kind = model.GroupKindTeam
+		group.Description = "Research"
+		if err := repos.Groups.Update(&group); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repos.Groups.FindByID(group.ID)
+		if err != nil || got.Kind != model.GroupKindTeam || got.Description != "Research" {
+			t.Fatalf("FindByID = %+v, %v", got, err)
+		}
+
+		all, err := repos.Groups.FindAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names := groupNames(all); len(names) != 2 || names[0] != "Facilities" || names[1] != "Lab" {
+			t.Fatalf("FindAll = %v, want [Facilities Lab]", names)
+		}
+		if _, err := repos.Groups.FindByID(uuid.New()); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("missing group error = %v, want ErrRecordNotFound", err)
+		}
+	})
+
+	t.Run("Members", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		bob := mustCreateUser(t, repos, "bob@example.com")
+		lab := mustCreateGroup(t, repos, "Lab", bob, alice)
+		mustCreateGroup(t, repos, "Facilities", alice)
+
+		// adding a member again is not an error
+		if err := repos.Groups.AddMember(lab.ID, alice.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		members, err := repos.Groups.FindMembers(lab.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(members) != 2 || members[0].ID != alice.ID || members[1].ID != bob.ID {
+			t.Fatalf("FindMembers = %+v, want alice and bob", members)
+		}
+
+		groups, err := repos.Groups.FindByUserID(alice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names := groupNames(groups); len(names) != 2 || names[0] != "Facilities" || names[1] != "Lab" {
+			t.Fatalf("FindByUserID = %v, want [Facilities Lab]", names)
+		}
+
+		if err := repos.Groups.RemoveMember(lab.ID, bob.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Groups.RemoveMember(lab.ID, bob.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("second RemoveMember error = %v, want ErrRecordNotFound", err)
+		}
+
+		// deleted users leave their groups
+		if err := repos.Users.Delete(alice.ID.String()); err != nil {
+			t.Fatal(err)
+		}
+		if members, err := repos.Groups.FindMembers(lab.ID); err != nil || len(members) != 0 {
+			t.Fatalf("FindMembers after removals = %d, %v, want none", len(members), err)
+		}
+	})
+
+	t.Run("DeleteCascades", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		lab := mustCreateGroup(t, repos, "Lab", alice)
+		room := mustCreateRoom(t, repos, "Lab 1")
+		if err := repos.Rooms.SetAccess(room.ID, []uuid.UUID{lab.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repos.Groups.Delete(lab.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Groups.Delete(lab.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("second Delete error = %v, want ErrRecordNotFound", err)
+		}
+		if groups, err := repos.Groups.FindByUserID(alice.ID); err != nil || len(groups) != 0 {
+			t.Fatalf("FindByUserID = %v, %v, want none", groupNames(groups), err)
+		}
+		if groups, err := repos.Rooms.FindAccess(room.ID); err != nil || len(groups) != 0 {
+			t.Fatalf("FindAccess = %v, %v, want none", groupNames(groups), err)
+		}
+	})
+}
+
+func testRoomAccess(t *testing.T, newRepos Factory) {
+	repos := newRepos(t)
+	alice := mustCreateUser(t, repos, "alice@example.com")
+	bob := mustCreateUser(t, repos, "bob@example.com")
+	lab := mustCreateGroup(t, repos, "Lab", alice)
+	execs := mustCreateGroup(t, repos, "Executives", bob)
+
+	open := mustCreateRoom(t, repos, "Orion")
+	restricted := model.Room{Name: "Lab 1", Capacity: 4, Restricted: true}
+	if err := repos.Rooms.Create(&restricted); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Rooms.SetAccess(restricted.ID, []uuid.UUID{lab.ID, execs.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := repos.Rooms.FindAccess(restricted.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := groupNames(groups); len(names) != 2 || names[0] != "Executives" || names[1] != "Lab" {
+		t.Fatalf("FindAccess = %v, want [Executives Lab]", names)
+	}
+
+	// replacing the list drops the groups left out
+	if err := repos.Rooms.SetAccess(restricted.ID, []uuid.UUID{lab.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	roomNames := func(userID uuid.UUID) []string {
+		t.Helper()
+		rooms, err := repos.Rooms.FindAllForUser(userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, len(rooms))
+		for i, r := range rooms {
+			names[i] = r.Name
+		}
+		return names
+	}
+	if names := roomNames(alice.ID); len(names) != 2 {
+		t.Fatalf("alice sees %v, want both rooms", names)
+	}
+	if names := roomNames(bob.ID); len(names) != 1 || names[0] != open.Name {
+		t.Fatalf("bob sees %v, want only %s", names, open.Name)
+	}
+	if names := roomNames(uuid.Nil); len(names) != 1 || names[0] != open.Name {
+		t.Fatalf("anonymous sees %v, want only %s", names, open.Name)
+	}
+
+	for _, tc := range []struct {
+		name string
+		user uuid.UUID
+		want bool
+	}{
+		{"member", alice.ID, true},
+		{"removed group", bob.ID, false},
+		{"anonymous", uuid.Nil, false},
+	} {
+		if ok, err := repos.Rooms.HasAccess(restricted.ID, tc.user); err != nil || ok != tc.want {
+			t.Fatalf("HasAccess(%s) = %v, %v, want %v", tc.name, ok, err, tc.want)
+		}
+	}
+
+	if err := repos.Rooms.SetAccess(restricted.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if names := roomNames(alice.ID); len(names) != 1 {
+		t.Fatalf("alice sees %v after the list was cleared, want only %s", names, open.Name)
+	}
+}
+
+func testRoles(t *testing.T, newRepos Factory) {
+	t.Run("CreateUpdateFind", func(t *testing.T) {
+		repos := newRepos(t)
+		role := model.Role{Name: "receptionist", Permissions: model.PermissionList{model.PermBookingsManageAny, model.PermUsersRead}}
+		if err := repos.Roles.Create(&role); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Roles.Create(&model.Role{Name: "facilities"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Roles.Create(&model.Role{Name: "receptionist"}); err == nil {
+			t.Fatal("expected duplicate name to fail")
+		}
+
+		got, err := repos.Roles.FindByID(role.ID)
+		if err != nil || len(got.Permissions) != 2 || got.Permissions[0] != model.PermBookingsManageAny {
+			t.Fatalf("FindByID = %+v, %v", got, err)
+		}
+
+		role.Permissions = model.PermissionList{model.PermRoomsWrite}
+		if err := repos.Roles.Update(&role); err != nil {
+			t.Fatal(err)
+		}
+		all, err := repos.Roles.FindAll()
+		if err != nil || len(all) != 2 || all[0].Name != "facilities" {
+			t.Fatalf("FindAll = %+v, %v, want facilities first", all, err)
+		}
+		if len(all[1].Permissions) != 1 || all[1].Permissions[0] != model.PermRoomsWrite {
+			t.Fatalf("updated permissions = %v", all[1].Permissions)
+		}
+		if _, err := repos.Roles.FindByID(uuid.New()); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("missing role error = %v, want ErrRecordNotFound", err)
+		}
+	})
+
+	t.Run("Assignments", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		bob := mustCreateUser(t, repos, "bob@example.com")
+		role := model.Role{Name: "facilities", Permissions: model.PermissionList{model.PermRoomsWrite}}
+		if err := repos.Roles.Create(&role); err != nil {
+			t.Fatal(err)
+		}
+
+		global := model.RoleAssignment{UserID: alice.ID, RoleID: role.ID}
+		if err := repos.Roles.Assign(&global); err != nil {
+			t.Fatal(err)
+		}
+		scoped := model.RoleAssignment{UserID: alice.ID, RoleID: role.ID, Building: "Tower A", CreatedAt: time.Now().Add(time.Second)}
+		if err := repos.Roles.Assign(&scoped); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Roles.Assign(&model.RoleAssignment{UserID: bob.ID, RoleID: uuid.New()}); err == nil {
+			t.Fatal("expected assigning a missing role to fail")
+		}
+
+		assignments, err := repos.Roles.FindAssignments(alice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(assignments) != 2 || assignments[0].ID != global.ID || assignments[1].Building != "Tower A" {
+			t.Fatalf("FindAssignments = %+v, want the global then the scoped assignment", assignments)
+		}
+		if assignments[0].Role.Name != "facilities" || len(assignments[0].Role.Permissions) != 1 {
+			t.Fatalf("assignment role = %+v, want it loaded", assignments[0].Role)
+		}
+
+		if err := repos.Roles.Unassign(bob.ID, global.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("Unassign of another user's assignment error = %v, want ErrRecordNotFound", err)
+		}
+		if err := repos.Roles.Unassign(alice.ID, global.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		// deleted users lose their roles
+		if err := repos.Users.Delete(alice.ID.String()); err != nil {
+			t.Fatal(err)
+		}
+		if assignments, err := repos.Roles.FindAssignments(alice.ID); err != nil || len(assignments) != 0 {
+			t.Fatalf("FindAssignments after delete = %d, %v, want none", len(assignments), err)
+		}
+	})
+
+	t.Run("DeleteCascades", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		role := model.Role{Name: "receptionist", Permissions: model.PermissionList{model.PermBookingsManageAny}}
+		if err := repos.Roles.Create(&role); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Roles.Assign(&model.RoleAssignment{UserID: alice.ID, RoleID: role.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repos.Roles.Delete(role.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Roles.Delete(role.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("second Delete error = %v, want ErrRecordNotFound", err)
+		}
+		if assignments, err := repos.Roles.FindAssignments(alice.ID); err != nil || len(assignments) != 0 {
+			t.Fatalf("FindAssignments = %d, %v, want none", len(assignments), err)
+		}
+	})
+}
+
+func testDelegations(t *testing.T, newRepos Factory) {
+	t.Run("GrantRevoke", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		bob := mustCreateUser(t, repos, "bob@example.com")
+		carol := mustCreateUser(t, repos, "carol@example.com")
+
+		if err := repos.Delegations.Grant(alice.ID, carol.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Delegations.Grant(alice.ID, bob.ID); err != nil {
+			t.Fatal(err)
+		}
+		// granting again is not an error
+		if err := repos.Delegations.Grant(alice.ID, bob.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Delegations.Grant(alice.ID, uuid.New()); !errors.Is(err, gorm.ErrForeignKeyViolated) {
+			t.Fatalf("Grant to unknown user error = %v, want ErrForeignKeyViolated", err)
+		}
+
+		if ok, err := repos.Delegations.IsDelegate(alice.ID, bob.ID); err != nil || !ok {
+			t.Fatalf("IsDelegate(alice, bob) = %v, %v, want true", ok, err)
+		}
+		// delegation only goes one way
+		if ok, err := repos.Delegations.IsDelegate(bob.ID, alice.ID); err != nil || ok {
+			t.Fatalf("IsDelegate(bob, alice) = %v, %v, want false", ok, err)
+		}
+
+		delegates, err := repos.Delegations.FindDelegates(alice.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(delegates) != 2 || delegates[0].ID != bob.ID || delegates[1].ID != carol.ID {
+			t.Fatalf("FindDelegates = %+v, want bob and carol", delegates)
+		}
+		principals, err := repos.Delegations.FindPrincipals(bob.ID)
+		if err != nil || len(principals) != 1 || principals[0].ID != alice.ID {
+			t.Fatalf("FindPrincipals = %+v, %v, want alice", principals, err)
+		}
+
+		if err := repos.Delegations.Revoke(alice.ID, bob.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Delegations.Revoke(alice.ID, bob.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("second Revoke error = %v, want ErrRecordNotFound", err)
+		}
+		if ok, _ := repos.Delegations.IsDelegate(alice.ID, bob.ID); ok {
+			t.Fatal("IsDelegate after Revoke = true")
+		}
+	})
+
+	t.Run("DeletedUsers", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		bob := mustCreateUser(t, repos, "bob@example.com")
+		if err := repos.Delegations.Grant(alice.ID, bob.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repos.Users.Delete(bob.ID.String()); err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := repos.Delegations.IsDelegate(alice.ID, bob.ID); err != nil || ok {
+			t.Fatalf("IsDelegate for deleted delegate = %v, %v, want false", ok, err)
+		}
+		if delegates, err := repos.Delegations.FindDelegates(alice.ID); err != nil || len(delegates) != 0 {
+			t.Fatalf("FindDelegates = %d, %v, want none", len(delegates), err)
+		}
+		if err := repos.Delegations.Grant(alice.ID, bob.ID); !errors.Is(err, gorm.ErrForeignKeyViolated) {
+			t.Fatalf("Grant to deleted user error = %v, want ErrForeignKeyViolated", err)
+		}
+	})
+
+	t.Run("OtherOrganization", func(t *testing.T) {
+		repos := newRepos(t)
+		acme := mustCreateOrganization(t, repos, "Acme", "acme")
+		other := repos.WithContext(tenant.WithContext(context.Background(), acme.ID))
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		bob := mustCreateUser(t, repos, "bob@example.com")
+		mallory := mustCreateUser(t, other, "mallory@example.com")
+
+		if err := repos.Delegations.Grant(alice.ID, mallory.ID); !errors.Is(err, gorm.ErrForeignKeyViolated) {
+			t.Fatalf("Grant across organizations error = %v, want ErrForeignKeyViolated", err)
+		}
+		if err := repos.Delegations.Grant(alice.ID, bob.ID); err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := other.Delegations.IsDelegate(alice.ID, bob.ID); err != nil || ok {
+			t.Fatalf("IsDelegate from another organization = %v, %v, want false", ok, err)
+		}
+		if err := other.Delegations.Revoke(alice.ID, bob.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("Revoke from another organization error = %v, want ErrRecordNotFound", err)
+		}
+		if delegates, err := other.Delegations.FindDelegates(alice.ID); err != nil || len(delegates) != 0 {
+			t.Fatalf("FindDelegates from another organization = %d, %v, want none", len(delegates), err)
+		}
+	})
+}
+
+func mustCreateOrganization(t *testing.T, repos Repositories, name, slug string) model.Organization {
+	t.Helper()
+	domain := slug + ".example.com"
+	org := model.Organization{Name: name, Slug: slug, Domain: &domain}
+	if err := repos.Orgs.Create(&org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	return org
+}
+
+func testOrganizations(t *testing.T, newRepos Factory) {
+	repos := newRepos(t)
+
+	def, err := repos.Orgs.FindByID(model.DefaultOrganizationID)
+	if err != nil || def.Slug != model.DefaultOrganizationSlug {
+		t.Fatalf("default organization = %+v, %v", def, err)
+	}
+
+	acme := mustCreateOrganization(t, repos, "Acme", "acme")
+	if dup := (model.Organization{Name: "Other", Slug: "acme"}); repos.Orgs.Create(&dup) == nil {
+		t.Fatal("expected duplicate slug to fail")
+	}
+
+	bySlug, err := repos.Orgs.FindBySlug("acme")
+	if err != nil || bySlug.ID != acme.ID {
+		t.Fatalf("FindBySlug = %+v, %v", bySlug, err)
+	}
+	byDomain, err := repos.Orgs.FindByDomain("acme.example.com")
+	if err != nil || byDomain.ID != acme.ID {
+		t.Fatalf("FindByDomain = %+v, %v", byDomain, err)
+	}
+	if _, err := repos.Orgs.FindByDomain("unknown.example.com"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("FindByDomain unknown error = %v, want ErrRecordNotFound", err)
+	}
+
+	all, err := repos.Orgs.FindAll()
+	if err != nil || len(all) != 2 || all[0].ID != acme.ID {
+		t.Fatalf("FindAll = %+v, %v, want ordered by name", all, err)
+	}
+}
+
+// testTenantIsolation checks that nothing created in the default
+// organization can be read or changed from another one
+func testTenantIsolation(t *testing.T, newRepos Factory) {
+	repos := newRepos(t)
+	acme := mustCreateOrganization(t, repos, "Acme", "acme")
+	other := repos.WithContext(tenant.WithContext(context.Background(), acme.ID))
+
+	t.Run("Users", func(t *testing.T) {
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		alice.Role = model.RoleAdmin
+		if err := repos.Users.Update(&alice); err != nil {
+			t.Fatal(err)
+		}
+		// The same email may sign up in another organization
+		twin := mustCreateUser(t, other, "alice@example.com")
+		if twin.OrganizationID != acme.ID {
+			t.Fatalf("OrganizationID = %v, want %v", twin.OrganizationID, acme.ID)
+		}
+
+		if _, err := other.Users.FindByID(alice.ID.String()); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("FindByID across organizations error = %v, want ErrRecordNotFound", err)
+		}
+		found, err := other.Users.FindByEmail("alice@example.com")
+		if err != nil || found.ID != twin.ID {
+			t.Fatalf("FindByEmail = %+v, %v, want the organization's own user", found, err)
+		}
+		if users, err := other.Users.FindAll(); err != nil || len(users) != 1 {
+			t.Fatalf("FindAll = %d users, %v, want 1", len(users), err)
+		}
+		if count, err := other.Users.CountActiveAdmins(); err != nil || count != 0 {
+			t.Fatalf("CountActiveAdmins = %d, %v, want 0", count, err)
+		}
+
+		alice.Name = "Mallory"
+		if err := other.Users.Update(&alice); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("Update across organizations error = %v, want ErrRecordNotFound", err)
+		}
+		if err := other.Users.Delete(alice.ID.String()); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("Delete across organizations error = %v, want ErrRecordNotFound", err)
+		}
+		if got, err := repos.Users.FindByID(alice.ID.String()); err != nil || got.Name == "Mallory" {
+			t.Fatalf("user changed from another organization: %+v, %v", got, err)
+		}
+	})
+
+	t.Run("RoomsAndBookings", func(t *testing.T) {
+		user := mustCreateUser(t, repos, "bob@example.com")
+		room := mustCreateRoom(t, repos, "Hall")
+		booking := mustCreateBooking(t, repos, room, user, 0, 1)
+		mustCreateRoom(t, other, "Lobby")
+
+		if rooms, err := other.Rooms.FindAll(); err != nil || len(rooms) != 1 || rooms[0].Name != "Lobby" {
+			t.Fatalf("FindAll = %+v, %v, want only the organization's room", rooms, err)
+		}
+		if rooms, err := other.Rooms.FindAllForUser(user.ID); err != nil || len(rooms) != 1 {
+			t.Fatalf("FindAllForUser = %+v, %v, want only the organization's room", rooms, err)
+		}
+		if found, err := other.Rooms.FindByID(room.ID); err != nil || found != nil {
+			t.Fatalf("FindByID across organizations = %+v, %v, want nil", found, err)
+		}
+
+		room.Name = "Taken"
+		if err := other.Rooms.Update(&room); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("Update across organizations error = %v, want ErrRecordNotFound", err)
+		}
+		if err := other.Rooms.Delete(room.ID); err != nil {
+			t.Fatal(err)
+		}
+		if found, err := repos.Rooms.FindByID(room.ID); err != nil || found == nil || found.Name != "Hall" {
+			t.Fatalf("room changed from another organization: %+v, %v", found, err)
+		}
+
+		if found, err := other.Bookings.FindByID(booking.ID); err != nil || found != nil {
+			t.Fatalf("booking FindByID across organizations = %+v, %v, want nil", found, err)
+		}
+		if bookings, err := other.Bookings.FindByRoomID(room.ID); err != nil || len(bookings) != 0 {
+			t.Fatalf("FindByRoomID across organizations = %d bookings, %v", len(bookings), err)
+		}
+		if bookings, err := other.Bookings.GetUpcomingBookings(); err != nil || len(bookings) != 0 {
+			t.Fatalf("GetUpcomingBookings across organizations = %d bookings, %v", len(bookings), err)
+		}
+		if err := other.Bookings.Cancel(booking.ID); err != nil {
+			t.Fatal(err)
+		}
+		if found, err := repos.Bookings.FindByID(booking.ID); err != nil || found.Status != model.BookingStatusActive {
+			t.Fatalf("booking cancelled from another organization: %+v, %v", found, err)
+		}
+		// Metrics count every organization
+		if count, err := other.Bookings.CountActiveAt(at(0).Add(time.Minute)); err != nil || count != 1 {
+			t.Fatalf("CountActiveAt = %d, %v, want 1", count, err)
+		}
+	})
+
+	t.Run("GroupsAndAccess", func(t *testing.T) {
+		carol := mustCreateUser(t, repos, "carol@example.com")
+		lab := mustCreateGroup(t, repos, "lab", carol)
+		mustCreateGroup(t, other, "lab")
+		room := mustCreateRoom(t, other, "Vault")
+
+		if _, err := other.Groups.FindByID(lab.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("FindByID across organizations error = %v, want ErrRecordNotFound", err)
+		}
+		if groups, err := other.Groups.FindByUserID(carol.ID); err != nil || len(groups) != 0 {
+			t.Fatalf("FindByUserID across organizations = %+v, %v", groups, err)
+		}
+		if err := other.Groups.AddMember(lab.ID, carol.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("AddMember across organizations error = %v, want ErrRecordNotFound", err)
+		}
+		if err := other.Groups.Delete(lab.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("Delete across organizations error = %v, want ErrRecordNotFound", err)
+		}
+		if err := other.Rooms.SetAccess(room.ID, []uuid.UUID{lab.ID}); err == nil {
+			t.Fatal("expected granting another organization's group to fail")
+		}
+		if members, err := repos.Groups.FindMembers(lab.ID); err != nil || len(members) != 1 {
+			t.Fatalf("group changed from another organization: %+v, %v", members, err)
+		}
+	})
+
+	t.Run("RolesAndAccounts", func(t *testing.T) {
+		role := model.Role{Name: "desk", Permissions: model.PermissionList{model.PermUsersRead}}
+		if err := repos.Roles.Create(&role); err != nil {
+			t.Fatal(err)
+		}
+		if twin := (model.Role{Name: "desk"}); other.Roles.Create(&twin) != nil {
+			t.Fatal("role names must be unique per organization only")
+		}
+		if _, err := other.Roles.FindByID(role.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("role FindByID across organizations error = %v, want ErrRecordNotFound", err)
+		}
+		if err := other.Roles.Delete(role.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("role Delete across organizations error = %v, want ErrRecordNotFound", err)
+		}
+
+		account := model.ServiceAccount{Name: "displays"}
+		if err := other.Accounts.Create(&account); err != nil {
+			t.Fatal(err)
+		}
+		key := model.APIKey{ServiceAccountID: account.ID, Name: "lobby", Prefix: "mbk_test", KeyHash: "tenant-hash"}
+		if err := repos.Accounts.CreateKey(&key); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("CreateKey across organizations error = %v, want ErrRecordNotFound", err)
+		}
+		if err := other.Accounts.CreateKey(&key); err != nil {
+			t.Fatal(err)
+		}
+		if keys, err := repos.Accounts.FindKeys(account.ID); err != nil || len(keys) != 0 {
+			t.Fatalf("FindKeys across organizations = %+v, %v", keys, err)
+		}
+		// Keys are looked up before the organization is known
+		found, err := repos.Accounts.FindKeyByHash("tenant-hash")
+		if err != nil || found.ServiceAccount.OrganizationID != acme.ID {
+			t.Fatalf("FindKeyByHash = %+v, %v, want the key with its account", found, err)
+		}
+	})
+
+	t.Run("Settings", func(t *testing.T) {
+		if err := repos.Settings.Set("feature", "true"); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := other.Settings.Get("feature", "unset"); err != nil || got != "unset" {
+			t.Fatalf("Get across organizations = %q, %v, want the default", got, err)
+		}
+		if err := other.Settings.Set("feature", "false"); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := repos.Settings.Get("feature", ""); err != nil || got != "true" {
+			t.Fatalf("setting changed from another organization: %q, %v", got, err)
+		}
+	})
+}
+
+func testAuditLogs(t *testing.T, newRepos Factory) {
+	repos := newRepos(t)
+	alice, bob := uuid.New(), uuid.New()
+	room, booking := uuid.New(), uuid.New()
+
+	add := func(repos Repositories, hours int, actor *uuid.UUID, entity model.AuditEntity, id uuid.UUID) model.AuditLog {
+		t.Helper()
+		entry := model.AuditLog{
+			ActorType:  model.AuditActorUser,
+			ActorID:    actor,
+			Action:     model.AuditActionUpdate,
+			EntityType: entity,
+			EntityID:   id,
+			Changes:    model.AuditChanges{"name": {From: "Old", To: "New"}},
+			CreatedAt:  at(hours),
+		}
+		if err := repos.Audit.Create(&entry); err != nil {
+			t.Fatalf("create audit log: %v", err)
+		}
+		return entry
+	}
+	first := add(repos, 0, &alice, model.AuditEntityRoom, room)
+	second := add(repos, 1, &bob, model.AuditEntityBooking, booking)
+	third := add(repos, 2, &alice, model.AuditEntityBooking, booking)
+	system := add(repos, 3, nil, model.AuditEntityRoom, room)
+
+	acme := mustCreateOrganization(t, repos, "Acme", "acme")
+	add(repos.WithContext(tenant.WithContext(context.Background(), acme.ID)), 1, &alice, model.AuditEntityRoom, room)
+
+	ids := func(entries []model.AuditLog) []uuid.UUID {
+		out := make([]uuid.UUID, len(entries))
+		for i, e := range entries {
+			out[i] = e.ID
+		}
+		return out
+	}
+	from, to := at(1), at(3)
+	cases := []struct {
+		name   string
+		filter model.AuditLogFilter
+		want   []model.AuditLog
+	}{
+		{"All", model.AuditLogFilter{}, []model.AuditLog{system, third, second, first}},
+		{"EntityType", model.AuditLogFilter{EntityType: model.AuditEntityRoom}, []model.AuditLog{system, first}},
+		{"Entity", model.AuditLogFilter{EntityType: model.AuditEntityBooking, EntityID: &booking}, []model.AuditLog{third, second}},
+		{"Actor", model.AuditLogFilter{ActorID: &alice}, []model.AuditLog{third, first}},
+		{"TimeRange", model.AuditLogFilter{From: &from, To: &to}, []model.AuditLog{third, second}},
+		{"Limit", model.AuditLogFilter{Limit: 2}, []model.AuditLog{system, third}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := repos.Audit.Find(tc.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g, w := ids(got), ids(tc.want); !slices.Equal(g, w) {
+				t.Fatalf("Find = %v, want %v", g, w)
+			}
+		})
+	}
+
+	got, err := repos.Audit.Find(model.AuditLogFilter{EntityID: &first.EntityID, ActorID: &alice})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("Find = %d entries, %v, want 1", len(got), err)
+	}
+	if c := got[0].Changes["name"]; c.From != "Old" || c.To != "New" || got[0].OrganizationID != model.DefaultOrganizationID {
+		t.Fatalf("entry = %+v, want the stored changes in the default organization", got[0])
+	}
+}
+
+func testAudited(t *testing.T, newRepos Factory) {
+	repos := newRepos(t)
+	admin := mustCreateUser(t, repos, "admin@example.com")
+	ctx := audit.WithActor(context.Background(), audit.Actor{Type: model.AuditActorUser, ID: admin.ID})
+	ctx = audit.WithSource(ctx, audit.Source{IP: "192.0.2.1", RequestID: "req-1"})
+
+	users := repository.NewAuditedUserRepository(repos.Users, repos.Audit).WithContext(ctx)
+	rooms := repository.NewAuditedRoomRepository(repos.Rooms, repos.Audit).WithContext(ctx)
+	bookings := repository.NewAuditedBookingRepository(repos.Bookings, repos.Audit).WithContext(ctx)
+
+	actions := func(t *testing.T, id uuid.UUID) []model.AuditLog {
+		t.Helper()
+		entries, err := repos.Audit.Find(model.AuditLogFilter{EntityID: &id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entries
+	}
+	assertActions := func(t *testing.T, entries []model.AuditLog, want ...model.AuditAction) {
+		t.Helper()
+		got := make([]model.AuditAction, len(entries))
+		for i, e := range entries {
+			got[i] = e.Action
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("actions = %v, want %v", got, want)
+		}
+	}
+
+	t.Run("Users", func(t *testing.T) {
+		user := model.User{Name: "Alice", Email: "alice@example.com", Password: "hash", Role: model.RoleUser}
+		if err := users.Create(&user); err != nil {
+			t.Fatal(err)
+		}
+		user.Name = "Alice Smith"
+		if err := users.Update(&user); err != nil {
+			t.Fatal(err)
+		}
+		// Updates that change nothing are not recorded
+		if err := users.Update(&user); err != nil {
+			t.Fatal(err)
+		}
+		if err := users.Delete(user.ID.String()); err != nil {
+			t.Fatal(err)
+		}
+
+		entries := actions(t, user.ID)
+		assertActions(t, entries, model.AuditActionDelete, model.AuditActionUpdate, model.AuditActionCreate)
+		update := entries[1]
+		if c := update.Changes["name"]; c.From != "Alice" || c.To != "Alice Smith" || len(update.Changes) != 1 {
+			t.Fatalf("update changes = %+v, want only the name", update.Changes)
+		}
+		if update.ActorType != model.AuditActorUser || update.ActorID == nil || *update.ActorID != admin.ID ||
+			update.IP != "192.0.2.1" || update.RequestID != "req-1" || update.EntityType != model.AuditEntityUser {
+			t.Fatalf("update entry = %+v, want the actor and source of the context", update)
+		}
+		if _, ok := entries[2].Changes["password"]; ok {
+			t.Fatal("password hash recorded in the audit log")
+		}
+		if c := entries[2].Changes["email"]; c.From != nil || c.To != "alice@example.com" {
+			t.Fatalf("create email change = %+v", c)
+		}
+	})
+
+	t.Run("UserSecrets", func(t *testing.T) {
+		user := model.User{Name: "Bob", Email: "bob@example.com", Password: "old-hash", Role: model.RoleUser}
+		if err := users.Create(&user); err != nil {
+			t.Fatal(err)
+		}
+		user.Password = "new-hash"
+		revoked := time.Now().UTC()
+		user.TokensValidAfter = &revoked
+		if err := users.Update(&user); err != nil {
+			t.Fatal(err)
+		}
+		user.TOTPSecret = "SECRET"
+		if err := users.Update(&user); err != nil {
+			t.Fatal(err)
+		}
+
+		entries := actions(t, user.ID)
+		assertActions(t, entries, model.AuditActionUpdate, model.AuditActionUpdate, model.AuditActionCreate)
+		want := model.AuditChanges{
+			"password":           {To: model.AuditRedacted},
+			"tokens_valid_after": {To: model.AuditRedacted},
+		}
+		if !reflect.DeepEqual(entries[1].Changes, want) {
+			t.Fatalf("password change = %+v, want %+v", entries[1].Changes, want)
+		}
+		if want := (model.AuditChanges{"totp_secret": {To: model.AuditRedacted}}); !reflect.DeepEqual(entries[0].Changes, want) {
+			t.Fatalf("2fa enrollment = %+v, want %+v", entries[0].Changes, want)
+		}
+	})
+
+	t.Run("Rooms", func(t *testing.T) {
+		room := model.Room{Name: "Orchid", Capacity: 4}
+		if err := rooms.Create(&room); err != nil {
+			t.Fatal(err)
+		}
+		room.Capacity = 6
+		if err := rooms.Update(&room); err != nil {
+			t.Fatal(err)
+		}
+		if err := rooms.Delete(room.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		entries := actions(t, room.ID)
+		assertActions(t, entries, model.AuditActionDelete, model.AuditActionUpdate, model.AuditActionCreate)
+		if c := entries[1].Changes["capacity"]; c.From != float64(4) || c.To != float64(6) {
+			t.Fatalf("capacity change = %+v", c)
+		}
+	})
+
+	t.Run("Bookings", func(t *testing.T) {
+		room := mustCreateRoom(t, repos, "Lotus")
+		booking := model.Booking{RoomID: room.ID, UserID: admin.ID, StartTime: at(0), EndTime: at(1)}
+		if err := bookings.Create(&booking); err != nil {
+			t.Fatal(err)
+		}
+		booking.EndTime = at(2)
+		if err := bookings.Update(&booking); err != nil {
+			t.Fatal(err)
+		}
+		if err := bookings.Cancel(booking.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		entries := actions(t, booking.ID)
+		assertActions(t, entries, model.AuditActionCancel, model.AuditActionUpdate, model.AuditActionCreate)
+		if c := entries[0].Changes["status"]; c.From != string(model.BookingStatusActive) || c.To != string(model.BookingStatusCancelled) {
+			t.Fatalf("cancel changes = %+v, want the status", entries[0].Changes)
+		}
+		if _, ok := entries[1].Changes["room"]; ok || len(entries[1].Changes) != 1 {
+			t.Fatalf("update changes = %+v, want only the end time", entries[1].Changes)
+		}
+	})
+
+	t.Run("BookingGroups", func(t *testing.T) {
+		room := mustCreateRoom(t, repos, "Jasmine")
+		group := model.BookingGroup{UserID: admin.ID}
+		batch := []model.Booking{
+			{RoomID: room.ID, UserID: admin.ID, StartTime: at(10), EndTime: at(11), Status: model.BookingStatusActive},
+			{RoomID: room.ID, UserID: admin.ID, StartTime: at(12), EndTime: at(13), Status: model.BookingStatusActive},
+		}
+		if conflicts, err := bookings.CreateGroup(&group, batch); err != nil || len(conflicts) > 0 {
+			t.Fatalf("CreateGroup = %v, %v", conflicts, err)
+		}
+		if err := bookings.CancelGroup(group.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, b := range batch {
+			assertActions(t, actions(t, b.ID), model.AuditActionCancel, model.AuditActionCreate)
+		}
+	})
+
+	t.Run("FailedWrite", func(t *testing.T) {
+		down := failingAuditLog{repos.Audit}
+		rooms := repository.NewAuditedRoomRepository(repos.Rooms, down).WithContext(ctx)
+		bookings := repository.NewAuditedBookingRepository(repos.Bookings, down).WithContext(ctx)
+
+		room := model.Room{Name: "Iris", Capacity: 4}
+		if err := rooms.Create(&room); !errors.Is(err, errAuditLogDown) {
+			t.Fatalf("Create room error = %v, want the audit log's", err)
+		}
+		booking := model.Booking{RoomID: room.ID, UserID: admin.ID, StartTime: at(14), EndTime: at(15), Status: model.BookingStatusActive}
+		if err := bookings.Create(&booking); !errors.Is(err, errAuditLogDown) {
+			t.Fatalf("Create booking error = %v, want the audit log's", err)
+		}
+		if err := bookings.Cancel(booking.ID); !errors.Is(err, errAuditLogDown) {
+			t.Fatalf("Cancel error = %v, want the audit log's", err)
+		}
+	})
+}
+
+var errAuditLogDown = errors.New("audit log unavailable")
+
+// failingAuditLog refuses every entry
+type failingAuditLog struct {
+	repository.AuditLogRepository
+}
+
+func (l failingAuditLog) WithContext(context.Context) repository.AuditLogRepository { return l }
+
+func (failingAuditLog) Create(*model.AuditLog) error { return errAuditLogDown }
+
+// testReports books a week in the past, starting Monday 6 January 2020,
+// and checks the sums of each grouping
+func testReports(t *testing.T, newRepos Factory) {
+	repos := newRepos(t)
+	user := mustCreateUser(t, repos, "alice@example.com")
+	large := model.Room{Name: "Large", Capacity: 10, Building: "Tower", Floor: "1"}
+	small := model.Room{Name: "Small", Capacity: 4, Building: "Tower", Floor: "2"}
+	unused := model.Room{Name: "Unused", Capacity: 6, Building: "Annex"}
+	for _, room := range []*model.Room{&large, &small, &unused} {
+		if err := repos.Rooms.Create(room); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	monday := time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+	book := func(room model.Room, start time.Time, hours int, attendees *int) model.Booking {
+		t.Helper()
+		b := model.Booking{
+			RoomID:    room.ID,
+			UserID:    user.ID,
+			StartTime: start,
+			EndTime:   start.Add(time.Duration(hours) * time.Hour),
+			CreatedAt: start.Add(-24 * time.Hour),
+		}
+		if attendees != nil {
+			checkedIn := start
+			b.CheckedInAt, b.Attendees = &checkedIn, attendees
+		}
+		if err := repos.Bookings.Create(&b); err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	five := 5
+	// held and checked in, Monday 09:00 to 11:00
+	book(large, monday.Add(9*time.Hour), 2, &five)
+	// a no-show past midnight, Tuesday 23:00 to Wednesday 01:00
+	book(large, monday.Add(47*time.Hour), 2, nil)
+	// cancelled, Wednesday 14:00 to 15:00
+	cancelled := book(small, monday.Add(62*time.Hour), 1, nil)
+	if err := repos.Bookings.Cancel(cancelled.ID); err != nil {
+		t.Fatal(err)
+	}
+	// after the report
+	book(small, monday.Add(9*24*time.Hour), 1, nil)
+
+	filter := model.UtilizationFilter{From: monday, To: monday.AddDate(0, 0, 7), Location: time.UTC}
+	utilization := func(by model.ReportGrouping, loc *time.Location) []model.UtilizationAggregate {
+		t.Helper()
+		f := filter
+		f.GroupBy, f.Location = by, loc
+		aggregates, err := repos.Reports.Utilization(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return aggregates
+	}
+	find := func(aggregates []model.UtilizationAggregate, match func(model.UtilizationAggregate) bool) model.UtilizationAggregate {
+		t.Helper()
+		for _, a := range aggregates {
+			if match(a) {
+				return a
+			}
+		}
+		t.Fatalf("no aggregate matches in %+v", aggregates)
+		return model.UtilizationAggregate{}
+	}
+	near := func(got, want float64) bool { return got > want-1 && got < want+1 }
+
+	t.Run("ByRoom", func(t *testing.T) {
+		aggregates := utilization(model.GroupByRoom, time.UTC)
+		if len(aggregates) != 2 {
+			t.Fatalf("got %d aggregates, want one per booked room", len(aggregates))
+		}
+		a := find(aggregates, func(a model.UtilizationAggregate) bool { return a.RoomID == large.ID })
+		if a.Bookings != 2 || a.Cancelled != 0 || a.Held != 2 || a.NoShows != 1 || a.Attended != 1 || a.Attendees != 5 {
+			t.Fatalf("large room counts = %+v", a)
+		}
+		if !near(a.BookedSeconds, 4*3600) || !near(a.LeadSeconds, 2*24*3600) || a.CapacityShare < 0.49 || a.CapacityShare > 0.51 {
+			t.Fatalf("large room sums = %+v", a)
+		}
+		a = find(aggregates, func(a model.UtilizationAggregate) bool { return a.RoomID == small.ID })
+		if a.Bookings != 1 || a.Cancelled != 1 || a.Held != 0 || a.BookedSeconds != 0 {
+			t.Fatalf("small room = %+v, want only the cancellation", a)
+		}
+	})
+
+	t.Run("ByFloorAndBuilding", func(t *testing.T) {
+		a := find(utilization(model.GroupByFloor, time.UTC), func(a model.UtilizationAggregate) bool {
+			return a.Building == "Tower" && a.Floor == "2"
+		})
+		if a.Bookings != 1 || a.Cancelled != 1 {
+			t.Fatalf("floor 2 = %+v", a)
+		}
+		aggregates := utilization(model.GroupByBuilding, time.UTC)
+		if len(aggregates) != 1 || aggregates[0].Building != "Tower" || aggregates[0].Bookings != 3 {
+			t.Fatalf("ByBuilding = %+v, want the three Tower bookings", aggregates)
+		}
+	})
+
+	t.Run("ByWeekday", func(t *testing.T) {
+		aggregates := utilization(model.GroupByWeekday, time.UTC)
+		for _, day := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday} {
+			if a := find(aggregates, func(a model.UtilizationAggregate) bool { return a.Weekday == int(day) }); a.Bookings != 1 {
+				t.Fatalf("%s = %+v, want one booking", day, a)
+			}
+		}
+	})
+
+	t.Run("ByHour", func(t *testing.T) {
+		aggregates := utilization(model.GroupByHour, time.UTC)
+		for _, hour := range []int{9, 10, 23, 0} {
+			a := find(aggregates, func(a model.UtilizationAggregate) bool { return a.Hour == hour })
+			if a.Bookings != 1 || !near(a.BookedSeconds, 3600) {
+				t.Fatalf("hour %d = %+v, want one booked hour", hour, a)
+			}
+		}
+		if a := find(aggregates, func(a model.UtilizationAggregate) bool { return a.Hour == 14 }); a.Cancelled != 1 || a.BookedSeconds != 0 {
+			t.Fatalf("hour 14 = %+v, want the cancellation", a)
+		}
+		if len(aggregates) != 5 {
+			t.Fatalf("got %d hours, want 5", len(aggregates))
+		}
+
+		// 09:00 UTC is 16:00 in Jakarta
+		jakarta, err := time.LoadLocation("Asia/Jakarta")
+		if err != nil {
+			t.Skip("no timezone database")
+		}
+		aggregates = utilization(model.GroupByHour, jakarta)
+		if a := find(aggregates, func(a model.UtilizationAggregate) bool { return a.Hour == 16 }); a.Bookings != 1 {
+			t.Fatalf("16:00 in Jakarta = %+v, want one booking", a)
+		}
+	})
+
+	t.Run("OtherOrganization", func(t *testing.T) {
+		acme := mustCreateOrganization(t, repos, "Acme", "acme")
+		other := repos.WithContext(tenant.WithContext(context.Background(), acme.ID))
+		aggregates, err := other.Reports.Utilization(filter)
+		if err != nil || len(aggregates) != 0 {
+			t.Fatalf("Utilization from another organization = %+v, %v, want none", aggregates, err)
+		}
+	})
+}
+
+func testStrikes(t *testing.T, newRepos Factory) {
+	t.Run("CreateFindClear", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		bob := mustCreateUser(t, repos, "bob@example.com")
+		room := mustCreateRoom(t, repos, "Hall")
+		first := mustCreateBooking(t, repos, room, alice, 0, 1)
+		second := mustCreateBooking(t, repos, room, alice, 1, 2)
+		third := mustCreateBooking(t, repos, room, bob, 2, 3)
+
+		strike := func(b model.Booking, reason model.StrikeReason, hour int) model.Strike {
+			t.Helper()
+			s := model.Strike{UserID: b.UserID, BookingID: b.ID, Reason: reason, OccurredAt: at(hour)}
+			created, err := repos.Strikes.Create(&s)
+			if err != nil || !created {
+				t.Fatalf("Create = %v, %v, want created", created, err)
+			}
+			return s
+		}
+		older := strike(first, model.StrikeNoShow, 1)
+		newer := strike(second, model.StrikeLateCancellation, 0)
+		strike(third, model.StrikeNoShow, 3)
+
+		// a booking earns one strike at most
+		again := model.Strike{UserID: alice.ID, BookingID: first.ID, Reason: model.StrikeLateCancellation, OccurredAt: at(1)}
+		if created, err := repos.Strikes.Create(&again); err != nil || created {
+			t.Fatalf("second Create for a booking = %v, %v, want not created", created, err)
+		}
+
+		strikes, err := repos.Strikes.FindByUserID(alice.ID, at(-1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(strikes) != 2 || strikes[0].ID != older.ID || strikes[1].ID != newer.ID {
+			t.Fatalf("FindByUserID = %+v, want newest first", strikes)
+		}
+		if strikes, _ := repos.Strikes.FindByUserID(alice.ID, at(1)); len(strikes) != 1 || strikes[0].ID != older.ID {
+			t.Fatalf("FindByUserID since +1h = %+v, want the older strike", strikes)
+		}
+		if strikes, _ := repos.Strikes.FindSince(at(-1)); len(strikes) != 3 || strikes[0].UserID != bob.ID {
+			t.Fatalf("FindSince = %+v, want all three, bob's first", strikes)
+		}
+
+		if err := repos.Strikes.Clear(bob.ID, older.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("Clear of another user's strike error = %v, want ErrRecordNotFound", err)
+		}
+		if err := repos.Strikes.Clear(alice.ID, older.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Strikes.Clear(alice.ID, older.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("second Clear error = %v, want ErrRecordNotFound", err)
+		}
+		if strikes, _ := repos.Strikes.FindByUserID(alice.ID, at(-1)); len(strikes) != 1 || strikes[0].ID != newer.ID {
+			t.Fatalf("FindByUserID after Clear = %+v, want the newer strike", strikes)
+		}
+		// cleared strikes still hold their booking
+		if created, err := repos.Strikes.Create(&again); err != nil || created {
+			t.Fatalf("Create for a cleared booking = %v, %v, want not created", created, err)
+		}
+
+		if cleared, err := repos.Strikes.ClearAll(alice.ID); err != nil || cleared != 1 {
+			t.Fatalf("ClearAll = %d, %v, want 1", cleared, err)
+		}
+		if strikes, _ := repos.Strikes.FindByUserID(alice.ID, at(-1)); len(strikes) != 0 {
+			t.Fatalf("FindByUserID after ClearAll = %+v, want none", strikes)
+		}
+		if strikes, _ := repos.Strikes.FindByUserID(bob.ID, at(-1)); len(strikes) != 1 {
+			t.Fatalf("ClearAll cleared another user's strikes: %+v", strikes)
+		}
+	})
+
+	t.Run("NoShows", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		hall := mustCreateRoom(t, repos, "Hall")
+		closed := mustCreateRoom(t, repos, "Closed")
+
+		missed := mustCreateBooking(t, repos, hall, alice, 0, 1)
+		earlier := mustCreateBooking(t, repos, hall, alice, -1, 0)
+		checkedIn := mustCreateBooking(t, repos, hall, alice, 1, 2)
+		checkedIn.CheckedInAt = &checkedIn.StartTime
+		if err := repos.Bookings.Update(&checkedIn); err != nil {
+			t.Fatal(err)
+		}
+		cancelled := mustCreateBooking(t, repos, hall, alice, 2, 3)
+		if err := repos.Bookings.Cancel(cancelled.ID); err != nil {
+			t.Fatal(err)
+		}
+		struck := mustCreateBooking(t, repos, hall, alice, 3, 4)
+		if _, err := repos.Strikes.Create(&model.Strike{UserID: alice.ID, BookingID: struck.ID, Reason: model.StrikeNoShow, OccurredAt: at(4)}); err != nil {
+			t.Fatal(err)
+		}
+		mustCreateBooking(t, repos, closed, alice, 4, 5)
+		if err := repos.Rooms.Delete(closed.ID); err != nil {
+			t.Fatal(err)
+		}
+		// ends after the range
+		mustCreateBooking(t, repos, hall, alice, 5, 6)
+
+		bookings, err := repos.Strikes.FindNoShows(at(0), at(6))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(bookingIDs(bookings), []uuid.UUID{earlier.ID, missed.ID}) {
+			t.Fatalf("FindNoShows = %v, want the two unchecked bookings oldest first", bookingIDs(bookings))
+		}
+		if bookings[0].Room.Name != "Hall" {
+			t.Fatalf("FindNoShows room = %+v, want it preloaded", bookings[0].Room)
+		}
+	})
+
+	t.Run("OtherOrganization", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustCreateUser(t, repos, "alice@example.com")
+		room := mustCreateRoom(t, repos, "Hall")
+		booking := mustCreateBooking(t, repos, room, alice, 0, 1)
+		s := model.Strike{UserID: alice.ID, BookingID: booking.ID, Reason: model.StrikeNoShow, OccurredAt: at(1)}
+		if _, err := repos.Strikes.Create(&s); err != nil {
+			t.Fatal(err)
+		}
+
+		acme := mustCreateOrganization(t, repos, "Acme", "acme")
+		other := repos.WithContext(tenant.WithContext(context.Background(), acme.ID))
+		if strikes, err := other.Strikes.FindSince(at(-1)); err != nil || len(strikes) != 0 {
+			t.Fatalf("FindSince from another organization = %+v, %v, want none", strikes, err)
+		}
+		if err := other.Strikes.Clear(alice.ID, s.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("Clear from another organization error = %v, want ErrRecordNotFound", err)
+		}
+		if bookings, err := other.Strikes.FindNoShows(at(-1), at(2)); err != nil || len(bookings) != 0 {
+			t.Fatalf("FindNoShows from another organization = %d, %v, want none", len(bookings), err)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	WithContext(ctx context.Context) RoleRepository
+	Create(role *model.Role) error
+	// FindAll returns every custom role ordered by name
+	FindAll() ([]model.Role, error)
+	FindByID(id uuid.UUID) (*model.Role, error)
+	Update(role *model.Role) error
+	// Delete removes the role and takes it away from everyone it was
+	// assigned to
+	Delete(id uuid.UUID) error
+	Assign(assignment *model.RoleAssignment) error
+	// Unassign removes one of the user's role assignments, it returns
+	// gorm.ErrRecordNotFound when the user has no such assignment
+	Unassign(userID, assignmentID uuid.UUID) error
+	// FindAssignments returns the roles assigned to a user with their Role
+	// loaded, oldest first
+	FindAssignments(userID uuid.UUID) ([]model.RoleAssignment, error)
+}
+
+type roleRepository struct {
+	db  *gorm.DB
+	org uuid.UUID
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository whose queries run with ctx, limited to
+// the organization ctx acts for
+func (r *roleRepository) WithContext(ctx context.Context) RoleRepository {
+	return &roleRepository{db: r.db.WithContext(ctx), org: tenant.FromContext(ctx)}
+}
+
+// scoped limits a query on roles to the repository's organization
+func (r *roleRepository) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("roles.organization_id = ?", r.org)
+}
+
+func (r *roleRepository) Create(role *model.Role) error {
+	role.OrganizationID = r.org
+	return r.db.Create(role).Error
+}
+
+func (r *roleRepository) FindAll() ([]model.Role, error) {
+	var roles []model.Role
+	err := r.scoped(r.db).Order("name").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) FindByID(id uuid.UUID) (*model.Role, error) {
+	return r.find(r.db, id)
+}
+
+func (r *roleRepository) find(db *gorm.DB, id uuid.UUID) (*model.Role, error) {
+	var role model.Role
+	err := r.scoped(db).First(&role, "id = ?", id).Error
+	return &role, err
+}
+
+func (r *roleRepository) Update(role *model.Role) error {
+	role.OrganizationID = r.org
+	return updateAll(r.scoped(r.db), role)
+}
+
+func (r *roleRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := r.find(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&model.RoleAssignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Role{}, "id = ?", id).Error
+	})
+}
+
+func (r *roleRepository) Assign(assignment *model.RoleAssignment) error {
+	if _, err := r.find(r.db, assignment.RoleID); err != nil {
+		return err
+	}
+	return r.db.Omit("Role", "User").Create(assignment).Error
+}
+
+func (r *roleRepository) Unassign(userID, assignmentID uuid.UUID) error {
+	result := r.db.
+		Where("id = ? AND user_id = ?", assignmentID, userID).
+		Where("role_id IN (?)", r.scoped(r.db.Model(&model.Role{})).Select("id")).
+		Delete(&model.RoleAssignment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *roleRepository) FindAssignments(userID uuid.UUID) ([]model.RoleAssignment, error) {
+	var assignments []model.RoleAssignment
+	err := r.db.
+		Preload("Role").
+		Where("user_id = ?", userID).
+		Where("role_id IN (?)", r.scoped(r.db.Model(&model.Role{})).Select("id")).
+		Order("created_at").
+		Find(&assignments).Error
+	return assignments, err
+}
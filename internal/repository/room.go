@@ -1,40 +1,69 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tenant"
 	"gorm.io/gorm"
 )
 
 type RoomRepository interface {
+	WithContext(ctx context.Context) RoomRepository
 	FindAll() ([]model.Room, error)
 	Create(room *model.Room) error
 	FindByID(id uuid.UUID) (*model.Room, error)
 	Update(room *model.Room) error
 	Delete(id uuid.UUID) error
+	// FindAllForUser returns the rooms userID may book, those that are not
+	// restricted and the restricted ones granted to one of their groups.
+	// uuid.Nil gets the unrestricted rooms only.
+	FindAllForUser(userID uuid.UUID) ([]model.Room, error)
+	// HasAccess reports whether one of the user's groups is on the room's
+	// access list
+	HasAccess(roomID, userID uuid.UUID) (bool, error)
+	// FindAccess returns the groups on the room's access list ordered by
+	// name
+	FindAccess(roomID uuid.UUID) ([]model.Group, error)
+	// SetAccess replaces the room's access list with groupIDs
+	SetAccess(roomID uuid.UUID, groupIDs []uuid.UUID) error
 }
 
 type roomRepository struct {
-	db *gorm.DB
+	db  *gorm.DB
+	org uuid.UUID
 }
 
 func NewRoomRepository(db *gorm.DB) RoomRepository {
-	return &roomRepository{db: db}
+	return &roomRepository{db: db, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository whose queries run with ctx, limited to
+// the organization ctx acts for
+func (r *roomRepository) WithContext(ctx context.Context) RoomRepository {
+	return &roomRepository{db: r.db.WithContext(ctx), org: tenant.FromContext(ctx)}
+}
+
+// scoped limits a query on rooms to the repository's organization
+func (r *roomRepository) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("rooms.organization_id = ?", r.org)
 }
 
 func (r *roomRepository) FindAll() ([]model.Room, error) {
 	var rooms []model.Room
-	err := r.db.Find(&rooms).Error
+	err := r.scoped(r.db).Find(&rooms).Error
 	return rooms, err
 }
 
 func (r *roomRepository) Create(room *model.Room) error {
+	room.OrganizationID = r.org
 	return r.db.Create(room).Error
 }
 
 func (r *roomRepository) FindByID(id uuid.UUID) (*model.Room, error) {
 	var room model.Room
-	err := r.db.First(&room, "id = ?", id).Error
+	err := r.scoped(r.db).First(&room, "id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -45,9 +74,83 @@ func (r *roomRepository) FindByID(id uuid.UUID) (*model.Room, error) {
 }
 
 func (r *roomRepository) Update(room *model.Room) error {
-	return r.db.Save(room).Error
+	room.OrganizationID = r.org
+	return updateAll(r.scoped(r.db), room)
 }
 
 func (r *roomRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&model.Room{}, "id = ?", id).Error
+	return r.scoped(r.db).Delete(&model.Room{}, "id = ?", id).Error
+}
+
+// grantedRooms selects the IDs of the rooms granted to one of the user's
+// groups
+func (r *roomRepository) grantedRooms(userID uuid.UUID) *gorm.DB {
+	return r.db.Model(&model.RoomAccess{}).
+		Select("room_accesses.room_id").
+		Joins("JOIN group_members ON group_members.group_id = room_accesses.group_id").
+		Where("group_members.user_id = ?", userID)
+}
+
+func (r *roomRepository) FindAllForUser(userID uuid.UUID) ([]model.Room, error) {
+	var rooms []model.Room
+	err := r.scoped(r.db).Where("restricted = ? OR id IN (?)", false, r.grantedRooms(userID)).Find(&rooms).Error
+	return rooms, err
+}
+
+func (r *roomRepository) HasAccess(roomID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.grantedRooms(userID).
+		Joins("JOIN rooms ON rooms.id = room_accesses.room_id").
+		Where("room_accesses.room_id = ?", roomID).
+		Scopes(r.scoped).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *roomRepository) FindAccess(roomID uuid.UUID) ([]model.Group, error) {
+	var groups []model.Group
+	err := r.db.
+		Where("organization_id = ?", r.org).
+		Where("id IN (?)", r.db.Model(&model.RoomAccess{}).Select("group_id").Where("room_id = ?", roomID)).
+		Order("name").
+		Find(&groups).Error
+	return groups, err
+}
+
+func (r *roomRepository) SetAccess(roomID uuid.UUID, groupIDs []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// The room and the groups must belong to this organization
+		var rooms, groups int64
+		if err := r.scoped(tx.Model(&model.Room{})).Where("id = ?", roomID).Count(&rooms).Error; err != nil {
+			return err
+		}
+		if len(groupIDs) > 0 {
+			err := tx.Model(&model.Group{}).Where("organization_id = ? AND id IN ?", r.org, groupIDs).Count(&groups).Error
+			if err != nil {
+				return err
+			}
+		}
+		if rooms == 0 || groups < int64(len(distinct(groupIDs))) {
+			return gorm.ErrForeignKeyViolated
+		}
+		if err := tx.Where("room_id = ?", roomID).Delete(&model.RoomAccess{}).Error; err != nil {
+			return err
+		}
+		if len(groupIDs) == 0 {
+			return nil
+		}
+		access := make([]model.RoomAccess, len(groupIDs))
+		for i, groupID := range groupIDs {
+			access[i] = model.RoomAccess{RoomID: roomID, GroupID: groupID}
+		}
+		return tx.Omit("Room", "Group").Create(&access).Error
+	})
+}
+
+func distinct(ids []uuid.UUID) map[uuid.UUID]struct{} {
+	set := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
 }
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type ServiceAccountRepository interface {
+	WithContext(ctx context.Context) ServiceAccountRepository
+	Create(account *model.ServiceAccount) error
+	FindAll() ([]model.ServiceAccount, error)
+	FindByID(id uuid.UUID) (*model.ServiceAccount, error)
+	// Delete removes the service account together with its keys
+	Delete(id uuid.UUID) error
+	CreateKey(key *model.APIKey) error
+	// FindKeys returns the keys of a service account, newest first
+	FindKeys(accountID uuid.UUID) ([]model.APIKey, error)
+	// FindKeyByHash returns the key with the given hash and its
+	// ServiceAccount, revoked and expired keys included. It looks in every
+	// organization, the key's account tells which one the caller acts for.
+	FindKeyByHash(keyHash string) (*model.APIKey, error)
+	// RevokeKey revokes a key of the service account, it returns
+	// gorm.ErrRecordNotFound when the account has no such key
+	RevokeKey(accountID, keyID uuid.UUID, now time.Time) error
+	// TouchKey records that the key was used at now
+	TouchKey(keyID uuid.UUID, now time.Time) error
+}
+
+type serviceAccountRepository struct {
+	db  *gorm.DB
+	org uuid.UUID
+}
+
+func NewServiceAccountRepository(db *gorm.DB) ServiceAccountRepository {
+	return &serviceAccountRepository{db: db, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository whose queries run with ctx, limited to
+// the organization ctx acts for
+func (r *serviceAccountRepository) WithContext(ctx context.Context) ServiceAccountRepository {
+	return &serviceAccountRepository{db: r.db.WithContext(ctx), org: tenant.FromContext(ctx)}
+}
+
+// scoped limits a query on service accounts to the repository's
+// organization
+func (r *serviceAccountRepository) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("service_accounts.organization_id = ?", r.org)
+}
+
+// accounts selects the IDs of the organization's service accounts, keys
+// belong to the organization of their account
+func (r *serviceAccountRepository) accounts() *gorm.DB {
+	return r.scoped(r.db.Model(&model.ServiceAccount{})).Select("id")
+}
+
+func (r *serviceAccountRepository) Create(account *model.ServiceAccount) error {
+	account.OrganizationID = r.org
+	return r.db.Create(account).Error
+}
+
+func (r *serviceAccountRepository) FindAll() ([]model.ServiceAccount, error) {
+	var accounts []model.ServiceAccount
+	err := r.scoped(r.db).Order("name").Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *serviceAccountRepository) FindByID(id uuid.UUID) (*model.ServiceAccount, error) {
+	return r.find(r.db, id)
+}
+
+func (r *serviceAccountRepository) find(db *gorm.DB, id uuid.UUID) (*model.ServiceAccount, error) {
+	var account model.ServiceAccount
+	err := r.scoped(db).First(&account, "id = ?", id).Error
+	return &account, err
+}
+
+func (r *serviceAccountRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := r.find(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Where("service_account_id = ?", id).Delete(&model.APIKey{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.ServiceAccount{}, "id = ?", id).Error
+	})
+}
+
+func (r *serviceAccountRepository) CreateKey(key *model.APIKey) error {
+	if _, err := r.find(r.db, key.ServiceAccountID); err != nil {
+		return err
+	}
+	return r.db.Omit("ServiceAccount").Create(key).Error
+}
+
+func (r *serviceAccountRepository) FindKeys(accountID uuid.UUID) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.db.
+		Where("service_account_id = ? AND service_account_id IN (?)", accountID, r.accounts()).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+func (r *serviceAccountRepository) FindKeyByHash(keyHash string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.Preload("ServiceAccount").First(&key, "key_hash = ?", keyHash).Error
+	return &key, err
+}
+
+func (r *serviceAccountRepository) RevokeKey(accountID, keyID uuid.UUID, now time.Time) error {
+	var key model.APIKey
+	err := r.db.First(&key, "id = ? AND service_account_id = ? AND service_account_id IN (?)", keyID, accountID, r.accounts()).Error
+	if err != nil {
+		return err
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+	return r.db.Model(&model.APIKey{}).Where("id = ?", keyID).Update("revoked_at", now.UTC()).Error
+}
+
+func (r *serviceAccountRepository) TouchKey(keyID uuid.UUID, now time.Time) error {
+	return r.db.Model(&model.APIKey{}).
+		Where("id = ? AND service_account_id IN (?)", keyID, r.accounts()).
+		Update("last_used_at", now.UTC()).Error
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SettingRepository interface {
+	WithContext(ctx context.Context) SettingRepository
+	// Get returns the value of key, or def when it was never set
+	Get(key, def string) (string, error)
+	Set(key, value string) error
+}
+
+type settingRepository struct {
+	db  *gorm.DB
+	org uuid.UUID
+}
+
+func NewSettingRepository(db *gorm.DB) SettingRepository {
+	return &settingRepository{db: db, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository whose queries run with ctx, reading and
+// writing the settings of the organization ctx acts for
+func (r *settingRepository) WithContext(ctx context.Context) SettingRepository {
+	return &settingRepository{db: r.db.WithContext(ctx), org: tenant.FromContext(ctx)}
+}
+
+func (r *settingRepository) Get(key, def string) (string, error) {
+	var setting model.Setting
+	err := r.db.First(&setting, "organization_id = ? AND key = ?", r.org, key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return def, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return setting.Value, nil
+}
+
+func (r *settingRepository) Set(key, value string) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&model.Setting{OrganizationID: r.org, Key: key, Value: value}).Error
+}
+
+// LoadOpeningHours returns the organization's opening hours, or the
+// defaults when they were never set
+func LoadOpeningHours(settings SettingRepository) (model.OpeningHours, error) {
+	var hours model.OpeningHours
+	ok, err := getJSON(settings, model.SettingOpeningHours, &hours)
+	if err != nil || !ok {
+		return model.DefaultOpeningHours, err
+	}
+	return hours, nil
+}
+
+// LoadStrikePolicy returns the organization's strike policy, or the
+// default, disabled one when it was never set
+func LoadStrikePolicy(settings SettingRepository) (model.StrikePolicy, error) {
+	var policy model.StrikePolicy
+	ok, err := getJSON(settings, model.SettingStrikePolicy, &policy)
+	if err != nil || !ok {
+		return model.DefaultStrikePolicy, err
+	}
+	return policy, nil
+}
+
+// getJSON decodes the JSON value of key into v, ok is false when the key
+// was never set
+func getJSON(settings SettingRepository, key string, v any) (ok bool, err error) {
+	value, err := settings.Get(key, "")
+	if err != nil || value == "" {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StrikeRepository interface {
+	WithContext(ctx context.Context) StrikeRepository
+	// Create records a strike, created is false when its booking already
+	// has one
+	Create(strike *model.Strike) (created bool, err error)
+	// FindByUserID returns the user's uncleared strikes that occurred from
+	// since, newest first
+	FindByUserID(userID uuid.UUID, since time.Time) ([]model.Strike, error)
+	// FindSince returns every uncleared strike that occurred from since,
+	// newest first
+	FindSince(since time.Time) ([]model.Strike, error)
+	// Clear clears one of the user's strikes, it returns
+	// gorm.ErrRecordNotFound when the user has no such uncleared strike
+	Clear(userID, id uuid.UUID) error
+	// ClearAll clears every strike of the user and returns how many it
+	// cleared
+	ClearAll(userID uuid.UUID) (int64, error)
+	// FindNoShows returns the active bookings in rooms that still exist
+	// that ended from from until before to without a check-in or a strike,
+	// with their rooms, oldest first
+	FindNoShows(from, to time.Time) ([]model.Booking, error)
+}
+
+type strikeRepository struct {
+	db  *gorm.DB
+	org uuid.UUID
+}
+
+func NewStrikeRepository(db *gorm.DB) StrikeRepository {
+	return &strikeRepository{db: db, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository whose queries run with ctx, limited to
+// the organization ctx acts for
+func (r *strikeRepository) WithContext(ctx context.Context) StrikeRepository {
+	return &strikeRepository{db: r.db.WithContext(ctx), org: tenant.FromContext(ctx)}
+}
+
+// uncleared limits a query on strikes to the organization's strikes that
+// still count
+func (r *strikeRepository) uncleared(db *gorm.DB) *gorm.DB {
+	return db.Where("organization_id = ? AND cleared_at IS NULL", r.org)
+}
+
+func (r *strikeRepository) Create(strike *model.Strike) (bool, error) {
+	strike.OrganizationID = r.org
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Omit("User", "Booking").Create(strike)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *strikeRepository) FindByUserID(userID uuid.UUID, since time.Time) ([]model.Strike, error) {
+	var strikes []model.Strike
+	err := r.uncleared(r.db).
+		Where("user_id = ? AND occurred_at >= ?", userID, since.UTC()).
+		Order("occurred_at DESC").
+		Find(&strikes).Error
+	return strikes, err
+}
+
+func (r *strikeRepository) FindSince(since time.Time) ([]model.Strike, error) {
+	var strikes []model.Strike
+	err := r.uncleared(r.db).
+		Where("occurred_at >= ?", since.UTC()).
+		Order("occurred_at DESC").
+		Find(&strikes).Error
+	return strikes, err
+}
+
+func (r *strikeRepository) Clear(userID, id uuid.UUID) error {
+	result := r.uncleared(r.db.Model(&model.Strike{})).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("cleared_at", time.Now().UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *strikeRepository) ClearAll(userID uuid.UUID) (int64, error) {
+	result := r.uncleared(r.db.Model(&model.Strike{})).
+		Where("user_id = ?", userID).
+		Update("cleared_at", time.Now().UTC())
+	return result.RowsAffected, result.Error
+}
+
+func (r *strikeRepository) FindNoShows(from, to time.Time) ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := r.db.
+		Preload("Room").
+		Joins("JOIN rooms ON rooms.id = bookings.room_id AND rooms.deleted_at IS NULL").
+		Where("bookings.organization_id = ?", r.org).
+		Where("bookings.status = ? AND bookings.checked_in_at IS NULL", model.BookingStatusActive).
+		Where("bookings.end_time >= ? AND bookings.end_time < ?", from.UTC(), to.UTC()).
+		Where("NOT EXISTS (SELECT 1 FROM strikes WHERE strikes.booking_id = bookings.id)").
+		Order("bookings.end_time").
+		Find(&bookings).Error
+	return bookings, err
+}
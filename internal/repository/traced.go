@@ -0,0 +1,737 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The traced repositories wrap another implementation and record a span
+// for each method call. The span is the parent of the GORM query spans.
+
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type tracedUserRepository struct {
+	ctx   context.Context
+	inner UserRepository
+}
+
+func NewTracedUserRepository(inner UserRepository) UserRepository {
+	return &tracedUserRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedUserRepository) WithContext(ctx context.Context) UserRepository {
+	return &tracedUserRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedUserRepository) FindAll() (users []model.User, err error) {
+	ctx, span := startSpan(r.ctx, "UserRepository.FindAll")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindAll()
+}
+
+func (r *tracedUserRepository) Create(user *model.User) (err error) {
+	ctx, span := startSpan(r.ctx, "UserRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Create(user)
+}
+
+func (r *tracedUserRepository) FindByID(id string) (user *model.User, err error) {
+	ctx, span := startSpan(r.ctx, "UserRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByID(id)
+}
+
+func (r *tracedUserRepository) FindByEmail(email string) (user *model.User, err error) {
+	ctx, span := startSpan(r.ctx, "UserRepository.FindByEmail")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByEmail(email)
+}
+
+func (r *tracedUserRepository) FindByOIDCSubject(issuer, subject string) (user *model.User, err error) {
+	ctx, span := startSpan(r.ctx, "UserRepository.FindByOIDCSubject")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByOIDCSubject(issuer, subject)
+}
+
+func (r *tracedUserRepository) Update(user *model.User) (err error) {
+	ctx, span := startSpan(r.ctx, "UserRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Update(user)
+}
+
+func (r *tracedUserRepository) CountActiveAdmins() (count int64, err error) {
+	ctx, span := startSpan(r.ctx, "UserRepository.CountActiveAdmins")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).CountActiveAdmins()
+}
+
+func (r *tracedUserRepository) Delete(id string) (err error) {
+	ctx, span := startSpan(r.ctx, "UserRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Delete(id)
+}
+
+type tracedRoomRepository struct {
+	ctx   context.Context
+	inner RoomRepository
+}
+
+func NewTracedRoomRepository(inner RoomRepository) RoomRepository {
+	return &tracedRoomRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedRoomRepository) WithContext(ctx context.Context) RoomRepository {
+	return &tracedRoomRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedRoomRepository) FindAll() (rooms []model.Room, err error) {
+	ctx, span := startSpan(r.ctx, "RoomRepository.FindAll")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindAll()
+}
+
+func (r *tracedRoomRepository) Create(room *model.Room) (err error) {
+	ctx, span := startSpan(r.ctx, "RoomRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Create(room)
+}
+
+func (r *tracedRoomRepository) FindByID(id uuid.UUID) (room *model.Room, err error) {
+	ctx, span := startSpan(r.ctx, "RoomRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByID(id)
+}
+
+func (r *tracedRoomRepository) Update(room *model.Room) (err error) {
+	ctx, span := startSpan(r.ctx, "RoomRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Update(room)
+}
+
+func (r *tracedRoomRepository) Delete(id uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "RoomRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Delete(id)
+}
+
+func (r *tracedRoomRepository) FindAllForUser(userID uuid.UUID) (rooms []model.Room, err error) {
+	ctx, span := startSpan(r.ctx, "RoomRepository.FindAllForUser")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindAllForUser(userID)
+}
+
+func (r *tracedRoomRepository) HasAccess(roomID, userID uuid.UUID) (ok bool, err error) {
+	ctx, span := startSpan(r.ctx, "RoomRepository.HasAccess")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).HasAccess(roomID, userID)
+}
+
+func (r *tracedRoomRepository) FindAccess(roomID uuid.UUID) (groups []model.Group, err error) {
+	ctx, span := startSpan(r.ctx, "RoomRepository.FindAccess")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindAccess(roomID)
+}
+
+func (r *tracedRoomRepository) SetAccess(roomID uuid.UUID, groupIDs []uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "RoomRepository.SetAccess")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).SetAccess(roomID, groupIDs)
+}
+
+type tracedBookingRepository struct {
+	ctx   context.Context
+	inner BookingRepository
+}
+
+func NewTracedBookingRepository(inner BookingRepository) BookingRepository {
+	return &tracedBookingRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedBookingRepository) WithContext(ctx context.Context) BookingRepository {
+	return &tracedBookingRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedBookingRepository) Create(booking *model.Booking) (err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Create(booking)
+}
+
+func (r *tracedBookingRepository) FindByID(id uuid.UUID) (booking *model.Booking, err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByID(id)
+}
+
+func (r *tracedBookingRepository) FindByUserID(userID uuid.UUID) (bookings []model.Booking, err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.FindByUserID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByUserID(userID)
+}
+
+func (r *tracedBookingRepository) FindByRoomID(roomID uuid.UUID) (bookings []model.Booking, err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.FindByRoomID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByRoomID(roomID)
+}
+
+func (r *tracedBookingRepository) FindByRoomIDAndDate(roomID uuid.UUID, date time.Time, status *string) (bookings []model.Booking, err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.FindByRoomIDAndDate")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByRoomIDAndDate(roomID, date, status)
+}
+
+func (r *tracedBookingRepository) Update(booking *model.Booking) (err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Update(booking)
+}
+
+func (r *tracedBookingRepository) Cancel(id uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.Cancel")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Cancel(id)
+}
+
+func (r *tracedBookingRepository) IsRoomAvailable(roomID uuid.UUID, startTime, endTime time.Time, excludeID *uuid.UUID) (available bool, err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.IsRoomAvailable")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).IsRoomAvailable(roomID, startTime, endTime, excludeID)
+}
+
+func (r *tracedBookingRepository) GetUpcomingBookings() (bookings []model.Booking, err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.GetUpcomingBookings")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).GetUpcomingBookings()
+}
+
+func (r *tracedBookingRepository) CreateGroup(group *model.BookingGroup, bookings []model.Booking) (conflicts []int, err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.CreateGroup")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).CreateGroup(group, bookings)
+}
+
+func (r *tracedBookingRepository) FindGroupByID(id uuid.UUID) (group *model.BookingGroup, err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.FindGroupByID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindGroupByID(id)
+}
+
+func (r *tracedBookingRepository) CancelGroup(id uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.CancelGroup")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).CancelGroup(id)
+}
+
+func (r *tracedBookingRepository) CountActiveAt(at time.Time) (count int64, err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.CountActiveAt")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).CountActiveAt(at)
+}
+
+func (r *tracedBookingRepository) CountActiveByUserID(userID uuid.UUID, at time.Time) (count int64, err error) {
+	ctx, span := startSpan(r.ctx, "BookingRepository.CountActiveByUserID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).CountActiveByUserID(userID, at)
+}
+
+type tracedUserTokenRepository struct {
+	ctx   context.Context
+	inner UserTokenRepository
+}
+
+func NewTracedUserTokenRepository(inner UserTokenRepository) UserTokenRepository {
+	return &tracedUserTokenRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedUserTokenRepository) WithContext(ctx context.Context) UserTokenRepository {
+	return &tracedUserTokenRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedUserTokenRepository) Create(token *model.UserToken) (err error) {
+	ctx, span := startSpan(r.ctx, "UserTokenRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Create(token)
+}
+
+func (r *tracedUserTokenRepository) Consume(purpose model.TokenPurpose, tokenHash string, now time.Time) (token *model.UserToken, err error) {
+	ctx, span := startSpan(r.ctx, "UserTokenRepository.Consume")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Consume(purpose, tokenHash, now)
+}
+
+func (r *tracedUserTokenRepository) ConsumeFor(userID uuid.UUID, purpose model.TokenPurpose, tokenHash string, now time.Time) (token *model.UserToken, err error) {
+	ctx, span := startSpan(r.ctx, "UserTokenRepository.ConsumeFor")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).ConsumeFor(userID, purpose, tokenHash, now)
+}
+
+func (r *tracedUserTokenRepository) RevokeAll(userID uuid.UUID, purpose model.TokenPurpose) (err error) {
+	ctx, span := startSpan(r.ctx, "UserTokenRepository.RevokeAll")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).RevokeAll(userID, purpose)
+}
+
+type tracedSettingRepository struct {
+	ctx   context.Context
+	inner SettingRepository
+}
+
+func NewTracedSettingRepository(inner SettingRepository) SettingRepository {
+	return &tracedSettingRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedSettingRepository) WithContext(ctx context.Context) SettingRepository {
+	return &tracedSettingRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedSettingRepository) Get(key, def string) (value string, err error) {
+	ctx, span := startSpan(r.ctx, "SettingRepository.Get")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Get(key, def)
+}
+
+func (r *tracedSettingRepository) Set(key, value string) (err error) {
+	ctx, span := startSpan(r.ctx, "SettingRepository.Set")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Set(key, value)
+}
+
+type tracedServiceAccountRepository struct {
+	ctx   context.Context
+	inner ServiceAccountRepository
+}
+
+func NewTracedServiceAccountRepository(inner ServiceAccountRepository) ServiceAccountRepository {
+	return &tracedServiceAccountRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedServiceAccountRepository) WithContext(ctx context.Context) ServiceAccountRepository {
+	return &tracedServiceAccountRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedServiceAccountRepository) Create(account *model.ServiceAccount) (err error) {
+	ctx, span := startSpan(r.ctx, "ServiceAccountRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Create(account)
+}
+
+func (r *tracedServiceAccountRepository) FindAll() (accounts []model.ServiceAccount, err error) {
+	ctx, span := startSpan(r.ctx, "ServiceAccountRepository.FindAll")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindAll()
+}
+
+func (r *tracedServiceAccountRepository) FindByID(id uuid.UUID) (account *model.ServiceAccount, err error) {
+	ctx, span := startSpan(r.ctx, "ServiceAccountRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByID(id)
+}
+
+func (r *tracedServiceAccountRepository) Delete(id uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "ServiceAccountRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Delete(id)
+}
+
+func (r *tracedServiceAccountRepository) CreateKey(key *model.APIKey) (err error) {
+	ctx, span := startSpan(r.ctx, "ServiceAccountRepository.CreateKey")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).CreateKey(key)
+}
+
+func (r *tracedServiceAccountRepository) FindKeys(accountID uuid.UUID) (keys []model.APIKey, err error) {
+	ctx, span := startSpan(r.ctx, "ServiceAccountRepository.FindKeys")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindKeys(accountID)
+}
+
+func (r *tracedServiceAccountRepository) FindKeyByHash(keyHash string) (key *model.APIKey, err error) {
+	ctx, span := startSpan(r.ctx, "ServiceAccountRepository.FindKeyByHash")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindKeyByHash(keyHash)
+}
+
+func (r *tracedServiceAccountRepository) RevokeKey(accountID, keyID uuid.UUID, now time.Time) (err error) {
+	ctx, span := startSpan(r.ctx, "ServiceAccountRepository.RevokeKey")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).RevokeKey(accountID, keyID, now)
+}
+
+func (r *tracedServiceAccountRepository) TouchKey(keyID uuid.UUID, now time.Time) (err error) {
+	ctx, span := startSpan(r.ctx, "ServiceAccountRepository.TouchKey")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).TouchKey(keyID, now)
+}
+
+type tracedInvitationRepository struct {
+	ctx   context.Context
+	inner InvitationRepository
+}
+
+func NewTracedInvitationRepository(inner InvitationRepository) InvitationRepository {
+	return &tracedInvitationRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedInvitationRepository) WithContext(ctx context.Context) InvitationRepository {
+	return &tracedInvitationRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedInvitationRepository) Create(invitation *model.Invitation) (err error) {
+	ctx, span := startSpan(r.ctx, "InvitationRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Create(invitation)
+}
+
+func (r *tracedInvitationRepository) FindAll() (invitations []model.Invitation, err error) {
+	ctx, span := startSpan(r.ctx, "InvitationRepository.FindAll")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindAll()
+}
+
+func (r *tracedInvitationRepository) FindPending(tokenHash string, now time.Time) (invitation *model.Invitation, err error) {
+	ctx, span := startSpan(r.ctx, "InvitationRepository.FindPending")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindPending(tokenHash, now)
+}
+
+func (r *tracedInvitationRepository) Accept(id uuid.UUID, now time.Time) (err error) {
+	ctx, span := startSpan(r.ctx, "InvitationRepository.Accept")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Accept(id, now)
+}
+
+func (r *tracedInvitationRepository) Revoke(id uuid.UUID, now time.Time) (err error) {
+	ctx, span := startSpan(r.ctx, "InvitationRepository.Revoke")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Revoke(id, now)
+}
+
+func (r *tracedInvitationRepository) RevokePending(email string, now time.Time) (err error) {
+	ctx, span := startSpan(r.ctx, "InvitationRepository.RevokePending")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).RevokePending(email, now)
+}
+
+type tracedGroupRepository struct {
+	ctx   context.Context
+	inner GroupRepository
+}
+
+func NewTracedGroupRepository(inner GroupRepository) GroupRepository {
+	return &tracedGroupRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedGroupRepository) WithContext(ctx context.Context) GroupRepository {
+	return &tracedGroupRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedGroupRepository) Create(group *model.Group) (err error) {
+	ctx, span := startSpan(r.ctx, "GroupRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Create(group)
+}
+
+func (r *tracedGroupRepository) FindAll() (groups []model.Group, err error) {
+	ctx, span := startSpan(r.ctx, "GroupRepository.FindAll")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindAll()
+}
+
+func (r *tracedGroupRepository) FindByID(id uuid.UUID) (group *model.Group, err error) {
+	ctx, span := startSpan(r.ctx, "GroupRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByID(id)
+}
+
+func (r *tracedGroupRepository) Update(group *model.Group) (err error) {
+	ctx, span := startSpan(r.ctx, "GroupRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Update(group)
+}
+
+func (r *tracedGroupRepository) Delete(id uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "GroupRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Delete(id)
+}
+
+func (r *tracedGroupRepository) AddMember(groupID, userID uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "GroupRepository.AddMember")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).AddMember(groupID, userID)
+}
+
+func (r *tracedGroupRepository) RemoveMember(groupID, userID uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "GroupRepository.RemoveMember")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).RemoveMember(groupID, userID)
+}
+
+func (r *tracedGroupRepository) FindMembers(groupID uuid.UUID) (users []model.User, err error) {
+	ctx, span := startSpan(r.ctx, "GroupRepository.FindMembers")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindMembers(groupID)
+}
+
+func (r *tracedGroupRepository) FindByUserID(userID uuid.UUID) (groups []model.Group, err error) {
+	ctx, span := startSpan(r.ctx, "GroupRepository.FindByUserID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByUserID(userID)
+}
+
+type tracedRoleRepository struct {
+	ctx   context.Context
+	inner RoleRepository
+}
+
+func NewTracedRoleRepository(inner RoleRepository) RoleRepository {
+	return &tracedRoleRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedRoleRepository) WithContext(ctx context.Context) RoleRepository {
+	return &tracedRoleRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedRoleRepository) Create(role *model.Role) (err error) {
+	ctx, span := startSpan(r.ctx, "RoleRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Create(role)
+}
+
+func (r *tracedRoleRepository) FindAll() (roles []model.Role, err error) {
+	ctx, span := startSpan(r.ctx, "RoleRepository.FindAll")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindAll()
+}
+
+func (r *tracedRoleRepository) FindByID(id uuid.UUID) (role *model.Role, err error) {
+	ctx, span := startSpan(r.ctx, "RoleRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByID(id)
+}
+
+func (r *tracedRoleRepository) Update(role *model.Role) (err error) {
+	ctx, span := startSpan(r.ctx, "RoleRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Update(role)
+}
+
+func (r *tracedRoleRepository) Delete(id uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "RoleRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Delete(id)
+}
+
+func (r *tracedRoleRepository) Assign(assignment *model.RoleAssignment) (err error) {
+	ctx, span := startSpan(r.ctx, "RoleRepository.Assign")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Assign(assignment)
+}
+
+func (r *tracedRoleRepository) Unassign(userID, assignmentID uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "RoleRepository.Unassign")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Unassign(userID, assignmentID)
+}
+
+func (r *tracedRoleRepository) FindAssignments(userID uuid.UUID) (assignments []model.RoleAssignment, err error) {
+	ctx, span := startSpan(r.ctx, "RoleRepository.FindAssignments")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindAssignments(userID)
+}
+
+type tracedOrganizationRepository struct {
+	ctx   context.Context
+	inner OrganizationRepository
+}
+
+func NewTracedOrganizationRepository(inner OrganizationRepository) OrganizationRepository {
+	return &tracedOrganizationRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedOrganizationRepository) WithContext(ctx context.Context) OrganizationRepository {
+	return &tracedOrganizationRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedOrganizationRepository) Create(org *model.Organization) (err error) {
+	ctx, span := startSpan(r.ctx, "OrganizationRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Create(org)
+}
+
+func (r *tracedOrganizationRepository) FindAll() (orgs []model.Organization, err error) {
+	ctx, span := startSpan(r.ctx, "OrganizationRepository.FindAll")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindAll()
+}
+
+func (r *tracedOrganizationRepository) FindByID(id uuid.UUID) (org *model.Organization, err error) {
+	ctx, span := startSpan(r.ctx, "OrganizationRepository.FindByID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByID(id)
+}
+
+func (r *tracedOrganizationRepository) FindBySlug(slug string) (org *model.Organization, err error) {
+	ctx, span := startSpan(r.ctx, "OrganizationRepository.FindBySlug")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindBySlug(slug)
+}
+
+func (r *tracedOrganizationRepository) FindByDomain(host string) (org *model.Organization, err error) {
+	ctx, span := startSpan(r.ctx, "OrganizationRepository.FindByDomain")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByDomain(host)
+}
+
+type tracedAuditLogRepository struct {
+	ctx   context.Context
+	inner AuditLogRepository
+}
+
+func NewTracedAuditLogRepository(inner AuditLogRepository) AuditLogRepository {
+	return &tracedAuditLogRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedAuditLogRepository) WithContext(ctx context.Context) AuditLogRepository {
+	return &tracedAuditLogRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedAuditLogRepository) Create(entry *model.AuditLog) (err error) {
+	ctx, span := startSpan(r.ctx, "AuditLogRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Create(entry)
+}
+
+func (r *tracedAuditLogRepository) Find(filter model.AuditLogFilter) (entries []model.AuditLog, err error) {
+	ctx, span := startSpan(r.ctx, "AuditLogRepository.Find")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Find(filter)
+}
+
+type tracedDelegationRepository struct {
+	ctx   context.Context
+	inner DelegationRepository
+}
+
+func NewTracedDelegationRepository(inner DelegationRepository) DelegationRepository {
+	return &tracedDelegationRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedDelegationRepository) WithContext(ctx context.Context) DelegationRepository {
+	return &tracedDelegationRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedDelegationRepository) Grant(principalID, delegateID uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "DelegationRepository.Grant")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Grant(principalID, delegateID)
+}
+
+func (r *tracedDelegationRepository) Revoke(principalID, delegateID uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "DelegationRepository.Revoke")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Revoke(principalID, delegateID)
+}
+
+func (r *tracedDelegationRepository) IsDelegate(principalID, delegateID uuid.UUID) (ok bool, err error) {
+	ctx, span := startSpan(r.ctx, "DelegationRepository.IsDelegate")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).IsDelegate(principalID, delegateID)
+}
+
+func (r *tracedDelegationRepository) FindDelegates(principalID uuid.UUID) (users []model.User, err error) {
+	ctx, span := startSpan(r.ctx, "DelegationRepository.FindDelegates")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindDelegates(principalID)
+}
+
+func (r *tracedDelegationRepository) FindPrincipals(delegateID uuid.UUID) (users []model.User, err error) {
+	ctx, span := startSpan(r.ctx, "DelegationRepository.FindPrincipals")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindPrincipals(delegateID)
+}
+
+type tracedReportRepository struct {
+	ctx   context.Context
+	inner ReportRepository
+}
+
+func NewTracedReportRepository(inner ReportRepository) ReportRepository {
+	return &tracedReportRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedReportRepository) WithContext(ctx context.Context) ReportRepository {
+	return &tracedReportRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedReportRepository) Utilization(filter model.UtilizationFilter) (aggregates []model.UtilizationAggregate, err error) {
+	ctx, span := startSpan(r.ctx, "ReportRepository.Utilization")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Utilization(filter)
+}
+
+type tracedStrikeRepository struct {
+	ctx   context.Context
+	inner StrikeRepository
+}
+
+func NewTracedStrikeRepository(inner StrikeRepository) StrikeRepository {
+	return &tracedStrikeRepository{ctx: context.Background(), inner: inner}
+}
+
+func (r *tracedStrikeRepository) WithContext(ctx context.Context) StrikeRepository {
+	return &tracedStrikeRepository{ctx: ctx, inner: r.inner}
+}
+
+func (r *tracedStrikeRepository) Create(strike *model.Strike) (created bool, err error) {
+	ctx, span := startSpan(r.ctx, "StrikeRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Create(strike)
+}
+
+func (r *tracedStrikeRepository) FindByUserID(userID uuid.UUID, since time.Time) (strikes []model.Strike, err error) {
+	ctx, span := startSpan(r.ctx, "StrikeRepository.FindByUserID")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindByUserID(userID, since)
+}
+
+func (r *tracedStrikeRepository) FindSince(since time.Time) (strikes []model.Strike, err error) {
+	ctx, span := startSpan(r.ctx, "StrikeRepository.FindSince")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindSince(since)
+}
+
+func (r *tracedStrikeRepository) Clear(userID, id uuid.UUID) (err error) {
+	ctx, span := startSpan(r.ctx, "StrikeRepository.Clear")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).Clear(userID, id)
+}
+
+func (r *tracedStrikeRepository) ClearAll(userID uuid.UUID) (cleared int64, err error) {
+	ctx, span := startSpan(r.ctx, "StrikeRepository.ClearAll")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).ClearAll(userID)
+}
+
+func (r *tracedStrikeRepository) FindNoShows(from, to time.Time) (bookings []model.Booking, err error) {
+	ctx, span := startSpan(r.ctx, "StrikeRepository.FindNoShows")
+	defer func() { endSpan(span, err) }()
+	return r.inner.WithContext(ctx).FindNoShows(from, to)
+}
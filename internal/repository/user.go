@@ -1,43 +1,123 @@
 package repository
 
 import (
+	"context"
+
+	"github.com/google/uuid"
 	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/tenant"
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
+	WithContext(ctx context.Context) UserRepository
 	FindAll() ([]model.User, error)
 	Create(user *model.User) error
 	FindByID(id string) (*model.User, error)
+	// FindByEmail ignores case, like mail servers do
 	FindByEmail(email string) (*model.User, error)
+	FindByOIDCSubject(issuer, subject string) (*model.User, error)
+	Update(user *model.User) error
+	// CountActiveAdmins counts admins whose accounts are not deactivated
+	CountActiveAdmins() (int64, error)
+	// Delete soft-deletes the user, takes them out of their groups, roles
+	// and delegations and releases their email and identity provider link,
+	// so the person can be invited or sign up again
+	Delete(id string) error
+}
+
+// DeletedUserEmail is the placeholder a deleted user's email is replaced
+// with, the .invalid domain can never receive mail
+func DeletedUserEmail(id string) string {
+	return "deleted-" + id + "@users.invalid"
 }
 
 type userRepository struct {
-	db *gorm.DB
+	db  *gorm.DB
+	org uuid.UUID
 }
 
 func NewUserRepository(db *gorm.DB) UserRepository {
-	return &userRepository{db: db}
+	return &userRepository{db: db, org: model.DefaultOrganizationID}
+}
+
+// WithContext returns a repository whose queries run with ctx, limited to
+// the organization ctx acts for
+func (r *userRepository) WithContext(ctx context.Context) UserRepository {
+	return &userRepository{db: r.db.WithContext(ctx), org: tenant.FromContext(ctx)}
+}
+
+// scoped limits a query on users to the repository's organization
+func (r *userRepository) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("users.organization_id = ?", r.org)
 }
 
 func (r *userRepository) FindAll() ([]model.User, error) {
 	var users []model.User
-	err := r.db.Find(&users).Error
+	err := r.scoped(r.db).Find(&users).Error
 	return users, err
 }
 
 func (r *userRepository) Create(user *model.User) error {
+	user.OrganizationID = r.org
 	return r.db.Create(user).Error
 }
 
 func (r *userRepository) FindByID(id string) (*model.User, error) {
 	var user model.User
-	err := r.db.First(&user, "id = ?", id).Error
+	err := r.scoped(r.db).First(&user, "id = ?", id).Error
 	return &user, err
 }
 
 func (r *userRepository) FindByEmail(email string) (*model.User, error) {
 	var user model.User
-	err := r.db.First(&user, "email = ?", email).Error
+	err := r.scoped(r.db).First(&user, "LOWER(email) = LOWER(?)", email).Error
 	return &user, err
 }
+
+func (r *userRepository) FindByOIDCSubject(issuer, subject string) (*model.User, error) {
+	var user model.User
+	err := r.scoped(r.db).First(&user, "oidc_issuer = ? AND oidc_subject = ?", issuer, subject).Error
+	return &user, err
+}
+
+func (r *userRepository) Update(user *model.User) error {
+	user.OrganizationID = r.org
+	return updateAll(r.scoped(r.db), user)
+}
+
+func (r *userRepository) CountActiveAdmins() (int64, error) {
+	var count int64
+	err := r.scoped(r.db.Model(&model.User{})).
+		Where("role = ? AND deactivated_at IS NULL", model.RoleAdmin).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *userRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := r.scoped(tx.Model(&model.User{})).Where("id = ?", id).Updates(map[string]any{
+			"email":        DeletedUserEmail(id),
+			"oidc_issuer":  nil,
+			"oidc_subject": nil,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		// The user is known to be in this organization now, so are the rows
+		// below
+		if err := tx.Where("user_id = ?", id).Delete(&model.GroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&model.RoleAssignment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("principal_id = ? OR delegate_id = ?", id, id).Delete(&model.Delegation{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.User{}, "id = ?", id).Error
+	})
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"gorm.io/gorm"
+)
+
+// UserTokenRepository is not limited to one organization, tokens are found
+// by their hash and belong to the organization of their user
+type UserTokenRepository interface {
+	WithContext(ctx context.Context) UserTokenRepository
+	Create(token *model.UserToken) error
+	// Consume marks the unused, unexpired token with the given hash as used
+	// and returns it. It returns gorm.ErrRecordNotFound when there is none,
+	// so a token can be consumed only once even by concurrent requests.
+	Consume(purpose model.TokenPurpose, tokenHash string, now time.Time) (*model.UserToken, error)
+	// ConsumeFor is Consume limited to the tokens of userID, another user's
+	// token with the hash is left unused
+	ConsumeFor(userID uuid.UUID, purpose model.TokenPurpose, tokenHash string, now time.Time) (*model.UserToken, error)
+	// RevokeAll marks every unused token of the user for purpose as used
+	RevokeAll(userID uuid.UUID, purpose model.TokenPurpose) error
+}
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+// WithContext returns a repository whose queries run with ctx
+func (r *userTokenRepository) WithContext(ctx context.Context) UserTokenRepository {
+	return &userTokenRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *userTokenRepository) Create(token *model.UserToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *userTokenRepository) Consume(purpose model.TokenPurpose, tokenHash string, now time.Time) (*model.UserToken, error) {
+	return r.consume(nil, purpose, tokenHash, now)
+}
+
+func (r *userTokenRepository) ConsumeFor(userID uuid.UUID, purpose model.TokenPurpose, tokenHash string, now time.Time) (*model.UserToken, error) {
+	return r.consume(&userID, purpose, tokenHash, now)
+}
+
+// consume marks the token with purpose and hash as used, only when it
+// belongs to userID if that is set
+func (r *userTokenRepository) consume(userID *uuid.UUID, purpose model.TokenPurpose, tokenHash string, now time.Time) (*model.UserToken, error) {
+	now = now.UTC()
+
+	var token model.UserToken
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now)
+		if userID != nil {
+			query = query.Where("user_id = ?", *userID)
+		}
+		if err := query.First(&token).Error; err != nil {
+			return err
+		}
+
+		// Guard on used_at so only one of two racing requests wins
+		result := tx.Model(&model.UserToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		token.UsedAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *userTokenRepository) RevokeAll(userID uuid.UUID, purpose model.TokenPurpose) error {
+	return r.db.Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now().UTC()).Error
+}
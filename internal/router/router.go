@@ -1,81 +1,266 @@
 package router
 
 import (
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/riparuk/meet-book-api/internal/config"
 	"github.com/riparuk/meet-book-api/internal/database"
 	"github.com/riparuk/meet-book-api/internal/handler"
+	"github.com/riparuk/meet-book-api/internal/mailer"
 	"github.com/riparuk/meet-book-api/internal/middleware"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/oidc"
+	"github.com/riparuk/meet-book-api/internal/ratelimit"
 	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/strike"
 )
 
-func SetupRoutes(r *gin.Engine) {
-	authRepo := repository.NewUserRepository(database.DB)
-	userRepo := repository.NewUserRepository(database.DB)
-	roomRepo := repository.NewRoomRepository(database.DB)
-	bookingRepo := repository.NewBookingRepository(database.DB)
+// SetupRoutes registers the API on r and returns the strike tracker its
+// handlers share, for the no-show worker to record strikes through the
+// same audited and traced repositories
+func SetupRoutes(r *gin.Engine, cfg *config.Config, limiter *ratelimit.Limiter) *strike.Tracker {
+	// Changes to users, rooms and bookings are recorded in the audit log
+	auditRepo := repository.NewTracedAuditLogRepository(repository.NewAuditLogRepository(database.DB))
+	authRepo := repository.NewAuditedUserRepository(repository.NewTracedUserRepository(repository.NewUserRepository(database.DB)), auditRepo)
+	userRepo := repository.NewAuditedUserRepository(repository.NewTracedUserRepository(repository.NewUserRepository(database.DB)), auditRepo)
+	roomRepo := repository.NewAuditedRoomRepository(repository.NewTracedRoomRepository(repository.NewRoomRepository(database.DB)), auditRepo)
+	bookingRepo := repository.NewAuditedBookingRepository(repository.NewTracedBookingRepository(repository.NewBookingRepository(database.DB)), auditRepo)
+	tokenRepo := repository.NewTracedUserTokenRepository(repository.NewUserTokenRepository(database.DB))
+	settingRepo := repository.NewTracedSettingRepository(repository.NewSettingRepository(database.DB))
+	serviceAccountRepo := repository.NewTracedServiceAccountRepository(repository.NewServiceAccountRepository(database.DB))
+	invitationRepo := repository.NewTracedInvitationRepository(repository.NewInvitationRepository(database.DB))
+	groupRepo := repository.NewTracedGroupRepository(repository.NewGroupRepository(database.DB))
+	roleRepo := repository.NewTracedRoleRepository(repository.NewRoleRepository(database.DB))
+	orgRepo := repository.NewTracedOrganizationRepository(repository.NewOrganizationRepository(database.DB))
+	delegationRepo := repository.NewTracedDelegationRepository(repository.NewDelegationRepository(database.DB))
+	reportRepo := repository.NewTracedReportRepository(repository.NewReportRepository(database.DB))
+	strikeRepo := repository.NewTracedStrikeRepository(repository.NewStrikeRepository(database.DB))
+
+	mail := mailer.New(cfg.Mail)
+	strikes := strike.NewTracker(strikeRepo, bookingRepo, roomRepo, reportRepo, settingRepo, userRepo, mail)
+	verificationHandler := handler.NewVerificationHandler(userRepo, tokenRepo, mail, limiter, handler.VerificationOptions{
+		TTL:              cfg.Auth.EmailVerificationTTL,
+		LinkBaseURL:      cfg.Mail.LinkBaseURL,
+		ResendPerAccount: cfg.RateLimit.VerificationPerAccount,
+	})
+	authHandler := handler.NewAuthHandler(authRepo, invitationRepo, limiter, handler.AuthLimits{
+		LoginPerAccount:    cfg.RateLimit.LoginPerAccount,
+		RegisterPerAccount: cfg.RateLimit.RegisterPerAccount,
+	}, verificationHandler)
+	passwordHandler := handler.NewPasswordHandler(userRepo, tokenRepo, mail, limiter, handler.PasswordResetOptions{
+		TTL:         cfg.Auth.PasswordResetTTL,
+		LinkBaseURL: cfg.Mail.LinkBaseURL,
+		PerAccount:  cfg.RateLimit.PasswordResetPerAccount,
+	})
+	twoFactorHandler := handler.NewTwoFactorHandler(userRepo, tokenRepo, settingRepo, limiter, cfg.Auth.TOTPIssuer)
+	settingsHandler := handler.NewSettingsHandler(settingRepo)
+	serviceAccountHandler := handler.NewServiceAccountHandler(serviceAccountRepo)
+	invitationHandler := handler.NewInvitationHandler(invitationRepo, userRepo, mail, handler.InvitationOptions{
+		TTL:         cfg.Auth.InvitationTTL,
+		LinkBaseURL: cfg.Mail.LinkBaseURL,
+	})
+	groupHandler := handler.NewGroupHandler(groupRepo, userRepo)
+	roleHandler := handler.NewRoleHandler(roleRepo, userRepo)
+	userHandler := handler.NewUserHandler(userRepo, bookingRepo, roomRepo, verificationHandler, mail, strikes)
+	roomHandler := handler.NewRoomHandler(roomRepo, groupRepo)
+	bookingHandler := handler.NewBookingHandler(bookingRepo, roomRepo, delegationRepo, userRepo, mail, strikes)
+	delegationHandler := handler.NewDelegationHandler(delegationRepo, userRepo)
+	organizationHandler := handler.NewOrganizationHandler(orgRepo)
+	auditHandler := handler.NewAuditHandler(auditRepo)
+	reportHandler := handler.NewReportHandler(reportRepo, roomRepo, settingRepo)
+	strikeHandler := handler.NewStrikeHandler(strikes, strikeRepo, userRepo)
 
-	authHandler := handler.NewAuthHandler(authRepo)
-	userHandler := handler.NewUserHandler(userRepo, bookingRepo)
-	roomHandler := handler.NewRoomHandler(roomRepo)
-	bookingHandler := handler.NewBookingHandler(bookingRepo)
+	// can requires a permission of the signed in user, perms loads them for
+	// handlers that check them per room
+	can := func(permission model.Permission) gin.HandlerFunc {
+		return middleware.RequirePermission(roleRepo, permission)
+	}
+	perms := middleware.LoadPermissions(roleRepo)
 
+	// Every request acts for the organization of its host, or of its token
+	// on hosts no organization claims
 	api := r.Group("/api")
+	api.Use(middleware.ResolveTenant(orgRepo))
 	{
+		api.GET("/organization", middleware.OptionalAuthMiddleware(userRepo), organizationHandler.GetOrganization)
+
 		// Public routes
 		auth := api.Group("/auth")
 		{
-			auth.POST("/login", authHandler.Login)
-			auth.POST("/register", authHandler.Register)
+			auth.POST("/login", middleware.RateLimit(limiter, "login", cfg.RateLimit.LoginPerIP), authHandler.Login)
+			auth.POST("/login/2fa", middleware.RateLimit(limiter, "login", cfg.RateLimit.LoginPerIP), twoFactorHandler.CompleteLogin)
+			auth.POST("/register", middleware.RateLimit(limiter, "register", cfg.RateLimit.RegisterPerIP), authHandler.Register)
+			auth.POST("/forgot-password", middleware.RateLimit(limiter, "password-reset", cfg.RateLimit.PasswordResetPerIP), passwordHandler.ForgotPassword)
+			auth.POST("/reset-password", middleware.RateLimit(limiter, "password-reset", cfg.RateLimit.PasswordResetPerIP), passwordHandler.ResetPassword)
+			auth.POST("/verify-email", verificationHandler.VerifyEmail)
+
+			// Single sign-on, password login stays available
+			if cfg.OIDC.Enabled() {
+				oidcHandler := handler.NewOIDCHandler(userRepo, oidc.New(cfg.OIDC), cfg.OIDC.PostLoginRedirectURL,
+					strings.HasPrefix(cfg.OIDC.RedirectURL, "https://"))
+				auth.GET("/oidc/login", middleware.RateLimit(limiter, "login", cfg.RateLimit.LoginPerIP), oidcHandler.Login)
+				auth.GET("/oidc/callback", middleware.RateLimit(limiter, "login", cfg.RateLimit.LoginPerIP), oidcHandler.Callback)
+			}
 		}
 
-		// Protected user routes
+		// Protected user routes, managed by users.read and users.write
 		users := api.Group("/users")
-		users.Use(middleware.JWTAuthMiddleware())
+		users.Use(middleware.JWTAuthMiddleware(userRepo), middleware.RequireAdminTwoFactor(settingRepo, roleRepo))
 		{
-			users.GET("", middleware.RequireRole("admin"), userHandler.GetUsers)    // Only admin can list all users
-			users.POST("", middleware.RequireRole("admin"), userHandler.CreateUser) // Only admin can create users
+			users.GET("", can(model.PermUsersRead), userHandler.GetUsers)
+			users.POST("", can(model.PermUsersWrite), userHandler.CreateUser)
+			users.GET("/:id", can(model.PermUsersRead), userHandler.GetUser)
+			users.PATCH("/:id", can(model.PermUsersWrite), userHandler.UpdateUser)
+			users.DELETE("/:id", can(model.PermUsersWrite), userHandler.DeleteUser)
+			users.POST("/:id/deactivate", can(model.PermUsersWrite), userHandler.DeactivateUser)
+			users.POST("/:id/reactivate", can(model.PermUsersWrite), userHandler.ReactivateUser)
+			users.POST("/:id/unlock", can(model.PermUsersWrite), authHandler.UnlockUser)
+			users.POST("/:id/password-reset", can(model.PermUsersWrite), passwordHandler.SendPasswordReset)
+			users.GET("/:id/groups", can(model.PermUsersRead), groupHandler.GetUserGroups)
+			users.GET("/:id/roles", can(model.PermUsersRead), roleHandler.GetUserRoles)
+			users.POST("/:id/roles", can(model.PermRolesWrite), roleHandler.AssignRole)
+			users.DELETE("/:id/roles/:assignment_id", can(model.PermRolesWrite), roleHandler.UnassignRole)
+			users.GET("/:id/delegates", can(model.PermUsersRead), delegationHandler.GetUserDelegates)
+			users.PUT("/:id/delegates/:delegate_id", can(model.PermUsersWrite), delegationHandler.AddUserDelegate)
+			users.DELETE("/:id/delegates/:delegate_id", can(model.PermUsersWrite), delegationHandler.RemoveUserDelegate)
+			users.GET("/:id/strikes", can(model.PermUsersRead), strikeHandler.GetUserStrikes)
+			users.DELETE("/:id/strikes", can(model.PermUsersWrite), strikeHandler.ClearUserStrikes)
+			users.DELETE("/:id/strikes/:strike_id", can(model.PermUsersWrite), strikeHandler.ClearUserStrike)
+		}
+
+		// Groups and teams, rooms grant access to them
+		groups := api.Group("/groups")
+		groups.Use(middleware.JWTAuthMiddleware(userRepo), middleware.RequireAdminTwoFactor(settingRepo, roleRepo))
+		{
+			groups.GET("", can(model.PermGroupsRead), groupHandler.GetGroups)
+			groups.POST("", can(model.PermGroupsWrite), groupHandler.CreateGroup)
+			groups.GET("/:id", can(model.PermGroupsRead), groupHandler.GetGroup)
+			groups.PATCH("/:id", can(model.PermGroupsWrite), groupHandler.UpdateGroup)
+			groups.DELETE("/:id", can(model.PermGroupsWrite), groupHandler.DeleteGroup)
+			groups.PUT("/:id/members/:user_id", can(model.PermGroupsWrite), groupHandler.AddGroupMember)
+			groups.DELETE("/:id/members/:user_id", can(model.PermGroupsWrite), groupHandler.RemoveGroupMember)
+		}
+
+		// Custom roles, built from permissions
+		roles := api.Group("/roles")
+		roles.Use(middleware.JWTAuthMiddleware(userRepo), can(model.PermRolesWrite), middleware.RequireAdminTwoFactor(settingRepo, roleRepo))
+		{
+			roles.GET("/permissions", roleHandler.GetPermissions)
+			roles.GET("", roleHandler.GetRoles)
+			roles.POST("", roleHandler.CreateRole)
+			roles.GET("/:id", roleHandler.GetRole)
+			roles.PATCH("/:id", roleHandler.UpdateRole)
+			roles.DELETE("/:id", roleHandler.DeleteRole)
+		}
+
+		// Admin settings
+		settings := api.Group("/settings")
+		settings.Use(middleware.JWTAuthMiddleware(userRepo), can(model.PermSettingsWrite), middleware.RequireAdminTwoFactor(settingRepo, roleRepo))
+		{
+			settings.GET("/security", settingsHandler.GetSecuritySettings)
+			settings.PUT("/security", settingsHandler.UpdateSecuritySettings)
+			settings.GET("/opening-hours", settingsHandler.GetOpeningHours)
+			settings.PUT("/opening-hours", settingsHandler.UpdateOpeningHours)
+			settings.GET("/strikes", settingsHandler.GetStrikePolicy)
+			settings.PUT("/strikes", settingsHandler.UpdateStrikePolicy)
+		}
+
+		// Audit log of changes to users, rooms and bookings
+		api.GET("/audit-logs", middleware.JWTAuthMiddleware(userRepo), can(model.PermAuditRead), middleware.RequireAdminTwoFactor(settingRepo, roleRepo), auditHandler.GetAuditLogs)
+
+		// Reports
+		api.GET("/reports/utilization", middleware.JWTAuthMiddleware(userRepo), can(model.PermReportsRead), middleware.RequireAdminTwoFactor(settingRepo, roleRepo), reportHandler.GetUtilization)
+
+		// No-show and late cancellation strikes of every user
+		api.GET("/strikes", middleware.JWTAuthMiddleware(userRepo), can(model.PermUsersRead), middleware.RequireAdminTwoFactor(settingRepo, roleRepo), strikeHandler.GetStrikes)
+
+		// Invitations to register with a role
+		invitations := api.Group("/invitations")
+		invitations.Use(middleware.JWTAuthMiddleware(userRepo), can(model.PermInvitationsWrite), middleware.RequireAdminTwoFactor(settingRepo, roleRepo))
+		{
+			invitations.GET("", invitationHandler.GetInvitations)
+			invitations.POST("", invitationHandler.CreateInvitation)
+			invitations.DELETE("/:id", invitationHandler.RevokeInvitation)
+		}
+
+		// Service accounts and their API keys
+		serviceAccounts := api.Group("/service-accounts")
+		serviceAccounts.Use(middleware.JWTAuthMiddleware(userRepo), can(model.PermServiceAccountsWrite), middleware.RequireAdminTwoFactor(settingRepo, roleRepo))
+		{
+			serviceAccounts.GET("", serviceAccountHandler.GetServiceAccounts)
+			serviceAccounts.POST("", serviceAccountHandler.CreateServiceAccount)
+			serviceAccounts.GET("/:id", serviceAccountHandler.GetServiceAccount)
+			serviceAccounts.DELETE("/:id", serviceAccountHandler.DeleteServiceAccount)
+			serviceAccounts.POST("/:id/keys", serviceAccountHandler.CreateAPIKey)
+			serviceAccounts.DELETE("/:id/keys/:key_id", serviceAccountHandler.RevokeAPIKey)
 		}
 
 		// User profile routes
 		me := api.Group("/me")
-		me.Use(middleware.JWTAuthMiddleware())
+		me.Use(middleware.JWTAuthMiddleware(userRepo), perms)
 		{
 			me.GET("", userHandler.Profile)
-			me.POST("/bookings", userHandler.CreateMyBooking)
+			me.GET("/permissions", roleHandler.MyPermissions)
+			me.PATCH("", userHandler.UpdateProfile)
+			me.POST("/password", passwordHandler.ChangePassword)
+			me.POST("/bookings", middleware.RequireVerified(), userHandler.CreateMyBooking)
+			me.POST("/verify-email/resend", verificationHandler.ResendVerification)
+			me.POST("/2fa/setup", twoFactorHandler.Setup)
+			me.POST("/2fa/enable", twoFactorHandler.Enable)
+			me.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+			me.POST("/2fa/disable", twoFactorHandler.Disable)
 			me.GET("/bookings", userHandler.GetMyBookings)
+			me.GET("/delegates", delegationHandler.GetMyDelegates)
+			me.PUT("/delegates/:user_id", delegationHandler.AddMyDelegate)
+			me.DELETE("/delegates/:user_id", delegationHandler.RemoveMyDelegate)
+			me.GET("/principals", delegationHandler.GetMyPrincipals)
+			me.GET("/strikes", strikeHandler.GetMyStrikes)
 		}
 
 		// Room routes
 		rooms := api.Group("/rooms")
 		{
-			// Public routes
-			rooms.GET("", roomHandler.GetRooms)
-			rooms.GET("/:id", roomHandler.GetRoom)
+			// Public routes, a signed in user also sees the restricted rooms
+			// they have access to, API keys need rooms:read
+			readRooms := middleware.OptionalAPIKeyAuthMiddleware(userRepo, serviceAccountRepo, model.ScopeRoomsRead)
+			rooms.GET("", readRooms, perms, roomHandler.GetRooms)
+			rooms.GET("/:id", readRooms, perms, roomHandler.GetRoom)
 
-			// Admin-only routes
+			// rooms.write routes, also open to API keys with rooms:write
 			adminRooms := rooms.Group("")
-			adminRooms.Use(middleware.JWTAuthMiddleware(), middleware.RequireRole("admin"))
+			adminRooms.Use(middleware.APIKeyAuthMiddleware(userRepo, serviceAccountRepo, model.ScopeRoomsWrite), can(model.PermRoomsWrite), middleware.RequireAdminTwoFactor(settingRepo, roleRepo))
 			{
 				adminRooms.POST("", roomHandler.CreateRoom)
 				adminRooms.PUT("/:id", roomHandler.UpdateRoom)
 				adminRooms.DELETE("/:id", roomHandler.DeleteRoom)
+				adminRooms.GET("/:id/access", roomHandler.GetRoomAccess)
+				adminRooms.PUT("/:id/access", roomHandler.SetRoomAccess)
 			}
 		}
 
-		// Booking routes
+		// Booking routes, API keys need the bookings:read or bookings:write
+		// scope and cannot use the routes acting as the signed in user
 		bookings := api.Group("/bookings")
-		bookings.Use(middleware.JWTAuthMiddleware())
 		{
-			// User routes
-			bookings.GET("/upcoming", bookingHandler.GetUpcomingBookings)
-			bookings.POST("", bookingHandler.CreateBooking)
-			bookings.GET("/:id", bookingHandler.GetBooking)
-			bookings.PUT("/:id", bookingHandler.UpdateBooking)
-			bookings.GET("/room/:room_id", bookingHandler.GetRoomBookings)
-			bookings.GET("/room/:room_id/:date", bookingHandler.GetRoomBookingsByDate)
-			bookings.POST("/:id/cancel", bookingHandler.CancelBooking)
-			bookings.GET("/users/:user_id", bookingHandler.GetUserBookings)
+			userAuth := middleware.JWTAuthMiddleware(userRepo)
+			readAuth := middleware.APIKeyAuthMiddleware(userRepo, serviceAccountRepo, model.ScopeBookingsRead)
+			writeAuth := middleware.APIKeyAuthMiddleware(userRepo, serviceAccountRepo, model.ScopeBookingsWrite)
+
+			bookings.GET("/upcoming", readAuth, perms, bookingHandler.GetUpcomingBookings)
+			bookings.POST("", writeAuth, perms, middleware.RequireVerified(), bookingHandler.CreateBooking)
+			bookings.POST("/batch", userAuth, perms, middleware.RequireVerified(), bookingHandler.CreateBatchBooking)
+			bookings.GET("/groups/:id", readAuth, perms, bookingHandler.GetBookingGroup)
+			bookings.POST("/groups/:id/cancel", writeAuth, perms, bookingHandler.CancelBookingGroup)
+			bookings.GET("/:id", readAuth, perms, bookingHandler.GetBooking)
+			bookings.PUT("/:id", writeAuth, perms, middleware.RequireVerified(), bookingHandler.UpdateBooking)
+			bookings.GET("/room/:room_id", readAuth, perms, bookingHandler.GetRoomBookings)
+			bookings.GET("/room/:room_id/:date", readAuth, perms, bookingHandler.GetRoomBookingsByDate)
+			bookings.POST("/:id/cancel", writeAuth, perms, bookingHandler.CancelBooking)
+			bookings.POST("/:id/check-in", writeAuth, perms, bookingHandler.CheckInBooking)
+			bookings.GET("/users/:user_id", readAuth, perms, bookingHandler.GetUserBookings)
 		}
 	}
 
+	return strikes
 }
@@ -0,0 +1,328 @@
+// Package strike counts no-shows and late cancellations against the users
+// who organized the bookings, and restricts booking for users who collect
+// too many, following the organization's StrikePolicy.
+package strike
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/logger"
+	"github.com/riparuk/meet-book-api/internal/mailer"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/tenant"
+)
+
+// RestrictedError is returned by Tracker.Check when strikes keep a user
+// from booking
+type RestrictedError struct {
+	// Limit is what the user may not do
+	Limit string
+	Until time.Time
+}
+
+func (e *RestrictedError) Error() string {
+	return fmt.Sprintf("%s until %s, after repeated no-shows or late cancellations", e.Limit, e.Until.UTC().Format(time.RFC3339))
+}
+
+// Tracker records strikes, tells users about them and enforces the
+// restrictions of the organization its context acts for
+type Tracker struct {
+	strikes  repository.StrikeRepository
+	bookings repository.BookingRepository
+	rooms    repository.RoomRepository
+	reports  repository.ReportRepository
+	settings repository.SettingRepository
+	users    repository.UserRepository
+	mailer   mailer.Mailer
+}
+
+func NewTracker(strikes repository.StrikeRepository, bookings repository.BookingRepository, rooms repository.RoomRepository, reports repository.ReportRepository, settings repository.SettingRepository, users repository.UserRepository, m mailer.Mailer) *Tracker {
+	return &Tracker{
+		strikes:  strikes,
+		bookings: bookings,
+		rooms:    rooms,
+		reports:  reports,
+		settings: settings,
+		users:    users,
+		mailer:   m,
+	}
+}
+
+// Status returns the strikes that count against userID and whether they
+// are restricted
+func (t *Tracker) Status(ctx context.Context, userID uuid.UUID) (model.StrikeStatus, error) {
+	policy, err := repository.LoadStrikePolicy(t.settings.WithContext(ctx))
+	if err != nil {
+		return model.StrikeStatus{}, err
+	}
+	strikes, err := t.strikes.WithContext(ctx).FindByUserID(userID, policy.Window(time.Now()))
+	if err != nil {
+		return model.StrikeStatus{}, err
+	}
+	return status(policy, userID, strikes), nil
+}
+
+// Statuses returns the status of every user with strikes that count, those
+// with the most first
+func (t *Tracker) Statuses(ctx context.Context) ([]model.StrikeStatus, error) {
+	policy, err := repository.LoadStrikePolicy(t.settings.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	strikes, err := t.strikes.WithContext(ctx).FindSince(policy.Window(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	var users []uuid.UUID
+	byUser := make(map[uuid.UUID][]model.Strike)
+	for _, s := range strikes {
+		if _, seen := byUser[s.UserID]; !seen {
+			users = append(users, s.UserID)
+		}
+		byUser[s.UserID] = append(byUser[s.UserID], s)
+	}
+	statuses := make([]model.StrikeStatus, len(users))
+	for i, id := range users {
+		statuses[i] = status(policy, id, byUser[id])
+	}
+	sort.SliceStable(statuses, func(i, j int) bool { return len(statuses[i].Strikes) > len(statuses[j].Strikes) })
+	return statuses, nil
+}
+
+func status(policy model.StrikePolicy, userID uuid.UUID, strikes []model.Strike) model.StrikeStatus {
+	if strikes == nil {
+		strikes = []model.Strike{}
+	}
+	return model.StrikeStatus{
+		UserID:          userID,
+		Strikes:         strikes,
+		Threshold:       policy.Threshold,
+		RestrictedUntil: policy.RestrictedUntil(strikes),
+	}
+}
+
+// Check returns a *RestrictedError when userID's strikes keep them from
+// adding bookings in rooms, adding is how many active bookings they would
+// gain
+func (t *Tracker) Check(ctx context.Context, userID uuid.UUID, rooms []uuid.UUID, adding int) error {
+	policy, err := repository.LoadStrikePolicy(t.settings.WithContext(ctx))
+	if err != nil || !policy.Enabled {
+		return err
+	}
+	now := time.Now()
+	strikes, err := t.strikes.WithContext(ctx).FindByUserID(userID, policy.Window(now))
+	if err != nil {
+		return err
+	}
+	until := policy.RestrictedUntil(strikes)
+	if until == nil {
+		return nil
+	}
+
+	if limit := policy.MaxActiveBookings; limit != nil && adding > 0 {
+		active, err := t.bookings.WithContext(ctx).CountActiveByUserID(userID, now)
+		if err != nil {
+			return err
+		}
+		if active+int64(adding) > int64(*limit) {
+			return &RestrictedError{Limit: fmt.Sprintf("you can have at most %d active bookings", *limit), Until: *until}
+		}
+	}
+
+	if policy.BlockPopularRooms && len(rooms) > 0 {
+		popular, err := t.popularRooms(ctx, policy, now)
+		if err != nil {
+			return err
+		}
+		for _, id := range rooms {
+			if popular[id] {
+				return &RestrictedError{Limit: "popular rooms cannot be booked", Until: *until}
+			}
+		}
+	}
+	return nil
+}
+
+// popularRooms returns the rooms booked for at least the policy's share of
+// their opening hours over the last PopularWindow
+func (t *Tracker) popularRooms(ctx context.Context, policy model.StrikePolicy, now time.Time) (map[uuid.UUID]bool, error) {
+	hours, err := repository.LoadOpeningHours(t.settings.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	loc := hours.Location()
+	start := now.Add(-model.PopularWindow).In(loc)
+	filter := model.UtilizationFilter{
+		From:     time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc),
+		To:       now,
+		GroupBy:  model.GroupByRoom,
+		Location: loc,
+	}
+
+	aggregates, err := t.reports.WithContext(ctx).Utilization(filter)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := t.rooms.WithContext(ctx).FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	popular := make(map[uuid.UUID]bool)
+	for _, row := range model.BuildUtilizationRows(filter, hours, rooms, aggregates) {
+		if row.BookedHours > 0 && row.Utilization >= policy.PopularUtilization {
+			id, err := uuid.Parse(row.Key)
+			if err != nil {
+				return nil, err
+			}
+			popular[id] = true
+		}
+	}
+	return popular, nil
+}
+
+// Cancelled records a strike for each booking cancelled within the
+// policy's late cancellation hours of its start, unless it was booked
+// within them. Callers leave out cancellations that should not count, such
+// as those made by admins. Failures are logged, the bookings have already
+// been cancelled.
+func (t *Tracker) Cancelled(ctx context.Context, bookings ...model.Booking) {
+	if len(bookings) == 0 {
+		return
+	}
+	log := logger.FromContext(ctx)
+	policy, err := repository.LoadStrikePolicy(t.settings.WithContext(ctx))
+	if err != nil {
+		log.Error("failed to load strike policy", "error", err)
+		return
+	}
+	if !policy.Enabled || policy.LateCancellationHours == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, b := range bookings {
+		late := b.StartTime.Add(-time.Duration(policy.LateCancellationHours) * time.Hour)
+		if now.Before(late) || !b.CreatedAt.Before(late) {
+			continue
+		}
+		if err := t.record(ctx, policy, b, model.StrikeLateCancellation, now); err != nil {
+			log.Error("failed to record strike", "error", err, "booking", b.ID)
+		}
+	}
+}
+
+// RecordNoShows records a strike for each booking that ended without a
+// check-in since the policy's window began
+func (t *Tracker) RecordNoShows(ctx context.Context) error {
+	policy, err := repository.LoadStrikePolicy(t.settings.WithContext(ctx))
+	if err != nil || !policy.Enabled {
+		return err
+	}
+	now := time.Now()
+	bookings, err := t.strikes.WithContext(ctx).FindNoShows(policy.Window(now), now)
+	if err != nil {
+		return err
+	}
+	for _, b := range bookings {
+		if err := t.record(ctx, policy, b, model.StrikeNoShow, b.EndTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run records the no-shows of every organization every interval until ctx
+// is cancelled
+func (t *Tracker) Run(ctx context.Context, orgs repository.OrganizationRepository, interval time.Duration) {
+	log := logger.FromContext(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		all, err := orgs.WithContext(ctx).FindAll()
+		if err != nil {
+			log.Warn("failed to list organizations for no-shows", "error", err)
+		}
+		for _, org := range all {
+			if err := t.RecordNoShows(tenant.WithContext(ctx, org.ID)); err != nil {
+				log.Warn("failed to record no-shows", "error", err, "organization", org.ID)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// record stores a strike against the organizer of b and emails them about
+// it, a booking that already has a strike gets no other
+func (t *Tracker) record(ctx context.Context, policy model.StrikePolicy, b model.Booking, reason model.StrikeReason, at time.Time) error {
+	strike := model.Strike{UserID: b.UserID, BookingID: b.ID, Reason: reason, OccurredAt: at}
+	created, err := t.strikes.WithContext(ctx).Create(&strike)
+	if err != nil || !created {
+		return err
+	}
+	log := logger.FromContext(ctx)
+	log.Info("strike recorded", "user", b.UserID, "booking", b.ID, "reason", reason)
+
+	strikes, err := t.strikes.WithContext(ctx).FindByUserID(b.UserID, policy.Window(time.Now()))
+	if err != nil {
+		return err
+	}
+	user, err := t.users.WithContext(ctx).FindByID(b.UserID.String())
+	if err != nil || user.Deactivated() {
+		return nil
+	}
+	if err := t.mailer.Send(ctx, strikeMessage(user, policy, b, reason, strikes)); err != nil {
+		log.Error("failed to send strike notification", "error", err, "user", user.ID)
+	}
+	return nil
+}
+
+// strikeMessage tells user about a new strike and what it means for their
+// bookings, with times in their timezone
+func strikeMessage(user *model.User, policy model.StrikePolicy, b model.Booking, reason model.StrikeReason, strikes []model.Strike) mailer.Message {
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	const layout = "Mon 2 Jan 2006 15:04 MST"
+
+	subject, what := "Meet Book strike for a missed booking", "ended without anyone checking in"
+	if reason == model.StrikeLateCancellation {
+		subject = "Meet Book strike for a late cancellation"
+		what = fmt.Sprintf("was cancelled less than %d hours before it started", policy.LateCancellationHours)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hi %s,\n\nYour booking of %s on %s %s, which counts as a strike. ",
+		user.Name, b.Room.Name, b.StartTime.In(loc).Format(layout), what)
+	fmt.Fprintf(&body, "You have %d of %d strikes within %d days.\n\n", len(strikes), policy.Threshold, policy.WindowDays)
+
+	if until := policy.RestrictedUntil(strikes); until != nil {
+		var limits []string
+		if policy.MaxActiveBookings != nil {
+			limits = append(limits, fmt.Sprintf("you can have at most %d active bookings", *policy.MaxActiveBookings))
+		}
+		if policy.BlockPopularRooms {
+			limits = append(limits, "you cannot book the most popular rooms")
+		}
+		fmt.Fprintf(&body, "Until %s %s.\n\n", until.In(loc).Format(layout), strings.Join(limits, " and "))
+	} else {
+		fmt.Fprintf(&body, "At %d strikes your bookings will be restricted for a while.\n\n", policy.Threshold)
+	}
+	fmt.Fprintf(&body, "Check in from %d minutes before your bookings start, and cancel early when plans change.\n", int(model.CheckInOpensBefore.Minutes()))
+
+	return mailer.Message{To: user.Email, Subject: subject, Body: body.String()}
+}
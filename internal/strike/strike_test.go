@@ -0,0 +1,295 @@
+package strike
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/mailer"
+	"github.com/riparuk/meet-book-api/internal/model"
+	"github.com/riparuk/meet-book-api/internal/repository"
+	"github.com/riparuk/meet-book-api/internal/repository/memory"
+)
+
+// recordingMailer keeps the messages sent through it
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *recordingMailer) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sent)
+}
+
+type fixture struct {
+	tracker  *Tracker
+	strikes  repository.StrikeRepository
+	bookings repository.BookingRepository
+	rooms    repository.RoomRepository
+	settings repository.SettingRepository
+	users    repository.UserRepository
+	mail     *recordingMailer
+	user     *model.User
+	room     *model.Room
+}
+
+// newFixture returns a tracker on memory repositories with one user and
+// one room, following policy
+func newFixture(t *testing.T, policy model.StrikePolicy) *fixture {
+	t.Helper()
+	store := memory.NewStore()
+	f := &fixture{
+		strikes:  memory.NewStrikeRepository(store),
+		bookings: memory.NewBookingRepository(store),
+		rooms:    memory.NewRoomRepository(store),
+		settings: memory.NewSettingRepository(store),
+		users:    memory.NewUserRepository(store),
+		mail:     &recordingMailer{},
+	}
+	f.tracker = NewTracker(f.strikes, f.bookings, f.rooms, memory.NewReportRepository(store), f.settings, f.users, f.mail)
+	f.setJSON(t, model.SettingStrikePolicy, policy)
+
+	f.user = &model.User{Name: "Alice", Email: "alice@example.com", Role: model.RoleUser}
+	if err := f.users.Create(f.user); err != nil {
+		t.Fatal(err)
+	}
+	f.room = f.newRoom(t, "Hall")
+	return f
+}
+
+func (f *fixture) setJSON(t *testing.T, key string, v any) {
+	t.Helper()
+	value, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.settings.Set(key, string(value)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f *fixture) newRoom(t *testing.T, name string) *model.Room {
+	t.Helper()
+	room := &model.Room{Name: name, Capacity: 8}
+	if err := f.rooms.Create(room); err != nil {
+		t.Fatal(err)
+	}
+	return room
+}
+
+// booking stores an active booking of room by the fixture's user, from
+// start for an hour, made at created
+func (f *fixture) booking(t *testing.T, room *model.Room, start, created time.Time) model.Booking {
+	t.Helper()
+	b := model.Booking{RoomID: room.ID, UserID: f.user.ID, StartTime: start, EndTime: start.Add(time.Hour), Status: model.BookingStatusActive, CreatedAt: created}
+	if err := f.bookings.Create(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// strike records n strikes against the fixture's user, the newest ago
+// before now
+func (f *fixture) strike(t *testing.T, n int, ago time.Duration) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		at := time.Now().Add(-ago - time.Duration(i)*time.Hour)
+		b := f.booking(t, f.room, at.Add(-time.Hour), at.Add(-48*time.Hour))
+		if _, err := f.strikes.Create(&model.Strike{UserID: f.user.ID, BookingID: b.ID, Reason: model.StrikeNoShow, OccurredAt: at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func (f *fixture) count(t *testing.T) int {
+	t.Helper()
+	strikes, err := f.strikes.FindSince(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(strikes)
+}
+
+// policy returns an enabled policy restricting users to maxActive active
+// bookings at two strikes within 30 days
+func policy(maxActive int) model.StrikePolicy {
+	since := time.Now().AddDate(0, 0, -60)
+	return model.StrikePolicy{
+		Enabled:               true,
+		Threshold:             2,
+		WindowDays:            30,
+		LateCancellationHours: 24,
+		MaxActiveBookings:     &maxActive,
+		PopularUtilization:    60,
+		Since:                 &since,
+	}
+}
+
+func TestCheck(t *testing.T) {
+	disabled := policy(0)
+	disabled.Enabled = false
+
+	cases := []struct {
+		name    string
+		policy  model.StrikePolicy
+		strikes int
+		// ago is how long before now the newest strike occurred
+		ago        time.Duration
+		active     int
+		adding     int
+		restricted bool
+	}{
+		{"BelowThreshold", policy(0), 1, time.Hour, 0, 1, false},
+		{"AtThreshold", policy(0), 2, time.Hour, 0, 1, true},
+		{"OutsideWindow", policy(0), 2, 31 * 24 * time.Hour, 0, 1, false},
+		{"Disabled", disabled, 2, time.Hour, 0, 1, false},
+		{"UnderMaxActive", policy(2), 2, time.Hour, 1, 1, false},
+		{"PastMaxActive", policy(2), 2, time.Hour, 2, 1, true},
+		// moving a booking adds none
+		{"AddingNone", policy(0), 2, time.Hour, 1, 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t, tc.policy)
+			f.strike(t, tc.strikes, tc.ago)
+			for i := 0; i < tc.active; i++ {
+				f.booking(t, f.room, time.Now().Add(time.Duration(24+i)*time.Hour), time.Now())
+			}
+
+			err := f.tracker.Check(context.Background(), f.user.ID, []uuid.UUID{f.room.ID}, tc.adding)
+			var restricted *RestrictedError
+			if errors.As(err, &restricted) != tc.restricted {
+				t.Fatalf("Check = %v, want restricted %v", err, tc.restricted)
+			}
+			if !tc.restricted && err != nil {
+				t.Fatalf("Check = %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckPopularRooms(t *testing.T) {
+	p := policy(0)
+	p.MaxActiveBookings, p.BlockPopularRooms, p.PopularUtilization = nil, true, 1
+	f := newFixture(t, p)
+	f.setJSON(t, model.SettingOpeningHours, model.OpeningHours{
+		Opens:    "00:00",
+		Closes:   "23:00",
+		Days:     []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+		Timezone: "UTC",
+	})
+	quiet := f.newRoom(t, "Quiet")
+	// ten hours over four weeks of 23 hour days is more than 1%
+	for i := 0; i < 10; i++ {
+		start := time.Now().Add(-time.Duration(48+i) * time.Hour)
+		b := f.booking(t, f.room, start, start.Add(-time.Hour))
+		now := b.EndTime
+		b.CheckedInAt = &now
+		if err := f.bookings.Update(&b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	if err := f.tracker.Check(ctx, f.user.ID, []uuid.UUID{f.room.ID}, 1); err != nil {
+		t.Fatalf("Check before strikes = %v, want nil", err)
+	}
+	f.strike(t, 2, time.Hour)
+
+	var restricted *RestrictedError
+	if err := f.tracker.Check(ctx, f.user.ID, []uuid.UUID{quiet.ID, f.room.ID}, 1); !errors.As(err, &restricted) {
+		t.Fatalf("Check popular room = %v, want restricted", err)
+	}
+	if err := f.tracker.Check(ctx, f.user.ID, []uuid.UUID{quiet.ID}, 1); err != nil {
+		t.Fatalf("Check quiet room = %v, want nil", err)
+	}
+}
+
+func TestCancelled(t *testing.T) {
+	now := time.Now()
+	noLateCancellations := policy(0)
+	noLateCancellations.LateCancellationHours = 0
+	disabled := policy(0)
+	disabled.Enabled = false
+
+	cases := []struct {
+		name    string
+		policy  model.StrikePolicy
+		start   time.Time
+		created time.Time
+		strike  bool
+	}{
+		{"Late", policy(0), now.Add(12 * time.Hour), now.AddDate(0, 0, -3), true},
+		{"Early", policy(0), now.Add(36 * time.Hour), now.AddDate(0, 0, -3), false},
+		// booked within the late cancellation hours to begin with
+		{"BookedLate", policy(0), now.Add(12 * time.Hour), now.Add(-time.Hour), false},
+		{"NoLateCancellations", noLateCancellations, now.Add(12 * time.Hour), now.AddDate(0, 0, -3), false},
+		{"Disabled", disabled, now.Add(12 * time.Hour), now.AddDate(0, 0, -3), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t, tc.policy)
+			b := f.booking(t, f.room, tc.start, tc.created)
+			f.tracker.Cancelled(context.Background(), b)
+
+			want := 0
+			if tc.strike {
+				want = 1
+			}
+			if got := f.count(t); got != want {
+				t.Fatalf("got %d strikes, want %d", got, want)
+			}
+			if got := f.mail.count(); got != want {
+				t.Fatalf("sent %d emails, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestRecordNoShows(t *testing.T) {
+	now := time.Now()
+	p := policy(0)
+	since := now.AddDate(0, 0, -2)
+	p.Since = &since
+
+	f := newFixture(t, p)
+	missed := f.booking(t, f.room, now.Add(-3*time.Hour), now.AddDate(0, 0, -1))
+	f.booking(t, f.room, now.AddDate(0, 0, -3), now.AddDate(0, 0, -4)) // before strikes were enabled
+	f.booking(t, f.room, now.Add(time.Hour), now)                      // yet to end
+	attended := f.booking(t, f.room, now.Add(-5*time.Hour), now.AddDate(0, 0, -1))
+	checkedIn := attended.StartTime
+	attended.CheckedInAt = &checkedIn
+	if err := f.bookings.Update(&attended); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := f.tracker.RecordNoShows(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	strikes, err := f.strikes.FindSince(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(strikes) != 1 || strikes[0].BookingID != missed.ID || !strikes[0].OccurredAt.Equal(missed.EndTime) {
+		t.Fatalf("strikes = %+v, want one for the missed booking", strikes)
+	}
+	if got := f.mail.count(); got != 1 {
+		t.Fatalf("sent %d emails, want 1", got)
+	}
+}
@@ -0,0 +1,26 @@
+// Package tenant carries the organization a request acts for through its
+// context. Repositories read it to scope every query to that organization.
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+)
+
+type ctxKey struct{}
+
+// WithContext returns a copy of ctx acting for organization id
+func WithContext(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the organization ctx acts for, or the default
+// organization when none was set
+func FromContext(ctx context.Context) uuid.UUID {
+	if id, ok := ctx.Value(ctxKey{}).(uuid.UUID); ok && id != uuid.Nil {
+		return id
+	}
+	return model.DefaultOrganizationID
+}
@@ -0,0 +1,107 @@
+// Package totp implements time-based one-time passwords (RFC 6238) with
+// the parameters authenticator apps expect: HMAC-SHA1, 6 digits and a 30
+// second period.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	Digits = 6
+	Period = 30 * time.Second
+	// Skew is the number of periods accepted either side of now, to allow
+	// for clock drift and slow typing
+	Skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a random 160 bit secret, base32 encoded
+func GenerateSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// Step returns the time step counter for t
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code returns the code for secret at time step
+func Code(secret string, step int64) (string, error) {
+	return hotp(secret, step, Digits)
+}
+
+// Validate checks code against the steps around now and returns the step
+// that matched. Steps at or before lastStep are rejected so a code can
+// only be used once.
+func Validate(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := Step(now)
+	for step := current - Skew; step <= current+Skew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		expected, err := Code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// ProvisioningURI returns the otpauth:// URI that authenticator apps
+// import, usually rendered as a QR code
+func ProvisioningURI(issuer, account, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(Digits))
+	params.Set("period", fmt.Sprint(int(Period/time.Second)))
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// hotp is the HMAC-based one-time password of RFC 4226
+func hotp(secret string, counter int64, digits int) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("invalid totp secret: %w", err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod), nil
+}
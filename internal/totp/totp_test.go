@@ -0,0 +1,65 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key from the RFC 6238 test vectors
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestRFC6238Vectors(t *testing.T) {
+	cases := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+	for unix, want := range cases {
+		got, err := hotp(rfcSecret, Step(time.Unix(unix, 0)), 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("t=%d: got %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := Code(rfcSecret, Step(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step, ok := Validate(rfcSecret, code, now, 0)
+	if !ok || step != Step(now) {
+		t.Fatalf("Validate = %d, %v", step, ok)
+	}
+	if _, ok := Validate(rfcSecret, code, now.Add(Period), 0); !ok {
+		t.Error("code from the previous period should be accepted")
+	}
+	if _, ok := Validate(rfcSecret, code, now.Add(3*Period), 0); ok {
+		t.Error("code outside the skew should be rejected")
+	}
+	if _, ok := Validate(rfcSecret, code, now, step); ok {
+		t.Error("a used step should be rejected")
+	}
+	if _, ok := Validate(rfcSecret, "12345", now, 0); ok {
+		t.Error("short codes should be rejected")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Meet Book", "alice@example.com", "JBSWY3DPEHPK3PXP")
+	for _, part := range []string{"otpauth://totp/Meet%20Book:alice@example.com?", "secret=JBSWY3DPEHPK3PXP", "issuer=Meet+Book", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("uri %q does not contain %q", uri, part)
+		}
+	}
+}
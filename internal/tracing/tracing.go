@@ -0,0 +1,90 @@
+// Package tracing configures OpenTelemetry tracing with an OTLP exporter.
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
+)
+
+const (
+	defaultServiceName  = "meet-book-api"
+	instrumentationName = "github.com/riparuk/meet-book-api"
+)
+
+// Tracer is used for the spans created by this module
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// ServiceName returns OTEL_SERVICE_NAME, defaulting to meet-book-api
+func ServiceName() string {
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		return name
+	}
+	return defaultServiceName
+}
+
+// Enabled reports whether an OTLP collector is configured. The standard
+// OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+// variables select the collector.
+func Enabled() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Init installs the global tracer provider and W3C trace context propagator.
+// The returned function flushes pending spans and must be called on shutdown.
+// Without a configured collector spans are still propagated but not exported.
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !Enabled() {
+		slog.Info("OTEL_EXPORTER_OTLP_ENDPOINT not set, traces are not exported")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := ServiceName()
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	slog.Info("tracing enabled", "service", serviceName)
+	return provider.Shutdown, nil
+}
+
+// InstrumentDB adds a span for every GORM query. Query arguments are left
+// out of the recorded SQL so no user data ends up in traces.
+func InstrumentDB(db *gorm.DB) error {
+	return db.Use(gormtracing.NewPlugin(
+		gormtracing.WithoutQueryVariables(),
+		gormtracing.WithoutMetrics(),
+	))
+}
@@ -3,7 +3,6 @@ package utils
 import (
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -15,10 +14,31 @@ import (
 	"github.com/riparuk/meet-book-api/internal/repository"
 )
 
-const (
-	tokenExpiration = 24 * time.Hour
-	tokenTypeBearer = "Bearer"
-)
+const tokenTypeBearer = "Bearer"
+
+// challengePurpose marks tokens that only prove the password step of a
+// two-factor login, they are never accepted as access tokens
+const challengePurpose = "2fa_challenge"
+
+// ChallengeTTL is how long a user has to enter their second factor
+const ChallengeTTL = 5 * time.Minute
+
+// challengeAudience keeps challenge tokens from being accepted by services
+// that verify our access tokens with the JWKS
+const challengeAudience = "2fa-challenge"
+
+// JWTOptions configures how tokens are signed and verified
+type JWTOptions struct {
+	// Keys signs tokens with RS256 or EdDSA. Without it tokens are signed
+	// with HS256 and Secret, which only this API can verify.
+	Keys     *KeySet
+	Secret   string
+	Issuer   string
+	Audience string
+	TTL      time.Duration
+}
+
+var jwtOptions = JWTOptions{TTL: 24 * time.Hour}
 
 var (
 	ErrInvalidToken         = errors.New("invalid or expired token")
@@ -28,39 +48,86 @@ var (
 	ErrUserNotFound         = errors.New("user not found")
 )
 
-// getJWTSecret retrieves the JWT secret from environment variables
-func getJWTSecret() ([]byte, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return nil, errors.New("JWT_SECRET environment variable not set")
+// ConfigureJWT sets the signing keys, issuer, audience and lifetime of
+// generated tokens, it must be called once at startup
+func ConfigureJWT(opts JWTOptions) {
+	if opts.TTL <= 0 {
+		opts.TTL = jwtOptions.TTL
 	}
-	return []byte(secret), nil
+	jwtOptions = opts
 }
 
-// GenerateJWT creates a new JWT token for the given user ID and role
-func GenerateJWT(userID string, role model.UserRole) (string, error) {
-	if userID == "" {
-		return "", errors.New("user ID cannot be empty")
+// PublicKeys returns the keys other services verify our tokens with, the
+// set is empty when tokens are signed with a shared secret
+func PublicKeys() JWKS {
+	if jwtOptions.Keys == nil {
+		return JWKS{Keys: []JWK{}}
 	}
+	return jwtOptions.Keys.JWKS()
+}
 
-	if _, err := uuid.Parse(userID); err != nil {
-		return "", fmt.Errorf("invalid user ID format: %w", err)
+// signJWT signs claims with the signing key, or the secret without keys
+func signJWT(claims jwt.MapClaims) (string, error) {
+	claims["iss"] = jwtOptions.Issuer
+	if jwtOptions.Keys != nil {
+		return jwtOptions.Keys.sign(claims)
+	}
+	if len(jwtOptions.Secret) == 0 {
+		return "", errors.New("JWT signing keys not configured")
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(jwtOptions.Secret))
+}
+
+// parseJWT verifies the signature, expiry, issuer and audience of a token
+func parseJWT(tokenString, audience string) (jwt.MapClaims, error) {
+	keyFunc := func(*jwt.Token) (interface{}, error) {
+		return []byte(jwtOptions.Secret), nil
+	}
+	methods := []string{jwt.SigningMethodHS256.Alg()}
+	if jwtOptions.Keys != nil {
+		keyFunc = jwtOptions.Keys.keyFunc
+		methods = jwtOptions.Keys.methods()
+	} else if len(jwtOptions.Secret) == 0 {
+		return nil, errors.New("JWT signing keys not configured")
 	}
 
-	secret, err := getJWTSecret()
+	token, err := jwt.Parse(tokenString, keyFunc,
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(jwtOptions.Issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
-		return "", fmt.Errorf("failed to get JWT secret: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
 	}
 
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"role":    role,
-		"exp":     time.Now().Add(tokenExpiration).Unix(),
-		"iat":     time.Now().Unix(),
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidClaims
+	}
+	return claims, nil
+}
+
+// GenerateJWT creates a new JWT token for the given user ID and role in
+// organization orgID
+func GenerateJWT(userID string, role model.UserRole, orgID uuid.UUID) (string, error) {
+	if userID == "" {
+		return "", errors.New("user ID cannot be empty")
+	}
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return "", fmt.Errorf("invalid user ID format: %w", err)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(secret)
+	now := time.Now()
+	tokenString, err := signJWT(jwt.MapClaims{
+		"sub":  userID,
+		"aud":  jwtOptions.Audience,
+		"role": role,
+		"org":  orgID.String(),
+		"exp":  now.Add(jwtOptions.TTL).Unix(),
+		"iat":  now.Unix(),
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -68,51 +135,122 @@ func GenerateJWT(userID string, role model.UserRole) (string, error) {
 	return tokenString, nil
 }
 
+// Claims are the verified contents of an access token
+type Claims struct {
+	UserID         string
+	Role           model.UserRole
+	OrganizationID uuid.UUID
+	IssuedAt       time.Time
+}
+
 // ValidateJWT validates the JWT token and returns the user ID and role if valid
 func ValidateJWT(tokenString string) (string, model.UserRole, error) {
+	claims, err := ParseJWT(tokenString)
+	if err != nil {
+		return "", "", err
+	}
+	return claims.UserID, claims.Role, nil
+}
+
+// ParseJWT validates the JWT token, including its issuer and audience, and
+// returns its claims
+func ParseJWT(tokenString string) (*Claims, error) {
 	if tokenString == "" {
-		return "", "", ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
 	// Remove 'Bearer ' prefix if present
 	tokenString = strings.TrimPrefix(tokenString, tokenTypeBearer+" ")
 
-	secret, err := getJWTSecret()
+	claims, err := parseJWT(tokenString, jwtOptions.Audience)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get JWT secret: %w", err)
+		return nil, err
 	}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("%w: %v", ErrInvalidSigningMethod, token.Header["alg"])
-		}
-		return secret, nil
-	})
+	userID, err := claims.GetSubject()
+	if err != nil || userID == "" {
+		return nil, ErrInvalidUserID
+	}
 
-	if err != nil {
-		return "", "", fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	if _, ok := claims["purpose"]; ok {
+		return nil, fmt.Errorf("%w: not an access token", ErrInvalidClaims)
 	}
 
-	if !token.Valid {
-		return "", "", ErrInvalidToken
+	roleStr, ok := claims["role"].(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid role type", ErrInvalidClaims)
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
-		return "", "", ErrInvalidClaims
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return nil, fmt.Errorf("%w: missing iat", ErrInvalidClaims)
 	}
 
-	userID, ok := claims["user_id"].(string)
-	if !ok || userID == "" {
-		return "", "", ErrInvalidUserID
+	orgID, err := organizationClaim(claims)
+	if err != nil {
+		return nil, err
 	}
 
-	roleStr, ok := claims["role"].(string)
+	return &Claims{UserID: userID, Role: model.UserRole(roleStr), OrganizationID: orgID, IssuedAt: issuedAt.Time}, nil
+}
+
+// organizationClaim returns the organization a token was issued in, tokens
+// from before organizations existed belong to the default one
+func organizationClaim(claims jwt.MapClaims) (uuid.UUID, error) {
+	raw, ok := claims["org"]
+	if !ok {
+		return model.DefaultOrganizationID, nil
+	}
+	s, ok := raw.(string)
 	if !ok {
-		return "", "", fmt.Errorf("%w: invalid role type", ErrInvalidClaims)
+		return uuid.Nil, fmt.Errorf("%w: invalid org type", ErrInvalidClaims)
+	}
+	orgID, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid org", ErrInvalidClaims)
+	}
+	return orgID, nil
+}
+
+// GenerateChallengeJWT creates a short lived token for a user of
+// organization orgID who passed the password step and still has to provide
+// a second factor
+func GenerateChallengeJWT(userID string, orgID uuid.UUID) (string, error) {
+	now := time.Now()
+	tokenString, err := signJWT(jwt.MapClaims{
+		"sub":     userID,
+		"org":     orgID.String(),
+		"aud":     challengeAudience,
+		"purpose": challengePurpose,
+		"exp":     now.Add(ChallengeTTL).Unix(),
+		"iat":     now.Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// ParseChallengeJWT validates a challenge token and returns the user ID
+// and their organization
+func ParseChallengeJWT(tokenString string) (string, uuid.UUID, error) {
+	claims, err := parseJWT(tokenString, challengeAudience)
+	if err != nil {
+		return "", uuid.Nil, ErrInvalidToken
+	}
+	if claims["purpose"] != challengePurpose {
+		return "", uuid.Nil, ErrInvalidClaims
 	}
 
-	return userID, model.UserRole(roleStr), nil
+	userID, err := claims.GetSubject()
+	if err != nil || userID == "" {
+		return "", uuid.Nil, ErrInvalidUserID
+	}
+	orgID, err := organizationClaim(claims)
+	if err != nil {
+		return "", uuid.Nil, err
+	}
+	return userID, orgID, nil
 }
 
 // GetUserAuth retrieves the authenticated user from the Gin context
@@ -127,7 +265,7 @@ func GetUserAuth(c *gin.Context) (*model.User, error) {
 		return nil, fmt.Errorf("%w: %v", ErrInvalidUserID, err)
 	}
 
-	userRepo := repository.NewUserRepository(database.DB)
+	userRepo := repository.NewUserRepository(database.DB).WithContext(c.Request.Context())
 	user, err := userRepo.FindByID(userID.String())
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrUserNotFound, err)
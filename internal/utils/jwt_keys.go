@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is one private key of a KeySet
+type signingKey struct {
+	id     string
+	method jwt.SigningMethod
+	key    crypto.Signer
+}
+
+// KeySet holds the keys access tokens are signed and verified with. The
+// first key signs new tokens, every key verifies, so a key can be published
+// before it signs and kept after it stops until its tokens have expired.
+type KeySet struct {
+	keys []signingKey
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS is the public half of a KeySet, served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadKeySet reads PEM encoded RSA or Ed25519 private keys, the first one
+// signs
+func LoadKeySet(files []string) (*KeySet, error) {
+	if len(files) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+
+	ks := &KeySet{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read signing key: %w", err)
+		}
+		key, err := ParseSigningKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", file, err)
+		}
+		if err := ks.Add(key); err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", file, err)
+		}
+	}
+	return ks, nil
+}
+
+// ParseSigningKey parses a PEM encoded PKCS #8 or PKCS #1 private key
+func ParseSigningKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	return signer, nil
+}
+
+// Add appends a key, the key ID is its RFC 7638 thumbprint
+func (ks *KeySet) Add(key crypto.Signer) error {
+	var method jwt.SigningMethod
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return errors.New("RSA keys must be at least 2048 bits")
+		}
+		method = jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return fmt.Errorf("unsupported key type %T, use RSA or Ed25519", key)
+	}
+
+	sk := signingKey{method: method, key: key}
+	jwk := sk.publicJWK()
+	for _, existing := range ks.keys {
+		if existing.id == jwk.KeyID {
+			return errors.New("duplicate signing key")
+		}
+	}
+	sk.id = jwk.KeyID
+	ks.keys = append(ks.keys, sk)
+	return nil
+}
+
+// JWKS returns the public keys
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, len(ks.keys))}
+	for i, k := range ks.keys {
+		set.Keys[i] = k.publicJWK()
+	}
+	return set
+}
+
+// SigningKeyID returns the ID of the key new tokens are signed with
+func (ks *KeySet) SigningKeyID() string {
+	return ks.keys[0].id
+}
+
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	k := ks.keys[0]
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.id
+	return token.SignedString(k.key)
+}
+
+// keyFunc returns the public key matching the token's kid header
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	for _, k := range ks.keys {
+		if k.id == kid {
+			if token.Method.Alg() != k.method.Alg() {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidSigningMethod, token.Header["alg"])
+			}
+			return k.key.Public(), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (ks *KeySet) methods() []string {
+	var algs []string
+	for _, k := range ks.keys {
+		algs = append(algs, k.method.Alg())
+	}
+	return algs
+}
+
+// publicJWK returns the public key with its thumbprint as key ID
+func (k signingKey) publicJWK() JWK {
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{Use: "sig", Algorithm: k.method.Alg()}
+
+	// The thumbprint hashes the required members in lexicographic order
+	var thumbprint []byte
+	switch pub := k.key.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType, jwk.N, jwk.E = "RSA", b64(pub.N.Bytes()), b64(big.NewInt(int64(pub.E)).Bytes())
+		thumbprint, _ = json.Marshal(struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N})
+	case ed25519.PublicKey:
+		jwk.KeyType, jwk.Curve, jwk.X = "OKP", "Ed25519", b64(pub)
+		thumbprint, _ = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X})
+	}
+
+	sum := sha256.Sum256(thumbprint)
+	jwk.KeyID = b64(sum[:])
+	return jwk
+}
@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/riparuk/meet-book-api/internal/model"
+)
+
+func newEd25519(t *testing.T) crypto.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newRSA(t *testing.T) crypto.Signer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func keySet(t *testing.T, keys ...crypto.Signer) *KeySet {
+	t.Helper()
+	ks := &KeySet{}
+	for _, k := range keys {
+		if err := ks.Add(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ks
+}
+
+func configure(t *testing.T, opts JWTOptions) {
+	t.Helper()
+	if opts.Issuer == "" {
+		opts.Issuer, opts.Audience = "meet-book-api", "meet-book-api"
+	}
+	previous := jwtOptions
+	ConfigureJWT(opts)
+	t.Cleanup(func() { jwtOptions = previous })
+}
+
+func TestJWTRoundTrip(t *testing.T) {
+	userID := uuid.NewString()
+	orgID := uuid.New()
+	cases := map[string]JWTOptions{
+		"HS256": {Secret: "k8Qw3nV0pZ7rT2yB5mX9cL1fH4jD6sGa"},
+		"EdDSA": {Keys: keySet(t, newEd25519(t))},
+		"RS256": {Keys: keySet(t, newRSA(t))},
+	}
+	for name, opts := range cases {
+		t.Run(name, func(t *testing.T) {
+			configure(t, opts)
+
+			token, err := GenerateJWT(userID, model.RoleAdmin, orgID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims, err := ParseJWT(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.UserID != userID || claims.Role != model.RoleAdmin || claims.OrganizationID != orgID {
+				t.Fatalf("claims = %+v", claims)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			mapClaims := parsed.Claims.(jwt.MapClaims)
+			if mapClaims["sub"] != userID || mapClaims["iss"] != "meet-book-api" || mapClaims["aud"] != "meet-book-api" {
+				t.Errorf("standard claims = %v", mapClaims)
+			}
+			if opts.Keys != nil && parsed.Header["kid"] != opts.Keys.SigningKeyID() {
+				t.Errorf("kid = %v, want %s", parsed.Header["kid"], opts.Keys.SigningKeyID())
+			}
+		})
+	}
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	oldKey, newKey := newEd25519(t), newRSA(t)
+	userID := uuid.NewString()
+
+	configure(t, JWTOptions{Keys: keySet(t, oldKey)})
+	token, err := GenerateJWT(userID, model.RoleUser, model.DefaultOrganizationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The new key signs, the old one still verifies until it is removed
+	configure(t, JWTOptions{Keys: keySet(t, newKey, oldKey)})
+	if _, err := ParseJWT(token); err != nil {
+		t.Fatalf("token of the previous key rejected during overlap: %v", err)
+	}
+	if got := len(PublicKeys().Keys); got != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", got)
+	}
+
+	configure(t, JWTOptions{Keys: keySet(t, newKey)})
+	if _, err := ParseJWT(token); err == nil {
+		t.Fatal("token of a removed key accepted")
+	}
+}
+
+func TestJWTRejectsWrongIssuerAndAudience(t *testing.T) {
+	keys := keySet(t, newEd25519(t))
+	configure(t, JWTOptions{Keys: keys, Issuer: "other-issuer", Audience: "meet-book-api"})
+	token, err := GenerateJWT(uuid.NewString(), model.RoleUser, model.DefaultOrganizationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configure(t, JWTOptions{Keys: keys, Issuer: "meet-book-api", Audience: "meet-book-api"})
+	if _, err := ParseJWT(token); err == nil {
+		t.Error("token from another issuer accepted")
+	}
+
+	configure(t, JWTOptions{Keys: keys, Issuer: "meet-book-api", Audience: "other-service"})
+	token, err = GenerateJWT(uuid.NewString(), model.RoleUser, model.DefaultOrganizationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configure(t, JWTOptions{Keys: keys, Issuer: "meet-book-api", Audience: "meet-book-api"})
+	if _, err := ParseJWT(token); err == nil {
+		t.Error("token for another audience accepted")
+	}
+}
+
+func TestChallengeTokenIsNotAnAccessToken(t *testing.T) {
+	configure(t, JWTOptions{Keys: keySet(t, newEd25519(t))})
+	userID := uuid.NewString()
+
+	orgID := uuid.New()
+
+	challenge, err := GenerateChallengeJWT(userID, orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseJWT(challenge); err == nil {
+		t.Error("challenge token accepted as access token")
+	}
+	if got, gotOrg, err := ParseChallengeJWT(challenge); err != nil || got != userID || gotOrg != orgID {
+		t.Errorf("ParseChallengeJWT = %q, %v, %v", got, gotOrg, err)
+	}
+
+	access, err := GenerateJWT(userID, model.RoleUser, model.DefaultOrganizationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ParseChallengeJWT(access); err == nil {
+		t.Error("access token accepted as challenge token")
+	}
+}
+
+func TestParseSigningKey(t *testing.T) {
+	rsaKey := newRSA(t).(*rsa.PrivateKey)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(newEd25519(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]*pem.Block{
+		"PKCS8 Ed25519": {Type: "PRIVATE KEY", Bytes: pkcs8},
+		"PKCS1 RSA":     {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+	}
+	for name, block := range cases {
+		key, err := ParseSigningKey(pem.EncodeToMemory(block))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if err := (&KeySet{}).Add(key); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	if _, err := ParseSigningKey([]byte("not a key")); err == nil {
+		t.Error("expected an error for data without a PEM block")
+	}
+}
+
+func TestJWTWithoutOrganizationBelongsToDefault(t *testing.T) {
+	configure(t, JWTOptions{Secret: "k8Qw3nV0pZ7rT2yB5mX9cL1fH4jD6sGa"})
+
+	// Tokens issued before organizations existed carry no org claim
+	now := time.Now()
+	token, err := signJWT(jwt.MapClaims{
+		"sub":  uuid.NewString(),
+		"aud":  jwtOptions.Audience,
+		"role": model.RoleUser,
+		"exp":  now.Add(time.Hour).Unix(),
+		"iat":  now.Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ParseJWT(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.OrganizationID != model.DefaultOrganizationID {
+		t.Fatalf("OrganizationID = %v, want the default organization", claims.OrganizationID)
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateOpaqueToken returns a random URL safe token to hand to the user
+// and its hash to store, so a database leak doesn't expose usable tokens
+func GenerateOpaqueToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hex SHA-256 of an opaque token. Tokens carry 256
+// bits of randomness so a fast hash is enough.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyPrefix marks API keys so they can be told apart from JWTs and
+// picked up by secret scanners
+const APIKeyPrefix = "mbk_"
+
+// GenerateAPIKey returns a new API key and its hash to store
+func GenerateAPIKey() (key, hash string, err error) {
+	token, _, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	key = APIKeyPrefix + token
+	return key, HashToken(key), nil
+}